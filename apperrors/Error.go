@@ -0,0 +1,96 @@
+// Package apperrors holds the errors the repositories and services report.
+// Each has a stable Code the frontend can switch on instead of comparing
+// messages, which stay human readable and unchanged.
+package apperrors
+
+import "errors"
+
+const (
+	// CodeNotFound: the entity does not exist or is in the trash.
+	CodeNotFound = "not_found"
+	// CodeAlreadyExists: a unique value is taken, possibly by an entry in the trash.
+	CodeAlreadyExists = "already_exists"
+	// CodeConflict: the entity was changed since it was read; Details hold
+	// the stored entity.
+	CodeConflict = "conflict"
+	// CodeValidation: the input is invalid; Fields map field names to messages.
+	CodeValidation = "validation"
+	// CodeIntegrity: the change would break a relation, e.g. deleting a bus
+	// still assigned to routes; Details hold the blocking entities.
+	CodeIntegrity = "integrity"
+	// CodeInternal is reported for errors that are not an *Error.
+	CodeInternal = "internal"
+)
+
+// Error is a domain error. Entity is one of the models.Entity constants, or
+// empty when the error is not about a single entity type.
+type Error struct {
+	Code    string
+	Entity  string
+	Message string
+	Fields  map[string]string
+	Details any
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the kind sentinels below by code, so that
+// errors.Is(err, apperrors.ErrNotFound) holds for every not found error.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Message == "" && t.Code == e.Code
+}
+
+var (
+	ErrNotFound      = &Error{Code: CodeNotFound}
+	ErrAlreadyExists = &Error{Code: CodeAlreadyExists}
+	ErrConflict      = &Error{Code: CodeConflict}
+	ErrValidation    = &Error{Code: CodeValidation}
+	ErrIntegrity     = &Error{Code: CodeIntegrity}
+)
+
+func NotFound(entity, message string) *Error {
+	return &Error{Code: CodeNotFound, Entity: entity, Message: message}
+}
+
+func AlreadyExists(entity, message string) *Error {
+	return &Error{Code: CodeAlreadyExists, Entity: entity, Message: message}
+}
+
+// Conflict reports a stale update; current is the entity as stored now.
+func Conflict(entity, message string, current any) *Error {
+	return &Error{Code: CodeConflict, Entity: entity, Message: message, Details: current}
+}
+
+// Validation reports invalid input; fields may be nil.
+func Validation(entity, message string, fields map[string]string) *Error {
+	return &Error{Code: CodeValidation, Entity: entity, Message: message, Fields: fields}
+}
+
+// Integrity reports a change refused because of related entities, listed in
+// details.
+func Integrity(entity, message string, details any) *Error {
+	return &Error{Code: CodeIntegrity, Entity: entity, Message: message, Details: details}
+}
+
+// Wrap sets the underlying cause of e and returns it.
+func (e *Error) Wrap(err error) *Error {
+	e.Err = err
+	return e
+}
+
+// CodeOf returns the code of err, CodeInternal when it is not an *Error.
+func CodeOf(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return CodeInternal
+}
@@ -0,0 +1,34 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestError_Is(t *testing.T) {
+	err := fmt.Errorf("saving: %w", NotFound("bus", "Bus not found"))
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected wrapped error to match ErrNotFound")
+	}
+	if errors.Is(err, ErrConflict) {
+		t.Errorf("Expected not found error not to match ErrConflict")
+	}
+	if CodeOf(err) != CodeNotFound {
+		t.Errorf("Expected code %s, got %s", CodeNotFound, CodeOf(err))
+	}
+	if CodeOf(errors.New("disk full")) != CodeInternal {
+		t.Errorf("Expected code %s for a plain error", CodeInternal)
+	}
+}
+
+func TestError_Wrap(t *testing.T) {
+	cause := errors.New("cause")
+	err := Validation("bus", "Invalid register number", map[string]string{"RegisterNumber": "Invalid register number"}).Wrap(cause)
+	if !errors.Is(err, cause) || !errors.Is(err, ErrValidation) {
+		t.Errorf("Expected error to match its cause and ErrValidation")
+	}
+	if err.Error() != "Invalid register number" {
+		t.Errorf("Expected message to be kept, got %q", err.Error())
+	}
+}
@@ -0,0 +1,34 @@
+// Package calendar finds the calendar days and weeks of times in the time
+// zone of the depot. Days start at local midnight, so across a daylight
+// saving change a day lasts 23 or 25 hours; weeks start on Monday.
+package calendar
+
+import "time"
+
+// Day returns the midnight starting the day of t in loc. A nil loc means
+// time.Local.
+func Day(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.Local
+	}
+	year, month, day := t.In(loc).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, loc)
+}
+
+// Bounds returns the start of the day of t in loc and the start of the next.
+func Bounds(t time.Time, loc *time.Location) (from, to time.Time) {
+	from = Day(t, loc)
+	return from, from.AddDate(0, 0, 1)
+}
+
+// Week returns the midnight starting the Monday of the week of t in loc.
+func Week(t time.Time, loc *time.Location) time.Time {
+	day := Day(t, loc)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// Month returns the midnight starting the first day of the month of t in loc.
+func Month(t time.Time, loc *time.Location) time.Time {
+	day := Day(t, loc)
+	return day.AddDate(0, 0, 1-day.Day())
+}
@@ -0,0 +1,55 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+)
+
+var moscow = time.FixedZone("MSK", 3*60*60)
+
+func TestDay(t *testing.T) {
+	// 22:30 UTC on May 6 is already May 7 in Moscow.
+	late := time.Date(2024, 5, 6, 22, 30, 0, 0, time.UTC)
+	if got := Day(late, time.UTC); !got.Equal(time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected May 6 in UTC, got %v", got)
+	}
+	if got := Day(late, moscow); !got.Equal(time.Date(2024, 5, 7, 0, 0, 0, 0, moscow)) {
+		t.Errorf("Expected May 7 in Moscow, got %v", got)
+	}
+	if got := Day(late, nil); !got.Equal(Day(late, time.Local)) {
+		t.Errorf("Expected nil to mean the local zone, got %v", got)
+	}
+}
+
+func TestBounds_DaylightSaving(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("No time zone database:", err)
+	}
+	from, to := Bounds(time.Date(2024, 3, 31, 12, 0, 0, 0, berlin), berlin)
+	if to.Sub(from) != 23*time.Hour || to.Day() != 1 || to.Hour() != 0 {
+		t.Errorf("Expected the 23 hour day of the clock change, got %v to %v", from, to)
+	}
+}
+
+func TestWeek(t *testing.T) {
+	// Sunday 23:00 in Moscow is Sunday 20:00 UTC, Monday 01:00 in Moscow is
+	// still Sunday in UTC.
+	sunday := time.Date(2024, 5, 12, 23, 0, 0, 0, moscow)
+	if got := Week(sunday, moscow); !got.Equal(time.Date(2024, 5, 6, 0, 0, 0, 0, moscow)) {
+		t.Errorf("Expected the week of Monday May 6, got %v", got)
+	}
+	monday := time.Date(2024, 5, 13, 1, 0, 0, 0, moscow)
+	if got := Week(monday, moscow); !got.Equal(time.Date(2024, 5, 13, 0, 0, 0, 0, moscow)) {
+		t.Errorf("Expected the week of Monday May 13 in Moscow, got %v", got)
+	}
+	if got := Week(monday, time.UTC); !got.Equal(time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the week of Monday May 6 in UTC, got %v", got)
+	}
+}
+
+func TestMonth(t *testing.T) {
+	if got := Month(time.Date(2024, 4, 30, 22, 0, 0, 0, time.UTC), moscow); !got.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, moscow)) {
+		t.Errorf("Expected May in Moscow, got %v", got)
+	}
+}
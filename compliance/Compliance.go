@@ -0,0 +1,117 @@
+// Package compliance checks the shifts of a driver against working time
+// limits: driving time per day and per week, continuous driving without a
+// break and rest between shifts. Days and weeks are calendar days and weeks
+// starting on Monday in the zone of the depot; a shift counts towards the day
+// it starts on.
+package compliance
+
+import (
+	"busManager/calendar"
+	"busManager/models"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Limits struct {
+	MaxDailyDriving      time.Duration
+	MaxWeeklyDriving     time.Duration
+	MaxContinuousDriving time.Duration
+	// MinBreak is the shortest break that interrupts continuous driving.
+	MinBreak time.Duration
+	MinRest  time.Duration
+	// Location is the zone in which days and weeks start; nil means
+	// time.Local.
+	Location *time.Location
+}
+
+// DefaultLimits follow the rules for bus drivers in Russia, without the
+// exceptions that allow longer days a few times a week.
+var DefaultLimits = Limits{
+	MaxDailyDriving:      9 * time.Hour,
+	MaxWeeklyDriving:     56 * time.Hour,
+	MaxContinuousDriving: 4*time.Hour + 30*time.Minute,
+	MinBreak:             15 * time.Minute,
+	MinRest:              11 * time.Hour,
+}
+
+// Check returns the violations in the shifts of one driver, ordered by
+// shift start.
+func (l Limits) Check(shifts []models.Shift) []models.Violation {
+	shifts = append([]models.Shift(nil), shifts...)
+	sort.SliceStable(shifts, func(i, j int) bool { return shifts[i].Start.Before(shifts[j].Start) })
+
+	var violations []models.Violation
+	daily := map[time.Time]time.Duration{}
+	weekly := map[time.Time]time.Duration{}
+	for i, shift := range shifts {
+		if i > 0 {
+			if rest := shift.Start.Sub(shifts[i-1].End); rest < l.MinRest {
+				violations = append(violations, models.Violation{Rule: models.RuleRestBetweenShifts, ShiftID: shift.ID,
+					Message: fmt.Sprintf("Rest before the shift starting %s is %s, less than %s", l.stamp(shift.Start), hours(rest), hours(l.MinRest))})
+			}
+		}
+		if longest := l.longestDriving(shift); longest > l.MaxContinuousDriving {
+			violations = append(violations, models.Violation{Rule: models.RuleContinuousDriving, ShiftID: shift.ID,
+				Message: fmt.Sprintf("Shift starting %s has %s of driving without a break, more than %s", l.stamp(shift.Start), hours(longest), hours(l.MaxContinuousDriving))})
+		}
+
+		driving := shift.DrivingTime()
+		day := calendar.Day(shift.Start, l.Location)
+		before := daily[day]
+		daily[day] += driving
+		if before <= l.MaxDailyDriving && daily[day] > l.MaxDailyDriving {
+			violations = append(violations, models.Violation{Rule: models.RuleDailyDriving, ShiftID: shift.ID,
+				Message: fmt.Sprintf("Driving time on %s is %s, more than %s", day.Format(time.DateOnly), hours(daily[day]), hours(l.MaxDailyDriving))})
+		}
+		week := calendar.Week(shift.Start, l.Location)
+		before = weekly[week]
+		weekly[week] += driving
+		if before <= l.MaxWeeklyDriving && weekly[week] > l.MaxWeeklyDriving {
+			violations = append(violations, models.Violation{Rule: models.RuleWeeklyDriving, ShiftID: shift.ID,
+				Message: fmt.Sprintf("Driving time in the week of %s is %s, more than %s", week.Format(time.DateOnly), hours(weekly[week]), hours(l.MaxWeeklyDriving))})
+		}
+	}
+	return violations
+}
+
+// longestDriving returns the longest stretch of the shift not interrupted by
+// a break of at least MinBreak. Shorter breaks are not driving time but do
+// not end the stretch.
+func (l Limits) longestDriving(shift models.Shift) time.Duration {
+	var longest, stretch time.Duration
+	last := shift.Start
+	for _, b := range shift.Breaks {
+		stretch += b.Start.Sub(last)
+		last = b.End
+		if b.Duration() >= l.MinBreak {
+			longest = max(longest, stretch)
+			stretch = 0
+		}
+	}
+	return max(longest, stretch+shift.End.Sub(last))
+}
+
+// stamp formats t in Location.
+func (l Limits) stamp(t time.Time) string {
+	loc := l.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	return t.In(loc).Format("2006-01-02 15:04")
+}
+
+// hours formats d as "9h", "4h30m" or "45m".
+func hours(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if d == 0 {
+		return "0m"
+	}
+	s := d.String()
+	s = strings.TrimSuffix(s, "0s")
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,102 @@
+package compliance
+
+import (
+	"busManager/models"
+	"testing"
+	"time"
+)
+
+// at returns a time day days after Monday 2024-05-06.
+func at(day, hour, minute int) time.Time {
+	return time.Date(2024, 5, 6+day, hour, minute, 0, 0, time.UTC)
+}
+
+// utcLimits are DefaultLimits counting days in UTC, like the times of at.
+var utcLimits = func() Limits {
+	l := DefaultLimits
+	l.Location = time.UTC
+	return l
+}()
+
+func shift(id string, start, end time.Time, breaks ...models.Break) models.Shift {
+	return models.Shift{ID: id, DriverID: "d", RouteID: "r", Start: start, End: end, Breaks: breaks}
+}
+
+func rules(violations []models.Violation) []string {
+	var got []string
+	for _, v := range violations {
+		got = append(got, v.ShiftID+":"+v.Rule)
+	}
+	return got
+}
+
+func TestLimits_Check(t *testing.T) {
+	lunch := models.Break{Start: at(0, 10, 0), End: at(0, 10, 45)}
+	tests := []struct {
+		name   string
+		shifts []models.Shift
+		want   []string
+	}{
+		{"Compliant day", []models.Shift{shift("a", at(0, 6, 0), at(0, 14, 0), lunch)}, nil},
+		{"Long day", []models.Shift{
+			shift("a", at(0, 6, 0), at(0, 10, 0)),
+			shift("b", at(0, 10, 30), at(0, 14, 30)),
+			shift("c", at(0, 15, 0), at(0, 17, 0)),
+		}, []string{"b:rest_between_shifts", "c:rest_between_shifts", "c:daily_driving"}},
+		{"No break", []models.Shift{shift("a", at(0, 6, 0), at(0, 11, 0))}, []string{"a:continuous_driving"}},
+		{"Short break does not count", []models.Shift{
+			shift("a", at(0, 6, 0), at(0, 11, 0), models.Break{Start: at(0, 8, 0), End: at(0, 8, 10)}),
+		}, []string{"a:continuous_driving"}},
+		{"Short rest", []models.Shift{
+			shift("a", at(0, 12, 0), at(0, 20, 0), models.Break{Start: at(0, 15, 0), End: at(0, 15, 30)}),
+			shift("b", at(1, 6, 0), at(1, 10, 0)),
+		}, []string{"b:rest_between_shifts"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules(utcLimits.Check(tt.shifts))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+
+	t.Run("Weekly driving", func(t *testing.T) {
+		var shifts []models.Shift
+		for day := 0; day < 7; day++ {
+			shifts = append(shifts, shift(string(rune('a'+day)), at(day, 6, 0), at(day, 14, 45),
+				models.Break{Start: at(day, 10, 0), End: at(day, 10, 30)}))
+		}
+		violations := utcLimits.Check(shifts)
+		if len(violations) != 1 || violations[0].ShiftID != "g" || violations[0].Rule != models.RuleWeeklyDriving {
+			t.Fatalf("Expected the seventh shift to exceed the week, got %v", rules(violations))
+		}
+		if violations[0].Message != "Driving time in the week of 2024-05-06 is 57h45m, more than 56h" {
+			t.Errorf("Unexpected message %q", violations[0].Message)
+		}
+	})
+}
+
+func TestLimits_Location(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	msk := func(day, hour, minute int) time.Time { return time.Date(2024, 5, 6+day, hour, minute, 0, 0, moscow) }
+	// The early shift starts at 02:00 in Moscow, which is still the day before in UTC.
+	shifts := []models.Shift{
+		shift("a", msk(0, 2, 0), msk(0, 9, 0), models.Break{Start: msk(0, 4, 30), End: msk(0, 5, 0)}),
+		shift("b", msk(0, 20, 0), msk(0, 23, 30)),
+	}
+	if got := rules(utcLimits.Check(shifts)); len(got) != 0 {
+		t.Errorf("Expected the shifts on two days in UTC, got %v", got)
+	}
+	limits := DefaultLimits
+	limits.Location = moscow
+	violations := limits.Check(shifts)
+	if len(violations) != 1 || violations[0].ShiftID != "b" || violations[0].Message != "Driving time on 2024-05-06 is 10h, more than 9h" {
+		t.Errorf("Expected the shifts on one day in Moscow, got %+v", violations)
+	}
+}
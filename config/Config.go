@@ -0,0 +1,341 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const appName = "busManager"
+
+// Environment variables overriding values from the configuration file.
+const (
+	EnvConfigFile = "BUSMANAGER_CONFIG"
+	EnvDataDir    = "BUSMANAGER_DATA_DIR"
+	EnvDBFile     = "BUSMANAGER_DB_FILE"
+	EnvLogLevel   = "BUSMANAGER_LOG_LEVEL"
+	EnvUser       = "BUSMANAGER_USER"
+	// EnvFeatures is a comma separated list, "-name" switches a feature off.
+	EnvFeatures = "BUSMANAGER_FEATURES"
+)
+
+// FeatureDemo runs the application on an in-memory store with sample data
+// instead of the database file.
+const FeatureDemo = "demo"
+
+// DeletePolicies holds the delete policy ("restrict" or "cascade") per entity.
+type DeletePolicies struct {
+	Bus     string `json:"bus"`
+	Driver  string `json:"driver"`
+	BusStop string `json:"busStop"`
+}
+
+// Validate fails on a policy other than "restrict" or "cascade"; case and
+// surrounding spaces do not matter.
+func (p DeletePolicies) Validate() error {
+	for _, policy := range []struct{ entity, value string }{{"bus", p.Bus}, {"driver", p.Driver}, {"busStop", p.BusStop}} {
+		switch strings.ToLower(strings.TrimSpace(policy.value)) {
+		case "restrict", "cascade":
+		default:
+			return fmt.Errorf("Unknown delete policy for %s: %q", policy.entity, policy.value)
+		}
+	}
+	return nil
+}
+
+// Backup configures the scheduled database backups. An empty Dir means
+// "backups" inside the data directory; an Interval of "0" or "" disables the
+// schedule, backups can still be made on demand.
+type Backup struct {
+	Dir      string `json:"dir"`
+	Keep     int    `json:"keep"`
+	Interval string `json:"interval"`
+}
+
+type Config struct {
+	DataDir        string          `json:"dataDir"`
+	DBFile         string          `json:"dbFile"`
+	LogLevel       string          `json:"logLevel"`
+	Features       map[string]bool `json:"features"`
+	DeletePolicies DeletePolicies  `json:"deletePolicies"`
+	Backup         Backup          `json:"backup"`
+	// User is recorded as the author of changes in the audit log. Empty
+	// means the operating system user.
+	User string `json:"user"`
+	// TimeZone is the IANA name of the zone of the depot, in which working
+	// days start and end. Empty means the zone of the computer.
+	TimeZone string `json:"timeZone"`
+}
+
+func Default() *Config {
+	return &Config{
+		DataDir:  DefaultDataDir(),
+		DBFile:   "db.db",
+		LogLevel: "info",
+		Features: map[string]bool{},
+		DeletePolicies: DeletePolicies{
+			Bus:     "restrict",
+			Driver:  "restrict",
+			BusStop: "restrict",
+		},
+		Backup: Backup{
+			Keep:     7,
+			Interval: "24h",
+		},
+	}
+}
+
+// DefaultDataDir is the per-user data directory: $XDG_DATA_HOME/busManager
+// (~/.local/share/busManager) on Linux and the user config dir elsewhere.
+func DefaultDataDir() string {
+	if runtime.GOOS == "linux" {
+		if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+			return filepath.Join(dir, appName)
+		}
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, ".local", "share", appName)
+		}
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, appName)
+	}
+	return "."
+}
+
+// DefaultFile is the location of config.json when BUSMANAGER_CONFIG is not set.
+func DefaultFile() string {
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, appName, "config.json")
+	}
+	return "config.json"
+}
+
+// Load builds the configuration from defaults, the configuration file and
+// environment overrides, in that order. A missing file is not an error.
+func Load() (*Config, error) {
+	path := os.Getenv(EnvConfigFile)
+	if path == "" {
+		path = DefaultFile()
+	}
+	cfg := Default()
+	if err := cfg.loadFile(path); err != nil {
+		return nil, err
+	}
+	cfg.applyEnv()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("Invalid config file %s: %w", path, err)
+	}
+	if c.Features == nil {
+		c.Features = map[string]bool{}
+	}
+	return nil
+}
+
+func (c *Config) applyEnv() {
+	if v := os.Getenv(EnvDataDir); v != "" {
+		c.DataDir = v
+	}
+	if v := os.Getenv(EnvDBFile); v != "" {
+		c.DBFile = v
+	}
+	if v := os.Getenv(EnvLogLevel); v != "" {
+		c.LogLevel = v
+	}
+	if v := os.Getenv(EnvUser); v != "" {
+		c.User = v
+	}
+	for _, name := range strings.Split(os.Getenv(EnvFeatures), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if strings.HasPrefix(name, "-") {
+			c.Features[strings.TrimPrefix(name, "-")] = false
+		} else {
+			c.Features[name] = true
+		}
+	}
+}
+
+func (c *Config) Validate() error {
+	if strings.TrimSpace(c.DBFile) == "" {
+		return errors.New("Database file cant be empty")
+	}
+	if _, err := c.SlogLevel(); err != nil {
+		return err
+	}
+	if c.Backup.Keep < 1 {
+		return errors.New("Backup keep must be at least 1")
+	}
+	if _, err := c.BackupInterval(); err != nil {
+		return err
+	}
+	if _, err := c.Location(); err != nil {
+		return err
+	}
+	return c.DeletePolicies.Validate()
+}
+
+// DBPath returns DBFile, resolved against DataDir unless it is absolute.
+func (c *Config) DBPath() string {
+	if filepath.IsAbs(c.DBFile) {
+		return c.DBFile
+	}
+	return filepath.Join(c.DataDir, c.DBFile)
+}
+
+// EnsureDataDir creates the directory holding the database file.
+func (c *Config) EnsureDataDir() error {
+	return os.MkdirAll(filepath.Dir(c.DBPath()), 0o755)
+}
+
+// LegacyDBFile is where versions before the per-user data directory kept the
+// database, relative to the working directory.
+const LegacyDBFile = "db.db"
+
+// MigrateLegacyDB copies the database of an earlier version, together with
+// its -wal and -shm files, to DBPath when nothing is there yet. The old files
+// are left in place. It returns the copied path, or "" when there was nothing
+// to migrate.
+func (c *Config) MigrateLegacyDB() (string, error) {
+	return c.migrateDB(LegacyDBFile)
+}
+
+func (c *Config) migrateDB(legacy string) (string, error) {
+	from, err := filepath.Abs(legacy)
+	if err != nil {
+		return "", err
+	}
+	to, err := filepath.Abs(c.DBPath())
+	if err != nil {
+		return "", err
+	}
+	if from == to {
+		return "", nil
+	}
+	if _, err := os.Stat(to); !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	if _, err := os.Stat(from); errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := copyFile(from+suffix, to+suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+	// The database file goes last and appears at once, so that an interrupted
+	// copy is simply repeated on the next start.
+	if err := copyFile(from, to+".tmp"); err != nil {
+		return "", err
+	}
+	if err := os.Rename(to+".tmp", to); err != nil {
+		return "", err
+	}
+	return from, nil
+}
+
+func copyFile(from, to string) error {
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.Create(to)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// BackupDir returns Backup.Dir, resolved against DataDir unless it is absolute.
+func (c *Config) BackupDir() string {
+	if c.Backup.Dir == "" {
+		return filepath.Join(c.DataDir, "backups")
+	}
+	if filepath.IsAbs(c.Backup.Dir) {
+		return c.Backup.Dir
+	}
+	return filepath.Join(c.DataDir, c.Backup.Dir)
+}
+
+// BackupInterval parses Backup.Interval; 0 means no scheduled backups.
+func (c *Config) BackupInterval() (time.Duration, error) {
+	if c.Backup.Interval == "" || c.Backup.Interval == "0" {
+		return 0, nil
+	}
+	interval, err := time.ParseDuration(c.Backup.Interval)
+	if err != nil || interval < 0 {
+		return 0, errors.New("Invalid backup interval: " + c.Backup.Interval)
+	}
+	return interval, nil
+}
+
+// Location loads TimeZone, or returns time.Local when it is empty.
+func (c *Config) Location() (*time.Location, error) {
+	if c.TimeZone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return nil, errors.New("Unknown time zone: " + c.TimeZone)
+	}
+	return loc, nil
+}
+
+// AuditUser returns User or, when it is empty, the operating system user.
+func (c *Config) AuditUser() string {
+	if c.User != "" {
+		return c.User
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return "unknown"
+}
+
+func (c *Config) Enabled(feature string) bool {
+	return c.Features[feature]
+}
+
+func (c *Config) SlogLevel() (slog.Level, error) {
+	switch strings.ToLower(c.LogLevel) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, errors.New("Unknown log level: " + c.LogLevel)
+}
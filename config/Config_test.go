@@ -0,0 +1,267 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func clearEnv(t *testing.T) {
+	for _, name := range []string{EnvConfigFile, EnvDataDir, EnvDBFile, EnvLogLevel, EnvUser, EnvFeatures} {
+		t.Setenv(name, "")
+	}
+}
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Defaults without file", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv(EnvConfigFile, filepath.Join(t.TempDir(), "missing.json"))
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if cfg.DBFile != "db.db" || cfg.LogLevel != "info" {
+			t.Errorf("Expected defaults, got %+v", cfg)
+		}
+		if cfg.DeletePolicies.Bus != "restrict" {
+			t.Errorf("Expected restrict delete policy, got %s", cfg.DeletePolicies.Bus)
+		}
+	})
+
+	t.Run("Values from file", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv(EnvConfigFile, writeConfig(t, `{
+			"dataDir": "/var/lib/busManager",
+			"logLevel": "debug",
+			"features": {"backup": true},
+			"deletePolicies": {"bus": "cascade"}
+		}`))
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if cfg.DBPath() != filepath.Join("/var/lib/busManager", "db.db") {
+			t.Errorf("Expected db path in data dir, got %s", cfg.DBPath())
+		}
+		if !cfg.Enabled("backup") {
+			t.Errorf("Expected backup feature to be enabled")
+		}
+		if cfg.DeletePolicies.Bus != "cascade" || cfg.DeletePolicies.Driver != "restrict" {
+			t.Errorf("Expected file values merged with defaults, got %+v", cfg.DeletePolicies)
+		}
+	})
+
+	t.Run("Environment overrides file", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv(EnvConfigFile, writeConfig(t, `{"dbFile": "file.db", "features": {"backup": true}}`))
+		t.Setenv(EnvDataDir, "/tmp/data")
+		t.Setenv(EnvDBFile, "env.db")
+		t.Setenv(EnvLogLevel, "warn")
+		t.Setenv(EnvFeatures, "demo, -backup")
+
+		cfg, err := Load()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if cfg.DBPath() != filepath.Join("/tmp/data", "env.db") {
+			t.Errorf("Expected env db path, got %s", cfg.DBPath())
+		}
+		if level, _ := cfg.SlogLevel(); level != slog.LevelWarn {
+			t.Errorf("Expected warn level, got %v", level)
+		}
+		if !cfg.Enabled("demo") || cfg.Enabled("backup") {
+			t.Errorf("Expected demo on and backup off, got %v", cfg.Features)
+		}
+	})
+
+	t.Run("Invalid file", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv(EnvConfigFile, writeConfig(t, `{"dbFile": `))
+
+		if _, err := Load(); err == nil {
+			t.Errorf("Expected error for invalid file")
+		}
+	})
+
+	t.Run("Invalid delete policy", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv(EnvConfigFile, writeConfig(t, `{"deletePolicies": {"bus": "cascde"}}`))
+
+		if _, err := Load(); err == nil || err.Error() != `Unknown delete policy for bus: "cascde"` {
+			t.Errorf("Expected error for unknown delete policy, got %v", err)
+		}
+	})
+
+	t.Run("Invalid log level", func(t *testing.T) {
+		clearEnv(t)
+		t.Setenv(EnvConfigFile, filepath.Join(t.TempDir(), "missing.json"))
+		t.Setenv(EnvLogLevel, "loud")
+
+		if _, err := Load(); err == nil {
+			t.Errorf("Expected error for invalid log level")
+		}
+	})
+}
+
+func TestConfig_DBPath(t *testing.T) {
+	cfg := Default()
+	cfg.DataDir = "/data"
+	cfg.DBFile = "/abs/other.db"
+	if cfg.DBPath() != "/abs/other.db" {
+		t.Errorf("Expected absolute db file to be used as is, got %s", cfg.DBPath())
+	}
+}
+
+func TestDefaultDataDir(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/xdg")
+	dir := DefaultDataDir()
+	if dir == "" {
+		t.Fatalf("Expected default data dir")
+	}
+	if filepath.Base(dir) != "busManager" {
+		t.Errorf("Expected per-app directory, got %s", dir)
+	}
+}
+
+func TestConfig_Backup(t *testing.T) {
+	cfg := Default()
+	cfg.DataDir = "/data"
+	if cfg.BackupDir() != filepath.Join("/data", "backups") {
+		t.Errorf("Expected backups in data dir, got %s", cfg.BackupDir())
+	}
+	if interval, err := cfg.BackupInterval(); err != nil || interval.Hours() != 24 {
+		t.Errorf("Expected daily backups, got %v (%v)", interval, err)
+	}
+
+	cfg.Backup.Interval = "0"
+	if interval, err := cfg.BackupInterval(); err != nil || interval != 0 {
+		t.Errorf("Expected disabled schedule, got %v (%v)", interval, err)
+	}
+
+	cfg.Backup.Interval = "often"
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("Expected error for invalid backup interval")
+	}
+
+	cfg.Backup.Interval = "1h"
+	cfg.Backup.Keep = 0
+	if err := cfg.Validate(); err == nil {
+		t.Errorf("Expected error for keep below 1")
+	}
+}
+
+func TestConfig_Location(t *testing.T) {
+	cfg := Default()
+	if loc, err := cfg.Location(); err != nil || loc != time.Local {
+		t.Errorf("Expected the local zone, got %v (%v)", loc, err)
+	}
+	cfg.TimeZone = "UTC"
+	if loc, err := cfg.Location(); err != nil || loc.String() != "UTC" {
+		t.Errorf("Expected UTC, got %v (%v)", loc, err)
+	}
+	cfg.TimeZone = "Europe/Moskow"
+	if err := cfg.Validate(); err == nil || err.Error() != "Unknown time zone: Europe/Moskow" {
+		t.Errorf("Expected error for unknown time zone, got %v", err)
+	}
+}
+
+func TestConfig_DeletePolicies(t *testing.T) {
+	cfg := Default()
+	cfg.DeletePolicies.BusStop = " Cascade "
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	cfg.DeletePolicies.Driver = "cascde"
+	if err := cfg.Validate(); err == nil || err.Error() != `Unknown delete policy for driver: "cascde"` {
+		t.Errorf("Expected error for unknown delete policy, got %v", err)
+	}
+}
+
+func TestConfig_AuditUser(t *testing.T) {
+	cfg := Default()
+	if cfg.AuditUser() == "" {
+		t.Errorf("Expected operating system user")
+	}
+	cfg.User = "dispatcher"
+	if cfg.AuditUser() != "dispatcher" {
+		t.Errorf("Expected configured user, got %s", cfg.AuditUser())
+	}
+}
+
+func TestConfig_MigrateLegacyDB(t *testing.T) {
+	newConfig := func(t *testing.T) (*Config, string) {
+		legacy := filepath.Join(t.TempDir(), "db.db")
+		for name, content := range map[string]string{legacy: "database", legacy + "-wal": "wal"} {
+			if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		}
+		cfg := Default()
+		cfg.DataDir = filepath.Join(t.TempDir(), "data")
+		if err := cfg.EnsureDataDir(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return cfg, legacy
+	}
+
+	t.Run("Copies the legacy database", func(t *testing.T) {
+		cfg, legacy := newConfig(t)
+		if from, err := cfg.migrateDB(legacy); err != nil || from != legacy {
+			t.Fatalf("Expected %s to be copied, got %q (%v)", legacy, from, err)
+		}
+		for name, want := range map[string]string{cfg.DBPath(): "database", cfg.DBPath() + "-wal": "wal"} {
+			if data, err := os.ReadFile(name); err != nil || string(data) != want {
+				t.Errorf("Expected %s to contain %q, got %q (%v)", name, want, data, err)
+			}
+		}
+		if _, err := os.Stat(legacy); err != nil {
+			t.Errorf("Expected the legacy database to be kept, got %v", err)
+		}
+		if _, err := os.Stat(cfg.DBPath() + "-shm"); !os.IsNotExist(err) {
+			t.Errorf("Expected no -shm file, got %v", err)
+		}
+	})
+
+	t.Run("Keeps an existing database", func(t *testing.T) {
+		cfg, legacy := newConfig(t)
+		if err := os.WriteFile(cfg.DBPath(), []byte("current"), 0o644); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if from, err := cfg.migrateDB(legacy); err != nil || from != "" {
+			t.Errorf("Expected nothing to be copied, got %q (%v)", from, err)
+		}
+		if data, _ := os.ReadFile(cfg.DBPath()); string(data) != "current" {
+			t.Errorf("Expected the database to be kept, got %q", data)
+		}
+	})
+
+	t.Run("No legacy database", func(t *testing.T) {
+		cfg, _ := newConfig(t)
+		if from, err := cfg.migrateDB(filepath.Join(t.TempDir(), "db.db")); err != nil || from != "" {
+			t.Errorf("Expected nothing to be copied, got %q (%v)", from, err)
+		}
+		if _, err := os.Stat(cfg.DBPath()); !os.IsNotExist(err) {
+			t.Errorf("Expected no database, got %v", err)
+		}
+	})
+
+	t.Run("Same file", func(t *testing.T) {
+		cfg, legacy := newConfig(t)
+		cfg.DBFile = legacy
+		if from, err := cfg.migrateDB(legacy); err != nil || from != "" {
+			t.Errorf("Expected nothing to be copied, got %q (%v)", from, err)
+		}
+	})
+}
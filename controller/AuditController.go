@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"busManager/models"
+	"busManager/service"
+	"strings"
+	"time"
+)
+
+type AuditController struct {
+	as service.IAuditService
+}
+
+func NewAuditController(as service.AuditService) *AuditController {
+	return &AuditController{as}
+}
+
+func (ac AuditController) Query(filter models.AuditFilter) ([]models.AuditEntry, error) {
+	return ac.as.Query(filter)
+}
+
+func (ac AuditController) GetByEntity(entityType, id string) ([]models.AuditEntry, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, required("", "ID", "ID cant be null")
+	}
+	return ac.as.GetByEntity(entityType, id)
+}
+
+func (ac AuditController) GetByRoute(routeId string) ([]models.AuditEntry, error) {
+	if strings.TrimSpace(routeId) == "" {
+		return nil, required("", "ID", "ID cant be null")
+	}
+	return ac.as.GetByRoute(routeId)
+}
+
+// GetByTimeRange returns the entries between from and to; a zero bound is
+// open.
+func (ac AuditController) GetByTimeRange(from, to time.Time) ([]models.AuditEntry, error) {
+	return ac.as.GetByTimeRange(from, to)
+}
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"busManager/models"
+	"busManager/service"
+	"strings"
+)
+
+type BackupController struct {
+	bs service.IBackupService
+}
+
+func NewBackupController(bs service.BackupService) *BackupController {
+	return &BackupController{bs}
+}
+
+func (bc BackupController) Create() (*models.Backup, error) {
+	return bc.bs.Create()
+}
+
+func (bc BackupController) List() ([]models.Backup, error) {
+	return bc.bs.List()
+}
+
+func (bc BackupController) Restore(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return required("", "Name", "Name cant be null")
+	}
+	return bc.bs.Restore(name)
+}
@@ -2,10 +2,7 @@ package controller
 
 import (
 	"busManager/models"
-	"busManager/responses"
 	"busManager/service"
-	"encoding/json"
-	"errors"
 	"strings"
 )
 
@@ -17,80 +14,100 @@ func NewBusController(bs service.BusService) *BusController {
 	return &BusController{bs}
 }
 
-func (bc BusController) GetById(id string) string {
+func (bc BusController) GetById(id string) (*models.Bus, error) {
 	if strings.TrimSpace(id) == "" {
-		return responses.NewJsonError(errors.New("ID cant be null"))
+		return nil, required(models.EntityBus, "ID", "ID cant be null")
 	}
-	data, err := bc.bs.GetById(id)
-	if err != nil {
-		return responses.NewJsonError(err)
-	}
-	jsonData, err := json.MarshalIndent(data, "", "    ")
-	if err != nil {
-		return responses.NewJsonError(err)
-	}
-	return string(jsonData)
+	return bc.bs.GetById(id)
 }
 
-func (bc BusController) GetByNumber(number string) string {
+func (bc BusController) GetByNumber(number string) (*models.Bus, error) {
 	if strings.TrimSpace(number) == "" {
-		return responses.NewJsonError(errors.New("Number cant be null"))
+		return nil, required(models.EntityBus, "RegisterNumber", "Number cant be null")
 	}
-	data, err := bc.bs.GetByNumber(number)
-	if err != nil {
-		return responses.NewJsonError(err)
+	return bc.bs.GetByNumber(number)
+}
+
+func (bc BusController) GetAll() ([]models.Bus, error) {
+	return bc.bs.GetAll()
+}
+
+func (bc BusController) GetAllWithInvalidNumber() ([]models.Bus, error) {
+	return bc.bs.GetAllWithInvalidNumber()
+}
+
+// Add returns the bus as stored, with its generated ID and version.
+func (bc BusController) Add(bus models.Bus) (*models.Bus, error) {
+	if err := bc.bs.Add(&bus); err != nil {
+		return nil, err
 	}
-	jsonData, err := json.MarshalIndent(data, "", "    ")
-	if err != nil {
-		return responses.NewJsonError(err)
+	return &bus, nil
+}
+
+func (bc BusController) DeleteById(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return required(models.EntityBus, "ID", "ID cant be null")
 	}
-	return string(jsonData)
+	return bc.bs.DeleteById(id)
 }
 
-func (bc BusController) GetAll() string {
-	data := bc.bs.GetAll()
-	jsonData, err := json.MarshalIndent(data, "", "    ")
-	if err != nil {
-		return responses.NewJsonError(err)
+func (bc BusController) List(query models.ListQuery) (models.Page[models.Bus], error) {
+	return bc.bs.List(query)
+}
+
+func (bc BusController) GetAllDeleted() ([]models.Trashed[models.Bus], error) {
+	return bc.bs.GetAllDeleted()
+}
+
+func (bc BusController) RestoreById(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return required(models.EntityBus, "ID", "ID cant be null")
 	}
-	return string(jsonData)
+	return bc.bs.RestoreById(id)
 }
 
-func (bc BusController) Add(busData string) string {
-	byteBus := []byte(busData)
-	var bus models.Bus
-	err := json.Unmarshal(byteBus, &bus)
-	if err != nil {
-		return responses.NewJsonError(err)
+func (bc BusController) PurgeById(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return required(models.EntityBus, "ID", "ID cant be null")
+	}
+	return bc.bs.PurgeById(id)
+}
+
+func (bc BusController) DeleteByIdWithPolicy(id, policy string) error {
+	if strings.TrimSpace(id) == "" {
+		return required(models.EntityBus, "ID", "ID cant be null")
 	}
-	err = bc.bs.Add(&bus)
+	deletePolicy, err := service.ParseDeletePolicy(policy)
 	if err != nil {
-		return responses.NewJsonError(err)
+		return err
 	}
-	return busData
+	return bc.bs.DeleteByIdWithPolicy(id, deletePolicy)
 }
 
-func (bc BusController) DeleteById(id string) string {
+func (bc BusController) GetAllRoutesById(id string) ([]models.Route, error) {
 	if strings.TrimSpace(id) == "" {
-		return responses.NewJsonError(errors.New("ID cant be null"))
+		return nil, required(models.EntityBus, "ID", "ID cant be null")
 	}
-	err := bc.bs.DeleteById(id)
-	if err != nil {
-		return responses.NewJsonError(err)
+	return bc.bs.GetAllRoutesById(id)
+}
+
+func (bc BusController) UpdateById(bus models.Bus) (*models.Bus, error) {
+	if err := bc.bs.UpdateById(&bus); err != nil {
+		return nil, err
 	}
-	return ""
+	return &bus, nil
 }
 
-func (bc BusController) UpdateById(busData string) string {
-	byteBus := []byte(busData)
-	var bus models.Bus
-	err := json.Unmarshal(byteBus, &bus)
-	if err != nil {
-		return responses.NewJsonError(err)
+func (bc BusController) ChangeStatus(id, status, reason string) (*models.Bus, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, required(models.EntityBus, "ID", "ID cant be null")
 	}
-	err = bc.bs.UpdateById(&bus)
-	if err != nil {
-		return responses.NewJsonError(err)
+	return bc.bs.ChangeStatus(id, status, reason)
+}
+
+func (bc BusController) GetStatusHistoryById(id string) ([]models.BusStatusChange, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, required(models.EntityBus, "ID", "ID cant be null")
 	}
-	return busData
+	return bc.bs.GetStatusHistoryById(id)
 }
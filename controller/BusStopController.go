@@ -2,10 +2,7 @@ package controller
 
 import (
 	"busManager/models"
-	"busManager/responses"
 	"busManager/service"
-	"encoding/json"
-	"errors"
 	"strings"
 )
 
@@ -17,83 +14,82 @@ func NewBusStopController(bss service.IBusStopService) *BusStopController {
 	return &BusStopController{bss}
 }
 
-func (bsc BusStopController) GetById(id string) string {
+func (bsc BusStopController) GetById(id string) (*models.BusStop, error) {
 	if strings.TrimSpace(id) == "" {
-		return responses.NewJsonError(errors.New("ID cant be null"))
+		return nil, required(models.EntityBusStop, "ID", "ID cant be null")
 	}
-	data, err := bsc.bss.GetById(id)
-	if err != nil {
-		return responses.NewJsonError(err)
-	}
-	jsonData, err := json.MarshalIndent(data, "", "    ")
-	if err != nil {
-		return responses.NewJsonError(err)
-	}
-	return string(jsonData)
+	return bsc.bss.GetById(id)
 }
 
-func (bsc BusStopController) GetByName(name string) string {
+func (bsc BusStopController) GetByName(name string) (*models.BusStop, error) {
 	if strings.TrimSpace(name) == "" {
-		return responses.NewJsonError(errors.New("Name cant be null"))
-	}
-	data, err := bsc.bss.GetByName(name)
-	if err != nil {
-		return responses.NewJsonError(err)
-	}
-	jsonData, err := json.MarshalIndent(data, "", "    ")
-	if err != nil {
-		return responses.NewJsonError(err)
+		return nil, required(models.EntityBusStop, "Name", "Name cant be null")
 	}
-	return string(jsonData)
+	return bsc.bss.GetByName(name)
 }
 
-func (bsc BusStopController) GetAll() string {
-	data, err := bsc.bss.GetAll()
-	if err != nil {
-		return responses.NewJsonError(err)
+func (bsc BusStopController) GetAll() ([]models.BusStop, error) {
+	return bsc.bss.GetAll()
+}
+
+// Add returns the bus stop as stored, with its generated ID and version.
+func (bsc BusStopController) Add(busStop models.BusStop) (*models.BusStop, error) {
+	if err := bsc.bss.Add(&busStop); err != nil {
+		return nil, err
 	}
-	jsonData, err := json.MarshalIndent(data, "", "    ")
-	if err != nil {
-		return responses.NewJsonError(err)
+	return &busStop, nil
+}
+
+func (bsc BusStopController) DeleteById(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return required(models.EntityBusStop, "ID", "ID cant be null")
 	}
-	return string(jsonData)
+	return bsc.bss.DeleteById(id)
 }
 
-func (bsc BusStopController) Add(busStopData string) string {
-	byteBusStop := []byte(busStopData)
-	var busStop models.BusStop
-	err := json.Unmarshal(byteBusStop, &busStop)
-	if err != nil {
-		return responses.NewJsonError(err)
+func (bsc BusStopController) List(query models.ListQuery) (models.Page[models.BusStop], error) {
+	return bsc.bss.List(query)
+}
+
+func (bsc BusStopController) GetAllDeleted() ([]models.Trashed[models.BusStop], error) {
+	return bsc.bss.GetAllDeleted()
+}
+
+func (bsc BusStopController) RestoreById(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return required(models.EntityBusStop, "ID", "ID cant be null")
 	}
-	err = bsc.bss.Add(&busStop)
-	if err != nil {
-		return responses.NewJsonError(err)
+	return bsc.bss.RestoreById(id)
+}
+
+func (bsc BusStopController) PurgeById(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return required(models.EntityBusStop, "ID", "ID cant be null")
 	}
-	return busStopData
+	return bsc.bss.PurgeById(id)
 }
 
-func (bsc BusStopController) DeleteById(id string) string {
+func (bsc BusStopController) DeleteByIdWithPolicy(id, policy string) error {
 	if strings.TrimSpace(id) == "" {
-		return responses.NewJsonError(errors.New("ID cant be null"))
+		return required(models.EntityBusStop, "ID", "ID cant be null")
 	}
-	err := bsc.bss.DeleteById(id)
+	deletePolicy, err := service.ParseDeletePolicy(policy)
 	if err != nil {
-		return responses.NewJsonError(err)
+		return err
 	}
-	return ""
+	return bsc.bss.DeleteByIdWithPolicy(id, deletePolicy)
 }
 
-func (bsc BusStopController) UpdateById(busStopData string) string {
-	byteBusStop := []byte(busStopData)
-	var busStop models.BusStop
-	err := json.Unmarshal(byteBusStop, &busStop)
-	if err != nil {
-		return responses.NewJsonError(err)
+func (bsc BusStopController) GetAllRoutesById(id string) ([]models.Route, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, required(models.EntityBusStop, "ID", "ID cant be null")
 	}
-	err = bsc.bss.UpdateById(&busStop)
-	if err != nil {
-		return responses.NewJsonError(err)
+	return bsc.bss.GetAllRoutesById(id)
+}
+
+func (bsc BusStopController) UpdateById(busStop models.BusStop) (*models.BusStop, error) {
+	if err := bsc.bss.UpdateById(&busStop); err != nil {
+		return nil, err
 	}
-	return busStopData
+	return &busStop, nil
 }
@@ -2,10 +2,7 @@ package controller
 
 import (
 	"busManager/models"
-	"busManager/responses"
 	"busManager/service"
-	"encoding/json"
-	"errors"
 	"strings"
 )
 
@@ -17,80 +14,97 @@ func NewDriverController(ds service.DriverService) *DriverController {
 	return &DriverController{ds}
 }
 
-func (dc DriverController) GetById(id string) string {
+func (dc DriverController) GetById(id string) (*models.Driver, error) {
 	if strings.TrimSpace(id) == "" {
-		return responses.NewJsonError(errors.New("ID cant be null"))
+		return nil, required(models.EntityDriver, "ID", "ID cant be null")
 	}
-	data, err := dc.ds.GetById(id)
-	if err != nil {
-		return responses.NewJsonError(err)
-	}
-	jsonData, err := json.MarshalIndent(data, "", "    ")
-	if err != nil {
-		return responses.NewJsonError(err)
-	}
-	return string(jsonData)
+	return dc.ds.GetById(id)
 }
 
-func (dc DriverController) GetByPassportSeries(series string) string {
+func (dc DriverController) GetByPassportSeries(series string) (*models.Driver, error) {
 	if strings.TrimSpace(series) == "" {
-		return responses.NewJsonError(errors.New("PassportSeries cant be null"))
+		return nil, required(models.EntityDriver, "PassportSeries", "PassportSeries cant be null")
 	}
-	data, err := dc.ds.GetByPassportSeries(series)
-	if err != nil {
-		return responses.NewJsonError(err)
+	return dc.ds.GetByPassportSeries(series)
+}
+
+func (dc DriverController) GetBySnils(snils string) (*models.Driver, error) {
+	if strings.TrimSpace(snils) == "" {
+		return nil, required(models.EntityDriver, "Snils", "Snils cant be null")
 	}
-	jsonData, err := json.MarshalIndent(data, "", "    ")
-	if err != nil {
-		return responses.NewJsonError(err)
+	return dc.ds.GetBySnils(snils)
+}
+
+func (dc DriverController) GetAll() ([]models.Driver, error) {
+	return dc.ds.GetAll()
+}
+
+func (dc DriverController) GetAllWithInvalidDocuments() ([]models.Driver, error) {
+	return dc.ds.GetAllWithInvalidDocuments()
+}
+
+// Add returns the driver as stored, with its generated ID and version.
+func (dc DriverController) Add(driver models.Driver) (*models.Driver, error) {
+	if err := dc.ds.Add(&driver); err != nil {
+		return nil, err
 	}
-	return string(jsonData)
+	return &driver, nil
 }
 
-func (dc DriverController) GetAll() string {
-	data := dc.ds.GetAll()
-	jsonData, err := json.MarshalIndent(data, "", "    ")
-	if err != nil {
-		return responses.NewJsonError(err)
+func (dc DriverController) DeleteById(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return required(models.EntityDriver, "ID", "ID cant be null")
 	}
-	return string(jsonData)
+	return dc.ds.DeleteById(id)
 }
 
-func (dc DriverController) Add(driverData string) string {
-	byteDriver := []byte(driverData)
-	var driver models.Driver
-	err := json.Unmarshal(byteDriver, &driver)
-	if err != nil {
-		return responses.NewJsonError(err)
+func (dc DriverController) List(query models.ListQuery) (models.Page[models.Driver], error) {
+	return dc.ds.List(query)
+}
+
+func (dc DriverController) GetAllDeleted() ([]models.Trashed[models.Driver], error) {
+	return dc.ds.GetAllDeleted()
+}
+
+func (dc DriverController) RestoreById(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return required(models.EntityDriver, "ID", "ID cant be null")
 	}
-	err = dc.ds.Add(&driver)
-	if err != nil {
-		return responses.NewJsonError(err)
+	return dc.ds.RestoreById(id)
+}
+
+func (dc DriverController) PurgeById(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return required(models.EntityDriver, "ID", "ID cant be null")
 	}
-	return driverData
+	return dc.ds.PurgeById(id)
 }
 
-func (dc DriverController) DeleteById(id string) string {
+func (dc DriverController) DeleteByIdWithPolicy(id, policy string) error {
 	if strings.TrimSpace(id) == "" {
-		return responses.NewJsonError(errors.New("ID cant be null"))
+		return required(models.EntityDriver, "ID", "ID cant be null")
 	}
-	err := dc.ds.DeleteById(id)
+	deletePolicy, err := service.ParseDeletePolicy(policy)
 	if err != nil {
-		return responses.NewJsonError(err)
+		return err
 	}
-	return ""
+	return dc.ds.DeleteByIdWithPolicy(id, deletePolicy)
 }
 
-func (dc DriverController) UpdateById(driverData string) string {
-	byteDriver := []byte(driverData)
-	var driver models.Driver
-	err := json.Unmarshal(byteDriver, &driver)
-	if err != nil {
-		return responses.NewJsonError(err)
+func (dc DriverController) GetAllRoutesById(id string) ([]models.Route, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, required(models.EntityDriver, "ID", "ID cant be null")
 	}
-	err = dc.ds.UpdateById(&driver)
-	if err != nil {
-		return responses.NewJsonError(err)
+	return dc.ds.GetAllRoutesById(id)
+}
+
+func (dc DriverController) GetAllLicensesExpiringWithin(days int) ([]models.Driver, error) {
+	return dc.ds.GetAllLicensesExpiringWithin(days)
+}
+
+func (dc DriverController) UpdateById(driver models.Driver) (*models.Driver, error) {
+	if err := dc.ds.UpdateById(&driver); err != nil {
+		return nil, err
 	}
-	return driverData
+	return &driver, nil
 }
@@ -0,0 +1,11 @@
+package controller
+
+import (
+	"busManager/apperrors"
+)
+
+// required reports an empty argument of the call as a validation error on
+// field.
+func required(entity, field, message string) error {
+	return apperrors.Validation(entity, message, map[string]string{field: message})
+}
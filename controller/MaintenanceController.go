@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"busManager/models"
+	"busManager/service"
+	"strings"
+)
+
+type MaintenanceController struct {
+	ms service.IMaintenanceService
+}
+
+func NewMaintenanceController(ms service.MaintenanceService) *MaintenanceController {
+	return &MaintenanceController{ms}
+}
+
+func (mc MaintenanceController) AddInterval(interval models.MaintenanceInterval) (*models.MaintenanceInterval, error) {
+	if err := mc.ms.AddInterval(&interval); err != nil {
+		return nil, err
+	}
+	return &interval, nil
+}
+
+func (mc MaintenanceController) GetAllIntervals() ([]models.MaintenanceInterval, error) {
+	return mc.ms.GetAllIntervals()
+}
+
+func (mc MaintenanceController) UpdateInterval(interval models.MaintenanceInterval) (*models.MaintenanceInterval, error) {
+	if strings.TrimSpace(interval.ID) == "" {
+		return nil, required(models.EntityMaintenanceInterval, "ID", "ID cant be null")
+	}
+	if err := mc.ms.UpdateInterval(&interval); err != nil {
+		return nil, err
+	}
+	return &interval, nil
+}
+
+func (mc MaintenanceController) DeleteIntervalById(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return required(models.EntityMaintenanceInterval, "ID", "ID cant be null")
+	}
+	return mc.ms.DeleteIntervalById(id)
+}
+
+func (mc MaintenanceController) AddRecord(record models.MaintenanceRecord) (*models.MaintenanceRecord, error) {
+	if err := mc.ms.AddRecord(&record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (mc MaintenanceController) GetAllRecordsByBusId(busId string) ([]models.MaintenanceRecord, error) {
+	if strings.TrimSpace(busId) == "" {
+		return nil, required(models.EntityMaintenance, "BusID", "BusID cant be null")
+	}
+	return mc.ms.GetAllRecordsByBusId(busId)
+}
+
+func (mc MaintenanceController) GetPlanByBusId(busId string) ([]models.MaintenanceDue, error) {
+	if strings.TrimSpace(busId) == "" {
+		return nil, required(models.EntityBus, "ID", "ID cant be null")
+	}
+	return mc.ms.GetPlanByBusId(busId)
+}
+
+func (mc MaintenanceController) GetDue(days, kilometers int) ([]models.MaintenanceDue, error) {
+	return mc.ms.GetDue(days, kilometers)
+}
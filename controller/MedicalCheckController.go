@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"busManager/models"
+	"busManager/service"
+	"strings"
+	"time"
+)
+
+type MedicalCheckController struct {
+	ms service.IMedicalCheckService
+}
+
+func NewMedicalCheckController(ms service.MedicalCheckService) *MedicalCheckController {
+	return &MedicalCheckController{ms}
+}
+
+func (mc MedicalCheckController) Add(check models.MedicalCheck) (*models.MedicalCheck, error) {
+	if err := mc.ms.Add(&check); err != nil {
+		return nil, err
+	}
+	return &check, nil
+}
+
+func (mc MedicalCheckController) GetById(id string) (*models.MedicalCheck, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, required(models.EntityMedicalCheck, "ID", "ID cant be null")
+	}
+	return mc.ms.GetById(id)
+}
+
+func (mc MedicalCheckController) GetAllByDriverId(driverId string) ([]models.MedicalCheck, error) {
+	if strings.TrimSpace(driverId) == "" {
+		return nil, required(models.EntityMedicalCheck, "DriverID", "DriverID cant be null")
+	}
+	return mc.ms.GetAllByDriverId(driverId)
+}
+
+func (mc MedicalCheckController) IsClearedToday(driverId string) (bool, error) {
+	if strings.TrimSpace(driverId) == "" {
+		return false, required(models.EntityMedicalCheck, "DriverID", "DriverID cant be null")
+	}
+	return mc.ms.IsClearedToday(driverId)
+}
+
+func (mc MedicalCheckController) GetClearedToday() ([]models.Driver, error) {
+	return mc.ms.GetClearedToday()
+}
+
+// GetDailyReport reports on today when day is zero.
+func (mc MedicalCheckController) GetDailyReport(day time.Time) ([]models.UnclearedDriver, error) {
+	if day.IsZero() {
+		day = time.Now()
+	}
+	return mc.ms.GetDailyReport(day)
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"busManager/models"
+	"busManager/service"
+	"strings"
+	"time"
+)
+
+type MileageController struct {
+	ms service.IMileageService
+}
+
+func NewMileageController(ms service.MileageService) *MileageController {
+	return &MileageController{ms}
+}
+
+func (mc MileageController) AddReading(reading models.OdometerReading) (*models.OdometerReading, error) {
+	if err := mc.ms.AddReading(&reading); err != nil {
+		return nil, err
+	}
+	return &reading, nil
+}
+
+func (mc MileageController) GetAllReadingsByBusId(busId string) ([]models.OdometerReading, error) {
+	if strings.TrimSpace(busId) == "" {
+		return nil, required(models.EntityOdometerReading, "BusID", "BusID cant be null")
+	}
+	return mc.ms.GetAllReadingsByBusId(busId)
+}
+
+func (mc MileageController) AddTrips(trips models.OperatedTrips) (*models.OperatedTrips, error) {
+	if err := mc.ms.AddTrips(&trips); err != nil {
+		return nil, err
+	}
+	return &trips, nil
+}
+
+func (mc MileageController) GetAllTripsByBusId(busId string, from, to time.Time) ([]models.OperatedTrips, error) {
+	if strings.TrimSpace(busId) == "" {
+		return nil, required(models.EntityOperatedTrips, "BusID", "BusID cant be null")
+	}
+	return mc.ms.GetAllTripsByBusId(busId, from, to)
+}
+
+func (mc MileageController) GetDailyMileage(busId string, from, to time.Time) ([]models.Mileage, error) {
+	if strings.TrimSpace(busId) == "" {
+		return nil, required(models.EntityBus, "BusID", "BusID cant be null")
+	}
+	return mc.ms.GetDailyMileage(busId, from, to)
+}
+
+func (mc MileageController) GetMonthlyMileage(busId string, from, to time.Time) ([]models.Mileage, error) {
+	if strings.TrimSpace(busId) == "" {
+		return nil, required(models.EntityBus, "BusID", "BusID cant be null")
+	}
+	return mc.ms.GetMonthlyMileage(busId, from, to)
+}
+
+func (mc MileageController) GetByBusId(busId string) (*models.BusMileage, error) {
+	if strings.TrimSpace(busId) == "" {
+		return nil, required(models.EntityBus, "BusID", "BusID cant be null")
+	}
+	return mc.ms.GetByBusId(busId)
+}
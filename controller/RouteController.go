@@ -2,10 +2,7 @@ package controller
 
 import (
 	"busManager/models"
-	"busManager/responses"
 	"busManager/service"
-	"encoding/json"
-	"errors"
 	"strings"
 )
 
@@ -17,212 +14,152 @@ func NewRouteController(rs service.IRouteService) *RouteController {
 	return &RouteController{rs}
 }
 
-func (rc RouteController) GetById(id string) string {
+func (rc RouteController) GetById(id string) (*models.Route, error) {
 	if strings.TrimSpace(id) == "" {
-		return responses.NewJsonError(errors.New("ID cant be null"))
+		return nil, required(models.EntityRoute, "ID", "ID cant be null")
 	}
-	data, err := rc.rs.GetById(id)
-	if err != nil {
-		return responses.NewJsonError(err)
-	}
-	jsonData, err := json.MarshalIndent(data, "", "    ")
-	if err != nil {
-		return responses.NewJsonError(err)
-	}
-	return string(jsonData)
+	return rc.rs.GetById(id)
 }
 
-func (rc RouteController) GetByNumber(number string) string {
+func (rc RouteController) GetByNumber(number string) (*models.Route, error) {
 	if strings.TrimSpace(number) == "" {
-		return responses.NewJsonError(errors.New("Number cant be null"))
-	}
-	data, err := rc.rs.GetByNumber(number)
-	if err != nil {
-		return responses.NewJsonError(err)
-	}
-	jsonData, err := json.MarshalIndent(data, "", "    ")
-	if err != nil {
-		return responses.NewJsonError(err)
+		return nil, required(models.EntityRoute, "Number", "Number cant be null")
 	}
-	return string(jsonData)
+	return rc.rs.GetByNumber(number)
 }
 
-func (rc RouteController) GetAll() string {
-	data, err := rc.rs.GetAll()
-	if err != nil {
-		return responses.NewJsonError(err)
-	}
-	jsonData, err := json.MarshalIndent(data, "", "    ")
-	if err != nil {
-		return responses.NewJsonError(err)
-	}
-	return string(jsonData)
+func (rc RouteController) GetAll() ([]models.Route, error) {
+	return rc.rs.GetAll()
 }
 
-func (rc RouteController) Add(routeData string) string {
-	byteRoute := []byte(routeData)
-	var route models.Route
-	err := json.Unmarshal(byteRoute, &route)
-	if err != nil {
-		return responses.NewJsonError(err)
+// Add returns the route as stored, with its generated ID and version.
+func (rc RouteController) Add(route models.Route) (*models.Route, error) {
+	if err := rc.rs.Add(&route); err != nil {
+		return nil, err
 	}
-	err = rc.rs.Add(&route)
-	if err != nil {
-		return responses.NewJsonError(err)
-	}
-	return routeData
+	return &route, nil
 }
 
-func (rc RouteController) DeleteById(id string) string {
+func (rc RouteController) DeleteById(id string) error {
 	if strings.TrimSpace(id) == "" {
-		return responses.NewJsonError(errors.New("ID cant be null"))
+		return required(models.EntityRoute, "ID", "ID cant be null")
 	}
-	err := rc.rs.DeleteById(id)
-	if err != nil {
-		return responses.NewJsonError(err)
+	return rc.rs.DeleteById(id)
+}
+
+func (rc RouteController) List(query models.ListQuery) (models.Page[models.Route], error) {
+	return rc.rs.List(query)
+}
+
+func (rc RouteController) GetAllDeleted() ([]models.Trashed[models.Route], error) {
+	return rc.rs.GetAllDeleted()
+}
+
+func (rc RouteController) RestoreById(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return required(models.EntityRoute, "ID", "ID cant be null")
 	}
-	return ""
+	return rc.rs.RestoreById(id)
 }
 
-func (rc RouteController) UpdateById(routeData string) string {
-	byteRoute := []byte(routeData)
-	var route models.Route
-	err := json.Unmarshal(byteRoute, &route)
-	if err != nil {
-		return responses.NewJsonError(err)
+func (rc RouteController) PurgeById(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return required(models.EntityRoute, "ID", "ID cant be null")
 	}
-	err = rc.rs.UpdateById(&route)
-	if err != nil {
-		return responses.NewJsonError(err)
+	return rc.rs.PurgeById(id)
+}
+
+func (rc RouteController) UpdateById(route models.Route) (*models.Route, error) {
+	if err := rc.rs.UpdateById(&route); err != nil {
+		return nil, err
 	}
-	return routeData
+	return &route, nil
 }
 
-func (rc RouteController) AssignDriver(routeId, driverId string) string {
+func (rc RouteController) AssignDriver(routeId, driverId string) error {
 	if strings.TrimSpace(routeId) == "" {
-		return responses.NewJsonError(errors.New("Route ID cant be null"))
+		return required(models.EntityRoute, "RouteID", "Route ID cant be null")
 	}
 	if strings.TrimSpace(driverId) == "" {
-		return responses.NewJsonError(errors.New("Driver ID cant be null"))
-	}
-	err := rc.rs.AssignDriver(routeId, driverId)
-	if err != nil {
-		return responses.NewJsonError(err)
+		return required(models.EntityDriver, "DriverID", "Driver ID cant be null")
 	}
-	return responses.NewSuccessResponse(`Assigned driver successfully`)
+	return rc.rs.AssignDriver(routeId, driverId)
 }
 
-func (rc RouteController) AssignBusStop(routeId, busStopId string) string {
+func (rc RouteController) AssignBusStop(routeId, busStopId string) error {
 	if strings.TrimSpace(routeId) == "" {
-		return responses.NewJsonError(errors.New("Route ID cant be null"))
+		return required(models.EntityRoute, "RouteID", "Route ID cant be null")
 	}
 	if strings.TrimSpace(busStopId) == "" {
-		return responses.NewJsonError(errors.New("Bus stop ID cant be null"))
+		return required(models.EntityBusStop, "BusStopID", "Bus stop ID cant be null")
 	}
-	err := rc.rs.AssignBusStop(routeId, busStopId)
-	if err != nil {
-		return responses.NewJsonError(err)
-	}
-	return responses.NewSuccessResponse(`Assigned bus stop successfully`)
+	return rc.rs.AssignBusStop(routeId, busStopId)
 }
 
-func (rc RouteController) AssignBus(routeId, busId string) string {
+func (rc RouteController) AssignBus(routeId, busId string) error {
 	if strings.TrimSpace(routeId) == "" {
-		return responses.NewJsonError(errors.New("Route ID cant be null"))
+		return required(models.EntityRoute, "RouteID", "Route ID cant be null")
 	}
 	if strings.TrimSpace(busId) == "" {
-		return responses.NewJsonError(errors.New("Bus ID cant be null"))
-	}
-	err := rc.rs.AssignBus(routeId, busId)
-	if err != nil {
-		return responses.NewJsonError(err)
+		return required(models.EntityBus, "BusID", "Bus ID cant be null")
 	}
-	return responses.NewSuccessResponse(`Assigned bus successfully`)
+	return rc.rs.AssignBus(routeId, busId)
 }
 
-func (rc RouteController) UnassignDriver(routeId, driverId string) string {
+func (rc RouteController) UnassignDriver(routeId, driverId string) error {
 	if strings.TrimSpace(routeId) == "" {
-		return responses.NewJsonError(errors.New("Route ID cant be null"))
+		return required(models.EntityRoute, "RouteID", "Route ID cant be null")
 	}
 	if strings.TrimSpace(driverId) == "" {
-		return responses.NewJsonError(errors.New("Driver ID cant be null"))
+		return required(models.EntityDriver, "DriverID", "Driver ID cant be null")
 	}
-	err := rc.rs.UnassignDriver(routeId, driverId)
-	if err != nil {
-		return responses.NewJsonError(err)
-	}
-	return responses.NewSuccessResponse(`Unassigned driver successfully`)
+	return rc.rs.UnassignDriver(routeId, driverId)
 }
 
-func (rc RouteController) UnassignBusStop(routeId, busStopId string) string {
+func (rc RouteController) UnassignBusStop(routeId, busStopId string) error {
 	if strings.TrimSpace(routeId) == "" {
-		return responses.NewJsonError(errors.New("Route ID cant be null"))
+		return required(models.EntityRoute, "RouteID", "Route ID cant be null")
 	}
 	if strings.TrimSpace(busStopId) == "" {
-		return responses.NewJsonError(errors.New("Bus stop ID cant be null"))
-	}
-	err := rc.rs.UnassignBusStop(routeId, busStopId)
-	if err != nil {
-		return responses.NewJsonError(err)
+		return required(models.EntityBusStop, "BusStopID", "Bus stop ID cant be null")
 	}
-	return responses.NewSuccessResponse(`Unassigned bus stop successfully`)
+	return rc.rs.UnassignBusStop(routeId, busStopId)
 }
 
-func (rc RouteController) UnassignBus(routeId, busId string) string {
+func (rc RouteController) UnassignBus(routeId, busId string) error {
 	if strings.TrimSpace(routeId) == "" {
-		return responses.NewJsonError(errors.New("Route ID cant be null"))
+		return required(models.EntityRoute, "RouteID", "Route ID cant be null")
 	}
 	if strings.TrimSpace(busId) == "" {
-		return responses.NewJsonError(errors.New("Bus ID cant be null"))
+		return required(models.EntityBus, "BusID", "Bus ID cant be null")
 	}
-	err := rc.rs.UnassignBus(routeId, busId)
-	if err != nil {
-		return responses.NewJsonError(err)
-	}
-	return responses.NewSuccessResponse(`Unassigned bus successfully`)
+	return rc.rs.UnassignBus(routeId, busId)
 }
 
-func (rc RouteController) GetAllDriversById(routeId string) string {
+func (rc RouteController) GetAllDriversById(routeId string) ([]models.Driver, error) {
 	if strings.TrimSpace(routeId) == "" {
-		return responses.NewJsonError(errors.New("Route ID cant be null"))
-	}
-	data, err := rc.rs.GetAllDriversById(routeId)
-	if err != nil {
-		return responses.NewJsonError(err)
+		return nil, required(models.EntityRoute, "RouteID", "Route ID cant be null")
 	}
-	jsonData, err := json.MarshalIndent(data, "", "    ")
-	if err != nil {
-		return responses.NewJsonError(err)
-	}
-	return string(jsonData)
+	return rc.rs.GetAllDriversById(routeId)
 }
 
-func (rc RouteController) GetAllBusesById(routeId string) string {
+func (rc RouteController) GetAllBusesById(routeId string) ([]models.Bus, error) {
 	if strings.TrimSpace(routeId) == "" {
-		return responses.NewJsonError(errors.New("Route ID cant be null"))
-	}
-	data, err := rc.rs.GetAllBusesById(routeId)
-	if err != nil {
-		return responses.NewJsonError(err)
+		return nil, required(models.EntityRoute, "RouteID", "Route ID cant be null")
 	}
-	jsonData, err := json.MarshalIndent(data, "", "    ")
-	if err != nil {
-		return responses.NewJsonError(err)
-	}
-	return string(jsonData)
+	return rc.rs.GetAllBusesById(routeId)
 }
 
-func (rc RouteController) GetAllBusStopsById(routeId string) string {
+func (rc RouteController) ListSuitableBuses(routeId string, query models.ListQuery) (models.Page[models.Bus], error) {
 	if strings.TrimSpace(routeId) == "" {
-		return responses.NewJsonError(errors.New("Route ID cant be null"))
-	}
-	data, err := rc.rs.GetAllBusStopsById(routeId)
-	if err != nil {
-		return responses.NewJsonError(err)
+		return models.Page[models.Bus]{}, required(models.EntityRoute, "RouteID", "Route ID cant be null")
 	}
-	jsonData, err := json.MarshalIndent(data, "", "    ")
-	if err != nil {
-		return responses.NewJsonError(err)
+	return rc.rs.ListSuitableBuses(routeId, query)
+}
+
+func (rc RouteController) GetAllBusStopsById(routeId string) ([]models.BusStop, error) {
+	if strings.TrimSpace(routeId) == "" {
+		return nil, required(models.EntityRoute, "RouteID", "Route ID cant be null")
 	}
-	return string(jsonData)
+	return rc.rs.GetAllBusStopsById(routeId)
 }
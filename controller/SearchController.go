@@ -0,0 +1,18 @@
+package controller
+
+import (
+	"busManager/models"
+	"busManager/service"
+)
+
+type SearchController struct {
+	ss service.ISearchService
+}
+
+func NewSearchController(ss service.SearchService) *SearchController {
+	return &SearchController{ss}
+}
+
+func (sc SearchController) Search(query string) ([]models.SearchHit, error) {
+	return sc.ss.Search(query)
+}
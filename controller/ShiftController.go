@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"busManager/models"
+	"busManager/service"
+	"strings"
+	"time"
+)
+
+type ShiftController struct {
+	ss service.IShiftService
+}
+
+func NewShiftController(ss service.ShiftService) *ShiftController {
+	return &ShiftController{ss}
+}
+
+func (sc ShiftController) Add(shift models.Shift) (*models.Shift, error) {
+	if err := sc.ss.Add(&shift); err != nil {
+		return nil, err
+	}
+	return &shift, nil
+}
+
+func (sc ShiftController) GetById(id string) (*models.Shift, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, required(models.EntityShift, "ID", "ID cant be null")
+	}
+	return sc.ss.GetById(id)
+}
+
+func (sc ShiftController) GetAllByDriverId(driverId string, from, to time.Time) ([]models.Shift, error) {
+	if strings.TrimSpace(driverId) == "" {
+		return nil, required(models.EntityShift, "DriverID", "DriverID cant be null")
+	}
+	return sc.ss.GetAllByDriverId(driverId, from, to)
+}
+
+func (sc ShiftController) DeleteById(id string) error {
+	if strings.TrimSpace(id) == "" {
+		return required(models.EntityShift, "ID", "ID cant be null")
+	}
+	return sc.ss.DeleteById(id)
+}
+
+func (sc ShiftController) GetComplianceReport(driverId string, from, to time.Time) (*models.ComplianceReport, error) {
+	if strings.TrimSpace(driverId) == "" {
+		return nil, required(models.EntityShift, "DriverID", "DriverID cant be null")
+	}
+	return sc.ss.GetComplianceReport(driverId, from, to)
+}
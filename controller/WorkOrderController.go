@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"busManager/models"
+	"busManager/service"
+	"strings"
+	"time"
+)
+
+type WorkOrderController struct {
+	ws service.IWorkOrderService
+}
+
+func NewWorkOrderController(ws service.WorkOrderService) *WorkOrderController {
+	return &WorkOrderController{ws}
+}
+
+func (wc WorkOrderController) Open(order models.WorkOrder) (*models.WorkOrder, error) {
+	if err := wc.ws.Open(&order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (wc WorkOrderController) GetById(id string) (*models.WorkOrder, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, required(models.EntityWorkOrder, "ID", "ID cant be null")
+	}
+	return wc.ws.GetById(id)
+}
+
+func (wc WorkOrderController) GetAllByBusId(busId string) ([]models.WorkOrder, error) {
+	if strings.TrimSpace(busId) == "" {
+		return nil, required(models.EntityWorkOrder, "BusID", "BusID cant be null")
+	}
+	return wc.ws.GetAllByBusId(busId)
+}
+
+func (wc WorkOrderController) GetAllActive() ([]models.WorkOrder, error) {
+	return wc.ws.GetAllActive()
+}
+
+func (wc WorkOrderController) UpdateById(order models.WorkOrder) (*models.WorkOrder, error) {
+	if strings.TrimSpace(order.ID) == "" {
+		return nil, required(models.EntityWorkOrder, "ID", "ID cant be null")
+	}
+	if err := wc.ws.UpdateById(&order); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (wc WorkOrderController) Start(id string) (*models.WorkOrder, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, required(models.EntityWorkOrder, "ID", "ID cant be null")
+	}
+	return wc.ws.Start(id)
+}
+
+func (wc WorkOrderController) Close(id string) (*models.WorkOrder, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, required(models.EntityWorkOrder, "ID", "ID cant be null")
+	}
+	return wc.ws.Close(id)
+}
+
+func (wc WorkOrderController) Cancel(id string) (*models.WorkOrder, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, required(models.EntityWorkOrder, "ID", "ID cant be null")
+	}
+	return wc.ws.Cancel(id)
+}
+
+func (wc WorkOrderController) GetCostByBus(from, to time.Time) ([]models.RepairCost, error) {
+	return wc.ws.GetCostByBus(from, to)
+}
+
+func (wc WorkOrderController) GetCostByModel(from, to time.Time) ([]models.RepairCost, error) {
+	return wc.ws.GetCostByModel(from, to)
+}
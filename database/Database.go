@@ -0,0 +1,77 @@
+package database
+
+import (
+	"busManager/migrations"
+	"database/sql"
+	"errors"
+	_ "github.com/mattn/go-sqlite3"
+	"sync"
+)
+
+// Database owns the single connection pool to the application database.
+// Every repository receives its handle instead of opening its own.
+type Database struct {
+	db        *sql.DB
+	path      string
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// ErrNoFTS5 is returned by Open when SQLite was built without FTS5, which
+// the search index needs.
+var ErrNoFTS5 = errors.New("SQLite is built without FTS5, build with -tags sqlite_fts5")
+
+func Open(path string) (*Database, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate&_foreign_keys=1")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows one writer at a time; a single connection keeps
+	// transactions from unit of work and plain queries strictly ordered.
+	db.SetMaxOpenConns(1)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	// Without FTS5 the triggers of search_index fail every write.
+	var fts5 bool
+	if err := db.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&fts5); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if !fts5 {
+		db.Close()
+		return nil, ErrNoFTS5
+	}
+	return &Database{db: db, path: path}, nil
+}
+
+func (d *Database) DB() *sql.DB {
+	return d.db
+}
+
+func (d *Database) Path() string {
+	return d.path
+}
+
+// Migrate applies all pending schema migrations.
+func (d *Database) Migrate() error {
+	m, err := migrations.NewMigrator(d.db)
+	if err != nil {
+		return err
+	}
+	return m.Up()
+}
+
+// Close checkpoints the write-ahead log into the main file and closes the
+// pool. Calls after the first one return the first result.
+func (d *Database) Close() error {
+	d.closeOnce.Do(func() {
+		_, err := d.db.Exec(`PRAGMA wal_checkpoint(TRUNCATE)`)
+		d.closeErr = d.db.Close()
+		if err != nil {
+			d.closeErr = err
+		}
+	})
+	return d.closeErr
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDatabase_OpenMigrateClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var mode string
+	if err := db.DB().QueryRow(`PRAGMA journal_mode`).Scan(&mode); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if mode != "wal" {
+		t.Errorf("Expected wal journal mode, got %s", mode)
+	}
+
+	_, err = db.DB().Exec(`INSERT INTO routes (id, number) VALUES ('1', '101')`)
+	if err != nil {
+		t.Fatalf("Failed to insert test data: %v", err)
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if info, err := os.Stat(path + "-wal"); err == nil && info.Size() > 0 {
+		t.Errorf("Expected write-ahead log to be checkpointed, got %d bytes", info.Size())
+	}
+
+	db, err = Open(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer db.Close()
+	var count int
+	if err := db.DB().QueryRow(`SELECT COUNT(*) FROM routes`).Scan(&count); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 route after reopen, got %d", count)
+	}
+}
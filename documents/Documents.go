@@ -0,0 +1,107 @@
+// Package documents checks the numbers of a driver's documents: SNILS, the
+// Russian internal passport and the driving licence with its categories.
+// Each Parse function accepts the value as typed and returns its canonical
+// form.
+package documents
+
+import (
+	"busManager/plates"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrInvalidSnils    = errors.New("Invalid SNILS")
+	ErrSnilsChecksum   = errors.New("SNILS check number does not match")
+	ErrInvalidPassport = errors.New("Invalid passport series and number")
+	ErrInvalidLicense  = errors.New("Invalid driving licence series and number")
+	ErrInvalidCategory = errors.New("Unknown driving licence category")
+)
+
+// digits returns s without spaces, dashes and the № sign.
+func digits(s string) string {
+	return strings.Map(func(c rune) rune {
+		if unicode.IsSpace(c) || c == '-' || c == '№' {
+			return -1
+		}
+		return c
+	}, s)
+}
+
+var (
+	snilsPattern    = regexp.MustCompile(`^\d{11}$`)
+	passportPattern = regexp.MustCompile(`^\d{10}$`)
+	// licences since 2011 have a series of four digits, older ones two
+	// digits and two Cyrillic letters
+	licensePattern = regexp.MustCompile(`^(\d{2})(\d{2}|[АВЕКМНОРСТУХ]{2})(\d{6})$`)
+)
+
+// ParseSnils returns the SNILS as "123-456-789 64". The check number is
+// verified for numbers above 001-001-998, the first one it was issued for.
+func ParseSnils(s string) (string, error) {
+	n := digits(s)
+	if !snilsPattern.MatchString(n) {
+		return "", ErrInvalidSnils
+	}
+	if n[:9] > "001001998" && snilsChecksum(n[:9]) != n[9:] {
+		return "", ErrSnilsChecksum
+	}
+	return n[0:3] + "-" + n[3:6] + "-" + n[6:9] + " " + n[9:], nil
+}
+
+// snilsChecksum weighs the digits 9 to 1 from the left; sums above 101 are
+// taken modulo 101, and 100 and 101 give 00.
+func snilsChecksum(number string) string {
+	sum := 0
+	for i, c := range number {
+		sum += int(c-'0') * (9 - i)
+	}
+	if sum > 101 {
+		sum %= 101
+	}
+	if sum >= 100 {
+		sum = 0
+	}
+	return fmt.Sprintf("%02d", sum)
+}
+
+// ParsePassport returns the series and number of a passport as
+// "4510 123456". The first two digits of the series are a region code and
+// cannot be 00; neither can the number be all zeros.
+func ParsePassport(s string) (string, error) {
+	n := digits(s)
+	if !passportPattern.MatchString(n) || n[:2] == "00" || n[4:] == "000000" {
+		return "", ErrInvalidPassport
+	}
+	return n[:4] + " " + n[4:], nil
+}
+
+// ParseLicense returns the series and number of a driving licence as
+// "77 12 345678" or, for old licences, "77 АВ 345678".
+func ParseLicense(s string) (string, error) {
+	m := licensePattern.FindStringSubmatch(plates.Normalize(digits(s)))
+	if m == nil || m[3] == "000000" {
+		return "", ErrInvalidLicense
+	}
+	return m[1] + " " + m[2] + " " + m[3], nil
+}
+
+// categories are the driving licence categories in their canonical spelling.
+var categories = []string{"A", "A1", "B", "B1", "BE", "C", "C1", "CE", "C1E", "D", "D1", "DE", "D1E", "M", "Tm", "Tb"}
+
+// ParseCategory returns a driving licence category as printed in the
+// licence: Latin capitals and digits, except for Tm and Tb. Cyrillic
+// look-alike letters and lower case are accepted.
+func ParseCategory(s string) (string, error) {
+	category := strings.ToUpper(digits(s))
+	category = strings.NewReplacer("А", "A", "В", "B", "С", "C", "Е", "E", "М", "M", "Т", "T").Replace(category)
+	for _, c := range categories {
+		if strings.ToUpper(c) == category {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("%w %s", ErrInvalidCategory, strings.TrimSpace(s))
+}
@@ -0,0 +1,91 @@
+package documents
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSnils(t *testing.T) {
+	tests := []struct {
+		in, want string
+		err      error
+	}{
+		{"123-456-789 64", "123-456-789 64", nil},
+		{"12345678964", "123-456-789 64", nil},
+		{" 112 233 445 95 ", "112-233-445 95", nil},
+		{"087-654-303 00", "087-654-303 00", nil},
+		// numbers up to 001-001-998 have no check number
+		{"001-001-998 17", "001-001-998 17", nil},
+		{"123-456-789 65", "", ErrSnilsChecksum},
+		{"123-456-789", "", ErrInvalidSnils},
+		{"123-456-78a 64", "", ErrInvalidSnils},
+	}
+	for _, tt := range tests {
+		got, err := ParseSnils(tt.in)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("ParseSnils(%q) = %q, %v; want %q, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParsePassport(t *testing.T) {
+	tests := []struct {
+		in, want string
+		err      error
+	}{
+		{"4510 123456", "4510 123456", nil},
+		{"45 10 № 123456", "4510 123456", nil},
+		{"4510123456", "4510 123456", nil},
+		{"0010 123456", "", ErrInvalidPassport},
+		{"4510 000000", "", ErrInvalidPassport},
+		{"4510 12345", "", ErrInvalidPassport},
+		{"AB123456", "", ErrInvalidPassport},
+	}
+	for _, tt := range tests {
+		got, err := ParsePassport(tt.in)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("ParsePassport(%q) = %q, %v; want %q, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParseLicense(t *testing.T) {
+	tests := []struct {
+		in, want string
+		err      error
+	}{
+		{"77 12 345678", "77 12 345678", nil},
+		{"7712345678", "77 12 345678", nil},
+		{"77 ав 345678", "77 АВ 345678", nil},
+		{"77 AB 345678", "77 АВ 345678", nil},
+		{"77 12 000000", "", ErrInvalidLicense},
+		{"77 ЖЖ 345678", "", ErrInvalidLicense},
+		{"CD789012", "", ErrInvalidLicense},
+	}
+	for _, tt := range tests {
+		got, err := ParseLicense(tt.in)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("ParseLicense(%q) = %q, %v; want %q, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParseCategory(t *testing.T) {
+	tests := []struct {
+		in, want string
+		err      error
+	}{
+		{"D", "D", nil},
+		{" d1e ", "D1E", nil},
+		{"ВЕ", "BE", nil},
+		{"tm", "Tm", nil},
+		{"Д", "", ErrInvalidCategory},
+		{"F", "", ErrInvalidCategory},
+	}
+	for _, tt := range tests {
+		got, err := ParseCategory(tt.in)
+		if !errors.Is(err, tt.err) || got != tt.want {
+			t.Errorf("ParseCategory(%q) = %q, %v; want %q, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+// A failed router call rejects its promise with the error described under
+// Errors in the README ({Error, Code, Entity, Fields, Details}). errorMessage
+// returns the text to show for it.
+export const errorMessage = (err) => err?.Error ?? err?.message ?? String(err);
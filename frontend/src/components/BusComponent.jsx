@@ -4,9 +4,10 @@ import {createGlobalStyle} from "styled-components";
 import ms_sans_serif from "../assets/fonts/fixedsys.woff2";
 import ms_sans_serif_bold from 'react95/dist/fonts/ms_sans_serif_bold.woff2';
 import original from 'react95/dist/themes/original';
-import {Add, DeleteById, GetAll, UpdateById} from "../../wailsjs/go/routers/BusRouter.js";
-import {GetById} from "../../wailsjs/go/routers/BusRouter.js";
+import {Add, DeleteById, GetAll, GetById, UpdateById} from "../../wailsjs/go/routers/BusRouter.js";
+import {models} from "../../wailsjs/go/models";
 import CustomAlert from "./CustomAlert.jsx";
+import {errorMessage} from "../api.js";
 
 const GlobalStyles = createGlobalStyle`
   ${styleReset}
@@ -35,16 +36,8 @@ const BusComponent = () => {
     const [alertMessage, setAlertMessage] = useState(null);
     useEffect(() => {
         GetAll().then(
-            result => {
-                console.log(result)
-                if (!result || result === "null") {
-                    setItems([]);
-                } else {
-                    console.log(JSON.parse(result))
-                    setItems(JSON.parse(result))
-                }
-            }
-        )
+            result => setItems(result ?? [])
+        ).catch(err => setAlertMessage(errorMessage(err)))
     }, []);
     const convertDateToISO = (date) => {
         if (!isValidDateFormat(date)) {
@@ -74,7 +67,7 @@ const BusComponent = () => {
 
         GetById(item.ID).then(
             result => {
-                const selectedData = JSON.parse(result);
+                const selectedData = result;
                 selectedData.AssemblyDate = convertISOToDate(selectedData.AssemblyDate)
                 selectedData.LastRepairDate = convertISOToDate(selectedData.LastRepairDate)
                 setSelectedItem(selectedData);
@@ -124,26 +117,21 @@ const BusComponent = () => {
             selectedItem.ID = null
             selectedItem.AssemblyDate = convertDateToISO(selectedItem.AssemblyDate)
             selectedItem.LastRepairDate = convertDateToISO(selectedItem.LastRepairDate)
-            Add(JSON.stringify(selectedItem)).then(
-                result => {
-                    if (JSON.parse(result).Error){
-                        console.log(JSON.parse(result).Error)
-                        setAlertMessage(JSON.parse(result).Error);
-                    }
-
+            Add(models.Bus.createFrom(selectedItem)).then(
+                () => {
                     GetAll().then(
                         result => {
-                            setItems(JSON.parse(result));
+                            setItems(result ?? []);
                             setSelectedItem(null);
                         }
                     ).catch(err => {
-                        setAlertMessage(err);
+                        setAlertMessage(errorMessage(err));
                         console.error("Ошибка при обновлении списка:", err)
                     });
                 }
             ).catch(err => {
                 console.error("Ошибка при создании:", err)
-                setAlertMessage(err);
+                setAlertMessage(errorMessage(err));
             });
         } else {
             setAlertMessage("Нет выбранного элемента для создания");
@@ -163,26 +151,21 @@ const BusComponent = () => {
             }
             selectedItem.AssemblyDate = convertDateToISO(selectedItem.AssemblyDate)
             selectedItem.LastRepairDate = convertDateToISO(selectedItem.LastRepairDate)
-            UpdateById(JSON.stringify(selectedItem)).then(
-                result => {
-                    if (JSON.parse(result).Error){
-                        console.log(JSON.parse(result).Error)
-                        setAlertMessage(JSON.parse(result).Error);
-                    }
-
+            UpdateById(models.Bus.createFrom(selectedItem)).then(
+                () => {
                     GetAll().then(
                         result => {
-                            setItems(JSON.parse(result));
+                            setItems(result ?? []);
                             setSelectedItem(null);
                         }
                     ).catch(err => {
-                        setAlertMessage(err);
+                        setAlertMessage(errorMessage(err));
                         console.error("Ошибка при обновлении списка:", err)
                     });
                 }
             ).catch(err => {
                 console.error("Ошибка при обновлении:", err)
-                setAlertMessage(err);
+                setAlertMessage(errorMessage(err));
             });
         } else {
             setAlertMessage("Нет выбранного элемента для обновления");
@@ -199,17 +182,17 @@ const BusComponent = () => {
 
                     GetAll().then(
                         result => {
-                            setItems(JSON.parse(result));
+                            setItems(result ?? []);
                             setSelectedItem(null);
                         }
                     ).catch(err => {
-                        setAlertMessage(err);
+                        setAlertMessage(errorMessage(err));
                         console.error("Ошибка при обновлении списка:", err)
                     });
                 }
             ).catch(err => {
                 console.error("Ошибка при удалении:", err)
-                setAlertMessage(err);
+                setAlertMessage(errorMessage(err));
             });
         } else {
             setAlertMessage("Нет выбранного элемента для удаления");
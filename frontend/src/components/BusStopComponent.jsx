@@ -7,7 +7,9 @@ import { MapContainer, TileLayer, useMap, Marker, Popup, Polyline, useMapEvents}
 import L from 'leaflet';
 import original from 'react95/dist/themes/original';
 import {Add, DeleteById, GetAll, GetById, UpdateById} from "../../wailsjs/go/routers/BusStopRouter.js";
+import {models} from "../../wailsjs/go/models";
 import CustomAlert from "./CustomAlert.jsx";
+import {errorMessage} from "../api.js";
 
 const GlobalStyles = createGlobalStyle`
   ${styleReset}
@@ -71,15 +73,8 @@ const BusStopComponent = () => {
 
     useEffect(() => {
         GetAll().then(
-            result => {
-                if (!result || result === "null") {
-                    setItems([]);
-                } else {
-                    console.log(JSON.parse(result))
-                    setItems(JSON.parse(result))
-                }
-            }
-        )
+            result => setItems(result ?? [])
+        ).catch(err => setAlertMessage(errorMessage(err)))
     }, []);
 
     const handleItemClick = (item) => {
@@ -87,7 +82,7 @@ const BusStopComponent = () => {
 
         GetById(item.ID).then(
             result => {
-                const selectedData = JSON.parse(result);
+                const selectedData = result;
                 setSelectedItem(selectedData);
                 console.log("GetById result:", selectedData);
                 const lat = parseFloat(selectedData.Lat);
@@ -191,26 +186,21 @@ const BusStopComponent = () => {
                 Lat: parseFloat(selectedItem.Lat),
                 Long: parseFloat(selectedItem.Long),
             };
-            Add(JSON.stringify(payload)).then(
-                result => {
-                    if (JSON.parse(result).Error){
-                        console.log(JSON.parse(result).Error)
-                        setAlertMessage(JSON.parse(result).Error);
-                    }
-
+            Add(models.BusStop.createFrom(payload)).then(
+                () => {
                     GetAll().then(
                         result => {
-                            setItems(JSON.parse(result));
+                            setItems(result ?? []);
                             setSelectedItem(null);
                         }
                     ).catch(err => {
-                        setAlertMessage(err);
+                        setAlertMessage(errorMessage(err));
                         console.error("Ошибка при обновлении списка:", err)
                     });
                 }
             ).catch(err => {
                 console.error("Ошибка при создании:", err)
-                setAlertMessage(err);
+                setAlertMessage(errorMessage(err));
             });
         } else {
             setAlertMessage("Нет выбранного элемента для создания");
@@ -236,26 +226,21 @@ const BusStopComponent = () => {
                 Lat: parseFloat(selectedItem.Lat),
                 Long: parseFloat(selectedItem.Long),
             };
-            UpdateById(JSON.stringify(payload)).then(
-                result => {
-                    if (JSON.parse(result).Error){
-                        console.log(JSON.parse(result).Error)
-                        setAlertMessage(JSON.parse(result).Error);
-                    }
-
+            UpdateById(models.BusStop.createFrom(payload)).then(
+                () => {
                     GetAll().then(
                         result => {
-                            setItems(JSON.parse(result));
+                            setItems(result ?? []);
                             setSelectedItem(null);
                         }
                     ).catch(err => {
-                        setAlertMessage(err);
+                        setAlertMessage(errorMessage(err));
                         console.error("Ошибка при обновлении списка:", err)
                     });
                 }
             ).catch(err => {
                 console.error("Ошибка при обновлении:", err)
-                setAlertMessage(err);
+                setAlertMessage(errorMessage(err));
             });
         } else {
             setAlertMessage("Нет выбранного элемента для обновления");
@@ -272,7 +257,7 @@ const BusStopComponent = () => {
                 result => {
                     GetAll().then(
                         result => {
-                            setItems(JSON.parse(result));
+                            setItems(result ?? []);
                             setSelectedItem(null);
                         }
                     ).catch(err => {
@@ -282,7 +267,7 @@ const BusStopComponent = () => {
                 }
             ).catch(err => {
                 console.error("Ошибка при удалении:", err)
-                setAlertMessage(err);
+                setAlertMessage(errorMessage(err));
             });
         } else {
             setAlertMessage("Нет выбранного элемента для удаления");
@@ -4,8 +4,10 @@ import {createGlobalStyle} from "styled-components";
 import ms_sans_serif from "../assets/fonts/fixedsys.woff2";
 import ms_sans_serif_bold from 'react95/dist/fonts/ms_sans_serif_bold.woff2';
 import original from 'react95/dist/themes/original';
-import {GetAll, Add, DeleteById, GetById, UpdateById} from "../../wailsjs/go/routers/DriverRouter.js";
+import {Add, DeleteById, GetAll, GetById, UpdateById} from "../../wailsjs/go/routers/DriverRouter.js";
+import {models} from "../../wailsjs/go/models";
 import CustomAlert from "./CustomAlert.jsx";
+import {errorMessage} from "../api.js";
 
 const GlobalStyles = createGlobalStyle`
   ${styleReset}
@@ -32,17 +34,8 @@ const DriverComponent = () => {
     const [alertMessage, setAlertMessage] = useState(null);
     useEffect(() => {
         GetAll().then(
-            result => {
-
-                if (!result || result === "null") {
-                    setItems([]);
-                } else {
-                    console.log(JSON.parse(result))
-                    setItems(JSON.parse(result))
-                }
-
-            }
-        )
+            result => setItems(result ?? [])
+        ).catch(err => setAlertMessage(errorMessage(err)))
     }, []);
     const convertDateToISO = (date) => {
         if (!isValidDateFormat(date)) {
@@ -58,6 +51,16 @@ const DriverComponent = () => {
         const year = date.getUTCFullYear().toString();
         return `${day}.${month}.${year}`;
     };
+    const zeroDate = '0001-01-01T00:00:00Z';
+    // даты лицензии необязательны: пустое поле отправляется как нулевая дата
+    const licenseDateToISO = (date) => date && date.trim() !== '' ? convertDateToISO(date) : zeroDate;
+    const licenseDateFromISO = (isoDate) => !isoDate || isoDate.startsWith('0001-') ? '' : convertISOToDate(isoDate);
+    const fromLicenseForm = (item) => ({
+        ...item,
+        LicenseCategories: (item.LicenseCategories || '').split(',').map(c => c.trim()).filter(c => c !== ''),
+        LicenseIssueDate: licenseDateToISO(item.LicenseIssueDate),
+        LicenseExpiryDate: licenseDateToISO(item.LicenseExpiryDate)
+    });
     const isValidDateFormat = (date) => {
         const regex = /^\d{2}\.\d{2}\.\d{4}$/;
         if (!regex.test(date)) return false;
@@ -72,8 +75,11 @@ const DriverComponent = () => {
 
         GetById(item.ID).then(
             result => {
-                const selectedData = JSON.parse(result);
+                const selectedData = result;
                 selectedData.BirthDate = convertISOToDate(selectedData.BirthDate)
+                selectedData.LicenseCategories = (selectedData.LicenseCategories || []).join(', ')
+                selectedData.LicenseIssueDate = licenseDateFromISO(selectedData.LicenseIssueDate)
+                selectedData.LicenseExpiryDate = licenseDateFromISO(selectedData.LicenseExpiryDate)
                 setSelectedItem(selectedData);
                 console.log(selectedData)
             }
@@ -109,6 +115,11 @@ const DriverComponent = () => {
             console.log(item)
             return { isValid: false, message: 'Дата должна быть в формате ДД.ММ.ГГГГ' };
         }
+        for (const key of ['LicenseIssueDate', 'LicenseExpiryDate']) {
+            if (item[key] && item[key].trim() !== '' && !isValidDateFormat(item[key])) {
+                return { isValid: false, message: 'Дата должна быть в формате ДД.ММ.ГГГГ' };
+            }
+        }
 
 
         return { isValid: true, message: '' };
@@ -122,26 +133,21 @@ const DriverComponent = () => {
             }
             selectedItem.ID = null
             selectedItem.BirthDate = convertDateToISO(selectedItem.BirthDate)
-            Add(JSON.stringify(selectedItem)).then(
-                result => {
-                    if (JSON.parse(result).Error){
-                        console.log(JSON.parse(result).Error)
-                        setAlertMessage(JSON.parse(result).Error);
-                    }
-
+            Add(models.Driver.createFrom(fromLicenseForm(selectedItem))).then(
+                () => {
                     GetAll().then(
                         result => {
-                            setItems(JSON.parse(result));
+                            setItems(result ?? []);
                             setSelectedItem(null);
                         }
                     ).catch(err => {
-                        setAlertMessage(err);
+                        setAlertMessage(errorMessage(err));
                         console.error("Ошибка при обновлении списка:", err)
                     });
                 }
             ).catch(err => {
                 console.error("Ошибка при создании:", err)
-                setAlertMessage(err);
+                setAlertMessage(errorMessage(err));
             });
         } else {
             setAlertMessage("Нет выбранного элемента для создания");
@@ -161,26 +167,21 @@ const DriverComponent = () => {
             }
             selectedItem.BirthDate = convertDateToISO(selectedItem.BirthDate)
 
-            UpdateById(JSON.stringify(selectedItem)).then(
-                result => {
-                    if (JSON.parse(result).Error){
-                        console.log(JSON.parse(result).Error)
-                        setAlertMessage(JSON.parse(result).Error);
-                    }
-
+            UpdateById(models.Driver.createFrom(fromLicenseForm(selectedItem))).then(
+                () => {
                     GetAll().then(
                         result => {
-                            setItems(JSON.parse(result));
+                            setItems(result ?? []);
                             setSelectedItem(null);
                         }
                     ).catch(err => {
-                        setAlertMessage(err);
+                        setAlertMessage(errorMessage(err));
                         console.error("Ошибка при обновлении списка:", err)
                     });
                 }
             ).catch(err => {
                 console.error("Ошибка при обновлении:", err)
-                setAlertMessage(err);
+                setAlertMessage(errorMessage(err));
             });
         } else {
             setAlertMessage("Нет выбранного элемента для обновления");
@@ -197,17 +198,17 @@ const DriverComponent = () => {
 
                     GetAll().then(
                         result => {
-                            setItems(JSON.parse(result));
+                            setItems(result ?? []);
                             setSelectedItem(null);
                         }
                     ).catch(err => {
-                        setAlertMessage(err);
+                        setAlertMessage(errorMessage(err));
                         console.error("Ошибка при обновлении списка:", err)
                     });
                 }
             ).catch(err => {
                 console.error("Ошибка при удалении:", err)
-                setAlertMessage(err);
+                setAlertMessage(errorMessage(err));
             });
         } else {
             setAlertMessage("Нет выбранного элемента для удаления");
@@ -270,6 +271,24 @@ const DriverComponent = () => {
                                onChange={handleInputChange('LicenseSeries')}></TextInput>
                     <div style={{marginRight: '20px'}}>Номер лицензии</div>
                 </div>
+                <div style={{ display: 'flex', flexDirection: 'row', alignItems: 'flex-start', marginBottom: '10px' }}>
+                    <TextInput style={{width: '150px', marginRight: '20px'}}
+                               value={selectedItem?.LicenseCategories || ''}
+                               onChange={handleInputChange('LicenseCategories')}></TextInput>
+                    <div style={{marginRight: '20px'}}>Категории</div>
+                </div>
+                <div style={{ display: 'flex', flexDirection: 'row', alignItems: 'flex-start', marginBottom: '10px' }}>
+                    <TextInput style={{width: '150px', marginRight: '20px'}}
+                               value={selectedItem?.LicenseIssueDate || ''}
+                               onChange={handleInputChange('LicenseIssueDate')}></TextInput>
+                    <div style={{marginRight: '20px'}}>Дата выдачи лицензии</div>
+                </div>
+                <div style={{ display: 'flex', flexDirection: 'row', alignItems: 'flex-start', marginBottom: '10px' }}>
+                    <TextInput style={{width: '150px', marginRight: '20px'}}
+                               value={selectedItem?.LicenseExpiryDate || ''}
+                               onChange={handleInputChange('LicenseExpiryDate')}></TextInput>
+                    <div style={{marginRight: '20px'}}>Лицензия действует до</div>
+                </div>
                 <div style={{ display: 'flex', flexDirection: 'row', alignItems: 'flex-start',  }}>
                     <Button style={{marginRight: '10px'}} onClick={handleSave}>Сохранить</Button>
                     <Button style={{marginRight: '10px'}} onClick={handleDelete}>Удалить</Button>
@@ -12,8 +12,6 @@ import {
     AssignDriver,
     AssignBus,
     AssignBusStop,
-    GetById,
-    GetByNumber,
     UnassignDriver,
     UnassignBus,
     UnassignBusStop,
@@ -26,10 +24,11 @@ import L from 'leaflet';
 import 'leaflet-routing-machine';
 import original from 'react95/dist/themes/original';
 import CustomAlert from "./CustomAlert.jsx";
+import {models} from "../../wailsjs/go/models";
+import {errorMessage} from "../api.js";
 import { createPortal } from "react-dom";
 import ms_sans_serif from "../assets/fonts/fixedsys.woff2";
 import ms_sans_serif_bold from 'react95/dist/fonts/ms_sans_serif_bold.woff2';
-import { UpdateById as BusUpdateById } from "../../wailsjs/go/routers/BusRouter.js";
 
 // Глобальные стили остаются без изменений
 const GlobalStyles = createGlobalStyle`
@@ -189,18 +188,13 @@ const RouteComponent = () => {
         // Загрузка всех маршрутов
         GetAll().then(
             result => {
-
-                if (!result || result === "null") {
+                if (!result) {
+                    setItems([]);
+                } else if (result.length === 0) {
                     setItems([]);
-                    // setAlertMessage("Список маршрутов пуст");
+                    setAlertMessage("Список маршрутов пуст");
                 } else {
-                    const parsed = JSON.parse(result);
-                    if (parsed.length === 0) {
-                        setItems([]);
-                        setAlertMessage("Список маршрутов пуст");
-                    } else {
-                        setItems(parsed);
-                    }
+                    setItems(result);
                 }
             }
         ).catch(err => {
@@ -211,18 +205,7 @@ const RouteComponent = () => {
 
         // Загрузка всех доступных водителей
         driverRouter.GetAll().then(result => {
-            if (!result || result === "null") {
-                setAvailableDrivers([]);
-                // setAlertMessage("Ошибка загрузки водителей: данные отсутствуют");
-            } else {
-                const parsed = JSON.parse(result);
-                if (parsed.Error) {
-                    setAvailableDrivers([]);
-                    // setAlertMessage("Ошибка загрузки водителей: " + parsed.Error);
-                } else {
-                    setAvailableDrivers(parsed);
-                }
-            }
+            setAvailableDrivers(result ?? []);
         }).catch(err => {
             // setAlertMessage("Ошибка при загрузке водителей: " + err);
             console.error("Ошибка загрузки водителей:", err);
@@ -231,18 +214,7 @@ const RouteComponent = () => {
 
         // Загрузка всех доступных остановок
         busStopRouter.GetAll().then(result => {
-            if (!result || result === "null") {
-                setAvailableBusStops([]);
-                // setAlertMessage("Ошибка загрузки остановок: данные отсутствуют");
-            } else {
-                const parsed = JSON.parse(result);
-                if (parsed.Error) {
-                    setAvailableBusStops([]);
-                    // setAlertMessage("Ошибка загрузки остановок: " + parsed.Error);
-                } else {
-                    setAvailableBusStops(parsed);
-                }
-            }
+            setAvailableBusStops(result ?? []);
         }).catch(err => {
             // setAlertMessage("Ошибка при загрузке остановок: " + err);
             console.error("Ошибка при загрузке остановок:", err);
@@ -251,18 +223,7 @@ const RouteComponent = () => {
 
         // Загрузка всех доступных автобусов
         busRouter.GetAll().then(result => {
-            if (!result || result === "null") {
-                setAvailableBuses([]);
-                // setAlertMessage("Ошибка загрузки автобусов: данные отсутствуют");
-            } else {
-                const parsed = JSON.parse(result);
-                if (parsed.Error) {
-                    setAvailableBuses([]);
-                    // setAlertMessage("Ошибка загрузки автобусов: " + parsed.Error);
-                } else {
-                    setAvailableBuses(parsed);
-                }
-            }
+            setAvailableBuses(result ?? []);
         }).catch(err => {
             // setAlertMessage("Ошибка при загрузке автобусов: " + err);
             console.error("Ошибка при загрузке автобусов:", err);
@@ -276,29 +237,18 @@ const RouteComponent = () => {
 
         GetAllDriversById(item.ID).then(
             driverResult => {
-                let driversData = [];
-                if (JSON.parse(driverResult).Error) {
-                    console.log(JSON.parse(driverResult));
-                    setDrivers([]);
-                    return;
-                }
-                driversData = JSON.parse(driverResult);
+                const driversData = driverResult ?? [];
                 console.log("Drivers:", driversData);
                 setDrivers(driversData);
             }
         ).catch(err => {
-            setAlertMessage("Ошибка при загрузке водителей: " + err);
+            setAlertMessage("Ошибка при загрузке водителей: " + errorMessage(err));
             console.error("Ошибка при загрузке водителей:", err);
         });
 
         GetAllBusStopsById(item.ID).then(
             stopResult => {
-                let stopsData = [];
-                if (JSON.parse(stopResult).Error) {
-                    setBusStops([]);
-                    return;
-                }
-                stopsData = JSON.parse(stopResult).map(stop => ({
+                const stopsData = (stopResult ?? []).map(stop => ({
                     ...stop,
                     Lat: parseFloat(stop.Lat),
                     Long: parseFloat(stop.Long),
@@ -310,23 +260,18 @@ const RouteComponent = () => {
                 }
             }
         ).catch(err => {
-            setAlertMessage("Ошибка при загрузке остановок: " + err);
+            setAlertMessage("Ошибка при загрузке остановок: " + errorMessage(err));
             console.error("Ошибка при загрузке остановок:", err);
         });
 
         GetAllBusesById(item.ID).then(
             busResult => {
-                let busesData = [];
-                if (JSON.parse(busResult).Error) {
-                    setBuses([]);
-                    return;
-                }
-                busesData = JSON.parse(busResult);
+                const busesData = busResult ?? [];
                 console.log("Buses:", busesData);
                 setBuses(busesData);
             }
         ).catch(err => {
-            setAlertMessage("Ошибка при загрузке автобусов: " + err);
+            setAlertMessage("Ошибка при загрузке автобусов: " + errorMessage(err));
             console.error("Ошибка при загрузке автобусов:", err);
         });
     };
@@ -400,67 +345,18 @@ const RouteComponent = () => {
                 setAlertMessage(valid.message);
             } else {
                 selectedItem.ID = null;
-                Add(JSON.stringify(selectedItem)).then(
-                    result => {
-                        if (JSON.parse(result).Error) {
-                            setAlertMessage(JSON.parse(result).Error);
-                        }
-                        GetAll().then(
-                            result => {
-                                setItems(JSON.parse(result));
-                            }
-                        ).catch(err => {
-                            setAlertMessage(err);
-                            console.error("Ошибка при обновлении списка:", err);
-                        });
-                    }
-                ).catch(err => {
-                    setAlertMessage(err);
-                    console.error("Ошибка при создании:", err);
-                });
-                const number = selectedItem.Number;
-                let id;
-                GetByNumber(number).then(
-                    result => {
-                        id = JSON.parse(result).ID;
-                        // Привязка водителей
-                        drivers.forEach((element) => {
-                            AssignDriver(id, element.ID).then(
-                                result => {
-                                    console.log("Водитель привязан:", result);
-                                }
-                            ).catch(err => {
-                                setAlertMessage(err);
-                                console.error("Ошибка при привязке водителя:", err);
-                            });
-                        });
-                        // Привязка автобусов
-                        buses.forEach((element) => {
-                            AssignBus(id, element.ID).then(
-                                result => {
-                                    console.log("Автобус привязан:", result);
-                                }
-                            ).catch(err => {
-                                setAlertMessage(err);
-                                console.error("Ошибка при привязке автобуса:", err);
-                            });
-                        });
-                        // Привязка остановок
-                        busStops.forEach((element) => {
-                            AssignBusStop(id, element.ID).then(
-                                result => {
-                                    console.log("Остановка привязана:", result);
-                                }
-                            ).catch(err => {
-                                setAlertMessage(err);
-                                console.error("Ошибка при привязке остановки:", err);
-                            });
-                        });
-                    }
-                ).catch(err => {
-                    setAlertMessage(err);
-                    console.error("Ошибка при получении маршрута:", err);
-                });
+                Add(models.Route.createFrom(selectedItem))
+                    .then(route => Promise.all([
+                        ...drivers.map(driver => AssignDriver(route.ID, driver.ID)),
+                        ...buses.map(bus => AssignBus(route.ID, bus.ID)),
+                        ...busStops.map(busStop => AssignBusStop(route.ID, busStop.ID)),
+                    ]))
+                    .then(() => GetAll())
+                    .then(result => setItems(result ?? []))
+                    .catch(err => {
+                        setAlertMessage(errorMessage(err));
+                        console.error("Ошибка при создании маршрута:", err);
+                    });
                 setSelectedItem(null);
             }
         } else {
@@ -478,15 +374,9 @@ const RouteComponent = () => {
             }
 
             const routeId = selectedItem.ID;
-            console.log(JSON.stringify(selectedItem))
             // Сначала обновляем маршрут
-            UpdateById(JSON.stringify(selectedItem))
-                .then(result => {
-                    const parsedResult = JSON.parse(result);
-                    console.log(result)
-                    if (parsedResult.Error) {
-                        throw new Error(parsedResult.Error);
-                    }
+            UpdateById(models.Route.createFrom(selectedItem))
+                .then(() => {
                     // Обещания для отвязки существующих сущностей только после успешного обновления
                     const unassignPromises = [
                         ...drivers.map(driver => UnassignDriver(routeId, driver.ID).catch(err => {
@@ -527,14 +417,14 @@ const RouteComponent = () => {
                     return GetAll();
                 })
                 .then(result => {
-                    setItems(JSON.parse(result));
+                    setItems(result ?? []);
                     setSelectedItem(null);
                     setDrivers([]);
                     setBusStops([]);
                     setBuses([]);
                 })
                 .catch(err => {
-                    setAlertMessage(err.message || "Ошибка при сохранении маршрута");
+                    setAlertMessage(errorMessage(err) || "Ошибка при сохранении маршрута");
                     console.error("Ошибка при сохранении маршрута:", err);
                 });
         } else {
@@ -573,14 +463,14 @@ const RouteComponent = () => {
                     return GetAll();
                 })
                 .then(result => {
-                    setItems(JSON.parse(result));
+                    setItems(result ?? []);
                     setSelectedItem(null);
                     setDrivers([]);
                     setBusStops([]);
                     setBuses([]);
                 })
                 .catch(err => {
-                    setAlertMessage(err.message || "Ошибка при удалении маршрута");
+                    setAlertMessage(errorMessage(err) || "Ошибка при удалении маршрута");
                     console.error("Ошибка при удалении маршрута:", err);
                 });
         } else {
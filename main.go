@@ -1,6 +1,9 @@
 package main
 
 import (
+	"busManager/config"
+	"busManager/database"
+	"busManager/responses"
 	"busManager/routers"
 	"context"
 	"embed"
@@ -8,32 +11,120 @@ import (
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
+	"log/slog"
+	"os"
 )
 
 //go:embed all:frontend/dist
 var assets embed.FS
 
+// newBackend opens and migrates the database, or builds the in-memory store
+// when the demo feature is enabled.
+func newBackend(cfg *config.Config) (*routers.Backend, error) {
+	if cfg.Enabled(config.FeatureDemo) {
+		slog.Info("Running in demo mode, changes are not saved")
+		return routers.NewMemoryBackend(cfg)
+	}
+	if err := cfg.EnsureDataDir(); err != nil {
+		return nil, err
+	}
+	if legacy, err := cfg.MigrateLegacyDB(); err != nil {
+		return nil, err
+	} else if legacy != "" {
+		slog.Info("Copied the database of an earlier version", "from", legacy, "to", cfg.DBPath())
+	}
+	slog.Info("Opening database", "path", cfg.DBPath())
+	db, err := database.Open(cfg.DBPath())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return routers.NewSqliteBackend(db, cfg), nil
+}
+
 func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Println("Failed to load config:", err)
+		return
+	}
+	level, _ := cfg.SlogLevel()
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+
+	backend, err := newBackend(cfg)
+	if err != nil {
+		slog.Error("Failed to open storage", "error", err)
+		return
+	}
+	defer backend.Close()
 	//// Create an instance of the app structure
 	app, err := NewApp()
 	if err != nil {
-		fmt.Println(err)
+		slog.Error("Failed to create app", "error", err)
+		return
+	}
+	busRouter, err := routers.NewBusRouter(backend)
+	if err != nil {
+		slog.Error("Failed to create bus router", "error", err)
+		return
 	}
-	busRouter, err := routers.NewBusRouter()
+	busStopRouter, err := routers.NewBusStopRouter(backend)
 	if err != nil {
-		fmt.Println(err)
+		slog.Error("Failed to create bus stop router", "error", err)
+		return
 	}
-	busStopRouter, err := routers.NewBusStopRouter()
+	driverRouter, err := routers.NewDriverRouter(backend)
 	if err != nil {
-		fmt.Println(err)
+		slog.Error("Failed to create driver router", "error", err)
+		return
 	}
-	driverRouter, err := routers.NewDriverRouter()
+	routeRouter, err := routers.NewRouteRouter(backend)
 	if err != nil {
-		fmt.Println(err)
+		slog.Error("Failed to create route router", "error", err)
+		return
 	}
-	routeRouter, err := routers.NewRouteRouter()
+	backupRouter, err := routers.NewBackupRouter(backend)
 	if err != nil {
-		fmt.Println(err)
+		slog.Error("Failed to create backup router", "error", err)
+		return
+	}
+	auditRouter, err := routers.NewAuditRouter(backend)
+	if err != nil {
+		slog.Error("Failed to create audit router", "error", err)
+		return
+	}
+	searchRouter, err := routers.NewSearchRouter(backend)
+	if err != nil {
+		slog.Error("Failed to create search router", "error", err)
+		return
+	}
+	medicalCheckRouter, err := routers.NewMedicalCheckRouter(backend)
+	if err != nil {
+		slog.Error("Failed to create medical check router", "error", err)
+		return
+	}
+	shiftRouter, err := routers.NewShiftRouter(backend)
+	if err != nil {
+		slog.Error("Failed to create shift router", "error", err)
+		return
+	}
+	maintenanceRouter, err := routers.NewMaintenanceRouter(backend)
+	if err != nil {
+		slog.Error("Failed to create maintenance router", "error", err)
+		return
+	}
+	workOrderRouter, err := routers.NewWorkOrderRouter(backend)
+	if err != nil {
+		slog.Error("Failed to create work order router", "error", err)
+		return
+	}
+	mileageRouter, err := routers.NewMileageRouter(backend)
+	if err != nil {
+		slog.Error("Failed to create mileage router", "error", err)
+		return
 	}
 	// Create application with options
 	err = wails.Run(&options.App{
@@ -44,12 +135,26 @@ func main() {
 			Assets: assets,
 		},
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
+		ErrorFormatter:   responses.FormatError,
 		OnStartup: func(ctx context.Context) {
 			app.startup(ctx)
 			busRouter.Startup(ctx)
 			busStopRouter.Startup(ctx)
 			driverRouter.Startup(ctx)
 			routeRouter.Startup(ctx)
+			backupRouter.Startup(ctx)
+			auditRouter.Startup(ctx)
+			searchRouter.Startup(ctx)
+			medicalCheckRouter.Startup(ctx)
+			shiftRouter.Startup(ctx)
+			maintenanceRouter.Startup(ctx)
+			workOrderRouter.Startup(ctx)
+			mileageRouter.Startup(ctx)
+		},
+		OnShutdown: func(ctx context.Context) {
+			if err := backend.Close(); err != nil {
+				slog.Error("Failed to close database", "error", err)
+			}
 		},
 		Bind: []interface{}{
 			app,
@@ -57,6 +162,14 @@ func main() {
 			busStopRouter,
 			driverRouter,
 			routeRouter,
+			backupRouter,
+			auditRouter,
+			searchRouter,
+			medicalCheckRouter,
+			shiftRouter,
+			maintenanceRouter,
+			workOrderRouter,
+			mileageRouter,
 		},
 	})
 
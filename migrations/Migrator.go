@@ -0,0 +1,305 @@
+package migrations
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+// Migration is one versioned schema step. Files are named
+// NNNN_name.up.sql and NNNN_name.down.sql. Data, when set, runs in the same
+// transaction after Up for changes SQL cannot express; it is not part of
+// the checksum and has no down step.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+	Data     func(tx *sql.Tx) error
+}
+
+// dataSteps are the Data steps of the embedded migrations by version.
+var dataSteps = map[int]func(tx *sql.Tx) error{
+	17: canonicalRegisterNumbers,
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator returns a migrator for the migrations embedded in the binary.
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	list, err := Load(embedded)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].Data = dataSteps[list[i].Version]
+	}
+	return NewMigratorFrom(db, list), nil
+}
+
+func NewMigratorFrom(db *sql.DB, list []Migration) *Migrator {
+	return &Migrator{db: db, migrations: list}
+}
+
+// Load reads migrations from the sql directory of fsys, ordered by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, "sql")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		version, name, direction, err := parseFileName(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		data, err := fs.ReadFile(fsys, path.Join("sql", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if m.Name != name {
+			return nil, fmt.Errorf("Migration %d has conflicting names %q and %q", version, m.Name, name)
+		}
+		if direction == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+	list := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" {
+			return nil, fmt.Errorf("Migration %d has no up step", m.Version)
+		}
+		m.Checksum = checksum(m.Up, m.Down)
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+func parseFileName(fileName string) (int, string, string, error) {
+	base := strings.TrimSuffix(fileName, ".sql")
+	var direction string
+	switch {
+	case strings.HasSuffix(base, ".up"):
+		direction = "up"
+	case strings.HasSuffix(base, ".down"):
+		direction = "down"
+	default:
+		return 0, "", "", fmt.Errorf("Migration file %s must end with .up.sql or .down.sql", fileName)
+	}
+	base = strings.TrimSuffix(base, "."+direction)
+	parts := strings.SplitN(base, "_", 2)
+	if len(parts) != 2 {
+		return 0, "", "", fmt.Errorf("Migration file %s must be named NNNN_name", fileName)
+	}
+	version, err := strconv.Atoi(parts[0])
+	if err != nil || version <= 0 {
+		return 0, "", "", fmt.Errorf("Migration file %s has invalid version", fileName)
+	}
+	return version, parts[1], direction, nil
+}
+
+func checksum(up, down string) string {
+	sum := sha256.Sum256([]byte(up + "\x00" + down))
+	return hex.EncodeToString(sum[:])
+}
+
+func (m *Migrator) ensureTable() error {
+	_, err := m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		checksum TEXT NOT NULL,
+		applied_at DATETIME NOT NULL
+	)`)
+	return err
+}
+
+type appliedMigration struct {
+	version  int
+	name     string
+	checksum string
+}
+
+func (m *Migrator) applied() ([]appliedMigration, error) {
+	rows, err := m.db.Query(`SELECT version, name, checksum FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []appliedMigration
+	for rows.Next() {
+		a := appliedMigration{}
+		if err := rows.Scan(&a.version, &a.name, &a.checksum); err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+// verify checks that every applied migration is still shipped unchanged.
+func (m *Migrator) verify(applied []appliedMigration) error {
+	known := map[int]Migration{}
+	for _, mig := range m.migrations {
+		known[mig.Version] = mig
+	}
+	for _, a := range applied {
+		mig, ok := known[a.version]
+		if !ok {
+			return fmt.Errorf("Database has migration %d (%s) which is unknown to this version of the application", a.version, a.name)
+		}
+		if mig.Checksum != a.checksum {
+			return fmt.Errorf("Checksum mismatch for migration %d (%s): applied migration was modified", a.version, a.name)
+		}
+	}
+	return nil
+}
+
+// Check verifies that the applied migrations are known and unchanged without
+// applying anything, e.g. before restoring a backup made by another version.
+func (m *Migrator) Check() error {
+	var count int
+	err := m.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`).Scan(&count)
+	if err != nil || count == 0 {
+		return err
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	return m.verify(applied)
+}
+
+// Version returns the highest applied migration version, 0 for an empty database.
+func (m *Migrator) Version() (int, error) {
+	if err := m.ensureTable(); err != nil {
+		return 0, err
+	}
+	var version sql.NullInt64
+	err := m.db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+	return int(version.Int64), nil
+}
+
+// Up applies all pending migrations in order, each in its own transaction.
+func (m *Migrator) Up() error {
+	if err := m.ensureTable(); err != nil {
+		return err
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	if err := m.verify(applied); err != nil {
+		return err
+	}
+	done := map[int]bool{}
+	for _, a := range applied {
+		done[a.version] = true
+	}
+	for _, mig := range m.migrations {
+		if done[mig.Version] {
+			continue
+		}
+		if err := m.apply(mig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) apply(mig Migration) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(mig.Up); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("Migration %d (%s) failed: %w", mig.Version, mig.Name, err)
+	}
+	if mig.Data != nil {
+		if err := mig.Data(tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("Migration %d (%s) failed: %w", mig.Version, mig.Name, err)
+		}
+	}
+	_, err = tx.Exec(`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES ($1, $2, $3, $4)`,
+		mig.Version, mig.Name, mig.Checksum, time.Now().UTC())
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Down reverts the last steps applied migrations, newest first.
+func (m *Migrator) Down(steps int) error {
+	if err := m.ensureTable(); err != nil {
+		return err
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	if err := m.verify(applied); err != nil {
+		return err
+	}
+	known := map[int]Migration{}
+	for _, mig := range m.migrations {
+		known[mig.Version] = mig
+	}
+	for i := len(applied) - 1; i >= 0 && steps > 0; i-- {
+		mig := known[applied[i].version]
+		if err := m.revert(mig); err != nil {
+			return err
+		}
+		steps--
+	}
+	return nil
+}
+
+func (m *Migrator) revert(mig Migration) error {
+	if strings.TrimSpace(mig.Down) == "" {
+		return fmt.Errorf("Migration %d (%s) has no down step", mig.Version, mig.Name)
+	}
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(mig.Down); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("Reverting migration %d (%s) failed: %w", mig.Version, mig.Name, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = $1`, mig.Version); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,327 @@
+package migrations
+
+import (
+	"database/sql"
+	_ "github.com/mattn/go-sqlite3"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func setupTestDB(t *testing.T) (*sql.DB, func()) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	return db, func() { db.Close() }
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = $1`, name).Scan(&count)
+	if err != nil {
+		t.Fatalf("Failed to query sqlite_master: %v", err)
+	}
+	return count > 0
+}
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"sql/0001_first.up.sql":    {Data: []byte(`CREATE TABLE first (id TEXT PRIMARY KEY);`)},
+		"sql/0001_first.down.sql":  {Data: []byte(`DROP TABLE first;`)},
+		"sql/0002_second.up.sql":   {Data: []byte(`CREATE TABLE second (id TEXT PRIMARY KEY);`)},
+		"sql/0002_second.down.sql": {Data: []byte(`DROP TABLE second;`)},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Embedded migrations are ordered", func(t *testing.T) {
+		list, err := Load(embedded)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(list) == 0 {
+			t.Fatalf("Expected embedded migrations")
+		}
+		for i, m := range list {
+			if m.Version != i+1 {
+				t.Errorf("Expected version %d, got %d", i+1, m.Version)
+			}
+			if m.Checksum == "" {
+				t.Errorf("Expected checksum for migration %d", m.Version)
+			}
+		}
+	})
+
+	t.Run("Invalid file name", func(t *testing.T) {
+		fsys := fstest.MapFS{"sql/first.up.sql": {Data: []byte(`SELECT 1;`)}}
+		_, err := Load(fsys)
+		if err == nil {
+			t.Errorf("Expected error for invalid file name")
+		}
+	})
+
+	t.Run("Missing up step", func(t *testing.T) {
+		fsys := fstest.MapFS{"sql/0001_first.down.sql": {Data: []byte(`SELECT 1;`)}}
+		_, err := Load(fsys)
+		if err == nil {
+			t.Errorf("Expected error for missing up step")
+		}
+	})
+}
+
+func TestMigrator_Up(t *testing.T) {
+	t.Run("Embedded migrations create schema", func(t *testing.T) {
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		m, err := NewMigrator(db)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := m.Up(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for _, table := range []string{"buses", "drivers", "bus_stops", "routes", "routes_buses", "routes_drivers", "routes_bus_stops"} {
+			if !tableExists(t, db, table) {
+				t.Errorf("Expected table %s to exist", table)
+			}
+		}
+	})
+
+	t.Run("Up is idempotent", func(t *testing.T) {
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		list, _ := Load(testFS())
+		m := NewMigratorFrom(db, list)
+		if err := m.Up(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := m.Up(); err != nil {
+			t.Errorf("Expected no error on second run, got %v", err)
+		}
+		version, err := m.Version()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if version != 2 {
+			t.Errorf("Expected version 2, got %d", version)
+		}
+	})
+
+	t.Run("Checksum mismatch", func(t *testing.T) {
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		list, _ := Load(testFS())
+		if err := NewMigratorFrom(db, list).Up(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		fsys := testFS()
+		fsys["sql/0001_first.up.sql"] = &fstest.MapFile{Data: []byte(`CREATE TABLE first (id INTEGER PRIMARY KEY);`)}
+		changed, _ := Load(fsys)
+		err := NewMigratorFrom(db, changed).Up()
+		if err == nil || !strings.Contains(err.Error(), "Checksum mismatch") {
+			t.Errorf("Expected checksum mismatch error, got %v", err)
+		}
+	})
+
+	t.Run("Unknown applied migration", func(t *testing.T) {
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		list, _ := Load(testFS())
+		if err := NewMigratorFrom(db, list).Up(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		err := NewMigratorFrom(db, list[:1]).Up()
+		if err == nil || !strings.Contains(err.Error(), "unknown") {
+			t.Errorf("Expected unknown migration error, got %v", err)
+		}
+	})
+
+	t.Run("Failed migration is rolled back", func(t *testing.T) {
+		db, cleanup := setupTestDB(t)
+		defer cleanup()
+
+		fsys := testFS()
+		fsys["sql/0002_second.up.sql"] = &fstest.MapFile{Data: []byte(`CREATE TABLE second (id TEXT); SELECT * FROM missing;`)}
+		list, _ := Load(fsys)
+		m := NewMigratorFrom(db, list)
+		if err := m.Up(); err == nil {
+			t.Fatalf("Expected error from broken migration")
+		}
+		if tableExists(t, db, "second") {
+			t.Errorf("Expected table second to be rolled back")
+		}
+		version, _ := m.Version()
+		if version != 1 {
+			t.Errorf("Expected version 1, got %d", version)
+		}
+	})
+}
+
+func TestMigrator_Down(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	list, _ := Load(testFS())
+	m := NewMigratorFrom(db, list)
+	if err := m.Up(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("Revert one step", func(t *testing.T) {
+		if err := m.Down(1); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if tableExists(t, db, "second") {
+			t.Errorf("Expected table second to be dropped")
+		}
+		if !tableExists(t, db, "first") {
+			t.Errorf("Expected table first to remain")
+		}
+		version, _ := m.Version()
+		if version != 1 {
+			t.Errorf("Expected version 1, got %d", version)
+		}
+	})
+
+	t.Run("Revert more steps than applied", func(t *testing.T) {
+		if err := m.Down(5); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		version, _ := m.Version()
+		if version != 0 {
+			t.Errorf("Expected version 0, got %d", version)
+		}
+	})
+}
+
+func TestEmbeddedMigrations_DownUp(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	m, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := m.Down(len(m.migrations)); err != nil {
+		t.Fatalf("Expected no error reverting all migrations, got %v", err)
+	}
+	if tableExists(t, db, "buses") {
+		t.Errorf("Expected table buses to be dropped")
+	}
+	if err := m.Up(); err != nil {
+		t.Errorf("Expected no error re-applying migrations, got %v", err)
+	}
+}
+
+func TestMigrator_Check(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	list, _ := Load(testFS())
+	if err := NewMigratorFrom(db, list[:1]).Check(); err != nil {
+		t.Errorf("Expected no error on empty database, got %v", err)
+	}
+	if err := NewMigratorFrom(db, list).Up(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := NewMigratorFrom(db, list).Check(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := NewMigratorFrom(db, list[:1]).Check(); err == nil {
+		t.Errorf("Expected error for database newer than migrations")
+	}
+}
+
+func TestEmbeddedMigrations_RegisterNumbers(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	m, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := NewMigratorFrom(db, m.migrations[:1]).Up(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	legacy := map[string]string{"1": "а 123 вс 77", "2": "ао 456 77 rus", "3": "б456ББ77", "4": "ж901жж77"}
+	for id, number := range legacy {
+		_, err := db.Exec(`INSERT INTO buses (id, brand, bus_model, register_number, assembly_date, last_repair_date)
+			VALUES ($1, 'ЛиАЗ', '5292', $2, '2018-03-01', '2024-05-12')`, id, number)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := map[string]string{"1": "А123ВС77", "2": "АО45677", "3": "Б456ББ77", "4": "Ж901ЖЖ77"}
+	for id, number := range want {
+		var stored string
+		if err := db.QueryRow(`SELECT register_number FROM buses WHERE id = $1`, id).Scan(&stored); err != nil || stored != number {
+			t.Errorf("Expected bus %s with %s, got %q (%v)", id, number, stored, err)
+		}
+	}
+	rows, err := db.Query(`SELECT bus_id, register_number FROM invalid_register_numbers ORDER BY bus_id`)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer rows.Close()
+	var invalid []string
+	for rows.Next() {
+		var id, number string
+		if err := rows.Scan(&id, &number); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		invalid = append(invalid, id+" "+number)
+	}
+	if strings.Join(invalid, ", ") != "3 Б456ББ77, 4 Ж901ЖЖ77" {
+		t.Errorf("Expected the numbers that are not plates recorded, got %v", invalid)
+	}
+}
+
+func TestEmbeddedMigrations_WorkOrderKopecks(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	m, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := NewMigratorFrom(db, m.migrations[:18]).Up(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	_, err = db.Exec(`INSERT INTO buses (id, brand, bus_model, register_number, assembly_date, last_repair_date)
+		VALUES ('bus', 'ЛиАЗ', '5292', 'А123ВС77', '2018-03-01', '2024-05-12');
+		INSERT INTO work_orders (id, bus_id, status, opened_at, defect, parts, labour_cost, cost, updated_at)
+		VALUES ('order', 'bus', 'open', '2024-05-01', 'Brakes', '[{"Name":"Pad","Quantity":4,"UnitPrice":15.1}]', 30.05, 90.45, '2024-05-01')`)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var parts string
+	var labour, cost int64
+	err = db.QueryRow(`SELECT parts, labour_cost, cost FROM work_orders WHERE id = 'order'`).Scan(&parts, &labour, &cost)
+	if err != nil || parts != `[{"Name":"Pad","Quantity":4,"UnitPrice":1510}]` || labour != 3005 || cost != 9045 {
+		t.Errorf("Expected the money in kopecks, got %s %d %d (%v)", parts, labour, cost, err)
+	}
+	if _, err := db.Exec(`PRAGMA foreign_keys = ON; DELETE FROM buses WHERE id = 'bus'`); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var busId sql.NullString
+	if err := db.QueryRow(`SELECT bus_id FROM work_orders WHERE id = 'order'`).Scan(&busId); err != nil || busId.Valid {
+		t.Errorf("Expected the order kept without its bus, got %v (%v)", busId, err)
+	}
+}
@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"busManager/plates"
+	"database/sql"
+)
+
+// canonicalRegisterNumbers is the data step of migration 17. It finishes
+// what migration 7 started in SQL: every register number is stored as
+// plates.Normalize returns it, upper case included, unless that would clash
+// with another bus. Numbers that still are not valid plates are recorded in
+// invalid_register_numbers.
+func canonicalRegisterNumbers(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT id, register_number FROM buses`)
+	if err != nil {
+		return err
+	}
+	numbers := map[string]string{}
+	for rows.Next() {
+		var id, number string
+		if err := rows.Scan(&id, &number); err != nil {
+			rows.Close()
+			return err
+		}
+		numbers[id] = number
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	taken := map[string]int{}
+	for _, number := range numbers {
+		taken[number]++
+		if normalized := plates.Normalize(number); normalized != number {
+			taken[normalized]++
+		}
+	}
+	for id, number := range numbers {
+		if normalized := plates.Normalize(number); normalized != number && taken[normalized] == 1 {
+			if _, err := tx.Exec(`UPDATE buses SET register_number = $1 WHERE id = $2`, normalized, id); err != nil {
+				return err
+			}
+			number = normalized
+		}
+		if _, err := plates.Parse(number); err != nil {
+			_, err := tx.Exec(`INSERT INTO invalid_register_numbers (bus_id, register_number) VALUES ($1, $2)`, id, number)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
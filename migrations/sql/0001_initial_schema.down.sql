@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "routes_buses";
+DROP TABLE IF EXISTS "routes_drivers";
+DROP TABLE IF EXISTS "routes_bus_stops";
+DROP TABLE IF EXISTS "drivers";
+DROP TABLE IF EXISTS "bus_stops";
+DROP TABLE IF EXISTS "routes";
+DROP TABLE IF EXISTS "buses";
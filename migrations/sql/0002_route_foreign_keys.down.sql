@@ -0,0 +1,26 @@
+DROP INDEX IF EXISTS "idx_routes_buses_bus_id";
+CREATE TABLE "routes_buses_old" (
+	"route_id"	TEXT,
+	"bus_id"	TEXT
+);
+INSERT INTO "routes_buses_old" ("route_id", "bus_id") SELECT "route_id", "bus_id" FROM "routes_buses";
+DROP TABLE "routes_buses";
+ALTER TABLE "routes_buses_old" RENAME TO "routes_buses";
+
+DROP INDEX IF EXISTS "idx_routes_drivers_driver_id";
+CREATE TABLE "routes_drivers_old" (
+	"route_id"	TEXT,
+	"driver_id"	TEXT
+);
+INSERT INTO "routes_drivers_old" ("route_id", "driver_id") SELECT "route_id", "driver_id" FROM "routes_drivers";
+DROP TABLE "routes_drivers";
+ALTER TABLE "routes_drivers_old" RENAME TO "routes_drivers";
+
+DROP INDEX IF EXISTS "idx_routes_bus_stops_bus_stop_id";
+CREATE TABLE "routes_bus_stops_old" (
+	"route_id"	TEXT,
+	"bus_stop_id"	TEXT
+);
+INSERT INTO "routes_bus_stops_old" ("route_id", "bus_stop_id") SELECT "route_id", "bus_stop_id" FROM "routes_bus_stops";
+DROP TABLE "routes_bus_stops";
+ALTER TABLE "routes_bus_stops_old" RENAME TO "routes_bus_stops";
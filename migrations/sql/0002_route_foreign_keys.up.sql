@@ -0,0 +1,44 @@
+-- Junction tables get a primary key and foreign keys to both sides.
+-- Rows pointing to missing routes or entities are dropped on the way.
+
+CREATE TABLE "routes_buses_new" (
+	"route_id"	TEXT NOT NULL REFERENCES "routes"("id") ON DELETE CASCADE,
+	"bus_id"	TEXT NOT NULL REFERENCES "buses"("id") ON DELETE CASCADE,
+	PRIMARY KEY("route_id", "bus_id")
+);
+INSERT INTO "routes_buses_new" ("route_id", "bus_id")
+SELECT DISTINCT j."route_id", j."bus_id"
+FROM "routes_buses" j
+JOIN "routes" r ON r."id" = j."route_id"
+JOIN "buses" p ON p."id" = j."bus_id";
+DROP TABLE "routes_buses";
+ALTER TABLE "routes_buses_new" RENAME TO "routes_buses";
+CREATE INDEX "idx_routes_buses_bus_id" ON "routes_buses"("bus_id");
+
+CREATE TABLE "routes_drivers_new" (
+	"route_id"	TEXT NOT NULL REFERENCES "routes"("id") ON DELETE CASCADE,
+	"driver_id"	TEXT NOT NULL REFERENCES "drivers"("id") ON DELETE CASCADE,
+	PRIMARY KEY("route_id", "driver_id")
+);
+INSERT INTO "routes_drivers_new" ("route_id", "driver_id")
+SELECT DISTINCT j."route_id", j."driver_id"
+FROM "routes_drivers" j
+JOIN "routes" r ON r."id" = j."route_id"
+JOIN "drivers" p ON p."id" = j."driver_id";
+DROP TABLE "routes_drivers";
+ALTER TABLE "routes_drivers_new" RENAME TO "routes_drivers";
+CREATE INDEX "idx_routes_drivers_driver_id" ON "routes_drivers"("driver_id");
+
+CREATE TABLE "routes_bus_stops_new" (
+	"route_id"	TEXT NOT NULL REFERENCES "routes"("id") ON DELETE CASCADE,
+	"bus_stop_id"	TEXT NOT NULL REFERENCES "bus_stops"("id") ON DELETE CASCADE,
+	PRIMARY KEY("route_id", "bus_stop_id")
+);
+INSERT INTO "routes_bus_stops_new" ("route_id", "bus_stop_id")
+SELECT DISTINCT j."route_id", j."bus_stop_id"
+FROM "routes_bus_stops" j
+JOIN "routes" r ON r."id" = j."route_id"
+JOIN "bus_stops" p ON p."id" = j."bus_stop_id";
+DROP TABLE "routes_bus_stops";
+ALTER TABLE "routes_bus_stops_new" RENAME TO "routes_bus_stops";
+CREATE INDEX "idx_routes_bus_stops_bus_stop_id" ON "routes_bus_stops"("bus_stop_id");
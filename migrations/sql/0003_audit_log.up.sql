@@ -0,0 +1,14 @@
+CREATE TABLE audit_log (
+    id TEXT PRIMARY KEY,
+    created_at DATETIME NOT NULL,
+    user TEXT NOT NULL,
+    action TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    route_id TEXT NOT NULL DEFAULT '',
+    before TEXT NOT NULL DEFAULT '',
+    after TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX idx_audit_log_entity ON audit_log (entity_type, entity_id, created_at);
+CREATE INDEX idx_audit_log_route_id ON audit_log (route_id, created_at);
+CREATE INDEX idx_audit_log_created_at ON audit_log (created_at);
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS trash_route_links;
+
+DELETE FROM buses WHERE deleted_at IS NOT NULL;
+DELETE FROM drivers WHERE deleted_at IS NOT NULL;
+DELETE FROM bus_stops WHERE deleted_at IS NOT NULL;
+DELETE FROM routes WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE buses DROP COLUMN deleted_at;
+ALTER TABLE drivers DROP COLUMN deleted_at;
+ALTER TABLE bus_stops DROP COLUMN deleted_at;
+ALTER TABLE routes DROP COLUMN deleted_at;
@@ -0,0 +1,15 @@
+ALTER TABLE buses ADD COLUMN deleted_at DATETIME;
+ALTER TABLE drivers ADD COLUMN deleted_at DATETIME;
+ALTER TABLE bus_stops ADD COLUMN deleted_at DATETIME;
+ALTER TABLE routes ADD COLUMN deleted_at DATETIME;
+
+-- route assignments removed when owner_type/owner_id was moved to the trash,
+-- re-created when it is restored
+CREATE TABLE trash_route_links (
+    owner_type TEXT NOT NULL,
+    owner_id TEXT NOT NULL,
+    route_id TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    PRIMARY KEY (owner_type, owner_id, route_id, entity_type, entity_id)
+);
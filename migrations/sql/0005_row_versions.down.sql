@@ -0,0 +1,8 @@
+ALTER TABLE buses DROP COLUMN version;
+ALTER TABLE buses DROP COLUMN updated_at;
+ALTER TABLE drivers DROP COLUMN version;
+ALTER TABLE drivers DROP COLUMN updated_at;
+ALTER TABLE bus_stops DROP COLUMN version;
+ALTER TABLE bus_stops DROP COLUMN updated_at;
+ALTER TABLE routes DROP COLUMN version;
+ALTER TABLE routes DROP COLUMN updated_at;
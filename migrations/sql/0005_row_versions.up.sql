@@ -0,0 +1,8 @@
+ALTER TABLE buses ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE buses ADD COLUMN updated_at DATETIME NOT NULL DEFAULT '0001-01-01 00:00:00+00:00';
+ALTER TABLE drivers ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE drivers ADD COLUMN updated_at DATETIME NOT NULL DEFAULT '0001-01-01 00:00:00+00:00';
+ALTER TABLE bus_stops ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE bus_stops ADD COLUMN updated_at DATETIME NOT NULL DEFAULT '0001-01-01 00:00:00+00:00';
+ALTER TABLE routes ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE routes ADD COLUMN updated_at DATETIME NOT NULL DEFAULT '0001-01-01 00:00:00+00:00';
@@ -0,0 +1,15 @@
+DROP TRIGGER IF EXISTS search_buses_insert;
+DROP TRIGGER IF EXISTS search_buses_update;
+DROP TRIGGER IF EXISTS search_buses_delete;
+DROP TRIGGER IF EXISTS search_drivers_insert;
+DROP TRIGGER IF EXISTS search_drivers_update;
+DROP TRIGGER IF EXISTS search_drivers_delete;
+DROP TRIGGER IF EXISTS search_bus_stops_insert;
+DROP TRIGGER IF EXISTS search_bus_stops_update;
+DROP TRIGGER IF EXISTS search_bus_stops_delete;
+DROP TRIGGER IF EXISTS search_routes_insert;
+DROP TRIGGER IF EXISTS search_routes_update;
+DROP TRIGGER IF EXISTS search_routes_delete;
+
+DROP TABLE IF EXISTS search_index;
+DROP TABLE IF EXISTS search_documents;
@@ -0,0 +1,94 @@
+-- one row per live bus, driver, bus stop and route with the text the global
+-- search looks at; the full-text index over it is created by the application
+CREATE TABLE search_documents (
+    id INTEGER PRIMARY KEY,
+    entity_type TEXT NOT NULL,
+    entity_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    UNIQUE (entity_type, entity_id)
+);
+
+INSERT INTO search_documents (entity_type, entity_id, title, body)
+SELECT 'bus', id, buses.register_number, buses.brand || ' ' || buses.bus_model FROM buses WHERE deleted_at IS NULL;
+
+CREATE TRIGGER search_buses_insert AFTER INSERT ON buses WHEN NEW.deleted_at IS NULL
+BEGIN
+    INSERT INTO search_documents (entity_type, entity_id, title, body)
+    VALUES ('bus', NEW.id, NEW.register_number, NEW.brand || ' ' || NEW.bus_model);
+END;
+
+CREATE TRIGGER search_buses_update AFTER UPDATE ON buses
+BEGIN
+    DELETE FROM search_documents WHERE entity_type = 'bus' AND entity_id = OLD.id;
+    INSERT INTO search_documents (entity_type, entity_id, title, body)
+    SELECT 'bus', NEW.id, NEW.register_number, NEW.brand || ' ' || NEW.bus_model WHERE NEW.deleted_at IS NULL;
+END;
+
+CREATE TRIGGER search_buses_delete AFTER DELETE ON buses
+BEGIN
+    DELETE FROM search_documents WHERE entity_type = 'bus' AND entity_id = OLD.id;
+END;
+
+INSERT INTO search_documents (entity_type, entity_id, title, body)
+SELECT 'driver', id, drivers.surname || ' ' || drivers.name || ' ' || drivers.patronymic, '' FROM drivers WHERE deleted_at IS NULL;
+
+CREATE TRIGGER search_drivers_insert AFTER INSERT ON drivers WHEN NEW.deleted_at IS NULL
+BEGIN
+    INSERT INTO search_documents (entity_type, entity_id, title, body)
+    VALUES ('driver', NEW.id, NEW.surname || ' ' || NEW.name || ' ' || NEW.patronymic, '');
+END;
+
+CREATE TRIGGER search_drivers_update AFTER UPDATE ON drivers
+BEGIN
+    DELETE FROM search_documents WHERE entity_type = 'driver' AND entity_id = OLD.id;
+    INSERT INTO search_documents (entity_type, entity_id, title, body)
+    SELECT 'driver', NEW.id, NEW.surname || ' ' || NEW.name || ' ' || NEW.patronymic, '' WHERE NEW.deleted_at IS NULL;
+END;
+
+CREATE TRIGGER search_drivers_delete AFTER DELETE ON drivers
+BEGIN
+    DELETE FROM search_documents WHERE entity_type = 'driver' AND entity_id = OLD.id;
+END;
+
+INSERT INTO search_documents (entity_type, entity_id, title, body)
+SELECT 'bus_stop', id, bus_stops.name, '' FROM bus_stops WHERE deleted_at IS NULL;
+
+CREATE TRIGGER search_bus_stops_insert AFTER INSERT ON bus_stops WHEN NEW.deleted_at IS NULL
+BEGIN
+    INSERT INTO search_documents (entity_type, entity_id, title, body)
+    VALUES ('bus_stop', NEW.id, NEW.name, '');
+END;
+
+CREATE TRIGGER search_bus_stops_update AFTER UPDATE ON bus_stops
+BEGIN
+    DELETE FROM search_documents WHERE entity_type = 'bus_stop' AND entity_id = OLD.id;
+    INSERT INTO search_documents (entity_type, entity_id, title, body)
+    SELECT 'bus_stop', NEW.id, NEW.name, '' WHERE NEW.deleted_at IS NULL;
+END;
+
+CREATE TRIGGER search_bus_stops_delete AFTER DELETE ON bus_stops
+BEGIN
+    DELETE FROM search_documents WHERE entity_type = 'bus_stop' AND entity_id = OLD.id;
+END;
+
+INSERT INTO search_documents (entity_type, entity_id, title, body)
+SELECT 'route', id, coalesce(routes.number, ''), '' FROM routes WHERE deleted_at IS NULL;
+
+CREATE TRIGGER search_routes_insert AFTER INSERT ON routes WHEN NEW.deleted_at IS NULL
+BEGIN
+    INSERT INTO search_documents (entity_type, entity_id, title, body)
+    VALUES ('route', NEW.id, coalesce(NEW.number, ''), '');
+END;
+
+CREATE TRIGGER search_routes_update AFTER UPDATE ON routes
+BEGIN
+    DELETE FROM search_documents WHERE entity_type = 'route' AND entity_id = OLD.id;
+    INSERT INTO search_documents (entity_type, entity_id, title, body)
+    SELECT 'route', NEW.id, coalesce(NEW.number, ''), '' WHERE NEW.deleted_at IS NULL;
+END;
+
+CREATE TRIGGER search_routes_delete AFTER DELETE ON routes
+BEGIN
+    DELETE FROM search_documents WHERE entity_type = 'route' AND entity_id = OLD.id;
+END;
@@ -0,0 +1,2 @@
+-- the original spelling of register numbers is not kept
+SELECT 1;
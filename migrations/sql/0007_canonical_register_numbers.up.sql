@@ -0,0 +1,26 @@
+-- store register numbers in the canonical form of plates.Normalize: no
+-- spaces or dashes, Cyrillic capitals instead of look-alike letters. A number
+-- whose canonical form would clash with another bus is left unchanged.
+CREATE TEMP TABLE canonical_plates AS SELECT id, register_number AS plate FROM buses;
+
+UPDATE canonical_plates SET plate = replace(replace(replace(replace(plate, ' ', ''), char(9), ''), char(160), ''), '-', '');
+UPDATE canonical_plates SET plate = replace(replace(replace(plate, 'A', 'А'), 'a', 'А'), 'а', 'А');
+UPDATE canonical_plates SET plate = replace(replace(replace(plate, 'B', 'В'), 'b', 'В'), 'в', 'В');
+UPDATE canonical_plates SET plate = replace(replace(replace(plate, 'E', 'Е'), 'e', 'Е'), 'е', 'Е');
+UPDATE canonical_plates SET plate = replace(replace(replace(plate, 'K', 'К'), 'k', 'К'), 'к', 'К');
+UPDATE canonical_plates SET plate = replace(replace(replace(plate, 'M', 'М'), 'm', 'М'), 'м', 'М');
+UPDATE canonical_plates SET plate = replace(replace(replace(plate, 'H', 'Н'), 'h', 'Н'), 'н', 'Н');
+UPDATE canonical_plates SET plate = replace(replace(replace(plate, 'O', 'О'), 'o', 'О'), 'о', 'О');
+UPDATE canonical_plates SET plate = replace(replace(replace(plate, 'P', 'Р'), 'p', 'Р'), 'р', 'Р');
+UPDATE canonical_plates SET plate = replace(replace(replace(plate, 'C', 'С'), 'c', 'С'), 'с', 'С');
+UPDATE canonical_plates SET plate = replace(replace(replace(plate, 'T', 'Т'), 't', 'Т'), 'т', 'Т');
+UPDATE canonical_plates SET plate = replace(replace(replace(plate, 'Y', 'У'), 'y', 'У'), 'у', 'У');
+UPDATE canonical_plates SET plate = replace(replace(replace(plate, 'X', 'Х'), 'x', 'Х'), 'х', 'Х');
+
+UPDATE buses SET register_number = (SELECT plate FROM canonical_plates c WHERE c.id = buses.id)
+WHERE (
+    SELECT COUNT(*) FROM canonical_plates c
+    WHERE c.plate = (SELECT plate FROM canonical_plates o WHERE o.id = buses.id)
+) = 1;
+
+DROP TABLE canonical_plates;
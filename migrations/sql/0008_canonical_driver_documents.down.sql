@@ -0,0 +1,2 @@
+-- the original spelling of driver documents is not kept
+SELECT 1;
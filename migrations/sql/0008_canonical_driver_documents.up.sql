@@ -0,0 +1,38 @@
+-- store driver documents in the canonical form of the documents package:
+-- "4510 123456", "123-456-789 64" and "77 12 345678". Only values made of the
+-- right number of digits are reformatted, and a value whose canonical form
+-- would clash with another driver is left unchanged.
+CREATE TEMP TABLE canonical_documents AS
+SELECT id,
+       replace(replace(passport_series, ' ', ''), '-', '') AS passport,
+       replace(replace(snils, ' ', ''), '-', '') AS snils,
+       replace(replace(license_series, ' ', ''), '-', '') AS license
+FROM drivers;
+
+UPDATE canonical_documents SET passport = substr(passport, 1, 4) || ' ' || substr(passport, 5)
+WHERE length(passport) = 10 AND passport NOT GLOB '*[^0-9]*';
+UPDATE canonical_documents SET snils = substr(snils, 1, 3) || '-' || substr(snils, 4, 3) || '-' || substr(snils, 7, 3) || ' ' || substr(snils, 10)
+WHERE length(snils) = 11 AND snils NOT GLOB '*[^0-9]*';
+UPDATE canonical_documents SET license = substr(license, 1, 2) || ' ' || substr(license, 3, 2) || ' ' || substr(license, 5)
+WHERE length(license) = 10 AND license NOT GLOB '*[^0-9]*';
+
+UPDATE drivers SET passport_series = (SELECT passport FROM canonical_documents c WHERE c.id = drivers.id)
+WHERE (SELECT passport FROM canonical_documents c WHERE c.id = drivers.id) LIKE '% %'
+  AND (
+    SELECT COUNT(*) FROM canonical_documents c
+    WHERE c.passport = (SELECT passport FROM canonical_documents o WHERE o.id = drivers.id)
+) = 1;
+UPDATE drivers SET snils = (SELECT snils FROM canonical_documents c WHERE c.id = drivers.id)
+WHERE (SELECT snils FROM canonical_documents c WHERE c.id = drivers.id) LIKE '% %'
+  AND (
+    SELECT COUNT(*) FROM canonical_documents c
+    WHERE c.snils = (SELECT snils FROM canonical_documents o WHERE o.id = drivers.id)
+) = 1;
+UPDATE drivers SET license_series = (SELECT license FROM canonical_documents c WHERE c.id = drivers.id)
+WHERE (SELECT license FROM canonical_documents c WHERE c.id = drivers.id) LIKE '% %'
+  AND (
+    SELECT COUNT(*) FROM canonical_documents c
+    WHERE c.license = (SELECT license FROM canonical_documents o WHERE o.id = drivers.id)
+) = 1;
+
+DROP TABLE canonical_documents;
@@ -0,0 +1,3 @@
+ALTER TABLE drivers DROP COLUMN license_expiry_date;
+ALTER TABLE drivers DROP COLUMN license_issue_date;
+ALTER TABLE drivers DROP COLUMN license_categories;
@@ -0,0 +1,5 @@
+-- licence categories are a comma separated list such as "B,C,D"; unknown
+-- dates are stored as the zero time
+ALTER TABLE drivers ADD COLUMN license_categories TEXT NOT NULL DEFAULT '';
+ALTER TABLE drivers ADD COLUMN license_issue_date DATETIME NOT NULL DEFAULT '0001-01-01 00:00:00+00:00';
+ALTER TABLE drivers ADD COLUMN license_expiry_date DATETIME NOT NULL DEFAULT '0001-01-01 00:00:00+00:00';
@@ -0,0 +1 @@
+DROP TABLE medical_checks;
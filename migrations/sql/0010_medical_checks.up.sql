@@ -0,0 +1,14 @@
+-- pre-trip medical checks; they go away when their driver is purged
+CREATE TABLE medical_checks (
+    id TEXT PRIMARY KEY,
+    driver_id TEXT NOT NULL REFERENCES drivers (id) ON DELETE CASCADE,
+    checked_at DATETIME NOT NULL,
+    examiner TEXT NOT NULL,
+    result TEXT NOT NULL,
+    systolic INTEGER NOT NULL,
+    diastolic INTEGER NOT NULL,
+    alcohol REAL NOT NULL,
+    notes TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX idx_medical_checks_driver_id ON medical_checks (driver_id, checked_at);
+CREATE INDEX idx_medical_checks_checked_at ON medical_checks (checked_at);
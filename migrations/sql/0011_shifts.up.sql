@@ -0,0 +1,12 @@
+-- shifts go away with their driver; a purged route leaves its shifts with
+-- route_id NULL so that the working time of the driver is kept
+CREATE TABLE shifts (
+    id TEXT PRIMARY KEY,
+    driver_id TEXT NOT NULL REFERENCES drivers (id) ON DELETE CASCADE,
+    route_id TEXT REFERENCES routes (id) ON DELETE SET NULL,
+    start_at DATETIME NOT NULL,
+    end_at DATETIME NOT NULL,
+    breaks TEXT NOT NULL DEFAULT '[]'
+);
+CREATE INDEX idx_shifts_driver_id ON shifts (driver_id, start_at);
+CREATE INDEX idx_shifts_route_id ON shifts (route_id);
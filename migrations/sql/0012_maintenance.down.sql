@@ -0,0 +1,2 @@
+DROP TABLE maintenance_records;
+DROP TABLE maintenance_intervals;
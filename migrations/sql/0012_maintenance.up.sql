@@ -0,0 +1,21 @@
+-- service intervals per brand and model, matched to buses by the service
+CREATE TABLE maintenance_intervals (
+    id TEXT PRIMARY KEY,
+    brand TEXT NOT NULL,
+    bus_model TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    days INTEGER NOT NULL DEFAULT 0,
+    kilometers INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (brand, bus_model, kind)
+);
+-- services performed on buses; they go away when their bus is purged
+CREATE TABLE maintenance_records (
+    id TEXT PRIMARY KEY,
+    bus_id TEXT NOT NULL REFERENCES buses (id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    performed_at DATETIME NOT NULL,
+    mileage INTEGER NOT NULL DEFAULT 0,
+    mechanic TEXT NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX idx_maintenance_records_bus_id ON maintenance_records (bus_id, performed_at);
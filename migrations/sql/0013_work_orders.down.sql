@@ -0,0 +1,2 @@
+DROP TABLE work_orders;
+ALTER TABLE buses DROP COLUMN in_repair;
@@ -0,0 +1,19 @@
+ALTER TABLE buses ADD COLUMN in_repair INTEGER NOT NULL DEFAULT 0;
+-- corrective repairs; they go away when their bus is purged
+CREATE TABLE work_orders (
+    id TEXT PRIMARY KEY,
+    bus_id TEXT NOT NULL REFERENCES buses (id) ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    opened_at DATETIME NOT NULL,
+    closed_at DATETIME,
+    defect TEXT NOT NULL,
+    mechanic TEXT NOT NULL DEFAULT '',
+    parts TEXT NOT NULL DEFAULT '[]',
+    labour_hours REAL NOT NULL DEFAULT 0,
+    labour_cost REAL NOT NULL DEFAULT 0,
+    cost REAL NOT NULL DEFAULT 0,
+    version INTEGER NOT NULL DEFAULT 1,
+    updated_at DATETIME NOT NULL
+);
+CREATE INDEX idx_work_orders_bus_id ON work_orders (bus_id, opened_at);
+CREATE INDEX idx_work_orders_closed_at ON work_orders (closed_at);
@@ -0,0 +1,3 @@
+DROP TABLE operated_trips;
+DROP TABLE odometer_readings;
+ALTER TABLE routes DROP COLUMN length;
@@ -0,0 +1,20 @@
+ALTER TABLE routes ADD COLUMN length REAL NOT NULL DEFAULT 0;
+-- odometer readings and operated trips go away with their bus; trips also
+-- go away with their route, whose length they are estimated from
+CREATE TABLE odometer_readings (
+    id TEXT PRIMARY KEY,
+    bus_id TEXT NOT NULL REFERENCES buses (id) ON DELETE CASCADE,
+    read_at DATETIME NOT NULL,
+    kilometers INTEGER NOT NULL,
+    notes TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX idx_odometer_readings_bus_id ON odometer_readings (bus_id, read_at);
+CREATE TABLE operated_trips (
+    id TEXT PRIMARY KEY,
+    bus_id TEXT NOT NULL REFERENCES buses (id) ON DELETE CASCADE,
+    route_id TEXT NOT NULL REFERENCES routes (id) ON DELETE CASCADE,
+    day DATETIME NOT NULL,
+    count INTEGER NOT NULL,
+    UNIQUE (bus_id, route_id, day)
+);
+CREATE INDEX idx_operated_trips_route_id ON operated_trips (route_id);
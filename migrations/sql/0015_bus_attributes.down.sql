@@ -0,0 +1,12 @@
+ALTER TABLE routes DROP COLUMN low_floor;
+ALTER TABLE routes DROP COLUMN bus_class;
+ALTER TABLE routes DROP COLUMN min_capacity;
+DROP INDEX idx_buses_vin;
+ALTER TABLE buses DROP COLUMN vin;
+ALTER TABLE buses DROP COLUMN eco_class;
+ALTER TABLE buses DROP COLUMN low_floor;
+ALTER TABLE buses DROP COLUMN fuel_type;
+ALTER TABLE buses DROP COLUMN length;
+ALTER TABLE buses DROP COLUMN class;
+ALTER TABLE buses DROP COLUMN standing_capacity;
+ALTER TABLE buses DROP COLUMN seated_capacity;
@@ -0,0 +1,14 @@
+-- technical attributes of buses are optional: zero or empty when unknown
+ALTER TABLE buses ADD COLUMN seated_capacity INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE buses ADD COLUMN standing_capacity INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE buses ADD COLUMN class TEXT NOT NULL DEFAULT '';
+ALTER TABLE buses ADD COLUMN length REAL NOT NULL DEFAULT 0;
+ALTER TABLE buses ADD COLUMN fuel_type TEXT NOT NULL DEFAULT '';
+ALTER TABLE buses ADD COLUMN low_floor INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE buses ADD COLUMN eco_class INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE buses ADD COLUMN vin TEXT NOT NULL DEFAULT '';
+CREATE UNIQUE INDEX idx_buses_vin ON buses (vin) WHERE vin != '';
+-- requirements a route puts on its buses; zero values allow any bus
+ALTER TABLE routes ADD COLUMN min_capacity INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE routes ADD COLUMN bus_class TEXT NOT NULL DEFAULT '';
+ALTER TABLE routes ADD COLUMN low_floor INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+DROP TABLE bus_status_changes;
+ALTER TABLE buses ADD COLUMN in_repair INTEGER NOT NULL DEFAULT 0;
+UPDATE buses SET in_repair = 1 WHERE status = 'in_repair';
+ALTER TABLE buses DROP COLUMN status;
@@ -0,0 +1,14 @@
+-- the in_repair flag becomes one of the operational statuses of a bus
+ALTER TABLE buses ADD COLUMN status TEXT NOT NULL DEFAULT 'active';
+UPDATE buses SET status = 'in_repair' WHERE in_repair != 0;
+ALTER TABLE buses DROP COLUMN in_repair;
+-- status changes go away with their bus
+CREATE TABLE bus_status_changes (
+    id TEXT PRIMARY KEY,
+    bus_id TEXT NOT NULL REFERENCES buses (id) ON DELETE CASCADE,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    changed_at DATETIME NOT NULL,
+    reason TEXT NOT NULL
+);
+CREATE INDEX idx_bus_status_changes_bus_id ON bus_status_changes (bus_id, changed_at);
@@ -0,0 +1,2 @@
+-- the normalized register numbers are kept
+DROP TABLE invalid_register_numbers;
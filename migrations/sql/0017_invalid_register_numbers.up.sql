@@ -0,0 +1,7 @@
+-- register numbers that are still not valid plates once the data step of
+-- this migration (canonicalRegisterNumbers) has normalized them, so that
+-- they can be found and corrected
+CREATE TABLE invalid_register_numbers (
+    bus_id TEXT PRIMARY KEY REFERENCES buses (id) ON DELETE CASCADE,
+    register_number TEXT NOT NULL
+);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS search_index_insert;
+DROP TRIGGER IF EXISTS search_index_update;
+DROP TRIGGER IF EXISTS search_index_delete;
+DROP TABLE IF EXISTS search_index;
@@ -0,0 +1,49 @@
+-- full-text index over search_documents, which earlier versions created on
+-- the first search, as FTS5 or, without it, as FTS4; both are replaced.
+-- Needs SQLite built with FTS5 (the sqlite_fts5 build tag).
+DROP TRIGGER IF EXISTS search_index_insert;
+DROP TRIGGER IF EXISTS search_index_update;
+DROP TRIGGER IF EXISTS search_index_delete;
+DROP TABLE IF EXISTS search_index;
+
+CREATE VIRTUAL TABLE search_index USING fts5(title, body, tokenize = 'unicode61 remove_diacritics 2');
+
+-- ё is folded into е, which the unicode61 tokenizer keeps apart. A register
+-- number, stored as one word, is also indexed in the spaced form of
+-- plates.Plate.String, so that its digits and region are words of their own.
+CREATE TRIGGER search_index_insert AFTER INSERT ON search_documents
+BEGIN
+    INSERT INTO search_index (rowid, title, body)
+    VALUES (NEW.id, replace(replace(CASE WHEN NEW.entity_type = 'bus' THEN NEW.title || ' ' || CASE
+        WHEN NEW.title GLOB '?[0-9][0-9][0-9]??[0-9][0-9]*' THEN
+            substr(NEW.title, 1, 1) || ' ' || substr(NEW.title, 2, 3) || ' ' || substr(NEW.title, 5, 2) || ' ' || substr(NEW.title, 7)
+        WHEN NEW.title GLOB '??[0-9][0-9][0-9][0-9][0-9]*' THEN
+            substr(NEW.title, 1, 2) || ' ' || substr(NEW.title, 3, 3) || ' ' || substr(NEW.title, 6)
+        ELSE '' END ELSE NEW.title END, 'ё', 'е'), 'Ё', 'Е'), replace(replace(NEW.body, 'ё', 'е'), 'Ё', 'Е'));
+END;
+
+CREATE TRIGGER search_index_update AFTER UPDATE ON search_documents
+BEGIN
+    DELETE FROM search_index WHERE rowid = OLD.id;
+    INSERT INTO search_index (rowid, title, body)
+    VALUES (NEW.id, replace(replace(CASE WHEN NEW.entity_type = 'bus' THEN NEW.title || ' ' || CASE
+        WHEN NEW.title GLOB '?[0-9][0-9][0-9]??[0-9][0-9]*' THEN
+            substr(NEW.title, 1, 1) || ' ' || substr(NEW.title, 2, 3) || ' ' || substr(NEW.title, 5, 2) || ' ' || substr(NEW.title, 7)
+        WHEN NEW.title GLOB '??[0-9][0-9][0-9][0-9][0-9]*' THEN
+            substr(NEW.title, 1, 2) || ' ' || substr(NEW.title, 3, 3) || ' ' || substr(NEW.title, 6)
+        ELSE '' END ELSE NEW.title END, 'ё', 'е'), 'Ё', 'Е'), replace(replace(NEW.body, 'ё', 'е'), 'Ё', 'Е'));
+END;
+
+CREATE TRIGGER search_index_delete AFTER DELETE ON search_documents
+BEGIN
+    DELETE FROM search_index WHERE rowid = OLD.id;
+END;
+
+INSERT INTO search_index (rowid, title, body)
+SELECT id, replace(replace(CASE WHEN entity_type = 'bus' THEN title || ' ' || CASE
+    WHEN title GLOB '?[0-9][0-9][0-9]??[0-9][0-9]*' THEN
+        substr(title, 1, 1) || ' ' || substr(title, 2, 3) || ' ' || substr(title, 5, 2) || ' ' || substr(title, 7)
+    WHEN title GLOB '??[0-9][0-9][0-9][0-9][0-9]*' THEN
+        substr(title, 1, 2) || ' ' || substr(title, 3, 3) || ' ' || substr(title, 6)
+    ELSE '' END ELSE title END, 'ё', 'е'), 'Ё', 'Е'), replace(replace(body, 'ё', 'е'), 'Ё', 'Е')
+FROM search_documents;
@@ -0,0 +1,28 @@
+-- Orders of purged buses cannot go back under NOT NULL and are dropped.
+CREATE TABLE work_orders_old (
+    id TEXT PRIMARY KEY,
+    bus_id TEXT NOT NULL REFERENCES buses (id) ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    opened_at DATETIME NOT NULL,
+    closed_at DATETIME,
+    defect TEXT NOT NULL,
+    mechanic TEXT NOT NULL DEFAULT '',
+    parts TEXT NOT NULL DEFAULT '[]',
+    labour_hours REAL NOT NULL DEFAULT 0,
+    labour_cost REAL NOT NULL DEFAULT 0,
+    cost REAL NOT NULL DEFAULT 0,
+    version INTEGER NOT NULL DEFAULT 1,
+    updated_at DATETIME NOT NULL
+);
+INSERT INTO work_orders_old (id, bus_id, status, opened_at, closed_at, defect, mechanic, parts, labour_hours,
+    labour_cost, cost, version, updated_at)
+SELECT id, bus_id, status, opened_at, closed_at, defect, mechanic,
+    COALESCE((SELECT json_group_array(json_set(p.value, '$.UnitPrice', json_extract(p.value, '$.UnitPrice') / 100.0))
+        FROM json_each(parts) p), '[]'),
+    labour_hours, labour_cost / 100.0, cost / 100.0, version, updated_at
+FROM work_orders
+WHERE bus_id IS NOT NULL;
+DROP TABLE work_orders;
+ALTER TABLE work_orders_old RENAME TO work_orders;
+CREATE INDEX idx_work_orders_bus_id ON work_orders (bus_id, opened_at);
+CREATE INDEX idx_work_orders_closed_at ON work_orders (closed_at);
@@ -0,0 +1,29 @@
+-- Money of work orders moves from roubles in REAL columns to whole kopecks,
+-- and orders are kept with bus_id NULL when their bus is purged, so that the
+-- repair costs stay complete.
+CREATE TABLE work_orders_new (
+    id TEXT PRIMARY KEY,
+    bus_id TEXT REFERENCES buses (id) ON DELETE SET NULL,
+    status TEXT NOT NULL,
+    opened_at DATETIME NOT NULL,
+    closed_at DATETIME,
+    defect TEXT NOT NULL,
+    mechanic TEXT NOT NULL DEFAULT '',
+    parts TEXT NOT NULL DEFAULT '[]',
+    labour_hours REAL NOT NULL DEFAULT 0,
+    labour_cost INTEGER NOT NULL DEFAULT 0,
+    cost INTEGER NOT NULL DEFAULT 0,
+    version INTEGER NOT NULL DEFAULT 1,
+    updated_at DATETIME NOT NULL
+);
+INSERT INTO work_orders_new (id, bus_id, status, opened_at, closed_at, defect, mechanic, parts, labour_hours,
+    labour_cost, cost, version, updated_at)
+SELECT id, bus_id, status, opened_at, closed_at, defect, mechanic,
+    COALESCE((SELECT json_group_array(json_set(p.value, '$.UnitPrice', CAST(round(json_extract(p.value, '$.UnitPrice') * 100) AS INTEGER)))
+        FROM json_each(parts) p), '[]'),
+    labour_hours, CAST(round(labour_cost * 100) AS INTEGER), CAST(round(cost * 100) AS INTEGER), version, updated_at
+FROM work_orders;
+DROP TABLE work_orders;
+ALTER TABLE work_orders_new RENAME TO work_orders;
+CREATE INDEX idx_work_orders_bus_id ON work_orders (bus_id, opened_at);
+CREATE INDEX idx_work_orders_closed_at ON work_orders (closed_at);
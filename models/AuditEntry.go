@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// Audit actions.
+const (
+	AuditAdd      = "add"
+	AuditUpdate   = "update"
+	AuditDelete   = "delete"
+	AuditAssign   = "assign"
+	AuditUnassign = "unassign"
+	AuditRestore  = "restore"
+	AuditPurge    = "purge"
+)
+
+// Audited entity types.
+const (
+	EntityBus     = "bus"
+	EntityDriver  = "driver"
+	EntityBusStop = "bus_stop"
+	EntityRoute   = "route"
+
+	EntityMedicalCheck        = "medical_check"
+	EntityShift               = "shift"
+	EntityMaintenanceInterval = "maintenance_interval"
+	EntityMaintenance         = "maintenance"
+	EntityWorkOrder           = "work_order"
+	EntityOdometerReading     = "odometer_reading"
+	EntityOperatedTrips       = "operated_trips"
+)
+
+// AuditEntry records one change. Before and After are JSON snapshots of the
+// entity, empty when there is none (before an add, after a delete). RouteId
+// is set for assignments.
+type AuditEntry struct {
+	ID         string
+	Time       time.Time
+	User       string
+	Action     string
+	EntityType string
+	EntityId   string
+	RouteId    string
+	Before     string
+	After      string
+}
+
+// AuditFilter selects audit entries; empty fields match everything. From is
+// inclusive, To is exclusive.
+type AuditFilter struct {
+	EntityType string
+	EntityId   string
+	RouteId    string
+	User       string
+	Action     string
+	From       time.Time
+	To         time.Time
+	Limit      int
+}
@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+type Backup struct {
+	Name      string
+	CreatedAt time.Time
+	Size      int64
+}
@@ -1,16 +1,99 @@
 package models
 
 import (
+	"busManager/validation"
 	"time"
 )
 
+// Vehicle classes of buses by their length.
+const (
+	BusClassSmall       = "small"
+	BusClassMedium      = "medium"
+	BusClassLarge       = "large"
+	BusClassArticulated = "articulated"
+)
+
+// Fuel types of buses.
+const (
+	FuelDiesel   = "diesel"
+	FuelPetrol   = "petrol"
+	FuelCNG      = "cng"
+	FuelLNG      = "lng"
+	FuelElectric = "electric"
+	FuelHybrid   = "hybrid"
+	FuelHydrogen = "hydrogen"
+)
+
+// busClass allows a vehicle class or none.
+var busClass = validation.Pattern(`|`+BusClassSmall+`|`+BusClassMedium+`|`+BusClassLarge+`|`+BusClassArticulated,
+	"must be small, medium, large or articulated")
+
+// Bus is a vehicle of the fleet. LastRepairDate is zero for a bus never
+// repaired; once the bus is added it follows the maintenance history and is
+// not changed by UpdateById. Status is the operational status of the bus; it
+// changes only along the allowed transitions, see CanChangeStatus, and is
+// in_repair while a work order of the bus is open.
+//
+// The technical attributes are optional and zero when unknown: Length is in
+// metres, EcoClass is the Euro emission standard from 1 to 6 and VIN is
+// unique among the buses that have one.
 type Bus struct {
-	ID             string
-	Brand          string
-	BusModel       string
-	RegisterNumber string
-	AssemblyDate   time.Time
-	LastRepairDate time.Time
+	ID               string
+	Brand            string
+	BusModel         string
+	RegisterNumber   string
+	AssemblyDate     time.Time
+	LastRepairDate   time.Time
+	Status           string
+	SeatedCapacity   int
+	StandingCapacity int
+	Class            string
+	Length           float64
+	FuelType         string
+	LowFloor         bool
+	EcoClass         int
+	VIN              string
+	Version          int
+	UpdatedAt        time.Time
+}
+
+// Operable reports whether the bus may be assigned to routes.
+func (b Bus) Operable() bool {
+	return b.Status == BusActive || b.Status == BusReserve
+}
+
+// Capacity is the number of passengers the bus takes, seated and standing.
+func (b Bus) Capacity() int {
+	return b.SeatedCapacity + b.StandingCapacity
+}
+
+// BusRules are checked by the bus service on every Add and UpdateById; the
+// format of the register number is checked there too, see package plates.
+var BusRules = validation.Rules[Bus]{
+	validation.Field("Brand", func(b Bus) string { return b.Brand }, validation.Required(), validation.MaxLength(50)),
+	validation.Field("BusModel", func(b Bus) string { return b.BusModel }, validation.Required(), validation.MaxLength(50)),
+	validation.Field("RegisterNumber", func(b Bus) string { return b.RegisterNumber }, validation.Required()),
+	validation.Field("AssemblyDate", func(b Bus) time.Time { return b.AssemblyDate },
+		validation.RequiredTime(), validation.NotBefore(1950, time.January, 1), validation.NotInFuture()),
+	validation.Field("LastRepairDate", func(b Bus) time.Time { return b.LastRepairDate }, validation.NotInFuture()),
+	validation.Field("Status", func(b Bus) string { return b.Status }, busStatus),
+	validation.Field("SeatedCapacity", func(b Bus) float64 { return float64(b.SeatedCapacity) }, validation.Range(0, 150)),
+	validation.Field("StandingCapacity", func(b Bus) float64 { return float64(b.StandingCapacity) }, validation.Range(0, 250)),
+	validation.Field("Class", func(b Bus) string { return b.Class }, busClass),
+	validation.Field("Length", func(b Bus) float64 { return b.Length }, validation.Range(0, 30)),
+	validation.Field("FuelType", func(b Bus) string { return b.FuelType }, validation.Pattern(
+		`|`+FuelDiesel+`|`+FuelPetrol+`|`+FuelCNG+`|`+FuelLNG+`|`+FuelElectric+`|`+FuelHybrid+`|`+FuelHydrogen,
+		"must be diesel, petrol, cng, lng, electric, hybrid or hydrogen")),
+	validation.Field("EcoClass", func(b Bus) float64 { return float64(b.EcoClass) }, validation.Range(0, 6)),
+	// VINs have 17 characters and do not use I, O and Q.
+	validation.Field("VIN", func(b Bus) string { return b.VIN }, validation.Pattern(
+		`([A-HJ-NPR-Z0-9]{17})?`, "must be 17 capital letters and digits without I, O and Q")),
+	validation.Cross("LastRepairDate", func(b Bus) string {
+		if !b.LastRepairDate.IsZero() && b.LastRepairDate.Before(b.AssemblyDate) {
+			return "LastRepairDate must not be before AssemblyDate"
+		}
+		return ""
+	}),
 }
 
 //func NewBus(id, brand, model, registerNumber string, assemblyDate, lastRepairDate)
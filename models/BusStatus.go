@@ -0,0 +1,51 @@
+package models
+
+import (
+	"busManager/validation"
+	"slices"
+	"time"
+)
+
+// Operational statuses of a bus. Only active and reserve buses are operable.
+const (
+	BusActive             = "active"
+	BusReserve            = "reserve"
+	BusInRepair           = "in_repair"
+	BusAwaitingInspection = "awaiting_inspection"
+	BusDecommissioned     = "decommissioned"
+)
+
+// busTransitions lists the statuses each status may change to. A
+// decommissioned bus stays decommissioned.
+var busTransitions = map[string][]string{
+	BusActive:             {BusReserve, BusInRepair, BusAwaitingInspection, BusDecommissioned},
+	BusReserve:            {BusActive, BusInRepair, BusAwaitingInspection, BusDecommissioned},
+	BusInRepair:           {BusActive, BusReserve, BusAwaitingInspection, BusDecommissioned},
+	BusAwaitingInspection: {BusActive, BusReserve, BusInRepair, BusDecommissioned},
+}
+
+// CanChangeStatus reports whether a bus may go from one status to another.
+func CanChangeStatus(from, to string) bool {
+	return slices.Contains(busTransitions[from], to)
+}
+
+var busStatus = validation.Pattern(
+	BusActive+`|`+BusReserve+`|`+BusInRepair+`|`+BusAwaitingInspection+`|`+BusDecommissioned,
+	"must be active, reserve, in_repair, awaiting_inspection or decommissioned")
+
+// BusStatusChange is a transition in the status history of a bus. Changes
+// are never changed once added.
+type BusStatusChange struct {
+	ID        string
+	BusID     string
+	From      string
+	To        string
+	ChangedAt time.Time
+	Reason    string
+}
+
+// BusStatusChangeRules are checked by the bus service on every change.
+var BusStatusChangeRules = validation.Rules[BusStatusChange]{
+	validation.Field("Status", func(c BusStatusChange) string { return c.To }, busStatus),
+	validation.Field("Reason", func(c BusStatusChange) string { return c.Reason }, validation.Required(), validation.MaxLength(500)),
+}
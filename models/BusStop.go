@@ -1,8 +1,30 @@
 package models
 
+import (
+	"busManager/validation"
+	"time"
+)
+
 type BusStop struct {
-	ID   string
-	Lat  float64
-	Long float64
-	Name string
+	ID        string
+	Lat       float64
+	Long      float64
+	Name      string
+	Version   int
+	UpdatedAt time.Time
+}
+
+// BusStopRules are checked by the bus stop service on every Add and
+// UpdateById.
+var BusStopRules = validation.Rules[BusStop]{
+	validation.Field("Name", func(s BusStop) string { return s.Name }, validation.Required(), validation.MaxLength(100)),
+	validation.Field("Lat", func(s BusStop) float64 { return s.Lat }, validation.Range(-90, 90)),
+	validation.Field("Long", func(s BusStop) float64 { return s.Long }, validation.Range(-180, 180)),
+	// 0, 0 is what an unfilled form sends, not a stop in the Gulf of Guinea
+	validation.Cross("Lat", func(s BusStop) string {
+		if s.Lat == 0 && s.Long == 0 {
+			return "Lat and Long are required"
+		}
+		return ""
+	}),
 }
@@ -1,6 +1,13 @@
 package models
 
-import "time"
+import (
+	"busManager/documents"
+	"busManager/validation"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
 
 type Driver struct {
 	ID             string
@@ -11,4 +18,110 @@ type Driver struct {
 	PassportSeries string
 	Snils          string
 	LicenseSeries  string
+	// LicenseCategories, LicenseIssueDate and LicenseExpiryDate are checked
+	// when the driver is assigned to a route; zero dates are unknown.
+	LicenseCategories LicenseCategories
+	LicenseIssueDate  time.Time
+	LicenseExpiryDate time.Time
+	Version           int
+	UpdatedAt         time.Time
+}
+
+// BusCategory is the licence category needed to drive a route bus.
+const BusCategory = "D"
+
+// LicenseCategories are the categories opened in a driving licence. They are
+// stored as a comma separated list.
+type LicenseCategories []string
+
+func (c LicenseCategories) Has(category string) bool {
+	for _, existing := range c {
+		if existing == category {
+			return true
+		}
+	}
+	return false
+}
+
+func (c LicenseCategories) Value() (driver.Value, error) {
+	return strings.Join(c, ","), nil
+}
+
+func (c *LicenseCategories) Scan(src any) error {
+	var s string
+	switch src := src.(type) {
+	case string:
+		s = src
+	case []byte:
+		s = string(src)
+	case nil:
+	default:
+		return fmt.Errorf("cannot scan %T into LicenseCategories", src)
+	}
+	*c = nil
+	if s != "" {
+		*c = strings.Split(s, ",")
+	}
+	return nil
+}
+
+// LicenseProblem returns the field and the reason why the driver's licence
+// does not allow driving a bus on the day of at, or two empty strings.
+func (d Driver) LicenseProblem(at time.Time) (field, reason string) {
+	if !d.LicenseCategories.Has(BusCategory) {
+		return "LicenseCategories", "Driver licence has no category " + BusCategory
+	}
+	if d.LicenseExpiryDate.IsZero() {
+		return "LicenseExpiryDate", "Driver licence expiry date is unknown"
+	}
+	if d.LicenseExpiryDate.Before(at.UTC().Truncate(24 * time.Hour)) {
+		return "LicenseExpiryDate", "Driver licence expired on " + d.LicenseExpiryDate.Format(time.DateOnly)
+	}
+	return "", ""
+}
+
+// MinDriverAge is the youngest age at which a driver can be employed.
+const MinDriverAge = 18
+
+const namePattern = `\p{L}[\p{L}' -]*`
+const nameReason = "must contain only letters, spaces, hyphens and apostrophes"
+
+// DriverRules are checked by the driver service on every Add and UpdateById,
+// which then stores the document numbers in their canonical form.
+var DriverRules = validation.Rules[Driver]{
+	validation.Field("Name", func(d Driver) string { return d.Name },
+		validation.Required(), validation.MaxLength(50), validation.Pattern(namePattern, nameReason)),
+	validation.Field("Surname", func(d Driver) string { return d.Surname },
+		validation.Required(), validation.MaxLength(50), validation.Pattern(namePattern, nameReason)),
+	validation.Field("Patronymic", func(d Driver) string { return d.Patronymic },
+		validation.MaxLength(50), validation.Pattern(`(`+namePattern+`)?`, nameReason)),
+	validation.Field("BirthDate", func(d Driver) time.Time { return d.BirthDate },
+		validation.RequiredTime(), validation.NotBefore(1900, time.January, 1), validation.NotInFuture()),
+	validation.Cross("BirthDate", func(d Driver) string {
+		if d.BirthDate.AddDate(MinDriverAge, 0, 0).After(time.Now()) {
+			return fmt.Sprintf("Driver must be at least %d years old", MinDriverAge)
+		}
+		return ""
+	}),
+	validation.Field("PassportSeries", func(d Driver) string { return d.PassportSeries }, validation.Required()),
+	validation.Parsed("PassportSeries", func(d Driver) string { return d.PassportSeries }, documents.ParsePassport),
+	validation.Field("Snils", func(d Driver) string { return d.Snils }, validation.Required()),
+	validation.Parsed("Snils", func(d Driver) string { return d.Snils }, documents.ParseSnils),
+	validation.Field("LicenseSeries", func(d Driver) string { return d.LicenseSeries }, validation.Required()),
+	validation.Parsed("LicenseSeries", func(d Driver) string { return d.LicenseSeries }, documents.ParseLicense),
+	validation.Cross("LicenseCategories", func(d Driver) string {
+		for _, category := range d.LicenseCategories {
+			if _, err := documents.ParseCategory(category); err != nil {
+				return err.Error()
+			}
+		}
+		return ""
+	}),
+	validation.Field("LicenseIssueDate", func(d Driver) time.Time { return d.LicenseIssueDate }, validation.NotInFuture()),
+	validation.Cross("LicenseExpiryDate", func(d Driver) string {
+		if !d.LicenseIssueDate.IsZero() && !d.LicenseExpiryDate.IsZero() && !d.LicenseExpiryDate.After(d.LicenseIssueDate) {
+			return "LicenseExpiryDate must be after LicenseIssueDate"
+		}
+		return ""
+	}),
 }
@@ -0,0 +1,27 @@
+package models
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
+
+// ListQuery selects one page of a list. Sort and the keys of Filters are
+// field names as they appear in the JSON of the entity, e.g. "RegisterNumber".
+// A filter keeps the rows whose text field contains the value, whose flag or
+// enumerated field, e.g. a bus Class, equals it, or whose number is at least
+// the value.
+type ListQuery struct {
+	Page    int
+	Limit   int
+	Sort    string
+	Desc    bool
+	Filters map[string]string
+}
+
+// Page is one page of a list together with the number of matching rows.
+type Page[T any] struct {
+	Items []T
+	Total int
+	Page  int
+	Limit int
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"busManager/validation"
+	"strings"
+	"time"
+)
+
+// Maintenance statuses, see MaintenanceDue.
+const (
+	MaintenanceOK      = "ok"
+	MaintenanceDueSoon = "due_soon"
+	MaintenanceOverdue = "overdue"
+)
+
+// MaintenanceInterval is how often buses of a brand and model need a kind of
+// service, e.g. "TO-1" every 90 days or 10000 km, whichever comes first. A
+// zero Days or Kilometers sets no limit of that kind.
+type MaintenanceInterval struct {
+	ID         string
+	Brand      string
+	BusModel   string
+	Kind       string
+	Days       int
+	Kilometers int
+}
+
+// Matches reports whether the interval applies to bus. Brand and model are
+// compared ignoring case and surrounding spaces.
+func (i MaintenanceInterval) Matches(bus Bus) bool {
+	return strings.EqualFold(strings.TrimSpace(i.Brand), strings.TrimSpace(bus.Brand)) &&
+		strings.EqualFold(strings.TrimSpace(i.BusModel), strings.TrimSpace(bus.BusModel))
+}
+
+// MaintenanceIntervalRules are checked by the maintenance service on add and
+// update.
+var MaintenanceIntervalRules = validation.Rules[MaintenanceInterval]{
+	validation.Field("Brand", func(i MaintenanceInterval) string { return i.Brand }, validation.Required(), validation.MaxLength(50)),
+	validation.Field("BusModel", func(i MaintenanceInterval) string { return i.BusModel }, validation.Required(), validation.MaxLength(50)),
+	validation.Field("Kind", func(i MaintenanceInterval) string { return i.Kind }, validation.Required(), validation.MaxLength(50)),
+	validation.Field("Days", func(i MaintenanceInterval) float64 { return float64(i.Days) }, validation.Range(0, 3650)),
+	validation.Field("Kilometers", func(i MaintenanceInterval) float64 { return float64(i.Kilometers) }, validation.Range(0, 1000000)),
+	validation.Cross("Days", func(i MaintenanceInterval) string {
+		if i.Days == 0 && i.Kilometers == 0 {
+			return "Days or Kilometers is required"
+		}
+		return ""
+	}),
+}
+
+// MaintenanceRecord is a service performed on a bus. Records are never
+// changed once added. Mileage is the odometer reading in km at the service.
+type MaintenanceRecord struct {
+	ID          string
+	BusID       string
+	Kind        string
+	PerformedAt time.Time
+	Mileage     int
+	Mechanic    string
+	Notes       string
+}
+
+// MaintenanceRecordRules are checked by the maintenance service on add.
+var MaintenanceRecordRules = validation.Rules[MaintenanceRecord]{
+	validation.Field("BusID", func(r MaintenanceRecord) string { return r.BusID }, validation.Required()),
+	validation.Field("Kind", func(r MaintenanceRecord) string { return r.Kind }, validation.Required(), validation.MaxLength(50)),
+	validation.Field("PerformedAt", func(r MaintenanceRecord) time.Time { return r.PerformedAt },
+		validation.RequiredTime(), validation.NotInFuture()),
+	validation.Field("Mileage", func(r MaintenanceRecord) float64 { return float64(r.Mileage) }, validation.Range(0, 10000000)),
+	validation.Field("Mechanic", func(r MaintenanceRecord) string { return r.Mechanic }, validation.MaxLength(100)),
+	validation.Field("Notes", func(r MaintenanceRecord) string { return r.Notes }, validation.MaxLength(1000)),
+}
+
+// MaintenanceDue is the next service of one kind a bus needs. Last is the
+// latest record of that kind, nil when the bus never had it; the interval
+// then counts from the assembly date and zero km. DueDate is zero when the
+// interval sets no Days, DueMileage when it sets no Kilometers. Mileage is
+// the highest odometer reading known for the bus.
+type MaintenanceDue struct {
+	Bus        Bus
+	Interval   MaintenanceInterval
+	Last       *MaintenanceRecord
+	Mileage    int
+	DueDate    time.Time
+	DueMileage int
+	Status     string
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"busManager/validation"
+	"time"
+)
+
+// Medical check results.
+const (
+	MedicalPassed = "passed"
+	MedicalFailed = "failed"
+)
+
+// MedicalCheck is a pre-trip examination of a driver. Checks are records and
+// are never changed once added. Blood pressure is in mmHg, alcohol in mg per
+// litre of exhaled air.
+type MedicalCheck struct {
+	ID        string
+	DriverID  string
+	CheckedAt time.Time
+	Examiner  string
+	Result    string
+	Systolic  int
+	Diastolic int
+	Alcohol   float64
+	Notes     string
+}
+
+// Cleared reports whether the check allows the driver to work.
+func (c MedicalCheck) Cleared() bool {
+	return c.Result == MedicalPassed
+}
+
+// MedicalCheckRules are checked by the medical check service on Add.
+var MedicalCheckRules = validation.Rules[MedicalCheck]{
+	validation.Field("DriverID", func(c MedicalCheck) string { return c.DriverID }, validation.Required()),
+	validation.Field("CheckedAt", func(c MedicalCheck) time.Time { return c.CheckedAt },
+		validation.RequiredTime(), validation.NotInFuture()),
+	validation.Field("Examiner", func(c MedicalCheck) string { return c.Examiner }, validation.Required(), validation.MaxLength(100)),
+	validation.Field("Result", func(c MedicalCheck) string { return c.Result },
+		validation.Required(), validation.Pattern(MedicalPassed+"|"+MedicalFailed, "must be passed or failed")),
+	validation.Field("Systolic", func(c MedicalCheck) float64 { return float64(c.Systolic) }, validation.Range(50, 250)),
+	validation.Field("Diastolic", func(c MedicalCheck) float64 { return float64(c.Diastolic) }, validation.Range(30, 150)),
+	validation.Cross("Diastolic", func(c MedicalCheck) string {
+		if c.Diastolic >= c.Systolic {
+			return "Diastolic must be below Systolic"
+		}
+		return ""
+	}),
+	validation.Field("Alcohol", func(c MedicalCheck) float64 { return c.Alcohol }, validation.Range(0, 5)),
+	validation.Cross("Result", func(c MedicalCheck) string {
+		if c.Result == MedicalPassed && c.Alcohol > 0 {
+			return "A driver with alcohol cannot pass"
+		}
+		return ""
+	}),
+	validation.Field("Notes", func(c MedicalCheck) string { return c.Notes }, validation.MaxLength(1000)),
+}
+
+// UnclearedDriver is a line of the daily medical report: a driver assigned
+// to routes without a passed check that day. LastCheck is the latest check
+// of the day, nil when the driver was not examined.
+type UnclearedDriver struct {
+	Driver    Driver
+	Routes    []Route
+	LastCheck *MedicalCheck
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"busManager/validation"
+	"time"
+)
+
+// OdometerReading is the odometer of a bus in km at a time. Readings are
+// records and are never changed once added.
+type OdometerReading struct {
+	ID         string
+	BusID      string
+	ReadAt     time.Time
+	Kilometers int
+	Notes      string
+}
+
+// OdometerReadingRules are checked by the mileage service on add.
+var OdometerReadingRules = validation.Rules[OdometerReading]{
+	validation.Field("BusID", func(r OdometerReading) string { return r.BusID }, validation.Required()),
+	validation.Field("ReadAt", func(r OdometerReading) time.Time { return r.ReadAt },
+		validation.RequiredTime(), validation.NotInFuture()),
+	validation.Field("Kilometers", func(r OdometerReading) float64 { return float64(r.Kilometers) }, validation.Range(0, 10000000)),
+	validation.Field("Notes", func(r OdometerReading) string { return r.Notes }, validation.MaxLength(1000)),
+}
+
+// OperatedTrips is the number of trips a bus made on a route on a UTC day.
+// There is at most one per bus, route and day.
+type OperatedTrips struct {
+	ID      string
+	BusID   string
+	RouteID string
+	Day     time.Time
+	Count   int
+}
+
+// OperatedTripsRules are checked by the mileage service on add.
+var OperatedTripsRules = validation.Rules[OperatedTrips]{
+	validation.Field("BusID", func(t OperatedTrips) string { return t.BusID }, validation.Required()),
+	validation.Field("RouteID", func(t OperatedTrips) string { return t.RouteID }, validation.Required()),
+	validation.Field("Day", func(t OperatedTrips) time.Time { return t.Day }, validation.RequiredTime(), validation.NotInFuture()),
+	validation.Field("Count", func(t OperatedTrips) float64 { return float64(t.Count) }, validation.Range(1, 200)),
+}
+
+// Mileage is the distance a bus covered from From until To. It is Estimated
+// from operated trips when odometer readings do not cover the period.
+type Mileage struct {
+	From       time.Time
+	To         time.Time
+	Kilometers float64
+	Estimated  bool
+}
+
+// BusMileage is the current mileage of a bus: its last odometer reading and
+// the distance estimated from the trips operated since. Kilometers is their
+// sum; all are zero when nothing is known.
+type BusMileage struct {
+	BusID      string
+	Odometer   int
+	ReadAt     time.Time
+	Estimated  float64
+	Kilometers float64
+}
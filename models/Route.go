@@ -1,6 +1,50 @@
 package models
 
+import (
+	"busManager/validation"
+	"fmt"
+	"time"
+)
+
+// Route is a bus line. Length is the length of one trip in km, zero when
+// unknown; it is used to estimate the mileage of buses, see OperatedTrips.
+// MinCapacity, BusClass and LowFloor restrict the buses that may be
+// assigned to the route; zero values allow any bus.
 type Route struct {
-	ID     string
-	Number string
+	ID          string
+	Number      string
+	Length      float64
+	MinCapacity int
+	BusClass    string
+	LowFloor    bool
+	Version     int
+	UpdatedAt   time.Time
+}
+
+// Unsuitable returns the reasons the bus may not serve the route by field
+// of the bus, or nil when it may.
+func (r Route) Unsuitable(bus Bus) map[string]string {
+	reasons := map[string]string{}
+	if bus.Capacity() < r.MinCapacity {
+		reasons["Capacity"] = fmt.Sprintf("Capacity must be at least %d on route %s", r.MinCapacity, r.Number)
+	}
+	if r.BusClass != "" && bus.Class != r.BusClass {
+		reasons["Class"] = fmt.Sprintf("Class must be %s on route %s", r.BusClass, r.Number)
+	}
+	if r.LowFloor && !bus.LowFloor {
+		reasons["LowFloor"] = "Route " + r.Number + " is served by low-floor buses only"
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+	return reasons
+}
+
+// RouteRules are checked by the route service on every Add and UpdateById.
+var RouteRules = validation.Rules[Route]{
+	validation.Field("Number", func(r Route) string { return r.Number }, validation.Required(), validation.MaxLength(10),
+		validation.Pattern(`[\p{L}\d-]+`, "must contain only letters, digits and hyphens")),
+	validation.Field("Length", func(r Route) float64 { return r.Length }, validation.Range(0, 1000)),
+	validation.Field("MinCapacity", func(r Route) float64 { return float64(r.MinCapacity) }, validation.Range(0, 400)),
+	validation.Field("BusClass", func(r Route) string { return r.BusClass }, busClass),
 }
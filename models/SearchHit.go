@@ -0,0 +1,11 @@
+package models
+
+// SearchHit is one result of the global search. Type is one of the Entity
+// constants, Title the matched name or number and Subtitle extra text, e.g.
+// the brand and model of a bus.
+type SearchHit struct {
+	Type     string
+	ID       string
+	Title    string
+	Subtitle string
+}
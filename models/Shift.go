@@ -0,0 +1,130 @@
+package models
+
+import (
+	"busManager/validation"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Shift is a period a driver works on a route. Time spent in Breaks is not
+// driving time. RouteID is empty once the route has been purged.
+type Shift struct {
+	ID       string
+	DriverID string
+	RouteID  string
+	Start    time.Time
+	End      time.Time
+	Breaks   Breaks
+}
+
+type Break struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (b Break) Duration() time.Duration {
+	return b.End.Sub(b.Start)
+}
+
+// Breaks are stored as a JSON array.
+type Breaks []Break
+
+func (b Breaks) Value() (driver.Value, error) {
+	if len(b) == 0 {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]Break(b))
+	return string(data), err
+}
+
+func (b *Breaks) Scan(src any) error {
+	var data []byte
+	switch src := src.(type) {
+	case string:
+		data = []byte(src)
+	case []byte:
+		data = src
+	default:
+		return fmt.Errorf("cannot scan %T into Breaks", src)
+	}
+	var breaks []Break
+	if err := json.Unmarshal(data, &breaks); err != nil {
+		return err
+	}
+	*b = breaks
+	return nil
+}
+
+// DrivingTime is the length of the shift without its breaks.
+func (s Shift) DrivingTime() time.Duration {
+	d := s.End.Sub(s.Start)
+	for _, b := range s.Breaks {
+		d -= b.Duration()
+	}
+	return d
+}
+
+// Overlaps reports whether the shifts share any time.
+func (s Shift) Overlaps(other Shift) bool {
+	return s.Start.Before(other.End) && other.Start.Before(s.End)
+}
+
+// MaxShiftLength is the longest shift that can be recorded.
+const MaxShiftLength = 24 * time.Hour
+
+// ShiftRules are checked by the shift service on Add, before the working
+// time limits of package compliance.
+var ShiftRules = validation.Rules[Shift]{
+	validation.Field("DriverID", func(s Shift) string { return s.DriverID }, validation.Required()),
+	validation.Field("RouteID", func(s Shift) string { return s.RouteID }, validation.Required()),
+	validation.Field("Start", func(s Shift) time.Time { return s.Start }, validation.RequiredTime()),
+	validation.Field("End", func(s Shift) time.Time { return s.End }, validation.RequiredTime()),
+	validation.Cross("End", func(s Shift) string {
+		if !s.End.After(s.Start) {
+			return "End must be after Start"
+		}
+		if s.End.Sub(s.Start) > MaxShiftLength {
+			return fmt.Sprintf("Shift must not be longer than %v", MaxShiftLength)
+		}
+		return ""
+	}),
+	validation.Cross("Breaks", func(s Shift) string {
+		last := s.Start
+		for _, b := range s.Breaks {
+			if !b.End.After(b.Start) || b.Start.Before(last) || b.End.After(s.End) {
+				return "Breaks must be within the shift, in order and not overlapping"
+			}
+			last = b.End
+		}
+		return ""
+	}),
+}
+
+// Compliance rules.
+const (
+	RuleDailyDriving      = "daily_driving"
+	RuleWeeklyDriving     = "weekly_driving"
+	RuleRestBetweenShifts = "rest_between_shifts"
+	RuleContinuousDriving = "continuous_driving"
+)
+
+// Violation is a broken working time limit. ShiftID is the shift at which
+// the limit was exceeded.
+type Violation struct {
+	Rule    string
+	ShiftID string
+	Message string
+}
+
+// ComplianceReport covers the shifts of a driver starting from From until
+// To.
+type ComplianceReport struct {
+	Driver         Driver
+	From           time.Time
+	To             time.Time
+	Shifts         []Shift
+	DrivingMinutes int
+	Violations     []Violation
+}
@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+// Trashed is a soft deleted entity as listed in the trash.
+type Trashed[T any] struct {
+	Item      T
+	DeletedAt time.Time
+}
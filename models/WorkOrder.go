@@ -0,0 +1,142 @@
+package models
+
+import (
+	"busManager/validation"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Work order statuses. An order is open until a mechanic starts on it and
+// active until it is closed or cancelled.
+const (
+	WorkOrderOpen       = "open"
+	WorkOrderInProgress = "in_progress"
+	WorkOrderClosed     = "closed"
+	WorkOrderCancelled  = "cancelled"
+)
+
+// workOrderTransitions lists the statuses each status can change to.
+var workOrderTransitions = map[string][]string{
+	WorkOrderOpen:       {WorkOrderInProgress, WorkOrderClosed, WorkOrderCancelled},
+	WorkOrderInProgress: {WorkOrderClosed, WorkOrderCancelled},
+}
+
+// CanTransition reports whether a work order in status from can move to to.
+func CanTransition(from, to string) bool {
+	for _, next := range workOrderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkOrder is a corrective repair of a bus. ClosedAt is zero while the order
+// is active, and BusID is empty once the bus was purged: the order is kept
+// for the repair costs. Money is in kopecks. Cost is the labour cost plus the
+// cost of the parts and is kept up to date by the work order service.
+type WorkOrder struct {
+	ID          string
+	BusID       string
+	Status      string
+	OpenedAt    time.Time
+	ClosedAt    time.Time
+	Defect      string
+	Mechanic    string
+	Parts       Parts
+	LabourHours float64
+	LabourCost  int64
+	Cost        int64
+	Version     int
+	UpdatedAt   time.Time
+}
+
+// Active reports whether the order keeps its bus in repair.
+func (o WorkOrder) Active() bool {
+	return o.Status == WorkOrderOpen || o.Status == WorkOrderInProgress
+}
+
+// Part is a spare part used by a work order; UnitPrice is in kopecks.
+type Part struct {
+	Name      string
+	Quantity  int
+	UnitPrice int64
+}
+
+// Parts are stored as a JSON array.
+type Parts []Part
+
+// Cost is the total price of the parts.
+func (p Parts) Cost() int64 {
+	var cost int64
+	for _, part := range p {
+		cost += int64(part.Quantity) * part.UnitPrice
+	}
+	return cost
+}
+
+func (p Parts) Value() (driver.Value, error) {
+	if len(p) == 0 {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]Part(p))
+	return string(data), err
+}
+
+func (p *Parts) Scan(src any) error {
+	var data []byte
+	switch src := src.(type) {
+	case string:
+		data = []byte(src)
+	case []byte:
+		data = src
+	default:
+		return fmt.Errorf("cannot scan %T into Parts", src)
+	}
+	var parts []Part
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return err
+	}
+	*p = parts
+	return nil
+}
+
+// WorkOrderRules are checked by the work order service on open and update.
+var WorkOrderRules = validation.Rules[WorkOrder]{
+	validation.Field("BusID", func(o WorkOrder) string { return o.BusID }, validation.Required()),
+	validation.Field("OpenedAt", func(o WorkOrder) time.Time { return o.OpenedAt },
+		validation.RequiredTime(), validation.NotInFuture()),
+	validation.Field("Defect", func(o WorkOrder) string { return o.Defect }, validation.Required(), validation.MaxLength(1000)),
+	validation.Field("Mechanic", func(o WorkOrder) string { return o.Mechanic }, validation.MaxLength(100)),
+	validation.Field("LabourHours", func(o WorkOrder) float64 { return o.LabourHours }, validation.Range(0, 10000)),
+	validation.Field("LabourCost", func(o WorkOrder) int64 { return o.LabourCost }, validation.IntRange(0, 1e11)),
+	validation.Cross("Parts", func(o WorkOrder) string {
+		for _, part := range o.Parts {
+			switch {
+			case part.Name == "":
+				return "Every part needs a name"
+			case part.Quantity < 1:
+				return fmt.Sprintf("Quantity of %s must be at least 1", part.Name)
+			case part.UnitPrice < 0:
+				return fmt.Sprintf("UnitPrice of %s must not be negative", part.Name)
+			}
+		}
+		return ""
+	}),
+}
+
+// RepairCost sums up the closed work orders of one bus, or of one brand and
+// model when BusID is empty. Money is in kopecks.
+type RepairCost struct {
+	BusID          string
+	RegisterNumber string
+	Brand          string
+	BusModel       string
+	Orders         int
+	LabourHours    float64
+	LabourCost     int64
+	PartsCost      int64
+	Cost           int64
+}
@@ -0,0 +1,105 @@
+// Package plates parses Russian vehicle registration plates (GOST R 50577)
+// in the formats used on buses.
+package plates
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Format is the plate type.
+type Format string
+
+const (
+	// FormatPrivate is type 1, e.g. А 123 ВС 77.
+	FormatPrivate Format = "private"
+	// FormatPublic is type 7 of route public transport, e.g. АА 123 77.
+	FormatPublic Format = "public"
+)
+
+// Plate is a parsed registration plate. Letters are upper case Cyrillic.
+type Plate struct {
+	Format Format
+	Series string
+	Number string
+	Region string
+}
+
+var ErrInvalid = errors.New("Invalid register number")
+
+// lookalikes maps the Latin letters allowed on plates, and lower case
+// Cyrillic ones, to the upper case Cyrillic letter they stand for.
+var lookalikes = strings.NewReplacer(
+	"A", "А", "a", "А", "а", "А",
+	"B", "В", "b", "В", "в", "В",
+	"E", "Е", "e", "Е", "е", "Е",
+	"K", "К", "k", "К", "к", "К",
+	"M", "М", "m", "М", "м", "М",
+	"H", "Н", "h", "Н", "н", "Н",
+	"O", "О", "o", "О", "о", "О",
+	"P", "Р", "p", "Р", "р", "Р",
+	"C", "С", "c", "С", "с", "С",
+	"T", "Т", "t", "Т", "т", "Т",
+	"Y", "У", "y", "У", "у", "У",
+	"X", "Х", "x", "Х", "х", "Х",
+)
+
+const letter = "[АВЕКМНОРСТУХ]"
+
+var (
+	privatePattern = regexp.MustCompile(`^(` + letter + `)(\d{3})(` + letter + `{2})(\d{2,3})$`)
+	publicPattern  = regexp.MustCompile(`^(` + letter + `{2})(\d{3})(\d{2,3})$`)
+)
+
+// Normalize removes spaces, dashes and a trailing RUS and maps look-alike
+// letters to upper case Cyrillic. It does not check the format, so it can be
+// applied to search input as well.
+func Normalize(s string) string {
+	s = strings.Map(func(c rune) rune {
+		if unicode.IsSpace(c) || c == '-' {
+			return -1
+		}
+		return c
+	}, s)
+	if len(s) > len("RUS") && strings.EqualFold(s[len(s)-len("RUS"):], "RUS") {
+		s = s[:len(s)-len("RUS")]
+	}
+	return strings.ToUpper(lookalikes.Replace(s))
+}
+
+// Parse accepts a plate in any spacing and case, with Latin look-alike
+// letters, and checks it against the bus plate formats.
+func Parse(s string) (Plate, error) {
+	n := Normalize(s)
+	var p Plate
+	if m := privatePattern.FindStringSubmatch(n); m != nil {
+		p = Plate{Format: FormatPrivate, Series: m[1] + m[3], Number: m[2], Region: m[4]}
+	} else if m := publicPattern.FindStringSubmatch(n); m != nil {
+		p = Plate{Format: FormatPublic, Series: m[1], Number: m[2], Region: m[3]}
+	} else {
+		return Plate{}, ErrInvalid
+	}
+	// There is no region 00, and three-digit regions never start with 0.
+	if p.Number == "000" || p.Region == "00" || (len(p.Region) == 3 && p.Region[0] == '0') {
+		return Plate{}, ErrInvalid
+	}
+	return p, nil
+}
+
+// Canonical is the stored form without spaces, e.g. А123ВС77 or АА12377.
+func (p Plate) Canonical() string {
+	if p.Format == FormatPrivate {
+		return p.Series[:len("А")] + p.Number + p.Series[len("А"):] + p.Region
+	}
+	return p.Series + p.Number + p.Region
+}
+
+// String is the form printed on the plate, e.g. А 123 ВС 77 or АА 123 77.
+func (p Plate) String() string {
+	if p.Format == FormatPrivate {
+		return p.Series[:len("А")] + " " + p.Number + " " + p.Series[len("А"):] + " " + p.Region
+	}
+	return p.Series + " " + p.Number + " " + p.Region
+}
@@ -0,0 +1,42 @@
+package plates
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	valid := []struct {
+		input     string
+		format    Format
+		canonical string
+		printed   string
+	}{
+		{"А123ВС77", FormatPrivate, "А123ВС77", "А 123 ВС 77"},
+		{"a 123 bc 777", FormatPrivate, "А123ВС777", "А 123 ВС 777"},
+		{"х001ух 50 RUS", FormatPrivate, "Х001УХ50", "Х 001 УХ 50"},
+		{"АА 123 77", FormatPublic, "АА12377", "АА 123 77"},
+		{"ao-456-102", FormatPublic, "АО456102", "АО 456 102"},
+		{"АА 123 07", FormatPublic, "АА12307", "АА 123 07"},
+		{"А123ВС02", FormatPrivate, "А123ВС02", "А 123 ВС 02"},
+	}
+	for _, tt := range valid {
+		p, err := Parse(tt.input)
+		if err != nil {
+			t.Errorf("Expected %q to be valid, got %v", tt.input, err)
+			continue
+		}
+		if p.Format != tt.format || p.Canonical() != tt.canonical || p.String() != tt.printed {
+			t.Errorf("Expected %q to parse as %s %s %q, got %s %s %q", tt.input, tt.format, tt.canonical, tt.printed, p.Format, p.Canonical(), p.String())
+		}
+	}
+
+	for _, input := range []string{"", "ABC123", "Б123ВС77", "А123ВС7", "А123ВС7777", "А000ВС77", "А123ВС00", "А123ВС077", "АА 123 00", "А12ВС77", "1234АА77"} {
+		if _, err := Parse(input); err != ErrInvalid {
+			t.Errorf("Expected %q to be invalid, got %v", input, err)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize("ab c-1"); got != "АВС1" {
+		t.Errorf("Expected АВС1, got %q", got)
+	}
+}
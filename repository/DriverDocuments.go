@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+)
+
+// sharesDocuments reports whether two drivers have the same passport, SNILS
+// or licence, each of which identifies a person.
+func sharesDocuments(a, b models.Driver) bool {
+	return a.PassportSeries == b.PassportSeries || a.Snils == b.Snils || a.LicenseSeries == b.LicenseSeries
+}
+
+// duplicateDriver is the error for adding or saving driver while other has
+// one of its documents.
+func duplicateDriver(other, driver models.Driver) error {
+	switch {
+	case other.PassportSeries == driver.PassportSeries:
+		return apperrors.AlreadyExists(models.EntityDriver, "Driver already exists")
+	case other.Snils == driver.Snils:
+		return apperrors.AlreadyExists(models.EntityDriver, "Driver with this SNILS already exists")
+	}
+	return apperrors.AlreadyExists(models.EntityDriver, "Driver with this licence already exists")
+}
@@ -0,0 +1,11 @@
+package repository
+
+import "database/sql"
+
+// Executor is implemented by both *sql.DB and *sql.Tx, so the same
+// repository can run on the shared connection or inside a unit of work.
+type Executor interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
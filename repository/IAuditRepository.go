@@ -0,0 +1,9 @@
+package repository
+
+import "busManager/models"
+
+type IAuditRepository interface {
+	Add(entry *models.AuditEntry) error
+	// Query returns matching entries, newest first.
+	Query(filter models.AuditFilter) ([]models.AuditEntry, error)
+}
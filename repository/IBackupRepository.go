@@ -0,0 +1,11 @@
+package repository
+
+type IBackupRepository interface {
+	// Backup writes a consistent copy of the live database to path.
+	Backup(path string) error
+	// Verify checks that the database file at path is intact and was
+	// written by a compatible schema version.
+	Verify(path string) error
+	// Restore replaces the contents of the live database with the file at path.
+	Restore(path string) error
+}
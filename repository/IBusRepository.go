@@ -5,8 +5,35 @@ import "busManager/models"
 type IBusRepository interface {
 	GetById(id string) (*models.Bus, error)
 	GetByNumber(number string) (*models.Bus, error)
+	// Add stores the bus as active unless it has a status.
 	Add(bus *models.Bus) error
+	// DeleteById moves the bus to the trash. Its route assignments are
+	// removed and remembered for RestoreById.
 	DeleteById(id string) error
+	// GetAllDeleted returns the trash, most recently deleted first.
+	GetAllDeleted() ([]models.Trashed[models.Bus], error)
+	// RestoreById takes the bus out of the trash and re-creates the route
+	// assignments it had, skipping routes that are gone.
+	RestoreById(id string) error
+	// PurgeById permanently removes a bus that is in the trash.
+	PurgeById(id string) error
 	GetAll() ([]models.Bus, error)
+	// GetAllWithInvalidNumber returns the live buses whose register number
+	// is not a valid plate.
+	GetAllWithInvalidNumber() ([]models.Bus, error)
+	// List returns one page of the buses matching query, sorted by
+	// RegisterNumber unless query names another field.
+	List(query models.ListQuery) (models.Page[models.Bus], error)
+	// GetAllRoutesById returns the routes the bus is assigned to.
+	GetAllRoutesById(id string) ([]models.Route, error)
+	// UpdateById saves the bus if its Version is still the stored one and
+	// increments it; otherwise it returns an apperrors.ErrConflict error
+	// carrying the stored entity.
 	UpdateById(bus *models.Bus) error
+	// AddStatusChange records a change in the status history of a bus,
+	// which may be in the trash.
+	AddStatusChange(change *models.BusStatusChange) error
+	// GetStatusHistoryById returns the status changes of the bus, oldest
+	// first.
+	GetStatusHistoryById(id string) ([]models.BusStatusChange, error)
 }
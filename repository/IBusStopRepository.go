@@ -6,7 +6,24 @@ type IBusStopRepository interface {
 	GetById(id string) (*models.BusStop, error)
 	GetByName(name string) (*models.BusStop, error)
 	Add(stop *models.BusStop) error
+	// DeleteById moves the bus stop to the trash. Its route assignments are
+	// removed and remembered for RestoreById.
 	DeleteById(id string) error
+	// GetAllDeleted returns the trash, most recently deleted first.
+	GetAllDeleted() ([]models.Trashed[models.BusStop], error)
+	// RestoreById takes the bus stop out of the trash and re-creates the route
+	// assignments it had, skipping routes that are gone.
+	RestoreById(id string) error
+	// PurgeById permanently removes a bus stop that is in the trash.
+	PurgeById(id string) error
 	GetAll() ([]models.BusStop, error)
+	// List returns one page of the bus stops matching query, sorted by
+	// Name unless query names another field.
+	List(query models.ListQuery) (models.Page[models.BusStop], error)
+	// GetAllRoutesById returns the routes the bus stop is assigned to.
+	GetAllRoutesById(id string) ([]models.Route, error)
+	// UpdateById saves the bus stop if its Version is still the stored one and
+	// increments it; otherwise it returns an apperrors.ErrConflict error
+	// carrying the stored entity.
 	UpdateById(stop *models.BusStop) error
 }
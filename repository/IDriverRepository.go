@@ -1,12 +1,39 @@
 package repository
 
-import "busManager/models"
+import (
+	"busManager/models"
+	"time"
+)
 
 type IDriverRepository interface {
 	GetById(id string) (*models.Driver, error)
 	GetByPassportSeries(passportSeries string) (*models.Driver, error)
+	GetBySnils(snils string) (*models.Driver, error)
+	// Add stores the driver unless its passport, SNILS or licence belongs to
+	// another driver, including one in the trash.
 	Add(driver *models.Driver) error
+	// DeleteById moves the driver to the trash. Its route assignments are
+	// removed and remembered for RestoreById.
 	DeleteById(id string) error
+	// GetAllDeleted returns the trash, most recently deleted first.
+	GetAllDeleted() ([]models.Trashed[models.Driver], error)
+	// RestoreById takes the driver out of the trash and re-creates the route
+	// assignments it had, skipping routes that are gone.
+	RestoreById(id string) error
+	// PurgeById permanently removes a driver that is in the trash.
+	PurgeById(id string) error
 	GetAll() ([]models.Driver, error)
+	// List returns one page of the drivers matching query, sorted by
+	// Surname unless query names another field.
+	List(query models.ListQuery) (models.Page[models.Driver], error)
+	// GetAllByLicenseExpiryBefore returns the drivers whose licence expires
+	// before date, including expired ones, soonest first. Drivers without an
+	// expiry date are left out.
+	GetAllByLicenseExpiryBefore(date time.Time) ([]models.Driver, error)
+	// GetAllRoutesById returns the routes the driver is assigned to.
+	GetAllRoutesById(id string) ([]models.Route, error)
+	// UpdateById saves the driver if its Version is still the stored one and
+	// increments it; otherwise it returns an apperrors.ErrConflict error
+	// carrying the stored entity.
 	UpdateById(driver *models.Driver) error
 }
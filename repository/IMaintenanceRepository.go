@@ -0,0 +1,20 @@
+package repository
+
+import "busManager/models"
+
+type IMaintenanceRepository interface {
+	// AddInterval stores an interval; brand, model and kind are unique
+	// together.
+	AddInterval(interval *models.MaintenanceInterval) error
+	GetIntervalById(id string) (*models.MaintenanceInterval, error)
+	// GetAllIntervals returns the intervals sorted by brand, model and kind.
+	GetAllIntervals() ([]models.MaintenanceInterval, error)
+	UpdateInterval(interval *models.MaintenanceInterval) error
+	DeleteIntervalById(id string) error
+	// AddRecord stores the record of an existing bus, in the trash or not.
+	AddRecord(record *models.MaintenanceRecord) error
+	// GetAllRecordsByBusId returns the records of a bus, newest first.
+	GetAllRecordsByBusId(busId string) ([]models.MaintenanceRecord, error)
+	// GetAllRecords returns the records of every bus, newest first.
+	GetAllRecords() ([]models.MaintenanceRecord, error)
+}
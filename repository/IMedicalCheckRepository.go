@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"busManager/models"
+	"time"
+)
+
+type IMedicalCheckRepository interface {
+	// Add stores the check of an existing driver, in the trash or not.
+	Add(check *models.MedicalCheck) error
+	GetById(id string) (*models.MedicalCheck, error)
+	// GetAllByDriverId returns the checks of a driver, newest first.
+	GetAllByDriverId(driverId string) ([]models.MedicalCheck, error)
+	// GetAllByTimeRange returns the checks made from from until to, newest
+	// first. from is inclusive, to is exclusive.
+	GetAllByTimeRange(from, to time.Time) ([]models.MedicalCheck, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"busManager/models"
+	"time"
+)
+
+type IMileageRepository interface {
+	// AddReading stores the reading of an existing bus, in the trash or not.
+	AddReading(reading *models.OdometerReading) error
+	// GetAllReadingsByBusId returns the readings of a bus, oldest first.
+	GetAllReadingsByBusId(busId string) ([]models.OdometerReading, error)
+	// AddTrips stores the trips of an existing bus on an existing route.
+	AddTrips(trips *models.OperatedTrips) error
+	// GetAllTripsByBusId returns the trips of a bus operated on days from
+	// from until to, oldest first. from is inclusive, to is exclusive.
+	GetAllTripsByBusId(busId string, from, to time.Time) ([]models.OperatedTrips, error)
+}
@@ -6,8 +6,23 @@ type IRouteRepository interface {
 	GetById(id string) (*models.Route, error)
 	GetByNumber(number string) (*models.Route, error)
 	Add(route *models.Route) error
+	// DeleteById moves the route to the trash. Its assignments are removed
+	// and remembered for RestoreById.
 	DeleteById(id string) error
+	// GetAllDeleted returns the trash, most recently deleted first.
+	GetAllDeleted() ([]models.Trashed[models.Route], error)
+	// RestoreById takes the route out of the trash and re-creates the
+	// assignments of buses, drivers and bus stops that are not deleted.
+	RestoreById(id string) error
+	// PurgeById permanently removes a route that is in the trash.
+	PurgeById(id string) error
 	GetAll() ([]models.Route, error)
+	// List returns one page of the routes matching query, sorted by
+	// Number unless query names another field.
+	List(query models.ListQuery) (models.Page[models.Route], error)
+	// UpdateById saves the route if its Version is still the stored one and
+	// increments it; otherwise it returns an apperrors.ErrConflict error
+	// carrying the stored entity.
 	UpdateById(route *models.Route) error
 	AssignDriver(routeId, driverId string) error
 	AssignBusStop(routeId, busStopId string) error
@@ -0,0 +1,9 @@
+package repository
+
+import "busManager/models"
+
+type ISearchRepository interface {
+	// Search returns up to limit live entities whose indexed text contains
+	// words starting with every word of query, best matches first.
+	Search(query string, limit int) ([]models.SearchHit, error)
+}
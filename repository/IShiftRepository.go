@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"busManager/models"
+	"time"
+)
+
+type IShiftRepository interface {
+	// Add stores the shift of an existing driver on an existing route. It
+	// does not check overlaps or working time limits.
+	Add(shift *models.Shift) error
+	GetById(id string) (*models.Shift, error)
+	// GetAllByDriverId returns the shifts of a driver overlapping from until
+	// to, ordered by start.
+	GetAllByDriverId(driverId string, from, to time.Time) ([]models.Shift, error)
+	DeleteById(id string) error
+}
@@ -0,0 +1,21 @@
+package repository
+
+// Repositories groups repositories that operate on the same connection or transaction.
+type Repositories struct {
+	Buses         IBusRepository
+	Drivers       IDriverRepository
+	BusStops      IBusStopRepository
+	Routes        IRouteRepository
+	Audit         IAuditRepository
+	MedicalChecks IMedicalCheckRepository
+	Shifts        IShiftRepository
+	Maintenance   IMaintenanceRepository
+	WorkOrders    IWorkOrderRepository
+	Mileage       IMileageRepository
+}
+
+type IUnitOfWork interface {
+	// Do runs fn with repositories bound to one transaction. The transaction
+	// is committed when fn returns nil and rolled back otherwise.
+	Do(fn func(repos Repositories) error) error
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"busManager/models"
+	"time"
+)
+
+type IWorkOrderRepository interface {
+	// Add stores the order of an existing bus, in the trash or not.
+	Add(order *models.WorkOrder) error
+	GetById(id string) (*models.WorkOrder, error)
+	// GetAllByBusId returns the orders of a bus, latest opened first.
+	GetAllByBusId(busId string) ([]models.WorkOrder, error)
+	// GetAllActive returns the open and in progress orders, oldest first.
+	GetAllActive() ([]models.WorkOrder, error)
+	// GetAllClosedBetween returns the orders closed from from until to, with
+	// from inclusive and to exclusive. Cancelled orders are left out.
+	GetAllClosedBetween(from, to time.Time) ([]models.WorkOrder, error)
+	// UpdateById saves the order if its Version is still the stored one and
+	// increments it; otherwise it returns an apperrors.ErrConflict error
+	// carrying the stored order.
+	UpdateById(order *models.WorkOrder) error
+}
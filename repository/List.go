@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// filterKind is how a list field is filtered.
+type filterKind int
+
+const (
+	filterNone     filterKind = iota
+	filterContains            // text containing the value
+	filterEqual               // text or flag equal to the value or one of a comma-separated list
+	filterAtLeast             // number not below the value
+)
+
+// listField is a field a list can be sorted by: its column, or an
+// expression over columns, in sqlite and its value in memory.
+type listField[T any] struct {
+	column string
+	filter filterKind
+	value  func(T) any
+}
+
+type listFields[T any] map[string]listField[T]
+
+var busFields = listFields[models.Bus]{
+	"Brand":            {"brand", filterContains, func(b models.Bus) any { return b.Brand }},
+	"BusModel":         {"bus_model", filterContains, func(b models.Bus) any { return b.BusModel }},
+	"RegisterNumber":   {"register_number", filterContains, func(b models.Bus) any { return b.RegisterNumber }},
+	"AssemblyDate":     {"assembly_date", filterNone, func(b models.Bus) any { return b.AssemblyDate }},
+	"LastRepairDate":   {"last_repair_date", filterNone, func(b models.Bus) any { return b.LastRepairDate }},
+	"Status":           {"status", filterEqual, func(b models.Bus) any { return b.Status }},
+	"SeatedCapacity":   {"seated_capacity", filterAtLeast, func(b models.Bus) any { return float64(b.SeatedCapacity) }},
+	"StandingCapacity": {"standing_capacity", filterAtLeast, func(b models.Bus) any { return float64(b.StandingCapacity) }},
+	"Capacity":         {"seated_capacity + standing_capacity", filterAtLeast, func(b models.Bus) any { return float64(b.Capacity()) }},
+	"Class":            {"class", filterEqual, func(b models.Bus) any { return b.Class }},
+	"Length":           {"length", filterAtLeast, func(b models.Bus) any { return b.Length }},
+	"FuelType":         {"fuel_type", filterEqual, func(b models.Bus) any { return b.FuelType }},
+	"LowFloor":         {"low_floor", filterEqual, func(b models.Bus) any { return b.LowFloor }},
+	"EcoClass":         {"eco_class", filterAtLeast, func(b models.Bus) any { return float64(b.EcoClass) }},
+	"VIN":              {"vin", filterContains, func(b models.Bus) any { return b.VIN }},
+	"UpdatedAt":        {"updated_at", filterNone, func(b models.Bus) any { return b.UpdatedAt }},
+}
+
+var driverFields = listFields[models.Driver]{
+	"Name":              {"name", filterContains, func(d models.Driver) any { return d.Name }},
+	"Surname":           {"surname", filterContains, func(d models.Driver) any { return d.Surname }},
+	"Patronymic":        {"patronymic", filterContains, func(d models.Driver) any { return d.Patronymic }},
+	"BirthDate":         {"birth_date", filterNone, func(d models.Driver) any { return d.BirthDate }},
+	"PassportSeries":    {"passport_series", filterContains, func(d models.Driver) any { return d.PassportSeries }},
+	"Snils":             {"snils", filterContains, func(d models.Driver) any { return d.Snils }},
+	"LicenseSeries":     {"license_series", filterContains, func(d models.Driver) any { return d.LicenseSeries }},
+	"LicenseIssueDate":  {"license_issue_date", filterNone, func(d models.Driver) any { return d.LicenseIssueDate }},
+	"LicenseExpiryDate": {"license_expiry_date", filterNone, func(d models.Driver) any { return d.LicenseExpiryDate }},
+	"UpdatedAt":         {"updated_at", filterNone, func(d models.Driver) any { return d.UpdatedAt }},
+}
+
+var busStopFields = listFields[models.BusStop]{
+	"Name":      {"name", filterContains, func(s models.BusStop) any { return s.Name }},
+	"Lat":       {"lat", filterNone, func(s models.BusStop) any { return s.Lat }},
+	"Long":      {"long", filterNone, func(s models.BusStop) any { return s.Long }},
+	"UpdatedAt": {"updated_at", filterNone, func(s models.BusStop) any { return s.UpdatedAt }},
+}
+
+var routeFields = listFields[models.Route]{
+	"Number":      {"number", filterContains, func(r models.Route) any { return r.Number }},
+	"Length":      {"length", filterNone, func(r models.Route) any { return r.Length }},
+	"MinCapacity": {"min_capacity", filterNone, func(r models.Route) any { return float64(r.MinCapacity) }},
+	"BusClass":    {"bus_class", filterEqual, func(r models.Route) any { return r.BusClass }},
+	"LowFloor":    {"low_floor", filterEqual, func(r models.Route) any { return r.LowFloor }},
+	"UpdatedAt":   {"updated_at", filterNone, func(r models.Route) any { return r.UpdatedAt }},
+}
+
+// prepare fills in the defaults of q and checks its field names.
+func (f listFields[T]) prepare(q models.ListQuery, defaultSort string) (models.ListQuery, error) {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit <= 0 {
+		q.Limit = models.DefaultPageLimit
+	}
+	if q.Limit > models.MaxPageLimit {
+		q.Limit = models.MaxPageLimit
+	}
+	if q.Sort == "" {
+		q.Sort = defaultSort
+	}
+	if _, ok := f[q.Sort]; !ok {
+		message := "Cannot sort by " + q.Sort
+		return q, apperrors.Validation("", message, map[string]string{"Sort": message})
+	}
+	for name, value := range q.Filters {
+		if field, ok := f[name]; !ok || field.filter == filterNone {
+			message := "Cannot filter by " + name
+			return q, apperrors.Validation("", message, map[string]string{"Filters": message})
+		}
+		if _, err := f.args(name, value); err != nil {
+			message := "Invalid filter value for " + name
+			return q, apperrors.Validation("", message, map[string]string{"Filters": message})
+		}
+	}
+	return q, nil
+}
+
+// args converts a filter value to the type of the field: flags are "true"
+// or "false" and numbers are decimal. The value of an equality filter is
+// split into its alternatives.
+func (f listFields[T]) args(name, value string) ([]any, error) {
+	values := []string{value}
+	if f[name].filter == filterEqual {
+		values = strings.Split(value, ",")
+	}
+	var zero T
+	args := make([]any, 0, len(values))
+	for _, value := range values {
+		var arg any = value
+		var err error
+		switch f[name].value(zero).(type) {
+		case bool:
+			arg, err = strconv.ParseBool(value)
+		case float64:
+			arg, err = strconv.ParseFloat(value, 64)
+		}
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// matches reports whether the value of the field in row passes the filter.
+func (f listFields[T]) matches(row T, name, value string) bool {
+	field := f[name]
+	args, _ := f.args(name, value)
+	switch field.filter {
+	case filterContains:
+		return strings.Contains(field.value(row).(string), value)
+	case filterEqual:
+		for _, arg := range args {
+			if compareValues(field.value(row), arg) == 0 {
+				return true
+			}
+		}
+		return false
+	case filterAtLeast:
+		return compareValues(field.value(row), args[0]) >= 0
+	}
+	return true
+}
+
+// filterNames returns the filtered fields in a fixed order.
+func filterNames(q models.ListQuery) []string {
+	names := make([]string, 0, len(q.Filters))
+	for name := range q.Filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sqliteList selects a page of the live rows of table. columns must list
+// the columns read by scan. Rows with equal sort values are ordered by id.
+func sqliteList[T any](db Executor, table, columns string, fields listFields[T], defaultSort string, q models.ListQuery, scan func(*sql.Rows) (T, error)) (models.Page[T], error) {
+	q, err := fields.prepare(q, defaultSort)
+	if err != nil {
+		return models.Page[T]{}, err
+	}
+	where := "deleted_at IS NULL"
+	var args []any
+	for _, name := range filterNames(q) {
+		values, _ := fields.args(name, q.Filters[name])
+		args = append(args, values...)
+		switch fields[name].filter {
+		case filterContains:
+			where += fmt.Sprintf(" AND instr(%s, $%d) > 0", fields[name].column, len(args))
+		case filterEqual:
+			placeholders := make([]string, len(values))
+			for i := range values {
+				placeholders[i] = fmt.Sprintf("$%d", len(args)-len(values)+i+1)
+			}
+			where += fmt.Sprintf(" AND %s IN (%s)", fields[name].column, strings.Join(placeholders, ", "))
+		case filterAtLeast:
+			where += fmt.Sprintf(" AND %s >= $%d", fields[name].column, len(args))
+		}
+	}
+	page := models.Page[T]{Items: []T{}, Page: q.Page, Limit: q.Limit}
+	if err := db.QueryRow(`SELECT COUNT(*) FROM `+table+` WHERE `+where, args...).Scan(&page.Total); err != nil {
+		return page, err
+	}
+	direction := "ASC"
+	if q.Desc {
+		direction = "DESC"
+	}
+	args = append(args, q.Limit, (q.Page-1)*q.Limit)
+	rows, err := db.Query(fmt.Sprintf(`SELECT %s FROM %s WHERE %s ORDER BY %s %s, id %s LIMIT $%d OFFSET $%d`,
+		columns, table, where, fields[q.Sort].column, direction, direction, len(args)-1, len(args)), args...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return page, err
+		}
+		page.Items = append(page.Items, item)
+	}
+	return page, rows.Err()
+}
+
+// memoryList is sqliteList over rows already in memory.
+func memoryList[T any](rows []T, id func(T) string, fields listFields[T], defaultSort string, q models.ListQuery) (models.Page[T], error) {
+	q, err := fields.prepare(q, defaultSort)
+	if err != nil {
+		return models.Page[T]{}, err
+	}
+	var matched []T
+	for _, row := range rows {
+		ok := true
+		for name, value := range q.Filters {
+			if !fields.matches(row, name, value) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, row)
+		}
+	}
+	sortBy := fields[q.Sort].value
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if q.Desc {
+			a, b = b, a
+		}
+		if c := compareValues(sortBy(a), sortBy(b)); c != 0 {
+			return c < 0
+		}
+		return id(a) < id(b)
+	})
+	page := models.Page[T]{Items: []T{}, Total: len(matched), Page: q.Page, Limit: q.Limit}
+	from := (q.Page - 1) * q.Limit
+	if from < len(matched) {
+		page.Items = append(page.Items, matched[from:min(from+q.Limit, len(matched))]...)
+	}
+	return page, nil
+}
+
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case float64:
+		switch {
+		case a < b.(float64):
+			return -1
+		case a > b.(float64):
+			return 1
+		}
+		return 0
+	case bool:
+		switch {
+		case a == b.(bool):
+			return 0
+		case b.(bool):
+			return -1
+		}
+		return 1
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	}
+	panic(fmt.Sprintf("unsupported list field type %T", a))
+}
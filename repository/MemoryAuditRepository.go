@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"busManager/models"
+	"github.com/google/uuid"
+	"strings"
+)
+
+type MemoryAuditRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryAuditRepository(store *MemoryStore) *MemoryAuditRepository {
+	return &MemoryAuditRepository{store: store}
+}
+
+func (r *MemoryAuditRepository) Add(entry *models.AuditEntry) error {
+	return r.store.write(func() error {
+		if strings.TrimSpace(entry.ID) == "" {
+			id, err := uuid.NewRandom()
+			if err != nil {
+				return err
+			}
+			entry.ID = id.String()
+		}
+		entry.Time = entry.Time.UTC()
+		r.store.audit = append(r.store.audit, *entry)
+		return nil
+	})
+}
+
+func (r *MemoryAuditRepository) Query(filter models.AuditFilter) ([]models.AuditEntry, error) {
+	entries := []models.AuditEntry{}
+	r.store.read(func() {
+		// entries are appended in time order, walk backwards for newest first
+		for i := len(r.store.audit) - 1; i >= 0; i-- {
+			if filter.Limit > 0 && len(entries) == filter.Limit {
+				break
+			}
+			entry := r.store.audit[i]
+			if matchesAuditFilter(entry, filter) {
+				entries = append(entries, entry)
+			}
+		}
+	})
+	return entries, nil
+}
+
+func matchesAuditFilter(entry models.AuditEntry, filter models.AuditFilter) bool {
+	switch {
+	case filter.EntityType != "" && entry.EntityType != filter.EntityType,
+		filter.EntityId != "" && entry.EntityId != filter.EntityId,
+		filter.RouteId != "" && entry.RouteId != filter.RouteId,
+		filter.User != "" && entry.User != filter.User,
+		filter.Action != "" && entry.Action != filter.Action,
+		!filter.From.IsZero() && entry.Time.Before(filter.From),
+		!filter.To.IsZero() && !entry.Time.Before(filter.To):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"busManager/plates"
+	"github.com/google/uuid"
+	"sort"
+	"strings"
+	"time"
+)
+
+type MemoryBusRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryBusRepository(store *MemoryStore) *MemoryBusRepository {
+	return &MemoryBusRepository{store: store}
+}
+
+func (r *MemoryBusRepository) GetById(id string) (*models.Bus, error) {
+	var bus models.Bus
+	var ok bool
+	r.store.read(func() {
+		bus, ok = r.store.buses.get(id)
+	})
+	if !ok {
+		return nil, apperrors.NotFound(models.EntityBus, "Bus not found")
+	}
+	return &bus, nil
+}
+
+func (r *MemoryBusRepository) GetByNumber(number string) (*models.Bus, error) {
+	var bus models.Bus
+	var ok bool
+	r.store.read(func() {
+		bus, ok = r.store.buses.find(func(b models.Bus) bool { return b.RegisterNumber == number })
+	})
+	if !ok {
+		return nil, apperrors.NotFound(models.EntityBus, "Bus not found")
+	}
+	return &bus, nil
+}
+
+func (r *MemoryBusRepository) Add(bus *models.Bus) error {
+	return r.store.write(func() error {
+		if _, exist := r.store.buses.find(func(b models.Bus) bool { return b.RegisterNumber == bus.RegisterNumber }); exist {
+			return apperrors.AlreadyExists(models.EntityBus, "Bus already exists")
+		}
+		if strings.TrimSpace(bus.ID) == "" {
+			id, err := uuid.NewRandom()
+			if err != nil {
+				return err
+			}
+			bus.ID = id.String()
+		}
+		if _, exist := r.store.buses.findTrashed(func(b models.Bus) bool { return b.RegisterNumber == bus.RegisterNumber }); exist {
+			return apperrors.AlreadyExists(models.EntityBus, "Bus already exists in trash")
+		}
+		if r.store.buses.has(bus.ID) {
+			return apperrors.AlreadyExists(models.EntityBus, "Bus already exists")
+		}
+		if err := r.checkVIN(bus); err != nil {
+			return err
+		}
+		if bus.Status == "" {
+			bus.Status = models.BusActive
+		}
+		bus.Version = 1
+		bus.UpdatedAt = time.Now().UTC()
+		r.store.buses.put(bus.ID, *bus)
+		return nil
+	})
+}
+
+func (r *MemoryBusRepository) GetAll() ([]models.Bus, error) {
+	var buses []models.Bus
+	r.store.read(func() {
+		buses = r.store.buses.all()
+	})
+	return buses, nil
+}
+
+// GetAllWithInvalidNumber checks the numbers with the plates package; the
+// memory store has no numbers from before plates were checked, but a bus
+// may be added to it directly.
+func (r *MemoryBusRepository) GetAllWithInvalidNumber() ([]models.Bus, error) {
+	buses := []models.Bus{}
+	r.store.read(func() {
+		for _, bus := range r.store.buses.all() {
+			if _, err := plates.Parse(bus.RegisterNumber); err != nil {
+				buses = append(buses, bus)
+			}
+		}
+	})
+	return buses, nil
+}
+
+func (r *MemoryBusRepository) GetAllRoutesById(id string) ([]models.Route, error) {
+	var routes []models.Route
+	r.store.read(func() {
+		routes = r.store.routesByIds(r.store.routeBuses.routesOf(id))
+	})
+	return routes, nil
+}
+
+func (r *MemoryBusRepository) List(query models.ListQuery) (models.Page[models.Bus], error) {
+	var buses []models.Bus
+	r.store.read(func() {
+		buses = r.store.buses.all()
+	})
+	return memoryList(buses, func(b models.Bus) string { return b.ID }, busFields, "RegisterNumber", query)
+}
+
+func (r *MemoryBusRepository) DeleteById(id string) error {
+	return r.store.write(func() error {
+		if _, exist := r.store.buses.get(id); !exist {
+			return apperrors.NotFound(models.EntityBus, "Bus not found")
+		}
+		r.store.buses.trash(id, time.Now().UTC())
+		r.store.trashEntityLinks(models.EntityBus, id)
+		return nil
+	})
+}
+
+func (r *MemoryBusRepository) GetAllDeleted() ([]models.Trashed[models.Bus], error) {
+	var trashed []models.Trashed[models.Bus]
+	r.store.read(func() {
+		trashed = r.store.buses.trashed()
+	})
+	return trashed, nil
+}
+
+func (r *MemoryBusRepository) RestoreById(id string) error {
+	return r.store.write(func() error {
+		if !r.store.buses.isTrashed(id) {
+			return apperrors.NotFound(models.EntityBus, "Bus not found")
+		}
+		r.store.buses.untrash(id)
+		r.store.restoreLinks(models.EntityBus, id)
+		return nil
+	})
+}
+
+func (r *MemoryBusRepository) PurgeById(id string) error {
+	return r.store.write(func() error {
+		if !r.store.buses.isTrashed(id) {
+			return apperrors.NotFound(models.EntityBus, "Bus not found")
+		}
+		r.store.buses.delete(id)
+		r.store.purgeLinks(models.EntityBus, id)
+		r.store.purgeBusRecords(id)
+		return nil
+	})
+}
+
+// checkVIN fails when another bus, in the trash or not, has the VIN of bus.
+// Callers hold the lock.
+func (r *MemoryBusRepository) checkVIN(bus *models.Bus) error {
+	if bus.VIN == "" {
+		return nil
+	}
+	taken := func(b models.Bus) bool { return b.VIN == bus.VIN && b.ID != bus.ID }
+	_, live := r.store.buses.find(taken)
+	_, trashed := r.store.buses.findTrashed(taken)
+	if live || trashed {
+		return apperrors.AlreadyExists(models.EntityBus, "Bus with this VIN already exists")
+	}
+	return nil
+}
+
+func (r *MemoryBusRepository) UpdateById(bus *models.Bus) error {
+	return r.store.write(func() error {
+		stored, exist := r.store.buses.get(bus.ID)
+		if !exist {
+			return apperrors.NotFound(models.EntityBus, "Bus not found")
+		}
+		if stored.Version != bus.Version {
+			return apperrors.Conflict(models.EntityBus, "Bus was changed by someone else", &stored)
+		}
+		other, exist := r.store.buses.find(func(b models.Bus) bool { return b.RegisterNumber == bus.RegisterNumber })
+		if exist && other.ID != bus.ID {
+			return apperrors.AlreadyExists(models.EntityBus, "Bus already exists")
+		}
+		if err := r.checkVIN(bus); err != nil {
+			return err
+		}
+		bus.Version++
+		bus.UpdatedAt = time.Now().UTC()
+		r.store.buses.put(bus.ID, *bus)
+		return nil
+	})
+}
+
+func (r *MemoryBusRepository) AddStatusChange(change *models.BusStatusChange) error {
+	return r.store.write(func() error {
+		if !r.store.buses.has(change.BusID) {
+			return apperrors.NotFound(models.EntityBus, "Bus not found")
+		}
+		if strings.TrimSpace(change.ID) == "" {
+			id, err := uuid.NewRandom()
+			if err != nil {
+				return err
+			}
+			change.ID = id.String()
+		}
+		if r.store.busStatusChanges.has(change.ID) {
+			return apperrors.AlreadyExists(models.EntityBus, "Bus already exists")
+		}
+		change.ChangedAt = change.ChangedAt.UTC()
+		r.store.busStatusChanges.put(change.ID, *change)
+		return nil
+	})
+}
+
+func (r *MemoryBusRepository) GetStatusHistoryById(id string) ([]models.BusStatusChange, error) {
+	changes := []models.BusStatusChange{}
+	r.store.read(func() {
+		for _, change := range r.store.busStatusChanges.all() {
+			if change.BusID == id {
+				changes = append(changes, change)
+			}
+		}
+	})
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].ChangedAt.Before(changes[j].ChangedAt) })
+	return changes, nil
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+type MemoryBusStopRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryBusStopRepository(store *MemoryStore) *MemoryBusStopRepository {
+	return &MemoryBusStopRepository{store: store}
+}
+
+func (r *MemoryBusStopRepository) GetById(id string) (*models.BusStop, error) {
+	var stop models.BusStop
+	var ok bool
+	r.store.read(func() {
+		stop, ok = r.store.busStops.get(id)
+	})
+	if !ok {
+		return nil, apperrors.NotFound(models.EntityBusStop, "Bus stop not found")
+	}
+	return &stop, nil
+}
+
+func (r *MemoryBusStopRepository) GetByName(name string) (*models.BusStop, error) {
+	var stop models.BusStop
+	var ok bool
+	r.store.read(func() {
+		stop, ok = r.store.busStops.find(func(s models.BusStop) bool { return s.Name == name })
+	})
+	if !ok {
+		return nil, apperrors.NotFound(models.EntityBusStop, "Bus stop not found")
+	}
+	return &stop, nil
+}
+
+func (r *MemoryBusStopRepository) Add(stop *models.BusStop) error {
+	return r.store.write(func() error {
+		if _, exist := r.store.busStops.find(func(s models.BusStop) bool { return s.Name == stop.Name }); exist {
+			return apperrors.AlreadyExists(models.EntityBusStop, "Bus stop already exists")
+		}
+		if strings.TrimSpace(stop.ID) == "" {
+			id, err := uuid.NewRandom()
+			if err != nil {
+				return err
+			}
+			stop.ID = id.String()
+		}
+		if _, exist := r.store.busStops.findTrashed(func(s models.BusStop) bool {
+			return s.Name == stop.Name || s.Lat == stop.Lat || s.Long == stop.Long
+		}); exist {
+			return apperrors.AlreadyExists(models.EntityBusStop, "Bus stop already exists in trash")
+		}
+		if r.store.busStops.has(stop.ID) {
+			return apperrors.AlreadyExists(models.EntityBusStop, "Bus stop already exists")
+		}
+		stop.Version = 1
+		stop.UpdatedAt = time.Now().UTC()
+		r.store.busStops.put(stop.ID, *stop)
+		return nil
+	})
+}
+
+func (r *MemoryBusStopRepository) GetAll() ([]models.BusStop, error) {
+	var stops []models.BusStop
+	r.store.read(func() {
+		stops = r.store.busStops.all()
+	})
+	return stops, nil
+}
+
+func (r *MemoryBusStopRepository) GetAllRoutesById(id string) ([]models.Route, error) {
+	var routes []models.Route
+	r.store.read(func() {
+		routes = r.store.routesByIds(r.store.routeBusStops.routesOf(id))
+	})
+	return routes, nil
+}
+
+func (r *MemoryBusStopRepository) List(query models.ListQuery) (models.Page[models.BusStop], error) {
+	var busStops []models.BusStop
+	r.store.read(func() {
+		busStops = r.store.busStops.all()
+	})
+	return memoryList(busStops, func(s models.BusStop) string { return s.ID }, busStopFields, "Name", query)
+}
+
+func (r *MemoryBusStopRepository) DeleteById(id string) error {
+	return r.store.write(func() error {
+		if _, exist := r.store.busStops.get(id); !exist {
+			return apperrors.NotFound(models.EntityBusStop, "Bus stop not found")
+		}
+		r.store.busStops.trash(id, time.Now().UTC())
+		r.store.trashEntityLinks(models.EntityBusStop, id)
+		return nil
+	})
+}
+
+func (r *MemoryBusStopRepository) GetAllDeleted() ([]models.Trashed[models.BusStop], error) {
+	var trashed []models.Trashed[models.BusStop]
+	r.store.read(func() {
+		trashed = r.store.busStops.trashed()
+	})
+	return trashed, nil
+}
+
+func (r *MemoryBusStopRepository) RestoreById(id string) error {
+	return r.store.write(func() error {
+		if !r.store.busStops.isTrashed(id) {
+			return apperrors.NotFound(models.EntityBusStop, "Bus stop not found")
+		}
+		r.store.busStops.untrash(id)
+		r.store.restoreLinks(models.EntityBusStop, id)
+		return nil
+	})
+}
+
+func (r *MemoryBusStopRepository) PurgeById(id string) error {
+	return r.store.write(func() error {
+		if !r.store.busStops.isTrashed(id) {
+			return apperrors.NotFound(models.EntityBusStop, "Bus stop not found")
+		}
+		r.store.busStops.delete(id)
+		r.store.purgeLinks(models.EntityBusStop, id)
+		return nil
+	})
+}
+
+func (r *MemoryBusStopRepository) UpdateById(stop *models.BusStop) error {
+	return r.store.write(func() error {
+		stored, exist := r.store.busStops.get(stop.ID)
+		if !exist {
+			return apperrors.NotFound(models.EntityBusStop, "Bus stop not found")
+		}
+		if stored.Version != stop.Version {
+			return apperrors.Conflict(models.EntityBusStop, "Bus stop was changed by someone else", &stored)
+		}
+		other, exist := r.store.busStops.find(func(s models.BusStop) bool { return s.Name == stop.Name })
+		if exist && other.ID != stop.ID {
+			return apperrors.AlreadyExists(models.EntityBusStop, "Bus stop already exists")
+		}
+		stop.Version++
+		stop.UpdatedAt = time.Now().UTC()
+		r.store.busStops.put(stop.ID, *stop)
+		return nil
+	})
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"github.com/google/uuid"
+	"sort"
+	"strings"
+	"time"
+)
+
+type MemoryDriverRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryDriverRepository(store *MemoryStore) *MemoryDriverRepository {
+	return &MemoryDriverRepository{store: store}
+}
+
+func (r *MemoryDriverRepository) GetById(id string) (*models.Driver, error) {
+	var driver models.Driver
+	var ok bool
+	r.store.read(func() {
+		driver, ok = r.store.drivers.get(id)
+	})
+	if !ok {
+		return nil, apperrors.NotFound(models.EntityDriver, "Driver not found")
+	}
+	return &driver, nil
+}
+
+func (r *MemoryDriverRepository) GetByPassportSeries(series string) (*models.Driver, error) {
+	var driver models.Driver
+	var ok bool
+	r.store.read(func() {
+		driver, ok = r.store.drivers.find(func(d models.Driver) bool { return d.PassportSeries == series })
+	})
+	if !ok {
+		return nil, apperrors.NotFound(models.EntityDriver, "Driver not found")
+	}
+	return &driver, nil
+}
+
+func (r *MemoryDriverRepository) GetBySnils(snils string) (*models.Driver, error) {
+	var driver models.Driver
+	var ok bool
+	r.store.read(func() {
+		driver, ok = r.store.drivers.find(func(d models.Driver) bool { return d.Snils == snils })
+	})
+	if !ok {
+		return nil, apperrors.NotFound(models.EntityDriver, "Driver not found")
+	}
+	return &driver, nil
+}
+
+func (r *MemoryDriverRepository) Add(driver *models.Driver) error {
+	return r.store.write(func() error {
+		if other, exist := r.store.drivers.find(func(d models.Driver) bool { return sharesDocuments(d, *driver) }); exist {
+			return duplicateDriver(other, *driver)
+		}
+		if strings.TrimSpace(driver.ID) == "" {
+			id, err := uuid.NewRandom()
+			if err != nil {
+				return err
+			}
+			driver.ID = id.String()
+		}
+		if _, exist := r.store.drivers.findTrashed(func(d models.Driver) bool { return sharesDocuments(d, *driver) }); exist {
+			return apperrors.AlreadyExists(models.EntityDriver, "Driver already exists in trash")
+		}
+		if r.store.drivers.has(driver.ID) {
+			return apperrors.AlreadyExists(models.EntityDriver, "Driver already exists")
+		}
+		driver.Version = 1
+		driver.UpdatedAt = time.Now().UTC()
+		r.store.drivers.put(driver.ID, *driver)
+		return nil
+	})
+}
+
+func (r *MemoryDriverRepository) GetAll() ([]models.Driver, error) {
+	var drivers []models.Driver
+	r.store.read(func() {
+		drivers = r.store.drivers.all()
+	})
+	return drivers, nil
+}
+
+func (r *MemoryDriverRepository) GetAllRoutesById(id string) ([]models.Route, error) {
+	var routes []models.Route
+	r.store.read(func() {
+		routes = r.store.routesByIds(r.store.routeDrivers.routesOf(id))
+	})
+	return routes, nil
+}
+
+func (r *MemoryDriverRepository) GetAllByLicenseExpiryBefore(date time.Time) ([]models.Driver, error) {
+	var drivers []models.Driver
+	r.store.read(func() {
+		for _, d := range r.store.drivers.all() {
+			if !d.LicenseExpiryDate.IsZero() && d.LicenseExpiryDate.Before(date) {
+				drivers = append(drivers, d)
+			}
+		}
+	})
+	sort.SliceStable(drivers, func(i, j int) bool {
+		if c := drivers[i].LicenseExpiryDate.Compare(drivers[j].LicenseExpiryDate); c != 0 {
+			return c < 0
+		}
+		return drivers[i].ID < drivers[j].ID
+	})
+	return drivers, nil
+}
+
+func (r *MemoryDriverRepository) List(query models.ListQuery) (models.Page[models.Driver], error) {
+	var drivers []models.Driver
+	r.store.read(func() {
+		drivers = r.store.drivers.all()
+	})
+	return memoryList(drivers, func(d models.Driver) string { return d.ID }, driverFields, "Surname", query)
+}
+
+func (r *MemoryDriverRepository) DeleteById(id string) error {
+	return r.store.write(func() error {
+		if _, exist := r.store.drivers.get(id); !exist {
+			return apperrors.NotFound(models.EntityDriver, "Driver not found")
+		}
+		r.store.drivers.trash(id, time.Now().UTC())
+		r.store.trashEntityLinks(models.EntityDriver, id)
+		return nil
+	})
+}
+
+func (r *MemoryDriverRepository) GetAllDeleted() ([]models.Trashed[models.Driver], error) {
+	var trashed []models.Trashed[models.Driver]
+	r.store.read(func() {
+		trashed = r.store.drivers.trashed()
+	})
+	return trashed, nil
+}
+
+func (r *MemoryDriverRepository) RestoreById(id string) error {
+	return r.store.write(func() error {
+		if !r.store.drivers.isTrashed(id) {
+			return apperrors.NotFound(models.EntityDriver, "Driver not found")
+		}
+		r.store.drivers.untrash(id)
+		r.store.restoreLinks(models.EntityDriver, id)
+		return nil
+	})
+}
+
+func (r *MemoryDriverRepository) PurgeById(id string) error {
+	return r.store.write(func() error {
+		if !r.store.drivers.isTrashed(id) {
+			return apperrors.NotFound(models.EntityDriver, "Driver not found")
+		}
+		r.store.drivers.delete(id)
+		r.store.purgeLinks(models.EntityDriver, id)
+		r.store.purgeDriverRecords(id)
+		return nil
+	})
+}
+
+func (r *MemoryDriverRepository) UpdateById(driver *models.Driver) error {
+	return r.store.write(func() error {
+		stored, exist := r.store.drivers.get(driver.ID)
+		if !exist {
+			return apperrors.NotFound(models.EntityDriver, "Driver not found")
+		}
+		if stored.Version != driver.Version {
+			return apperrors.Conflict(models.EntityDriver, "Driver was changed by someone else", &stored)
+		}
+		other, exist := r.store.drivers.find(func(d models.Driver) bool { return d.ID != driver.ID && sharesDocuments(d, *driver) })
+		if exist {
+			return duplicateDriver(other, *driver)
+		}
+		driver.Version++
+		driver.UpdatedAt = time.Now().UTC()
+		r.store.drivers.put(driver.ID, *driver)
+		return nil
+	})
+}
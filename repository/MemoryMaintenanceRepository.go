@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"github.com/google/uuid"
+	"sort"
+	"strings"
+)
+
+type MemoryMaintenanceRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryMaintenanceRepository(store *MemoryStore) *MemoryMaintenanceRepository {
+	return &MemoryMaintenanceRepository{store: store}
+}
+
+// sameInterval reports whether a and b are for the same brand, model and kind.
+func sameInterval(a, b models.MaintenanceInterval) bool {
+	return a.Brand == b.Brand && a.BusModel == b.BusModel && a.Kind == b.Kind
+}
+
+func (r *MemoryMaintenanceRepository) AddInterval(interval *models.MaintenanceInterval) error {
+	return r.store.write(func() error {
+		if strings.TrimSpace(interval.ID) == "" {
+			id, err := uuid.NewRandom()
+			if err != nil {
+				return err
+			}
+			interval.ID = id.String()
+		}
+		_, taken := r.store.maintenanceIntervals.find(func(i models.MaintenanceInterval) bool { return sameInterval(i, *interval) })
+		if taken || r.store.maintenanceIntervals.has(interval.ID) {
+			return apperrors.AlreadyExists(models.EntityMaintenanceInterval, "Maintenance interval already exists")
+		}
+		r.store.maintenanceIntervals.put(interval.ID, *interval)
+		return nil
+	})
+}
+
+func (r *MemoryMaintenanceRepository) GetIntervalById(id string) (*models.MaintenanceInterval, error) {
+	var interval models.MaintenanceInterval
+	var ok bool
+	r.store.read(func() { interval, ok = r.store.maintenanceIntervals.get(id) })
+	if !ok {
+		return nil, apperrors.NotFound(models.EntityMaintenanceInterval, "Maintenance interval not found")
+	}
+	return &interval, nil
+}
+
+func (r *MemoryMaintenanceRepository) GetAllIntervals() ([]models.MaintenanceInterval, error) {
+	intervals := []models.MaintenanceInterval{}
+	r.store.read(func() { intervals = append(intervals, r.store.maintenanceIntervals.all()...) })
+	sort.SliceStable(intervals, func(i, j int) bool {
+		a, b := intervals[i], intervals[j]
+		if a.Brand != b.Brand {
+			return a.Brand < b.Brand
+		}
+		if a.BusModel != b.BusModel {
+			return a.BusModel < b.BusModel
+		}
+		return a.Kind < b.Kind
+	})
+	return intervals, nil
+}
+
+func (r *MemoryMaintenanceRepository) UpdateInterval(interval *models.MaintenanceInterval) error {
+	return r.store.write(func() error {
+		if _, ok := r.store.maintenanceIntervals.get(interval.ID); !ok {
+			return apperrors.NotFound(models.EntityMaintenanceInterval, "Maintenance interval not found")
+		}
+		other, taken := r.store.maintenanceIntervals.find(func(i models.MaintenanceInterval) bool { return sameInterval(i, *interval) })
+		if taken && other.ID != interval.ID {
+			return apperrors.AlreadyExists(models.EntityMaintenanceInterval, "Maintenance interval already exists")
+		}
+		r.store.maintenanceIntervals.put(interval.ID, *interval)
+		return nil
+	})
+}
+
+func (r *MemoryMaintenanceRepository) DeleteIntervalById(id string) error {
+	return r.store.write(func() error {
+		if _, ok := r.store.maintenanceIntervals.get(id); !ok {
+			return apperrors.NotFound(models.EntityMaintenanceInterval, "Maintenance interval not found")
+		}
+		r.store.maintenanceIntervals.delete(id)
+		return nil
+	})
+}
+
+func (r *MemoryMaintenanceRepository) AddRecord(record *models.MaintenanceRecord) error {
+	return r.store.write(func() error {
+		if !r.store.buses.has(record.BusID) {
+			return apperrors.NotFound(models.EntityBus, "Bus not found")
+		}
+		if strings.TrimSpace(record.ID) == "" {
+			id, err := uuid.NewRandom()
+			if err != nil {
+				return err
+			}
+			record.ID = id.String()
+		}
+		for _, existing := range r.store.maintenanceRecords {
+			if existing.ID == record.ID {
+				return apperrors.AlreadyExists(models.EntityMaintenance, "Maintenance record already exists")
+			}
+		}
+		record.PerformedAt = record.PerformedAt.UTC()
+		r.store.maintenanceRecords = append(r.store.maintenanceRecords, *record)
+		return nil
+	})
+}
+
+func (r *MemoryMaintenanceRepository) GetAllRecordsByBusId(busId string) ([]models.MaintenanceRecord, error) {
+	return r.queryRecords(func(m models.MaintenanceRecord) bool { return m.BusID == busId }), nil
+}
+
+func (r *MemoryMaintenanceRepository) GetAllRecords() ([]models.MaintenanceRecord, error) {
+	return r.queryRecords(func(models.MaintenanceRecord) bool { return true }), nil
+}
+
+// queryRecords returns the matching records newest first; records of the
+// same time are ordered latest added first, as in sqlite.
+func (r *MemoryMaintenanceRepository) queryRecords(match func(models.MaintenanceRecord) bool) []models.MaintenanceRecord {
+	records := []models.MaintenanceRecord{}
+	r.store.read(func() {
+		for i := len(r.store.maintenanceRecords) - 1; i >= 0; i-- {
+			if match(r.store.maintenanceRecords[i]) {
+				records = append(records, r.store.maintenanceRecords[i])
+			}
+		}
+	})
+	sort.SliceStable(records, func(i, j int) bool { return records[i].PerformedAt.After(records[j].PerformedAt) })
+	return records
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"github.com/google/uuid"
+	"sort"
+	"strings"
+	"time"
+)
+
+type MemoryMedicalCheckRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryMedicalCheckRepository(store *MemoryStore) *MemoryMedicalCheckRepository {
+	return &MemoryMedicalCheckRepository{store: store}
+}
+
+func (r *MemoryMedicalCheckRepository) Add(check *models.MedicalCheck) error {
+	return r.store.write(func() error {
+		if !r.store.drivers.has(check.DriverID) {
+			return apperrors.NotFound(models.EntityDriver, "Driver not found")
+		}
+		if strings.TrimSpace(check.ID) == "" {
+			id, err := uuid.NewRandom()
+			if err != nil {
+				return err
+			}
+			check.ID = id.String()
+		}
+		for _, existing := range r.store.medicalChecks {
+			if existing.ID == check.ID {
+				return apperrors.AlreadyExists(models.EntityMedicalCheck, "Medical check already exists")
+			}
+		}
+		check.CheckedAt = check.CheckedAt.UTC()
+		r.store.medicalChecks = append(r.store.medicalChecks, *check)
+		return nil
+	})
+}
+
+func (r *MemoryMedicalCheckRepository) GetById(id string) (*models.MedicalCheck, error) {
+	checks := r.query(func(c models.MedicalCheck) bool { return c.ID == id })
+	if len(checks) == 0 {
+		return nil, apperrors.NotFound(models.EntityMedicalCheck, "Medical check not found")
+	}
+	return &checks[0], nil
+}
+
+func (r *MemoryMedicalCheckRepository) GetAllByDriverId(driverId string) ([]models.MedicalCheck, error) {
+	return r.query(func(c models.MedicalCheck) bool { return c.DriverID == driverId }), nil
+}
+
+func (r *MemoryMedicalCheckRepository) GetAllByTimeRange(from, to time.Time) ([]models.MedicalCheck, error) {
+	return r.query(func(c models.MedicalCheck) bool { return !c.CheckedAt.Before(from) && c.CheckedAt.Before(to) }), nil
+}
+
+// query returns the matching checks newest first; checks made at the same
+// time are ordered latest added first, as in sqlite.
+func (r *MemoryMedicalCheckRepository) query(match func(models.MedicalCheck) bool) []models.MedicalCheck {
+	checks := []models.MedicalCheck{}
+	r.store.read(func() {
+		for i := len(r.store.medicalChecks) - 1; i >= 0; i-- {
+			if match(r.store.medicalChecks[i]) {
+				checks = append(checks, r.store.medicalChecks[i])
+			}
+		}
+	})
+	sort.SliceStable(checks, func(i, j int) bool { return checks[i].CheckedAt.After(checks[j].CheckedAt) })
+	return checks
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"github.com/google/uuid"
+	"sort"
+	"strings"
+	"time"
+)
+
+type MemoryMileageRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryMileageRepository(store *MemoryStore) *MemoryMileageRepository {
+	return &MemoryMileageRepository{store: store}
+}
+
+func (r *MemoryMileageRepository) AddReading(reading *models.OdometerReading) error {
+	return r.store.write(func() error {
+		if !r.store.buses.has(reading.BusID) {
+			return apperrors.NotFound(models.EntityBus, "Bus not found")
+		}
+		if strings.TrimSpace(reading.ID) == "" {
+			id, err := uuid.NewRandom()
+			if err != nil {
+				return err
+			}
+			reading.ID = id.String()
+		}
+		if r.store.odometerReadings.has(reading.ID) {
+			return apperrors.AlreadyExists(models.EntityOdometerReading, "Odometer reading already exists")
+		}
+		reading.ReadAt = reading.ReadAt.UTC()
+		r.store.odometerReadings.put(reading.ID, *reading)
+		return nil
+	})
+}
+
+func (r *MemoryMileageRepository) GetAllReadingsByBusId(busId string) ([]models.OdometerReading, error) {
+	readings := []models.OdometerReading{}
+	r.store.read(func() {
+		for _, reading := range r.store.odometerReadings.all() {
+			if reading.BusID == busId {
+				readings = append(readings, reading)
+			}
+		}
+	})
+	sort.SliceStable(readings, func(i, j int) bool { return readings[i].ReadAt.Before(readings[j].ReadAt) })
+	return readings, nil
+}
+
+func (r *MemoryMileageRepository) AddTrips(trips *models.OperatedTrips) error {
+	return r.store.write(func() error {
+		if !r.store.buses.has(trips.BusID) {
+			return apperrors.NotFound(models.EntityBus, "Bus not found")
+		}
+		if !r.store.routes.has(trips.RouteID) {
+			return apperrors.NotFound(models.EntityRoute, "Route not found")
+		}
+		if strings.TrimSpace(trips.ID) == "" {
+			id, err := uuid.NewRandom()
+			if err != nil {
+				return err
+			}
+			trips.ID = id.String()
+		}
+		trips.Day = trips.Day.UTC()
+		_, taken := r.store.operatedTrips.find(func(t models.OperatedTrips) bool {
+			return t.BusID == trips.BusID && t.RouteID == trips.RouteID && t.Day.Equal(trips.Day)
+		})
+		if taken || r.store.operatedTrips.has(trips.ID) {
+			return apperrors.AlreadyExists(models.EntityOperatedTrips, "Trip record already exists")
+		}
+		r.store.operatedTrips.put(trips.ID, *trips)
+		return nil
+	})
+}
+
+func (r *MemoryMileageRepository) GetAllTripsByBusId(busId string, from, to time.Time) ([]models.OperatedTrips, error) {
+	all := []models.OperatedTrips{}
+	r.store.read(func() {
+		for _, trips := range r.store.operatedTrips.all() {
+			if trips.BusID == busId && !trips.Day.Before(from) && trips.Day.Before(to) {
+				all = append(all, trips)
+			}
+		}
+	})
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Day.Before(all[j].Day) })
+	return all, nil
+}
@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+type MemoryRouteRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryRouteRepository(store *MemoryStore) *MemoryRouteRepository {
+	return &MemoryRouteRepository{store: store}
+}
+
+func (r *MemoryRouteRepository) GetById(id string) (*models.Route, error) {
+	var route models.Route
+	var ok bool
+	r.store.read(func() {
+		route, ok = r.store.routes.get(id)
+	})
+	if !ok {
+		return nil, apperrors.NotFound(models.EntityRoute, "Route not found")
+	}
+	return &route, nil
+}
+
+func (r *MemoryRouteRepository) GetByNumber(number string) (*models.Route, error) {
+	var route models.Route
+	var ok bool
+	r.store.read(func() {
+		route, ok = r.store.routes.find(func(rt models.Route) bool { return rt.Number == number })
+	})
+	if !ok {
+		return nil, apperrors.NotFound(models.EntityRoute, "Route not found")
+	}
+	return &route, nil
+}
+
+func (r *MemoryRouteRepository) Add(route *models.Route) error {
+	return r.store.write(func() error {
+		if _, exist := r.store.routes.find(func(rt models.Route) bool { return rt.Number == route.Number }); exist {
+			return apperrors.AlreadyExists(models.EntityRoute, "Route already exists")
+		}
+		if strings.TrimSpace(route.ID) == "" {
+			id, err := uuid.NewRandom()
+			if err != nil {
+				return err
+			}
+			route.ID = id.String()
+		}
+		if _, exist := r.store.routes.findTrashed(func(rt models.Route) bool { return rt.Number == route.Number }); exist {
+			return apperrors.AlreadyExists(models.EntityRoute, "Route already exists in trash")
+		}
+		if r.store.routes.has(route.ID) {
+			return apperrors.AlreadyExists(models.EntityRoute, "Route already exists")
+		}
+		route.Version = 1
+		route.UpdatedAt = time.Now().UTC()
+		r.store.routes.put(route.ID, *route)
+		return nil
+	})
+}
+
+func (r *MemoryRouteRepository) GetAll() ([]models.Route, error) {
+	var routes []models.Route
+	r.store.read(func() {
+		routes = r.store.routes.all()
+	})
+	return routes, nil
+}
+
+func (r *MemoryRouteRepository) List(query models.ListQuery) (models.Page[models.Route], error) {
+	var routes []models.Route
+	r.store.read(func() {
+		routes = r.store.routes.all()
+	})
+	return memoryList(routes, func(rt models.Route) string { return rt.ID }, routeFields, "Number", query)
+}
+
+func (r *MemoryRouteRepository) DeleteById(id string) error {
+	return r.store.write(func() error {
+		if _, exist := r.store.routes.get(id); !exist {
+			return apperrors.NotFound(models.EntityRoute, "Route not found")
+		}
+		r.store.routes.trash(id, time.Now().UTC())
+		r.store.trashRouteLinks(id)
+		return nil
+	})
+}
+
+func (r *MemoryRouteRepository) GetAllDeleted() ([]models.Trashed[models.Route], error) {
+	var trashed []models.Trashed[models.Route]
+	r.store.read(func() {
+		trashed = r.store.routes.trashed()
+	})
+	return trashed, nil
+}
+
+func (r *MemoryRouteRepository) RestoreById(id string) error {
+	return r.store.write(func() error {
+		if !r.store.routes.isTrashed(id) {
+			return apperrors.NotFound(models.EntityRoute, "Route not found")
+		}
+		r.store.routes.untrash(id)
+		r.store.restoreLinks(models.EntityRoute, id)
+		return nil
+	})
+}
+
+func (r *MemoryRouteRepository) PurgeById(id string) error {
+	return r.store.write(func() error {
+		if !r.store.routes.isTrashed(id) {
+			return apperrors.NotFound(models.EntityRoute, "Route not found")
+		}
+		r.store.routes.delete(id)
+		r.store.purgeLinks(models.EntityRoute, id)
+		r.store.detachShifts(id)
+		return nil
+	})
+}
+
+func (r *MemoryRouteRepository) UpdateById(route *models.Route) error {
+	return r.store.write(func() error {
+		stored, exist := r.store.routes.get(route.ID)
+		if !exist {
+			return apperrors.NotFound(models.EntityRoute, "Route not found")
+		}
+		if stored.Version != route.Version {
+			return apperrors.Conflict(models.EntityRoute, "Route was changed by someone else", &stored)
+		}
+		other, exist := r.store.routes.find(func(rt models.Route) bool { return rt.Number == route.Number })
+		if exist && other.ID != route.ID {
+			return apperrors.AlreadyExists(models.EntityRoute, "Route already exists")
+		}
+		route.Version++
+		route.UpdatedAt = time.Now().UTC()
+		r.store.routes.put(route.ID, *route)
+		return nil
+	})
+}
+
+// assign links id to the route; exists reports whether the assigned entity
+// is present, mirroring the foreign keys of the sqlite schema.
+func (r *MemoryRouteRepository) assign(links func() memoryLinks, routeId, id string, exists func() bool, entityType, notFound, duplicate string) error {
+	return r.store.write(func() error {
+		if _, ok := r.store.routes.get(routeId); !ok {
+			return apperrors.NotFound(models.EntityRoute, "Route not found")
+		}
+		if links().has(routeId, id) {
+			return apperrors.AlreadyExists(models.EntityRoute, duplicate)
+		}
+		if !exists() {
+			return apperrors.NotFound(entityType, notFound)
+		}
+		links().add(routeId, id)
+		return nil
+	})
+}
+
+func (r *MemoryRouteRepository) unassign(links func() memoryLinks, routeId, id string) error {
+	return r.store.write(func() error {
+		if _, ok := r.store.routes.get(routeId); !ok {
+			return apperrors.NotFound(models.EntityRoute, "Route not found")
+		}
+		links().remove(routeId, id)
+		return nil
+	})
+}
+
+func (r *MemoryRouteRepository) AssignDriver(routeId, driverId string) error {
+	return r.assign(func() memoryLinks { return r.store.routeDrivers }, routeId, driverId,
+		func() bool { _, ok := r.store.drivers.get(driverId); return ok },
+		models.EntityDriver, "Driver not found", "Pair route_id and driver_id already exists")
+}
+
+func (r *MemoryRouteRepository) AssignBusStop(routeId, busStopId string) error {
+	return r.assign(func() memoryLinks { return r.store.routeBusStops }, routeId, busStopId,
+		func() bool { _, ok := r.store.busStops.get(busStopId); return ok },
+		models.EntityBusStop, "Bus stop not found", "Pair route_id and bus_stop_id already exists")
+}
+
+func (r *MemoryRouteRepository) AssignBus(routeId, busId string) error {
+	return r.assign(func() memoryLinks { return r.store.routeBuses }, routeId, busId,
+		func() bool { _, ok := r.store.buses.get(busId); return ok },
+		models.EntityBus, "Bus not found", "Pair route_id and bus_id already exists")
+}
+
+func (r *MemoryRouteRepository) UnassignDriver(routeId, driverId string) error {
+	return r.unassign(func() memoryLinks { return r.store.routeDrivers }, routeId, driverId)
+}
+
+func (r *MemoryRouteRepository) UnassignBusStop(routeId, busStopId string) error {
+	return r.unassign(func() memoryLinks { return r.store.routeBusStops }, routeId, busStopId)
+}
+
+func (r *MemoryRouteRepository) UnassignBus(routeId, busId string) error {
+	return r.unassign(func() memoryLinks { return r.store.routeBuses }, routeId, busId)
+}
+
+func (r *MemoryRouteRepository) GetAllDriversById(routeId string) ([]models.Driver, error) {
+	var drivers []models.Driver
+	var ok bool
+	r.store.read(func() {
+		if _, ok = r.store.routes.get(routeId); !ok {
+			return
+		}
+		for _, id := range r.store.routeDrivers[routeId] {
+			if driver, exist := r.store.drivers.get(id); exist {
+				drivers = append(drivers, driver)
+			}
+		}
+	})
+	if !ok {
+		return nil, apperrors.NotFound(models.EntityRoute, "Route not found")
+	}
+	return drivers, nil
+}
+
+func (r *MemoryRouteRepository) GetAllBusStopsById(routeId string) ([]models.BusStop, error) {
+	var busStops []models.BusStop
+	var ok bool
+	r.store.read(func() {
+		if _, ok = r.store.routes.get(routeId); !ok {
+			return
+		}
+		for _, id := range r.store.routeBusStops[routeId] {
+			if busStop, exist := r.store.busStops.get(id); exist {
+				busStops = append(busStops, busStop)
+			}
+		}
+	})
+	if !ok {
+		return nil, apperrors.NotFound(models.EntityRoute, "Route not found")
+	}
+	return busStops, nil
+}
+
+func (r *MemoryRouteRepository) GetAllBusesById(routeId string) ([]models.Bus, error) {
+	var buses []models.Bus
+	var ok bool
+	r.store.read(func() {
+		if _, ok = r.store.routes.get(routeId); !ok {
+			return
+		}
+		for _, id := range r.store.routeBuses[routeId] {
+			if bus, exist := r.store.buses.get(id); exist {
+				buses = append(buses, bus)
+			}
+		}
+	})
+	if !ok {
+		return nil, apperrors.NotFound(models.EntityRoute, "Route not found")
+	}
+	return buses, nil
+}
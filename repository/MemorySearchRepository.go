@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"busManager/models"
+	"busManager/plates"
+	"sort"
+	"strings"
+)
+
+// MemorySearchRepository matches the same text as the sqlite search and
+// ranks hits whose title matches more words first.
+type MemorySearchRepository struct {
+	store *MemoryStore
+}
+
+func NewMemorySearchRepository(store *MemoryStore) *MemorySearchRepository {
+	return &MemorySearchRepository{store: store}
+}
+
+func (r *MemorySearchRepository) documents() []models.SearchHit {
+	var docs []models.SearchHit
+	r.store.read(func() {
+		for _, b := range r.store.buses.all() {
+			docs = append(docs, models.SearchHit{Type: models.EntityBus, ID: b.ID, Title: b.RegisterNumber, Subtitle: b.Brand + " " + b.BusModel})
+		}
+		for _, d := range r.store.drivers.all() {
+			docs = append(docs, models.SearchHit{Type: models.EntityDriver, ID: d.ID, Title: d.Surname + " " + d.Name + " " + d.Patronymic})
+		}
+		for _, s := range r.store.busStops.all() {
+			docs = append(docs, models.SearchHit{Type: models.EntityBusStop, ID: s.ID, Title: s.Name})
+		}
+		for _, rt := range r.store.routes.all() {
+			docs = append(docs, models.SearchHit{Type: models.EntityRoute, ID: rt.ID, Title: rt.Number})
+		}
+	})
+	return docs
+}
+
+// hasPrefix reports whether one of words starts with term.
+func hasPrefix(words []string, term string) bool {
+	for _, word := range words {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// titleWords are the words of the title of doc; a register number also
+// counts with the words of its spaced form, as in the sqlite index.
+func titleWords(doc models.SearchHit) []string {
+	words := searchTerms(doc.Title)
+	if plate, err := plates.Parse(doc.Title); err == nil && doc.Type == models.EntityBus {
+		words = append(words, searchTerms(plate.String())...)
+	}
+	return words
+}
+
+func (r *MemorySearchRepository) Search(query string, limit int) ([]models.SearchHit, error) {
+	terms := queryTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	type scoredHit struct {
+		hit   models.SearchHit
+		score int
+	}
+	var scored []scoredHit
+	for _, doc := range r.documents() {
+		title, body := titleWords(doc), searchTerms(doc.Subtitle)
+		score := 0
+		for _, term := range terms {
+			if hasPrefix(title, term) {
+				score++
+			} else if !hasPrefix(body, term) {
+				score = -1
+				break
+			}
+		}
+		if score >= 0 {
+			scored = append(scored, scoredHit{doc, score})
+		}
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].score != scored[j].score {
+			return scored[i].score > scored[j].score
+		}
+		return scored[i].hit.Title < scored[j].hit.Title
+	})
+	var hits []models.SearchHit
+	for i := 0; i < len(scored) && i < limit; i++ {
+		hits = append(hits, scored[i].hit)
+	}
+	return hits, nil
+}
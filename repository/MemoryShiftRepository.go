@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"github.com/google/uuid"
+	"sort"
+	"strings"
+	"time"
+)
+
+type MemoryShiftRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryShiftRepository(store *MemoryStore) *MemoryShiftRepository {
+	return &MemoryShiftRepository{store: store}
+}
+
+func (r *MemoryShiftRepository) Add(shift *models.Shift) error {
+	return r.store.write(func() error {
+		if !r.store.drivers.has(shift.DriverID) {
+			return apperrors.NotFound(models.EntityDriver, "Driver not found")
+		}
+		if !r.store.routes.has(shift.RouteID) {
+			return apperrors.NotFound(models.EntityRoute, "Route not found")
+		}
+		if strings.TrimSpace(shift.ID) == "" {
+			id, err := uuid.NewRandom()
+			if err != nil {
+				return err
+			}
+			shift.ID = id.String()
+		}
+		if r.store.shifts.has(shift.ID) {
+			return apperrors.AlreadyExists(models.EntityShift, "Shift already exists")
+		}
+		shift.Start = shift.Start.UTC()
+		shift.End = shift.End.UTC()
+		breaks := make(models.Breaks, len(shift.Breaks))
+		for i, b := range shift.Breaks {
+			breaks[i] = models.Break{Start: b.Start.UTC(), End: b.End.UTC()}
+		}
+		shift.Breaks = breaks
+		r.store.shifts.put(shift.ID, *shift)
+		return nil
+	})
+}
+
+func (r *MemoryShiftRepository) GetById(id string) (*models.Shift, error) {
+	var shift models.Shift
+	var ok bool
+	r.store.read(func() {
+		shift, ok = r.store.shifts.get(id)
+	})
+	if !ok {
+		return nil, apperrors.NotFound(models.EntityShift, "Shift not found")
+	}
+	return &shift, nil
+}
+
+func (r *MemoryShiftRepository) GetAllByDriverId(driverId string, from, to time.Time) ([]models.Shift, error) {
+	shifts := []models.Shift{}
+	r.store.read(func() {
+		for _, shift := range r.store.shifts.all() {
+			if shift.DriverID == driverId && shift.End.After(from) && shift.Start.Before(to) {
+				shifts = append(shifts, shift)
+			}
+		}
+	})
+	sort.SliceStable(shifts, func(i, j int) bool {
+		if !shifts[i].Start.Equal(shifts[j].Start) {
+			return shifts[i].Start.Before(shifts[j].Start)
+		}
+		return shifts[i].ID < shifts[j].ID
+	})
+	return shifts, nil
+}
+
+func (r *MemoryShiftRepository) DeleteById(id string) error {
+	return r.store.write(func() error {
+		if !r.store.shifts.has(id) {
+			return apperrors.NotFound(models.EntityShift, "Shift not found")
+		}
+		r.store.shifts.delete(id)
+		return nil
+	})
+}
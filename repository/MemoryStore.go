@@ -0,0 +1,502 @@
+package repository
+
+import (
+	"busManager/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// memoryTable keeps rows by id and remembers insertion order for GetAll.
+// Rows in deleted are in the trash and hidden from get, find and all.
+type memoryTable[T any] struct {
+	ids     []string
+	rows    map[string]T
+	deleted map[string]time.Time
+}
+
+func newMemoryTable[T any]() memoryTable[T] {
+	return memoryTable[T]{rows: map[string]T{}, deleted: map[string]time.Time{}}
+}
+
+func (t *memoryTable[T]) get(id string) (T, bool) {
+	row, ok := t.rows[id]
+	if _, trashed := t.deleted[id]; trashed {
+		var zero T
+		return zero, false
+	}
+	return row, ok
+}
+
+// has reports whether id is taken, in the trash or not.
+func (t *memoryTable[T]) has(id string) bool {
+	_, ok := t.rows[id]
+	return ok
+}
+
+func (t *memoryTable[T]) put(id string, row T) {
+	if _, ok := t.rows[id]; !ok {
+		t.ids = append(t.ids, id)
+	}
+	t.rows[id] = row
+}
+
+func (t *memoryTable[T]) delete(id string) {
+	if _, ok := t.rows[id]; !ok {
+		return
+	}
+	delete(t.rows, id)
+	delete(t.deleted, id)
+	for i, existing := range t.ids {
+		if existing == id {
+			t.ids = append(t.ids[:i:i], t.ids[i+1:]...)
+			break
+		}
+	}
+}
+
+func (t *memoryTable[T]) all() []T {
+	var rows []T
+	for _, id := range t.ids {
+		if _, trashed := t.deleted[id]; !trashed {
+			rows = append(rows, t.rows[id])
+		}
+	}
+	return rows
+}
+
+func (t *memoryTable[T]) find(match func(T) bool) (T, bool) {
+	for _, id := range t.ids {
+		if _, trashed := t.deleted[id]; !trashed && match(t.rows[id]) {
+			return t.rows[id], true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// findTrashed is find over the rows in the trash.
+func (t *memoryTable[T]) findTrashed(match func(T) bool) (T, bool) {
+	for _, id := range t.ids {
+		if _, trashed := t.deleted[id]; trashed && match(t.rows[id]) {
+			return t.rows[id], true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (t *memoryTable[T]) isTrashed(id string) bool {
+	_, trashed := t.deleted[id]
+	return trashed
+}
+
+func (t *memoryTable[T]) trash(id string, at time.Time) {
+	t.deleted[id] = at
+}
+
+func (t *memoryTable[T]) untrash(id string) {
+	delete(t.deleted, id)
+}
+
+// trashed returns the rows in the trash, most recently deleted first.
+func (t *memoryTable[T]) trashed() []models.Trashed[T] {
+	var rows []models.Trashed[T]
+	for _, id := range t.ids {
+		if at, trashed := t.deleted[id]; trashed {
+			rows = append(rows, models.Trashed[T]{Item: t.rows[id], DeletedAt: at})
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].DeletedAt.After(rows[j].DeletedAt) })
+	return rows
+}
+
+func (t memoryTable[T]) clone() memoryTable[T] {
+	c := memoryTable[T]{
+		ids:     append([]string(nil), t.ids...),
+		rows:    make(map[string]T, len(t.rows)),
+		deleted: make(map[string]time.Time, len(t.deleted)),
+	}
+	for id, row := range t.rows {
+		c.rows[id] = row
+	}
+	for id, at := range t.deleted {
+		c.deleted[id] = at
+	}
+	return c
+}
+
+// memoryLinks is a route junction table: route id -> assigned ids in order.
+type memoryLinks map[string][]string
+
+func (l memoryLinks) has(routeId, id string) bool {
+	for _, existing := range l[routeId] {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (l memoryLinks) add(routeId, id string) {
+	l[routeId] = append(l[routeId], id)
+}
+
+func (l memoryLinks) remove(routeId, id string) {
+	ids := l[routeId]
+	for i, existing := range ids {
+		if existing == id {
+			l[routeId] = append(ids[:i:i], ids[i+1:]...)
+			return
+		}
+	}
+}
+
+// removeAll drops id from every route.
+func (l memoryLinks) removeAll(id string) {
+	for routeId := range l {
+		l.remove(routeId, id)
+	}
+}
+
+// routesOf returns the ids of routes id is assigned to, sorted so that the
+// result does not depend on map iteration order.
+func (l memoryLinks) routesOf(id string) []string {
+	var routeIds []string
+	for routeId := range l {
+		if l.has(routeId, id) {
+			routeIds = append(routeIds, routeId)
+		}
+	}
+	sort.Strings(routeIds)
+	return routeIds
+}
+
+func (l memoryLinks) clone() memoryLinks {
+	c := make(memoryLinks, len(l))
+	for routeId, ids := range l {
+		c[routeId] = append([]string(nil), ids...)
+	}
+	return c
+}
+
+// MemoryStore holds every entity in memory. It backs the demo mode and tests
+// and is safe for concurrent use: reads share a lock, writes are serialized
+// with each other and with units of work.
+type MemoryStore struct {
+	mu   sync.RWMutex
+	txMu sync.Mutex
+
+	buses    memoryTable[models.Bus]
+	drivers  memoryTable[models.Driver]
+	busStops memoryTable[models.BusStop]
+	routes   memoryTable[models.Route]
+	shifts   memoryTable[models.Shift]
+
+	maintenanceIntervals memoryTable[models.MaintenanceInterval]
+	workOrders           memoryTable[models.WorkOrder]
+	odometerReadings     memoryTable[models.OdometerReading]
+	operatedTrips        memoryTable[models.OperatedTrips]
+	busStatusChanges     memoryTable[models.BusStatusChange]
+
+	routeBuses    memoryLinks
+	routeDrivers  memoryLinks
+	routeBusStops memoryLinks
+
+	// trashLinks remembers assignments removed by soft deletes
+	trashLinks []trashLink
+
+	audit []models.AuditEntry
+
+	// medicalChecks are kept in the order they were added
+	medicalChecks []models.MedicalCheck
+	// maintenanceRecords are kept in the order they were added
+	maintenanceRecords []models.MaintenanceRecord
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buses:         newMemoryTable[models.Bus](),
+		drivers:       newMemoryTable[models.Driver](),
+		busStops:      newMemoryTable[models.BusStop](),
+		routes:        newMemoryTable[models.Route](),
+		shifts:        newMemoryTable[models.Shift](),
+		routeBuses:    memoryLinks{},
+		routeDrivers:  memoryLinks{},
+		routeBusStops: memoryLinks{},
+
+		maintenanceIntervals: newMemoryTable[models.MaintenanceInterval](),
+		workOrders:           newMemoryTable[models.WorkOrder](),
+		odometerReadings:     newMemoryTable[models.OdometerReading](),
+		operatedTrips:        newMemoryTable[models.OperatedTrips](),
+		busStatusChanges:     newMemoryTable[models.BusStatusChange](),
+	}
+}
+
+// Repositories returns repositories working directly on the store.
+func (s *MemoryStore) Repositories() Repositories {
+	return Repositories{
+		Buses:         &MemoryBusRepository{store: s},
+		Drivers:       &MemoryDriverRepository{store: s},
+		BusStops:      &MemoryBusStopRepository{store: s},
+		Routes:        &MemoryRouteRepository{store: s},
+		Audit:         &MemoryAuditRepository{store: s},
+		MedicalChecks: &MemoryMedicalCheckRepository{store: s},
+		Shifts:        &MemoryShiftRepository{store: s},
+		Maintenance:   &MemoryMaintenanceRepository{store: s},
+		WorkOrders:    &MemoryWorkOrderRepository{store: s},
+		Mileage:       &MemoryMileageRepository{store: s},
+	}
+}
+
+func (s *MemoryStore) read(fn func()) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn()
+}
+
+func (s *MemoryStore) write(fn func() error) error {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn()
+}
+
+func (s *MemoryStore) clone() *MemoryStore {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return &MemoryStore{
+		buses:         s.buses.clone(),
+		drivers:       s.drivers.clone(),
+		busStops:      s.busStops.clone(),
+		routes:        s.routes.clone(),
+		shifts:        s.shifts.clone(),
+		routeBuses:    s.routeBuses.clone(),
+		routeDrivers:  s.routeDrivers.clone(),
+		routeBusStops: s.routeBusStops.clone(),
+		trashLinks:    append([]trashLink(nil), s.trashLinks...),
+		audit:         append([]models.AuditEntry(nil), s.audit...),
+		medicalChecks: append([]models.MedicalCheck(nil), s.medicalChecks...),
+
+		maintenanceIntervals: s.maintenanceIntervals.clone(),
+		workOrders:           s.workOrders.clone(),
+		odometerReadings:     s.odometerReadings.clone(),
+		operatedTrips:        s.operatedTrips.clone(),
+		busStatusChanges:     s.busStatusChanges.clone(),
+		maintenanceRecords:   append([]models.MaintenanceRecord(nil), s.maintenanceRecords...),
+	}
+}
+
+func (s *MemoryStore) replace(from *MemoryStore) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buses = from.buses
+	s.drivers = from.drivers
+	s.busStops = from.busStops
+	s.routes = from.routes
+	s.shifts = from.shifts
+	s.routeBuses = from.routeBuses
+	s.routeDrivers = from.routeDrivers
+	s.routeBusStops = from.routeBusStops
+	s.trashLinks = from.trashLinks
+	s.audit = from.audit
+	s.medicalChecks = from.medicalChecks
+	s.maintenanceIntervals = from.maintenanceIntervals
+	s.workOrders = from.workOrders
+	s.odometerReadings = from.odometerReadings
+	s.operatedTrips = from.operatedTrips
+	s.busStatusChanges = from.busStatusChanges
+	s.maintenanceRecords = from.maintenanceRecords
+}
+
+// purgeDriverRecords drops the medical checks and shifts of a purged driver,
+// as the foreign keys of the sqlite schema do. Callers hold the lock.
+func (s *MemoryStore) purgeDriverRecords(driverId string) {
+	var kept []models.MedicalCheck
+	for _, check := range s.medicalChecks {
+		if check.DriverID != driverId {
+			kept = append(kept, check)
+		}
+	}
+	s.medicalChecks = kept
+	for _, shift := range s.shifts.all() {
+		if shift.DriverID == driverId {
+			s.shifts.delete(shift.ID)
+		}
+	}
+}
+
+// purgeBusRecords drops the maintenance records, odometer readings,
+// operated trips and status history of a purged bus and detaches its work
+// orders, which are kept for the repair costs. Callers hold the lock.
+func (s *MemoryStore) purgeBusRecords(busId string) {
+	var kept []models.MaintenanceRecord
+	for _, record := range s.maintenanceRecords {
+		if record.BusID != busId {
+			kept = append(kept, record)
+		}
+	}
+	s.maintenanceRecords = kept
+	for _, order := range s.workOrders.all() {
+		if order.BusID == busId {
+			order.BusID = ""
+			s.workOrders.put(order.ID, order)
+		}
+	}
+	for _, reading := range s.odometerReadings.all() {
+		if reading.BusID == busId {
+			s.odometerReadings.delete(reading.ID)
+		}
+	}
+	for _, trips := range s.operatedTrips.all() {
+		if trips.BusID == busId {
+			s.operatedTrips.delete(trips.ID)
+		}
+	}
+	for _, change := range s.busStatusChanges.all() {
+		if change.BusID == busId {
+			s.busStatusChanges.delete(change.ID)
+		}
+	}
+}
+
+// detachShifts clears the route of the shifts on a purged route and drops
+// the trips operated on it. Callers hold the lock.
+func (s *MemoryStore) detachShifts(routeId string) {
+	for _, trips := range s.operatedTrips.all() {
+		if trips.RouteID == routeId {
+			s.operatedTrips.delete(trips.ID)
+		}
+	}
+	for _, shift := range s.shifts.all() {
+		if shift.RouteID == routeId {
+			shift.RouteID = ""
+			s.shifts.put(shift.ID, shift)
+		}
+	}
+}
+
+// routesByIds resolves route ids sorted by route number. Callers hold the lock.
+func (s *MemoryStore) routesByIds(ids []string) []models.Route {
+	var routes []models.Route
+	for _, id := range ids {
+		if route, ok := s.routes.get(id); ok {
+			routes = append(routes, route)
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Number < routes[j].Number })
+	return routes
+}
+
+// trashLink is a route assignment removed when owner was moved to the trash.
+type trashLink struct {
+	ownerType  string
+	ownerId    string
+	routeId    string
+	entityType string
+	entityId   string
+}
+
+// linksOf returns the junction table of entityType. Callers hold the lock.
+func (s *MemoryStore) linksOf(entityType string) memoryLinks {
+	switch entityType {
+	case models.EntityBus:
+		return s.routeBuses
+	case models.EntityDriver:
+		return s.routeDrivers
+	case models.EntityBusStop:
+		return s.routeBusStops
+	}
+	panic("unknown route link " + entityType)
+}
+
+func (s *MemoryStore) isLive(entityType, id string) bool {
+	var ok bool
+	switch entityType {
+	case models.EntityBus:
+		_, ok = s.buses.get(id)
+	case models.EntityDriver:
+		_, ok = s.drivers.get(id)
+	case models.EntityBusStop:
+		_, ok = s.busStops.get(id)
+	case models.EntityRoute:
+		_, ok = s.routes.get(id)
+	}
+	return ok
+}
+
+// trashEntityLinks moves the assignments of a bus, driver or bus stop into
+// trashLinks. Callers hold the lock.
+func (s *MemoryStore) trashEntityLinks(entityType, id string) {
+	links := s.linksOf(entityType)
+	for _, routeId := range links.routesOf(id) {
+		s.trashLinks = append(s.trashLinks, trashLink{entityType, id, routeId, entityType, id})
+	}
+	links.removeAll(id)
+}
+
+// trashRouteLinks moves all assignments of a route into trashLinks. Callers
+// hold the lock.
+func (s *MemoryStore) trashRouteLinks(routeId string) {
+	for _, entityType := range []string{models.EntityBus, models.EntityDriver, models.EntityBusStop} {
+		links := s.linksOf(entityType)
+		for _, id := range links[routeId] {
+			s.trashLinks = append(s.trashLinks, trashLink{models.EntityRoute, routeId, routeId, entityType, id})
+		}
+		delete(links, routeId)
+	}
+}
+
+// restoreLinks re-creates the assignments remembered for owner whose route
+// and entity are both live. Callers hold the lock.
+func (s *MemoryStore) restoreLinks(ownerType, ownerId string) {
+	var kept []trashLink
+	for _, link := range s.trashLinks {
+		if link.ownerType != ownerType || link.ownerId != ownerId {
+			kept = append(kept, link)
+			continue
+		}
+		links := s.linksOf(link.entityType)
+		if s.isLive(models.EntityRoute, link.routeId) && s.isLive(link.entityType, link.entityId) && !links.has(link.routeId, link.entityId) {
+			links.add(link.routeId, link.entityId)
+		}
+	}
+	s.trashLinks = kept
+}
+
+// purgeLinks forgets every remembered assignment referring to the entity.
+// Callers hold the lock.
+func (s *MemoryStore) purgeLinks(entityType, id string) {
+	var kept []trashLink
+	for _, link := range s.trashLinks {
+		owner := link.ownerType == entityType && link.ownerId == id
+		entity := link.entityType == entityType && link.entityId == id
+		route := entityType == models.EntityRoute && link.routeId == id
+		if !owner && !entity && !route {
+			kept = append(kept, link)
+		}
+	}
+	s.trashLinks = kept
+}
+
+type MemoryUnitOfWork struct {
+	store *MemoryStore
+}
+
+func NewMemoryUnitOfWork(store *MemoryStore) *MemoryUnitOfWork {
+	return &MemoryUnitOfWork{store: store}
+}
+
+// Do runs fn on a copy of the store and swaps it in when fn succeeds. Other
+// writers wait until the unit of work finishes.
+func (u *MemoryUnitOfWork) Do(fn func(repos Repositories) error) error {
+	u.store.txMu.Lock()
+	defer u.store.txMu.Unlock()
+	tx := u.store.clone()
+	if err := fn(tx.Repositories()); err != nil {
+		return err
+	}
+	u.store.replace(tx)
+	return nil
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"github.com/google/uuid"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+type MemoryWorkOrderRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryWorkOrderRepository(store *MemoryStore) *MemoryWorkOrderRepository {
+	return &MemoryWorkOrderRepository{store: store}
+}
+
+func (r *MemoryWorkOrderRepository) Add(order *models.WorkOrder) error {
+	return r.store.write(func() error {
+		if !r.store.buses.has(order.BusID) {
+			return apperrors.NotFound(models.EntityBus, "Bus not found")
+		}
+		if strings.TrimSpace(order.ID) == "" {
+			id, err := uuid.NewRandom()
+			if err != nil {
+				return err
+			}
+			order.ID = id.String()
+		}
+		if r.store.workOrders.has(order.ID) {
+			return apperrors.AlreadyExists(models.EntityWorkOrder, "Work order already exists")
+		}
+		order.OpenedAt = order.OpenedAt.UTC()
+		order.ClosedAt = order.ClosedAt.UTC()
+		order.Version = 1
+		order.UpdatedAt = time.Now().UTC()
+		r.store.workOrders.put(order.ID, *order)
+		return nil
+	})
+}
+
+func (r *MemoryWorkOrderRepository) GetById(id string) (*models.WorkOrder, error) {
+	var order models.WorkOrder
+	var ok bool
+	r.store.read(func() { order, ok = r.store.workOrders.get(id) })
+	if !ok {
+		return nil, apperrors.NotFound(models.EntityWorkOrder, "Work order not found")
+	}
+	return &order, nil
+}
+
+func (r *MemoryWorkOrderRepository) GetAllByBusId(busId string) ([]models.WorkOrder, error) {
+	orders := r.query(func(o models.WorkOrder) bool { return o.BusID == busId })
+	slices.Reverse(orders)
+	sort.SliceStable(orders, func(i, j int) bool { return orders[i].OpenedAt.After(orders[j].OpenedAt) })
+	return orders, nil
+}
+
+func (r *MemoryWorkOrderRepository) GetAllActive() ([]models.WorkOrder, error) {
+	orders := r.query(models.WorkOrder.Active)
+	sort.SliceStable(orders, func(i, j int) bool { return orders[i].OpenedAt.Before(orders[j].OpenedAt) })
+	return orders, nil
+}
+
+func (r *MemoryWorkOrderRepository) GetAllClosedBetween(from, to time.Time) ([]models.WorkOrder, error) {
+	orders := r.query(func(o models.WorkOrder) bool {
+		return o.Status == models.WorkOrderClosed && !o.ClosedAt.Before(from) && o.ClosedAt.Before(to)
+	})
+	sort.SliceStable(orders, func(i, j int) bool { return orders[i].ClosedAt.Before(orders[j].ClosedAt) })
+	return orders, nil
+}
+
+func (r *MemoryWorkOrderRepository) UpdateById(order *models.WorkOrder) error {
+	return r.store.write(func() error {
+		stored, exist := r.store.workOrders.get(order.ID)
+		if !exist {
+			return apperrors.NotFound(models.EntityWorkOrder, "Work order not found")
+		}
+		if stored.Version != order.Version {
+			return apperrors.Conflict(models.EntityWorkOrder, "Work order was changed by someone else", &stored)
+		}
+		updated := *order
+		updated.BusID = stored.BusID
+		updated.OpenedAt = stored.OpenedAt
+		updated.ClosedAt = order.ClosedAt.UTC()
+		updated.Version++
+		updated.UpdatedAt = time.Now().UTC()
+		r.store.workOrders.put(order.ID, updated)
+		*order = updated
+		return nil
+	})
+}
+
+// query returns the matching orders in the order they were added.
+func (r *MemoryWorkOrderRepository) query(match func(models.WorkOrder) bool) []models.WorkOrder {
+	orders := []models.WorkOrder{}
+	r.store.read(func() {
+		for _, order := range r.store.workOrders.all() {
+			if match(order) {
+				orders = append(orders, order)
+			}
+		}
+	})
+	return orders
+}
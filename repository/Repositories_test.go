@@ -0,0 +1,1071 @@
+package repository
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// backends returns every IUnitOfWork/Repositories implementation so the
+// same behaviour is checked for sqlite and memory storage.
+func backends() map[string]func(t *testing.T) (Repositories, IUnitOfWork) {
+	return map[string]func(t *testing.T) (Repositories, IUnitOfWork){
+		"Sqlite": func(t *testing.T) (Repositories, IUnitOfWork) {
+			db := openTestDBWithForeignKeys(t)
+			t.Cleanup(func() { db.Close() })
+			return NewSqliteRepositories(db), NewSqliteUnitOfWork(db)
+		},
+		"Memory": func(t *testing.T) (Repositories, IUnitOfWork) {
+			store := NewMemoryStore()
+			return store.Repositories(), NewMemoryUnitOfWork(store)
+		},
+	}
+}
+
+func newTestBus(number string) *models.Bus {
+	fixedTime, _ := time.Parse(time.RFC3339, "2022-11-11T11:11:11Z")
+	return &models.Bus{Brand: "Volvo", BusModel: "B7R", RegisterNumber: number, AssemblyDate: fixedTime, LastRepairDate: fixedTime}
+}
+
+func TestRepositories_Buses(t *testing.T) {
+	for name, open := range backends() {
+		t.Run(name, func(t *testing.T) {
+			repos, _ := open(t)
+
+			bus := newTestBus("ABC123")
+			if err := repos.Buses.Add(bus); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if bus.ID == "" {
+				t.Errorf("Expected bus ID to be set")
+			}
+			if err := repos.Buses.Add(newTestBus("ABC123")); err == nil || err.Error() != "Bus already exists" {
+				t.Errorf("Expected 'Bus already exists' error, got %v", err)
+			}
+
+			found, err := repos.Buses.GetByNumber("ABC123")
+			if err != nil || found.ID != bus.ID {
+				t.Errorf("Expected bus %s, got %v (%v)", bus.ID, found, err)
+			}
+			if _, err := repos.Buses.GetById("missing"); err == nil || err.Error() != "Bus not found" {
+				t.Errorf("Expected 'Bus not found' error, got %v", err)
+			}
+
+			found.Brand = "Mercedes"
+			if err := repos.Buses.UpdateById(found); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			updated, _ := repos.Buses.GetById(bus.ID)
+			if updated == nil || updated.Brand != "Mercedes" {
+				t.Errorf("Expected updated brand, got %v", updated)
+			}
+			// returned values are copies
+			updated.Brand = "Changed"
+			again, _ := repos.Buses.GetById(bus.ID)
+			if again.Brand != "Mercedes" {
+				t.Errorf("Expected stored bus to be unaffected, got %s", again.Brand)
+			}
+
+			if err := repos.Buses.Add(newTestBus("XYZ789")); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			buses, err := repos.Buses.GetAll()
+			if err != nil || len(buses) != 2 {
+				t.Errorf("Expected 2 buses, got %d (%v)", len(buses), err)
+			}
+
+			if err := repos.Buses.DeleteById(bus.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := repos.Buses.DeleteById(bus.ID); err == nil || err.Error() != "Bus not found" {
+				t.Errorf("Expected 'Bus not found' error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRepositories_Assignments(t *testing.T) {
+	for name, open := range backends() {
+		t.Run(name, func(t *testing.T) {
+			repos, _ := open(t)
+			route, bus, driver, busStop := seedAssignments(t, repos)
+
+			if err := repos.Routes.AssignBus(route.ID, bus.ID); err == nil || err.Error() != "Pair route_id and bus_id already exists" {
+				t.Errorf("Expected duplicate pair error, got %v", err)
+			}
+			if err := repos.Routes.AssignBus(route.ID, "missing"); err == nil {
+				t.Errorf("Expected error assigning a missing bus")
+			}
+			if err := repos.Routes.AssignBus("missing", bus.ID); err == nil || err.Error() != "Route not found" {
+				t.Errorf("Expected 'Route not found' error, got %v", err)
+			}
+
+			buses, _ := repos.Routes.GetAllBusesById(route.ID)
+			drivers, _ := repos.Routes.GetAllDriversById(route.ID)
+			busStops, _ := repos.Routes.GetAllBusStopsById(route.ID)
+			if len(buses) != 1 || len(drivers) != 1 || len(busStops) != 1 {
+				t.Errorf("Expected one assignment of each kind, got %d %d %d", len(buses), len(drivers), len(busStops))
+			}
+			if _, err := repos.Routes.GetAllBusesById("missing"); err == nil || err.Error() != "Route not found" {
+				t.Errorf("Expected 'Route not found' error, got %v", err)
+			}
+
+			routes, _ := repos.Drivers.GetAllRoutesById(driver.ID)
+			if len(routes) != 1 || routes[0].ID != route.ID {
+				t.Errorf("Expected driver on route %s, got %v", route.Number, routes)
+			}
+
+			if err := repos.Routes.UnassignDriver(route.ID, driver.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			drivers, _ = repos.Routes.GetAllDriversById(route.ID)
+			if len(drivers) != 0 {
+				t.Errorf("Expected no drivers after unassign, got %d", len(drivers))
+			}
+
+			if err := repos.BusStops.DeleteById(busStop.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			busStops, _ = repos.Routes.GetAllBusStopsById(route.ID)
+			if len(busStops) != 0 {
+				t.Errorf("Expected deleted bus stop to leave the route, got %d", len(busStops))
+			}
+
+			if err := repos.Routes.DeleteById(route.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			routes, _ = repos.Buses.GetAllRoutesById(bus.ID)
+			if len(routes) != 0 {
+				t.Errorf("Expected no routes for bus after route delete, got %d", len(routes))
+			}
+		})
+	}
+}
+
+func TestRepositories_RoutesOrder(t *testing.T) {
+	for name, open := range backends() {
+		t.Run(name, func(t *testing.T) {
+			repos, _ := open(t)
+			bus := newTestBus("ABC123")
+			if err := repos.Buses.Add(bus); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			for _, number := range []string{"7", "12", "3", "101"} {
+				route := &models.Route{Number: number}
+				if err := repos.Routes.Add(route); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if err := repos.Routes.AssignBus(route.ID, bus.ID); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+			}
+			numbers := func() []string {
+				routes, err := repos.Buses.GetAllRoutesById(bus.ID)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				var numbers []string
+				for _, route := range routes {
+					numbers = append(numbers, route.Number)
+				}
+				return numbers
+			}
+			want := []string{"101", "12", "3", "7"}
+			if got := numbers(); !slices.Equal(got, want) {
+				t.Errorf("Expected routes ordered by number %v, got %v", want, got)
+			}
+
+			if err := repos.Buses.DeleteById(bus.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := repos.Buses.RestoreById(bus.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got := numbers(); !slices.Equal(got, want) {
+				t.Errorf("Expected restored routes ordered by number %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestMemoryLinks_RoutesOf(t *testing.T) {
+	links := memoryLinks{}
+	for _, routeId := range []string{"d", "b", "a", "c", "e"} {
+		links.add(routeId, "bus")
+	}
+	links.add("f", "other")
+	for i := 0; i < 10; i++ {
+		if got := links.routesOf("bus"); !slices.Equal(got, []string{"a", "b", "c", "d", "e"}) {
+			t.Fatalf("Expected sorted route ids, got %v", got)
+		}
+	}
+}
+
+func TestRepositories_UnitOfWorkRollback(t *testing.T) {
+	for name, open := range backends() {
+		t.Run(name, func(t *testing.T) {
+			repos, uow := open(t)
+
+			err := uow.Do(func(tx Repositories) error {
+				if err := tx.Buses.Add(newTestBus("ABC123")); err != nil {
+					return err
+				}
+				return errors.New("Database error")
+			})
+			if err == nil || err.Error() != "Database error" {
+				t.Errorf("Expected 'Database error', got %v", err)
+			}
+			if _, err := repos.Buses.GetByNumber("ABC123"); err == nil {
+				t.Errorf("Expected bus to be rolled back")
+			}
+
+			err = uow.Do(func(tx Repositories) error {
+				return tx.Buses.Add(newTestBus("ABC123"))
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if _, err := repos.Buses.GetByNumber("ABC123"); err != nil {
+				t.Errorf("Expected committed bus, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMemoryStore_Concurrent(t *testing.T) {
+	store := NewMemoryStore()
+	repos := store.Repositories()
+	uow := NewMemoryUnitOfWork(store)
+	route := &models.Route{Number: "1"}
+	if err := repos.Routes.Add(route); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			bus := newTestBus(fmt.Sprintf("BUS%03d", i))
+			err := uow.Do(func(tx Repositories) error {
+				if err := tx.Buses.Add(bus); err != nil {
+					return err
+				}
+				return tx.Routes.AssignBus(route.ID, bus.ID)
+			})
+			if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			repos.Buses.GetAll()
+			repos.Routes.GetAllBusesById(route.ID)
+		}(i)
+	}
+	wg.Wait()
+
+	buses, _ := repos.Buses.GetAll()
+	assigned, _ := repos.Routes.GetAllBusesById(route.ID)
+	if len(buses) != 50 || len(assigned) != 50 {
+		t.Errorf("Expected 50 buses on the route, got %d buses and %d assigned", len(buses), len(assigned))
+	}
+}
+
+func TestRepositories_Audit(t *testing.T) {
+	for name, open := range backends() {
+		t.Run(name, func(t *testing.T) {
+			repos, uow := open(t)
+			start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+			for i, action := range []string{models.AuditAdd, models.AuditAssign, models.AuditUnassign} {
+				entry := &models.AuditEntry{Time: start.Add(time.Duration(i) * time.Hour), User: "dispatcher",
+					Action: action, EntityType: models.EntityBus, EntityId: "bus-1", RouteId: "route-1"}
+				if err := repos.Audit.Add(entry); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+			}
+			err := uow.Do(func(tx Repositories) error {
+				if err := tx.Audit.Add(&models.AuditEntry{Time: start, Action: models.AuditDelete, EntityType: models.EntityBus, EntityId: "bus-1"}); err != nil {
+					return err
+				}
+				return errors.New("Database error")
+			})
+			if err == nil {
+				t.Fatalf("Expected error")
+			}
+
+			entries, err := repos.Audit.Query(models.AuditFilter{EntityType: models.EntityBus, EntityId: "bus-1"})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(entries) != 3 || entries[0].Action != models.AuditUnassign {
+				t.Errorf("Expected 3 entries newest first without the rolled back one, got %v", entries)
+			}
+			entries, _ = repos.Audit.Query(models.AuditFilter{From: start.Add(30 * time.Minute), To: start.Add(2 * time.Hour)})
+			if len(entries) != 1 || entries[0].Action != models.AuditAssign {
+				t.Errorf("Expected only the assign in the time range, got %v", entries)
+			}
+			entries, _ = repos.Audit.Query(models.AuditFilter{RouteId: "route-1", Limit: 2})
+			if len(entries) != 2 {
+				t.Errorf("Expected limit of 2, got %d", len(entries))
+			}
+		})
+	}
+}
+
+func TestRepositories_Trash(t *testing.T) {
+	for name, open := range backends() {
+		t.Run(name, func(t *testing.T) {
+			repos, _ := open(t)
+			route, bus, driver, _ := seedAssignments(t, repos)
+
+			if err := repos.Buses.DeleteById(bus.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if _, err := repos.Buses.GetById(bus.ID); err == nil || err.Error() != "Bus not found" {
+				t.Errorf("Expected deleted bus to be hidden, got %v", err)
+			}
+			if buses, _ := repos.Buses.GetAll(); len(buses) != 0 {
+				t.Errorf("Expected no live buses, got %d", len(buses))
+			}
+			if buses, _ := repos.Routes.GetAllBusesById(route.ID); len(buses) != 0 {
+				t.Errorf("Expected deleted bus to leave the route, got %d", len(buses))
+			}
+			trash, err := repos.Buses.GetAllDeleted()
+			if err != nil || len(trash) != 1 || trash[0].Item.ID != bus.ID || trash[0].DeletedAt.IsZero() {
+				t.Errorf("Expected bus in trash, got %v (%v)", trash, err)
+			}
+			if err := repos.Buses.Add(newTestBus(bus.RegisterNumber)); err == nil || err.Error() != "Bus already exists in trash" {
+				t.Errorf("Expected 'Bus already exists in trash' error, got %v", err)
+			}
+			if err := repos.Routes.AssignBus(route.ID, bus.ID); err == nil {
+				t.Errorf("Expected error assigning a deleted bus")
+			}
+
+			if err := repos.Buses.RestoreById(bus.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if buses, _ := repos.Routes.GetAllBusesById(route.ID); len(buses) != 1 {
+				t.Errorf("Expected restored bus back on the route, got %d", len(buses))
+			}
+			if err := repos.Buses.RestoreById(bus.ID); err == nil || err.Error() != "Bus not found" {
+				t.Errorf("Expected 'Bus not found' for a bus not in the trash, got %v", err)
+			}
+
+			// a driver deleted while its route is in the trash comes back without it
+			if err := repos.Routes.DeleteById(route.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := repos.Drivers.DeleteById(driver.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := repos.Routes.RestoreById(route.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			buses, _ := repos.Routes.GetAllBusesById(route.ID)
+			drivers, _ := repos.Routes.GetAllDriversById(route.ID)
+			busStops, _ := repos.Routes.GetAllBusStopsById(route.ID)
+			if len(buses) != 1 || len(drivers) != 0 || len(busStops) != 1 {
+				t.Errorf("Expected bus and bus stop back on the restored route, got %d %d %d", len(buses), len(drivers), len(busStops))
+			}
+
+			if err := repos.Drivers.PurgeById(driver.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if trash, _ := repos.Drivers.GetAllDeleted(); len(trash) != 0 {
+				t.Errorf("Expected empty trash after purge, got %d", len(trash))
+			}
+			if err := repos.Drivers.RestoreById(driver.ID); err == nil {
+				t.Errorf("Expected error restoring a purged driver")
+			}
+			if err := repos.Buses.PurgeById(bus.ID); err == nil || err.Error() != "Bus not found" {
+				t.Errorf("Expected live bus not to be purged, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRepositories_Versions(t *testing.T) {
+	for name, open := range backends() {
+		t.Run(name, func(t *testing.T) {
+			repos, _ := open(t)
+
+			bus := newTestBus("ABC123")
+			if err := repos.Buses.Add(bus); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			first, _ := repos.Buses.GetById(bus.ID)
+			if first.Version != 1 || first.UpdatedAt.IsZero() {
+				t.Errorf("Expected version 1 with updated_at, got %d %v", first.Version, first.UpdatedAt)
+			}
+			second, _ := repos.Buses.GetById(bus.ID)
+
+			first.Brand = "Mercedes"
+			if err := repos.Buses.UpdateById(first); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if first.Version != 2 {
+				t.Errorf("Expected version 2 after update, got %d", first.Version)
+			}
+
+			second.Brand = "Scania"
+			err := repos.Buses.UpdateById(second)
+			var conflict *apperrors.Error
+			if !errors.As(err, &conflict) || !errors.Is(err, apperrors.ErrConflict) {
+				t.Fatalf("Expected conflict error, got %v", err)
+			}
+			current, ok := conflict.Details.(*models.Bus)
+			if conflict.Entity != models.EntityBus || !ok || current.Brand != "Mercedes" || current.Version != 2 {
+				t.Errorf("Expected current bus in conflict, got %v", conflict.Details)
+			}
+			stored, _ := repos.Buses.GetById(bus.ID)
+			if stored.Brand != "Mercedes" {
+				t.Errorf("Expected stale update to be rejected, got %s", stored.Brand)
+			}
+
+			route := &models.Route{Number: "101"}
+			if err := repos.Routes.Add(route); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			route.Number = "102"
+			if err := repos.Routes.UpdateById(route); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			route.Version = 1
+			if err := repos.Routes.UpdateById(route); !errors.Is(err, apperrors.ErrConflict) {
+				t.Errorf("Expected conflict error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRepositories_List(t *testing.T) {
+	for name, open := range backends() {
+		t.Run(name, func(t *testing.T) {
+			repos, _ := open(t)
+
+			for i, number := range []string{"C300", "A100", "B200", "A101", "D400"} {
+				bus := newTestBus(number)
+				bus.AssemblyDate = bus.AssemblyDate.AddDate(i, 0, 0)
+				if err := repos.Buses.Add(bus); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+			}
+			gone, _ := repos.Buses.GetByNumber("D400")
+			if err := repos.Buses.DeleteById(gone.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			page, err := repos.Buses.List(models.ListQuery{Limit: 2})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if page.Total != 4 || page.Page != 1 || len(page.Items) != 2 || page.Items[0].RegisterNumber != "A100" || page.Items[1].RegisterNumber != "A101" {
+				t.Errorf("Expected first page sorted by number, got %+v", page)
+			}
+			page, _ = repos.Buses.List(models.ListQuery{Page: 2, Limit: 3})
+			if page.Total != 4 || len(page.Items) != 1 || page.Items[0].RegisterNumber != "C300" {
+				t.Errorf("Expected last page with C300, got %+v", page)
+			}
+			page, _ = repos.Buses.List(models.ListQuery{Page: 5})
+			if page.Total != 4 || page.Items == nil || len(page.Items) != 0 {
+				t.Errorf("Expected empty page past the end, got %+v", page)
+			}
+
+			page, _ = repos.Buses.List(models.ListQuery{Sort: "AssemblyDate", Desc: true})
+			if len(page.Items) != 4 || page.Items[0].RegisterNumber != "A101" || page.Items[3].RegisterNumber != "C300" {
+				t.Errorf("Expected buses newest first, got %+v", page.Items)
+			}
+
+			page, _ = repos.Buses.List(models.ListQuery{Filters: map[string]string{"RegisterNumber": "A10", "Brand": "Volvo"}})
+			if page.Total != 2 || len(page.Items) != 2 {
+				t.Errorf("Expected 2 buses matching A10, got %+v", page)
+			}
+
+			if _, err := repos.Buses.List(models.ListQuery{Sort: "Color"}); err == nil || err.Error() != "Cannot sort by Color" {
+				t.Errorf("Expected 'Cannot sort by Color' error, got %v", err)
+			}
+			if _, err := repos.Buses.List(models.ListQuery{Filters: map[string]string{"AssemblyDate": "2022"}}); err == nil {
+				t.Errorf("Expected error filtering by a date")
+			}
+
+			stop := &models.BusStop{Lat: 2, Long: 1, Name: "South"}
+			north := &models.BusStop{Lat: 10, Long: 3, Name: "North"}
+			if err := repos.BusStops.Add(stop); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := repos.BusStops.Add(north); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			stops, _ := repos.BusStops.List(models.ListQuery{Sort: "Lat", Desc: true})
+			if len(stops.Items) != 2 || stops.Items[0].Name != "North" {
+				t.Errorf("Expected stops sorted by latitude, got %+v", stops.Items)
+			}
+		})
+	}
+}
+
+func TestRepositories_DriverDocuments(t *testing.T) {
+	for name, open := range backends() {
+		t.Run(name, func(t *testing.T) {
+			repos, _ := open(t)
+			birth, _ := time.Parse(time.RFC3339, "1985-11-11T11:11:11Z")
+			newDriver := func(passport, snils, license string) *models.Driver {
+				return &models.Driver{Name: "John", Surname: "Doe", BirthDate: birth,
+					PassportSeries: passport, Snils: snils, LicenseSeries: license}
+			}
+
+			driver := newDriver("4510 123456", "123-456-789 64", "77 12 345678")
+			if err := repos.Drivers.Add(driver); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			found, err := repos.Drivers.GetBySnils("123-456-789 64")
+			if err != nil || found.ID != driver.ID {
+				t.Errorf("Expected driver by SNILS, got %v %v", found, err)
+			}
+			if _, err := repos.Drivers.GetBySnils("112-233-445 95"); !errors.Is(err, apperrors.ErrNotFound) {
+				t.Errorf("Expected not found error, got %v", err)
+			}
+
+			err = repos.Drivers.Add(newDriver("4511 654321", "123-456-789 64", "77 13 111111"))
+			if !errors.Is(err, apperrors.ErrAlreadyExists) || err.Error() != "Driver with this SNILS already exists" {
+				t.Errorf("Expected duplicate SNILS error, got %v", err)
+			}
+			err = repos.Drivers.Add(newDriver("4511 654321", "112-233-445 95", "77 12 345678"))
+			if !errors.Is(err, apperrors.ErrAlreadyExists) || err.Error() != "Driver with this licence already exists" {
+				t.Errorf("Expected duplicate licence error, got %v", err)
+			}
+
+			other := newDriver("4511 654321", "112-233-445 95", "77 13 111111")
+			if err := repos.Drivers.Add(other); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			other.Snils = driver.Snils
+			if err := repos.Drivers.UpdateById(other); !errors.Is(err, apperrors.ErrAlreadyExists) {
+				t.Errorf("Expected duplicate SNILS error on update, got %v", err)
+			}
+			driver.Name = "Ivan"
+			if err := repos.Drivers.UpdateById(driver); err != nil {
+				t.Errorf("Expected a driver to keep its own documents, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRepositories_DriverLicences(t *testing.T) {
+	for name, open := range backends() {
+		t.Run(name, func(t *testing.T) {
+			repos, _ := open(t)
+			birth, _ := time.Parse(time.RFC3339, "1985-11-11T11:11:11Z")
+			issued := time.Date(2015, 3, 1, 0, 0, 0, 0, time.UTC)
+			add := func(passport, snils, license string, expiry time.Time) *models.Driver {
+				driver := &models.Driver{Name: "John", Surname: "Doe", BirthDate: birth,
+					PassportSeries: passport, Snils: snils, LicenseSeries: license,
+					LicenseCategories: models.LicenseCategories{"B", "D"}, LicenseIssueDate: issued, LicenseExpiryDate: expiry}
+				if err := repos.Drivers.Add(driver); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return driver
+			}
+			later := add("4510 123456", "123-456-789 64", "77 12 345678", time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+			sooner := add("4511 654321", "112-233-445 95", "77 13 111111", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+			add("4512 111111", "111-111-111 45", "77 14 222222", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+			add("4513 222222", "222-222-222 90", "77 15 333333", time.Time{})
+
+			stored, _ := repos.Drivers.GetById(later.ID)
+			if !stored.LicenseCategories.Has("D") || len(stored.LicenseCategories) != 2 || !stored.LicenseIssueDate.Equal(issued) {
+				t.Errorf("Expected licence to be stored, got %v %v", stored.LicenseCategories, stored.LicenseIssueDate)
+			}
+
+			drivers, err := repos.Drivers.GetAllByLicenseExpiryBefore(time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(drivers) != 2 || drivers[0].ID != sooner.ID || drivers[1].ID != later.ID {
+				t.Errorf("Expected the two licences expiring in 2025 soonest first, got %+v", drivers)
+			}
+		})
+	}
+}
+
+func TestRepositories_MedicalChecks(t *testing.T) {
+	for name, open := range backends() {
+		t.Run(name, func(t *testing.T) {
+			repos, _ := open(t)
+			_, _, driver, _ := seedAssignments(t, repos)
+			day := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+			add := func(at time.Time, result string) *models.MedicalCheck {
+				check := &models.MedicalCheck{DriverID: driver.ID, CheckedAt: at, Examiner: "Dr. Ivanova", Result: result,
+					Systolic: 120, Diastolic: 80}
+				if err := repos.MedicalChecks.Add(check); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return check
+			}
+			add(day.Add(-time.Hour), models.MedicalPassed)
+			morning := add(day.Add(6*time.Hour), models.MedicalFailed)
+			again := add(day.Add(6*time.Hour), models.MedicalPassed)
+
+			err := repos.MedicalChecks.Add(&models.MedicalCheck{DriverID: "missing", CheckedAt: day})
+			if !errors.Is(err, apperrors.ErrNotFound) || err.Error() != "Driver not found" {
+				t.Errorf("Expected 'Driver not found' error, got %v", err)
+			}
+			if err := repos.MedicalChecks.Add(&models.MedicalCheck{ID: morning.ID, DriverID: driver.ID, CheckedAt: day}); !errors.Is(err, apperrors.ErrAlreadyExists) {
+				t.Errorf("Expected duplicate id error, got %v", err)
+			}
+
+			stored, err := repos.MedicalChecks.GetById(morning.ID)
+			if err != nil || stored.Result != models.MedicalFailed || !stored.CheckedAt.Equal(morning.CheckedAt) {
+				t.Errorf("Expected stored check, got %+v (%v)", stored, err)
+			}
+			checks, _ := repos.MedicalChecks.GetAllByTimeRange(day, day.Add(24*time.Hour))
+			if len(checks) != 2 || checks[0].ID != again.ID || checks[1].ID != morning.ID {
+				t.Errorf("Expected the two checks of the day, latest first, got %+v", checks)
+			}
+			if checks, _ := repos.MedicalChecks.GetAllByDriverId(driver.ID); len(checks) != 3 {
+				t.Errorf("Expected 3 checks of the driver, got %d", len(checks))
+			}
+
+			if err := repos.Drivers.DeleteById(driver.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if checks, _ := repos.MedicalChecks.GetAllByDriverId(driver.ID); len(checks) != 3 {
+				t.Errorf("Expected checks to be kept while the driver is in the trash, got %d", len(checks))
+			}
+			if err := repos.Drivers.PurgeById(driver.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if checks, _ := repos.MedicalChecks.GetAllByDriverId(driver.ID); len(checks) != 0 {
+				t.Errorf("Expected checks to be purged with the driver, got %d", len(checks))
+			}
+		})
+	}
+}
+
+func TestRepositories_Shifts(t *testing.T) {
+	for name, open := range backends() {
+		t.Run(name, func(t *testing.T) {
+			repos, _ := open(t)
+			route, _, driver, _ := seedAssignments(t, repos)
+			day := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+			add := func(start, end time.Time, breaks ...models.Break) *models.Shift {
+				shift := &models.Shift{DriverID: driver.ID, RouteID: route.ID, Start: start, End: end, Breaks: breaks}
+				if err := repos.Shifts.Add(shift); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return shift
+			}
+			lunch := models.Break{Start: day.Add(10 * time.Hour), End: day.Add(10*time.Hour + 45*time.Minute)}
+			first := add(day.Add(6*time.Hour), day.Add(14*time.Hour), lunch)
+			second := add(day.Add(30*time.Hour), day.Add(38*time.Hour))
+
+			if err := repos.Shifts.Add(&models.Shift{DriverID: "missing", RouteID: route.ID, Start: day, End: day.Add(time.Hour)}); err == nil || err.Error() != "Driver not found" {
+				t.Errorf("Expected 'Driver not found' error, got %v", err)
+			}
+			if err := repos.Shifts.Add(&models.Shift{DriverID: driver.ID, RouteID: "missing", Start: day, End: day.Add(time.Hour)}); err == nil || err.Error() != "Route not found" {
+				t.Errorf("Expected 'Route not found' error, got %v", err)
+			}
+
+			stored, err := repos.Shifts.GetById(first.ID)
+			if err != nil || len(stored.Breaks) != 1 || !stored.Breaks[0].Start.Equal(lunch.Start) || stored.DrivingTime() != 7*time.Hour+15*time.Minute {
+				t.Errorf("Expected shift with its break, got %+v (%v)", stored, err)
+			}
+			shifts, _ := repos.Shifts.GetAllByDriverId(driver.ID, day.Add(13*time.Hour), day.Add(31*time.Hour))
+			if len(shifts) != 2 || shifts[0].ID != first.ID || shifts[1].ID != second.ID {
+				t.Errorf("Expected both overlapping shifts in order, got %+v", shifts)
+			}
+			if shifts, _ := repos.Shifts.GetAllByDriverId(driver.ID, day.Add(14*time.Hour), day.Add(30*time.Hour)); len(shifts) != 0 {
+				t.Errorf("Expected no shift between the two, got %+v", shifts)
+			}
+
+			if err := repos.Shifts.DeleteById(second.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := repos.Shifts.DeleteById(second.ID); !errors.Is(err, apperrors.ErrNotFound) {
+				t.Errorf("Expected not found error, got %v", err)
+			}
+
+			if err := repos.Routes.DeleteById(route.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := repos.Routes.PurgeById(route.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if stored, err := repos.Shifts.GetById(first.ID); err != nil || stored.RouteID != "" {
+				t.Errorf("Expected shift to be kept without its purged route, got %+v (%v)", stored, err)
+			}
+			if err := repos.Drivers.DeleteById(driver.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := repos.Drivers.PurgeById(driver.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if _, err := repos.Shifts.GetById(first.ID); !errors.Is(err, apperrors.ErrNotFound) {
+				t.Errorf("Expected shift to be purged with its driver, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRepositories_Maintenance(t *testing.T) {
+	for name, open := range backends() {
+		t.Run(name, func(t *testing.T) {
+			repos, _ := open(t)
+			bus := newTestBus("ABC123")
+			if err := repos.Buses.Add(bus); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			to1 := &models.MaintenanceInterval{Brand: "Volvo", BusModel: "B7R", Kind: "TO-1", Days: 90, Kilometers: 10000}
+			to2 := &models.MaintenanceInterval{Brand: "Volvo", BusModel: "B7R", Kind: "TO-2", Days: 365}
+			for _, interval := range []*models.MaintenanceInterval{to2, to1} {
+				if err := repos.Maintenance.AddInterval(interval); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+			}
+			if err := repos.Maintenance.AddInterval(&models.MaintenanceInterval{Brand: "Volvo", BusModel: "B7R", Kind: "TO-1", Days: 30}); !errors.Is(err, apperrors.ErrAlreadyExists) {
+				t.Errorf("Expected already exists error, got %v", err)
+			}
+			intervals, _ := repos.Maintenance.GetAllIntervals()
+			if len(intervals) != 2 || intervals[0].ID != to1.ID || intervals[1].ID != to2.ID {
+				t.Errorf("Expected intervals sorted by kind, got %+v", intervals)
+			}
+			to2.Kind = "TO-1"
+			if err := repos.Maintenance.UpdateInterval(to2); !errors.Is(err, apperrors.ErrAlreadyExists) {
+				t.Errorf("Expected already exists error, got %v", err)
+			}
+			to2.Kind, to2.Kilometers = "TO-2", 40000
+			if err := repos.Maintenance.UpdateInterval(to2); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if stored, err := repos.Maintenance.GetIntervalById(to2.ID); err != nil || stored.Kilometers != 40000 {
+				t.Errorf("Expected updated interval, got %+v (%v)", stored, err)
+			}
+			if err := repos.Maintenance.DeleteIntervalById(to2.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := repos.Maintenance.DeleteIntervalById(to2.ID); !errors.Is(err, apperrors.ErrNotFound) {
+				t.Errorf("Expected not found error, got %v", err)
+			}
+			if err := repos.Maintenance.UpdateInterval(to2); !errors.Is(err, apperrors.ErrNotFound) {
+				t.Errorf("Expected not found error, got %v", err)
+			}
+
+			day := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+			add := func(at time.Time, mileage int) *models.MaintenanceRecord {
+				record := &models.MaintenanceRecord{BusID: bus.ID, Kind: "TO-1", PerformedAt: at, Mileage: mileage, Mechanic: "Petrov"}
+				if err := repos.Maintenance.AddRecord(record); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return record
+			}
+			older := add(day, 120000)
+			newer := add(day.AddDate(0, 3, 0), 130000)
+			if err := repos.Maintenance.AddRecord(&models.MaintenanceRecord{BusID: "missing", Kind: "TO-1", PerformedAt: day}); err == nil || err.Error() != "Bus not found" {
+				t.Errorf("Expected 'Bus not found' error, got %v", err)
+			}
+			records, _ := repos.Maintenance.GetAllRecordsByBusId(bus.ID)
+			if len(records) != 2 || records[0].ID != newer.ID || records[1].ID != older.ID || records[1].Mileage != 120000 {
+				t.Errorf("Expected records newest first, got %+v", records)
+			}
+			if records, _ := repos.Maintenance.GetAllRecords(); len(records) != 2 {
+				t.Errorf("Expected 2 records, got %d", len(records))
+			}
+
+			if err := repos.Buses.DeleteById(bus.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if records, _ := repos.Maintenance.GetAllRecordsByBusId(bus.ID); len(records) != 2 {
+				t.Errorf("Expected records of a bus in the trash to be kept, got %d", len(records))
+			}
+			if err := repos.Buses.PurgeById(bus.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if records, _ := repos.Maintenance.GetAllRecordsByBusId(bus.ID); len(records) != 0 {
+				t.Errorf("Expected records to be purged with their bus, got %+v", records)
+			}
+		})
+	}
+}
+
+func TestRepositories_WorkOrders(t *testing.T) {
+	for name, open := range backends() {
+		t.Run(name, func(t *testing.T) {
+			repos, _ := open(t)
+			bus := newTestBus("ABC123")
+			if err := repos.Buses.Add(bus); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			bus.Status = models.BusInRepair
+			if err := repos.Buses.UpdateById(bus); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if stored, _ := repos.Buses.GetById(bus.ID); stored.Status != models.BusInRepair {
+				t.Errorf("Expected bus in repair, got %+v", stored)
+			}
+
+			day := time.Date(2024, 5, 6, 8, 0, 0, 0, time.UTC)
+			add := func(openedAt time.Time) *models.WorkOrder {
+				order := &models.WorkOrder{BusID: bus.ID, Status: models.WorkOrderOpen, OpenedAt: openedAt, Defect: "Brakes",
+					Parts: models.Parts{{Name: "Pad", Quantity: 4, UnitPrice: 1500}}, LabourCost: 3000, Cost: 9000}
+				if err := repos.WorkOrders.Add(order); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return order
+			}
+			first := add(day)
+			second := add(day.AddDate(0, 0, 1))
+			if err := repos.WorkOrders.Add(&models.WorkOrder{BusID: "missing", OpenedAt: day}); err == nil || err.Error() != "Bus not found" {
+				t.Errorf("Expected 'Bus not found' error, got %v", err)
+			}
+
+			stored, err := repos.WorkOrders.GetById(first.ID)
+			if err != nil || stored.Version != 1 || !stored.ClosedAt.IsZero() || len(stored.Parts) != 1 || stored.Parts.Cost() != 6000 {
+				t.Errorf("Expected stored order with its parts, got %+v (%v)", stored, err)
+			}
+			orders, _ := repos.WorkOrders.GetAllByBusId(bus.ID)
+			if len(orders) != 2 || orders[0].ID != second.ID || orders[1].ID != first.ID {
+				t.Errorf("Expected orders latest opened first, got %+v", orders)
+			}
+
+			closed := *stored
+			closed.Status = models.WorkOrderClosed
+			closed.ClosedAt = day.Add(6 * time.Hour)
+			closed.Mechanic = "Petrov"
+			if err := repos.WorkOrders.UpdateById(&closed); err != nil || closed.Version != 2 {
+				t.Fatalf("Expected no error and version 2, got %v (%d)", err, closed.Version)
+			}
+			if err := repos.WorkOrders.UpdateById(stored); !errors.Is(err, apperrors.ErrConflict) {
+				t.Errorf("Expected conflict error, got %v", err)
+			}
+			if active, _ := repos.WorkOrders.GetAllActive(); len(active) != 1 || active[0].ID != second.ID {
+				t.Errorf("Expected the second order active, got %+v", active)
+			}
+			done, _ := repos.WorkOrders.GetAllClosedBetween(day, day.AddDate(0, 0, 1))
+			if len(done) != 1 || done[0].ID != first.ID || !done[0].ClosedAt.Equal(closed.ClosedAt) || done[0].Mechanic != "Petrov" {
+				t.Errorf("Expected the closed order, got %+v", done)
+			}
+			if done, _ := repos.WorkOrders.GetAllClosedBetween(day.Add(7*time.Hour), day.AddDate(0, 0, 1)); len(done) != 0 {
+				t.Errorf("Expected no order closed in the period, got %+v", done)
+			}
+
+			if err := repos.Buses.DeleteById(bus.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := repos.Buses.PurgeById(bus.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if kept, err := repos.WorkOrders.GetById(first.ID); err != nil || kept.BusID != "" || kept.Cost != 9000 {
+				t.Errorf("Expected the order kept without its bus, got %+v (%v)", kept, err)
+			}
+		})
+	}
+}
+
+func TestRepositories_Mileage(t *testing.T) {
+	for name, open := range backends() {
+		t.Run(name, func(t *testing.T) {
+			repos, _ := open(t)
+			bus := newTestBus("ABC123")
+			if err := repos.Buses.Add(bus); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			route := &models.Route{Number: "7", Length: 12.5}
+			if err := repos.Routes.Add(route); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if stored, _ := repos.Routes.GetById(route.ID); stored.Length != 12.5 {
+				t.Errorf("Expected route length 12.5, got %+v", stored)
+			}
+
+			day := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+			later := &models.OdometerReading{BusID: bus.ID, ReadAt: day.Add(30 * time.Hour), Kilometers: 120400}
+			earlier := &models.OdometerReading{BusID: bus.ID, ReadAt: day.Add(6 * time.Hour), Kilometers: 120000, Notes: "Depot"}
+			for _, reading := range []*models.OdometerReading{later, earlier} {
+				if err := repos.Mileage.AddReading(reading); err != nil || reading.ID == "" {
+					t.Fatalf("Expected no error and an ID, got %v", err)
+				}
+			}
+			if err := repos.Mileage.AddReading(&models.OdometerReading{BusID: "missing", ReadAt: day}); err == nil || err.Error() != "Bus not found" {
+				t.Errorf("Expected 'Bus not found' error, got %v", err)
+			}
+			readings, _ := repos.Mileage.GetAllReadingsByBusId(bus.ID)
+			if len(readings) != 2 || readings[0].ID != earlier.ID || readings[0].Notes != "Depot" || !readings[1].ReadAt.Equal(later.ReadAt) {
+				t.Errorf("Expected readings oldest first, got %+v", readings)
+			}
+
+			trips := &models.OperatedTrips{BusID: bus.ID, RouteID: route.ID, Day: day, Count: 8}
+			if err := repos.Mileage.AddTrips(trips); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := repos.Mileage.AddTrips(&models.OperatedTrips{BusID: bus.ID, RouteID: route.ID, Day: day, Count: 2}); !errors.Is(err, apperrors.ErrAlreadyExists) {
+				t.Errorf("Expected already exists error for the same day, got %v", err)
+			}
+			if err := repos.Mileage.AddTrips(&models.OperatedTrips{BusID: bus.ID, RouteID: "missing", Day: day, Count: 2}); err == nil || err.Error() != "Route not found" {
+				t.Errorf("Expected 'Route not found' error, got %v", err)
+			}
+			if err := repos.Mileage.AddTrips(&models.OperatedTrips{BusID: bus.ID, RouteID: route.ID, Day: day.AddDate(0, 0, 1), Count: 6}); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			all, _ := repos.Mileage.GetAllTripsByBusId(bus.ID, day, day.AddDate(0, 0, 2))
+			if len(all) != 2 || all[0].ID != trips.ID || all[1].Count != 6 {
+				t.Errorf("Expected trips oldest first, got %+v", all)
+			}
+			if all, _ := repos.Mileage.GetAllTripsByBusId(bus.ID, day.AddDate(0, 0, 1), day.AddDate(0, 0, 2)); len(all) != 1 {
+				t.Errorf("Expected one trip record in the period, got %+v", all)
+			}
+
+			if err := repos.Routes.DeleteById(route.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := repos.Routes.PurgeById(route.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if all, _ := repos.Mileage.GetAllTripsByBusId(bus.ID, day, day.AddDate(0, 0, 2)); len(all) != 0 {
+				t.Errorf("Expected trips to be purged with their route, got %+v", all)
+			}
+			if err := repos.Buses.DeleteById(bus.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := repos.Buses.PurgeById(bus.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if readings, _ := repos.Mileage.GetAllReadingsByBusId(bus.ID); len(readings) != 0 {
+				t.Errorf("Expected readings to be purged with their bus, got %+v", readings)
+			}
+		})
+	}
+}
+
+func TestRepositories_BusAttributes(t *testing.T) {
+	for name, open := range backends() {
+		t.Run(name, func(t *testing.T) {
+			repos, _ := open(t)
+			large := newTestBus("A100AA")
+			large.SeatedCapacity, large.StandingCapacity, large.Class, large.Length = 28, 72, models.BusClassLarge, 12
+			large.FuelType, large.LowFloor, large.EcoClass, large.VIN = models.FuelDiesel, true, 5, "XTY529222J0012345"
+			small := newTestBus("A200AA")
+			small.SeatedCapacity, small.StandingCapacity, small.Class, small.FuelType = 23, 18, models.BusClassSmall, models.FuelPetrol
+			for _, bus := range []*models.Bus{large, small} {
+				if err := repos.Buses.Add(bus); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+			}
+			stored, err := repos.Buses.GetById(large.ID)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if stored.SeatedCapacity != 28 || stored.StandingCapacity != 72 || stored.Class != models.BusClassLarge || stored.Length != 12 ||
+				stored.FuelType != models.FuelDiesel || !stored.LowFloor || stored.EcoClass != 5 || stored.VIN != large.VIN {
+				t.Errorf("Expected the technical attributes to be stored, got %+v", stored)
+			}
+
+			duplicate := newTestBus("A300AA")
+			duplicate.VIN = large.VIN
+			if err := repos.Buses.Add(duplicate); !errors.Is(err, apperrors.ErrAlreadyExists) {
+				t.Errorf("Expected already exists error for the VIN, got %v", err)
+			}
+			small.VIN = large.VIN
+			if err := repos.Buses.UpdateById(small); !errors.Is(err, apperrors.ErrAlreadyExists) {
+				t.Errorf("Expected already exists error for the VIN on update, got %v", err)
+			}
+			small.VIN = ""
+
+			for filters, want := range map[[2]string]int{
+				{"Class", "large"}:     1,
+				{"Class", "larg"}:      0,
+				{"Capacity", "41"}:     2,
+				{"Capacity", "42"}:     1,
+				{"LowFloor", "false"}:  1,
+				{"EcoClass", "5"}:      1,
+				{"FuelType", "petrol"}: 1,
+				{"VIN", "XTY"}:         1,
+			} {
+				page, err := repos.Buses.List(models.ListQuery{Filters: map[string]string{filters[0]: filters[1]}})
+				if err != nil || page.Total != want {
+					t.Errorf("Expected %d buses for %v, got %+v (%v)", want, filters, page, err)
+				}
+			}
+			page, _ := repos.Buses.List(models.ListQuery{Sort: "Capacity", Desc: true})
+			if len(page.Items) != 2 || page.Items[0].ID != large.ID {
+				t.Errorf("Expected the large bus first, got %+v", page.Items)
+			}
+			if _, err := repos.Buses.List(models.ListQuery{Filters: map[string]string{"LowFloor": "yes"}}); err == nil || err.Error() != "Invalid filter value for LowFloor" {
+				t.Errorf("Expected invalid filter value error, got %v", err)
+			}
+
+			route := &models.Route{Number: "12", MinCapacity: 80, BusClass: models.BusClassLarge, LowFloor: true}
+			if err := repos.Routes.Add(route); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			route.MinCapacity = 90
+			if err := repos.Routes.UpdateById(route); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if stored, _ := repos.Routes.GetById(route.ID); stored.MinCapacity != 90 || stored.BusClass != models.BusClassLarge || !stored.LowFloor {
+				t.Errorf("Expected the route requirements, got %+v", stored)
+			}
+			if err := repos.Routes.AssignBus(route.ID, large.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if buses, _ := repos.Routes.GetAllBusesById(route.ID); len(buses) != 1 || buses[0].Capacity() != 100 || buses[0].VIN != large.VIN {
+				t.Errorf("Expected the bus with its attributes on the route, got %+v", buses)
+			}
+			if routes, _ := repos.Buses.GetAllRoutesById(large.ID); len(routes) != 1 || routes[0].MinCapacity != 90 {
+				t.Errorf("Expected the route with its requirements, got %+v", routes)
+			}
+		})
+	}
+}
+
+func TestRepositories_BusStatus(t *testing.T) {
+	for name, open := range backends() {
+		t.Run(name, func(t *testing.T) {
+			repos, _ := open(t)
+			active, reserve, retired := newTestBus("A100AA"), newTestBus("A200AA"), newTestBus("A300AA")
+			reserve.Status, retired.Status = models.BusReserve, models.BusDecommissioned
+			for _, bus := range []*models.Bus{active, reserve, retired} {
+				if err := repos.Buses.Add(bus); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+			}
+			if stored, _ := repos.Buses.GetById(active.ID); stored.Status != models.BusActive {
+				t.Errorf("Expected a new bus to be active, got %q", stored.Status)
+			}
+			for filter, want := range map[string]int{"reserve": 1, "active,reserve": 2, "in_repair": 0} {
+				page, err := repos.Buses.List(models.ListQuery{Filters: map[string]string{"Status": filter}})
+				if err != nil || page.Total != want {
+					t.Errorf("Expected %d buses for %q, got %+v (%v)", want, filter, page, err)
+				}
+			}
+
+			changedAt := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+			for i, to := range []string{models.BusInRepair, models.BusAwaitingInspection} {
+				change := &models.BusStatusChange{BusID: active.ID, From: models.BusActive, To: to,
+					ChangedAt: changedAt.AddDate(0, 0, 1-i), Reason: "Change " + to}
+				if err := repos.Buses.AddStatusChange(change); err != nil || change.ID == "" {
+					t.Fatalf("Expected the change stored with an id, got %+v (%v)", change, err)
+				}
+			}
+			history, err := repos.Buses.GetStatusHistoryById(active.ID)
+			if err != nil || len(history) != 2 || history[0].To != models.BusAwaitingInspection || !history[1].ChangedAt.Equal(changedAt.AddDate(0, 0, 1)) {
+				t.Errorf("Expected the history oldest first, got %+v (%v)", history, err)
+			}
+			if err := repos.Buses.AddStatusChange(&models.BusStatusChange{BusID: "missing", To: models.BusReserve, Reason: "Lost"}); err == nil {
+				t.Error("Expected an error for a change of a missing bus")
+			}
+
+			if err := repos.Buses.DeleteById(active.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := repos.Buses.PurgeById(active.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if history, _ := repos.Buses.GetStatusHistoryById(active.ID); len(history) != 0 {
+				t.Errorf("Expected the history purged with the bus, got %+v", history)
+			}
+		})
+	}
+}
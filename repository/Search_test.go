@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"busManager/migrations"
+	"busManager/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func searchBackends() map[string]func(t *testing.T) (Repositories, ISearchRepository) {
+	return map[string]func(t *testing.T) (Repositories, ISearchRepository){
+		"Sqlite": func(t *testing.T) (Repositories, ISearchRepository) {
+			db := openTestDBWithForeignKeys(t)
+			t.Cleanup(func() { db.Close() })
+			return NewSqliteRepositories(db), NewSqliteSearchRepository(db)
+		},
+		"Memory": func(t *testing.T) (Repositories, ISearchRepository) {
+			store := NewMemoryStore()
+			return store.Repositories(), NewMemorySearchRepository(store)
+		},
+	}
+}
+
+func hitIds(hits []models.SearchHit) map[string]string {
+	ids := map[string]string{}
+	for _, hit := range hits {
+		ids[hit.ID] = hit.Type
+	}
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	for name, open := range searchBackends() {
+		t.Run(name, func(t *testing.T) {
+			repos, search := open(t)
+
+			bus := newTestBus("А123ВС77")
+			if err := repos.Buses.Add(bus); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			driver := &models.Driver{Name: "Пётр", Surname: "Иванов", Patronymic: "Сергеевич", BirthDate: time.Now(),
+				PassportSeries: "4500123456", Snils: "112-233-445 95", LicenseSeries: "7700123456"}
+			if err := repos.Drivers.Add(driver); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			stop := &models.BusStop{Lat: 55.75, Long: 37.61, Name: "Ивановская площадь"}
+			if err := repos.BusStops.Add(stop); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			route := &models.Route{Number: "77К"}
+			if err := repos.Routes.Add(route); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			hits, err := search.Search("иван", 10)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			ids := hitIds(hits)
+			if len(hits) != 2 || ids[driver.ID] != models.EntityDriver || ids[stop.ID] != models.EntityBusStop {
+				t.Errorf("Expected driver and stop, got %+v", hits)
+			}
+			if hits, _ := search.Search("Петр иван", 10); len(hits) != 1 || hits[0].ID != driver.ID {
+				t.Errorf("Expected driver for every word, got %+v", hits)
+			}
+			if hits, _ := search.Search("volvo", 10); len(hits) != 1 || hits[0].ID != bus.ID || hits[0].Subtitle != "Volvo B7R" {
+				t.Errorf("Expected bus by brand, got %+v", hits)
+			}
+			if hits, _ := search.Search("77к", 10); len(hits) != 1 || hits[0].Type != models.EntityRoute {
+				t.Errorf("Expected route, got %+v", hits)
+			}
+			if hits, _ := search.Search(`" * OR`, 10); len(hits) != 0 {
+				t.Errorf("Expected no hits for punctuation, got %+v", hits)
+			}
+
+			// the index follows updates and the trash
+			found, _ := repos.Buses.GetById(bus.ID)
+			found.Brand = "Mercedes"
+			if err := repos.Buses.UpdateById(found); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if hits, _ := search.Search("volvo", 10); len(hits) != 0 {
+				t.Errorf("Expected old brand to be gone, got %+v", hits)
+			}
+			if hits, _ := search.Search("merc", 10); len(hits) != 1 {
+				t.Errorf("Expected new brand, got %+v", hits)
+			}
+			if err := repos.Drivers.DeleteById(driver.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if hits, _ := search.Search("иван", 10); len(hits) != 1 || hits[0].ID != stop.ID {
+				t.Errorf("Expected deleted driver to be hidden, got %+v", hits)
+			}
+			if err := repos.Drivers.RestoreById(driver.ID); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if hits, _ := search.Search("иван", 1); len(hits) != 1 {
+				t.Errorf("Expected limit to apply, got %+v", hits)
+			}
+			if hits, _ := search.Search("иван", 10); len(hits) != 2 {
+				t.Errorf("Expected restored driver, got %+v", hits)
+			}
+		})
+	}
+}
+
+func TestSearch_Plates(t *testing.T) {
+	for name, open := range searchBackends() {
+		t.Run(name, func(t *testing.T) {
+			repos, search := open(t)
+			private, public := newTestBus("А123ВС77"), newTestBus("АО45699")
+			for _, bus := range []*models.Bus{private, public} {
+				if err := repos.Buses.Add(bus); err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+			}
+			for query, want := range map[string]*models.Bus{
+				"А123":        private,
+				"А 123":       private,
+				"а123вс 77":   private,
+				"А 123 ВС 77": private,
+				"a123":        private,
+				"A123BC77":    private,
+				"123":         private,
+				"123 77":      private,
+				"ВС":          private,
+				"ao 456":      public,
+				"456 99":      public,
+				"99":          public,
+			} {
+				if hits, err := search.Search(query, 10); err != nil || len(hits) != 1 || hits[0].ID != want.ID {
+					t.Errorf("Expected %s for %q, got %+v (%v)", want.RegisterNumber, query, hits, err)
+				}
+			}
+			if hits, _ := search.Search("124", 10); len(hits) != 0 {
+				t.Errorf("Expected no hits for other digits, got %+v", hits)
+			}
+			if hits, _ := search.Search("volvo 123", 10); len(hits) != 1 || hits[0].ID != private.ID {
+				t.Errorf("Expected the bus by brand and digits, got %+v", hits)
+			}
+		})
+	}
+}
+
+func TestSqliteSearchRepository_ReplacesLazyIndex(t *testing.T) {
+	db := openTestDBWithForeignKeys(t)
+	defer db.Close()
+	m, err := migrations.NewMigrator(db)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	latest, _ := m.Version()
+	// back to the schema before migration 0018 created the index
+	if err := m.Down(latest - 17); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	repos, search := NewSqliteRepositories(db), NewSqliteSearchRepository(db)
+	if err := repos.Buses.Add(newTestBus("А123ВС77")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// an FTS4 index as created on the first search by earlier versions,
+	// before register numbers were indexed spaced
+	_, err = db.Exec(`CREATE VIRTUAL TABLE search_index USING fts4(title, body, tokenize=unicode61 "remove_diacritics=2");
+		CREATE TRIGGER search_index_insert AFTER INSERT ON search_documents
+		BEGIN
+			INSERT INTO search_index (rowid, title, body) VALUES (NEW.id, NEW.title, NEW.body);
+		END;
+		INSERT INTO search_index (rowid, title, body) SELECT id, title, body FROM search_documents;`)
+	if err != nil {
+		t.Fatalf("Failed to create the earlier index: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var schema string
+	if err := db.QueryRow(`SELECT sql FROM sqlite_master WHERE name = 'search_index'`).Scan(&schema); err != nil || !strings.Contains(schema, "fts5") {
+		t.Errorf("Expected an FTS5 index, got %q (%v)", schema, err)
+	}
+	if hits, err := search.Search("123", 10); err != nil || len(hits) != 1 {
+		t.Errorf("Expected the index rebuilt with the spaced plate, got %+v (%v)", hits, err)
+	}
+	if err := repos.Buses.Add(newTestBus("В456ОР77")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if hits, err := search.Search("456", 10); err != nil || len(hits) != 1 {
+		t.Errorf("Expected the new bus indexed, got %+v (%v)", hits, err)
+	}
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"busManager/models"
+	"github.com/google/uuid"
+	"strconv"
+	"strings"
+)
+
+type SqliteAuditRepository struct {
+	db Executor
+}
+
+func NewSqliteAuditRepository(db Executor) *SqliteAuditRepository {
+	return &SqliteAuditRepository{db: db}
+}
+
+func (r *SqliteAuditRepository) Add(entry *models.AuditEntry) error {
+	if strings.TrimSpace(entry.ID) == "" {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return err
+		}
+		entry.ID = id.String()
+	}
+	entry.Time = entry.Time.UTC()
+	_, err := r.db.Exec(`INSERT INTO audit_log (id, created_at, user, action, entity_type, entity_id, route_id, before, after)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		entry.ID,
+		entry.Time,
+		entry.User,
+		entry.Action,
+		entry.EntityType,
+		entry.EntityId,
+		entry.RouteId,
+		entry.Before,
+		entry.After,
+	)
+	return err
+}
+
+func (r *SqliteAuditRepository) Query(filter models.AuditFilter) ([]models.AuditEntry, error) {
+	var where []string
+	var args []any
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, cond+" $"+strconv.Itoa(len(args)))
+	}
+	if filter.EntityType != "" {
+		add("entity_type =", filter.EntityType)
+	}
+	if filter.EntityId != "" {
+		add("entity_id =", filter.EntityId)
+	}
+	if filter.RouteId != "" {
+		add("route_id =", filter.RouteId)
+	}
+	if filter.User != "" {
+		add("user =", filter.User)
+	}
+	if filter.Action != "" {
+		add("action =", filter.Action)
+	}
+	if !filter.From.IsZero() {
+		add("created_at >=", filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		add("created_at <", filter.To.UTC())
+	}
+	query := `SELECT id, created_at, user, action, entity_type, entity_id, route_id, before, after FROM audit_log`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY created_at DESC, rowid DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT " + strconv.Itoa(filter.Limit)
+	}
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		entry := models.AuditEntry{}
+		err := rows.Scan(
+			&entry.ID,
+			&entry.Time,
+			&entry.User,
+			&entry.Action,
+			&entry.EntityType,
+			&entry.EntityId,
+			&entry.RouteId,
+			&entry.Before,
+			&entry.After,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
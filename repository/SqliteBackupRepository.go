@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"busManager/migrations"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/mattn/go-sqlite3"
+)
+
+// SqliteBackupRepository copies databases with the SQLite online backup API,
+// so backups and restores run while the application keeps its connection.
+type SqliteBackupRepository struct {
+	db *sql.DB
+}
+
+func NewSqliteBackupRepository(db *sql.DB) *SqliteBackupRepository {
+	return &SqliteBackupRepository{db: db}
+}
+
+func (r *SqliteBackupRepository) Backup(path string) error {
+	file, err := sql.Open("sqlite3", "file:"+path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return copyDatabase(file, r.db)
+}
+
+func (r *SqliteBackupRepository) Verify(path string) error {
+	file, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	var result string
+	if err := file.QueryRow(`PRAGMA integrity_check`).Scan(&result); err != nil {
+		return err
+	}
+	if result != "ok" {
+		return errors.New("Backup integrity check failed: " + result)
+	}
+	m, err := migrations.NewMigrator(file)
+	if err != nil {
+		return err
+	}
+	return m.Check()
+}
+
+func (r *SqliteBackupRepository) Restore(path string) error {
+	file, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := copyDatabase(r.db, file); err != nil {
+		return err
+	}
+	// an older backup is brought up to the current schema
+	m, err := migrations.NewMigrator(r.db)
+	if err != nil {
+		return err
+	}
+	return m.Up()
+}
+
+// copyDatabase copies the main database of src into dest page by page.
+func copyDatabase(dest, src *sql.DB) error {
+	ctx := context.Background()
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	return destConn.Raw(func(destDriver any) error {
+		return srcConn.Raw(func(srcDriver any) error {
+			destSqlite, ok := destDriver.(*sqlite3.SQLiteConn)
+			if !ok {
+				return errors.New("Backup requires a sqlite3 connection")
+			}
+			srcSqlite, ok := srcDriver.(*sqlite3.SQLiteConn)
+			if !ok {
+				return errors.New("Backup requires a sqlite3 connection")
+			}
+			backup, err := destSqlite.Backup("main", srcSqlite, "main")
+			if err != nil {
+				return err
+			}
+			if _, err := backup.Step(-1); err != nil {
+				backup.Finish()
+				return err
+			}
+			return backup.Finish()
+		})
+	})
+}
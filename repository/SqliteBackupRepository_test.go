@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSqliteBackupRepository(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	repo := NewSqliteBackupRepository(db)
+	buses := NewSqliteBusRepository(db)
+	if err := buses.Add(newTestBus("ABC123")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "backup.db")
+
+	t.Run("Backup and verify", func(t *testing.T) {
+		if err := repo.Backup(path); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := repo.Verify(path); err != nil {
+			t.Errorf("Expected valid backup, got %v", err)
+		}
+	})
+
+	t.Run("Restore replaces live data", func(t *testing.T) {
+		if err := buses.Add(newTestBus("XYZ789")); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := repo.Restore(path); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		all, _ := buses.GetAll()
+		if len(all) != 1 || all[0].RegisterNumber != "ABC123" {
+			t.Errorf("Expected only the backed up bus, got %v", all)
+		}
+	})
+
+	t.Run("Verify rejects a corrupt file", func(t *testing.T) {
+		corrupt := filepath.Join(t.TempDir(), "corrupt.db")
+		if err := os.WriteFile(corrupt, []byte("not a database"), 0o644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if err := repo.Verify(corrupt); err == nil {
+			t.Errorf("Expected error for corrupt file")
+		}
+	})
+}
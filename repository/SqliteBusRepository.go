@@ -1,44 +1,52 @@
 package repository
 
 import (
+	"busManager/apperrors"
 	"busManager/models"
+	"busManager/plates"
 	"database/sql"
-	"errors"
 	"github.com/google/uuid"
 	_ "github.com/mattn/go-sqlite3"
 	"strings"
+	"time"
 )
 
 type SqliteBusRepository struct {
-	db *sql.DB
+	db Executor
 }
 
-func NewSqliteBusRepository(dbPath string) (*SqliteBusRepository, error) {
-	db, err := sql.Open("sqlite3", dbPath+"?parseTime=true")
-	//defer db.Close()
-	if err != nil {
-		return nil, err
-	}
-	repo := &SqliteBusRepository{db: db}
-	return repo, nil
+func NewSqliteBusRepository(db Executor) *SqliteBusRepository {
+	return &SqliteBusRepository{db: db}
 }
 
 func (r *SqliteBusRepository) GetById(id string) (*models.Bus, error) {
 	bus := &models.Bus{}
 	err := r.db.QueryRow(`
-		SELECT id, brand, bus_model, register_number, assembly_date, last_repair_date 
+		SELECT id, brand, bus_model, register_number, assembly_date, last_repair_date, status,
+			seated_capacity, standing_capacity, class, length, fuel_type, low_floor, eco_class, vin, version, updated_at 
 		FROM buses 
-		WHERE id = $1`, id).Scan(
+		WHERE id = $1 AND deleted_at IS NULL`, id).Scan(
 		&bus.ID,
 		&bus.Brand,
 		&bus.BusModel,
 		&bus.RegisterNumber,
 		&bus.AssemblyDate,
 		&bus.LastRepairDate,
+		&bus.Status,
+		&bus.SeatedCapacity,
+		&bus.StandingCapacity,
+		&bus.Class,
+		&bus.Length,
+		&bus.FuelType,
+		&bus.LowFloor,
+		&bus.EcoClass,
+		&bus.VIN,
+		&bus.Version,
+		&bus.UpdatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("Bus not found")
+			return nil, apperrors.NotFound(models.EntityBus, "Bus not found")
 		}
 		return nil, err
 	}
@@ -49,19 +57,31 @@ func (r *SqliteBusRepository) GetById(id string) (*models.Bus, error) {
 func (r *SqliteBusRepository) GetByNumber(number string) (*models.Bus, error) {
 	bus := &models.Bus{}
 	err := r.db.QueryRow(`
-		SELECT id, brand, bus_model, register_number, assembly_date, last_repair_date 
+		SELECT id, brand, bus_model, register_number, assembly_date, last_repair_date, status,
+			seated_capacity, standing_capacity, class, length, fuel_type, low_floor, eco_class, vin, version, updated_at 
 		FROM buses 
-		WHERE register_number = $1`, number).Scan(
+		WHERE register_number = $1 AND deleted_at IS NULL`, number).Scan(
 		&bus.ID,
 		&bus.Brand,
 		&bus.BusModel,
 		&bus.RegisterNumber,
 		&bus.AssemblyDate,
 		&bus.LastRepairDate,
+		&bus.Status,
+		&bus.SeatedCapacity,
+		&bus.StandingCapacity,
+		&bus.Class,
+		&bus.Length,
+		&bus.FuelType,
+		&bus.LowFloor,
+		&bus.EcoClass,
+		&bus.VIN,
+		&bus.Version,
+		&bus.UpdatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("Bus not found")
+			return nil, apperrors.NotFound(models.EntityBus, "Bus not found")
 		}
 		return nil, err
 	}
@@ -72,7 +92,18 @@ func (r *SqliteBusRepository) GetByNumber(number string) (*models.Bus, error) {
 func (r *SqliteBusRepository) Add(bus *models.Bus) error {
 	exist, err := r.GetByNumber(bus.RegisterNumber)
 	if exist != nil {
-		return errors.New("Bus already exists")
+		return apperrors.AlreadyExists(models.EntityBus, "Bus already exists")
+	}
+	var trashed int
+	err = r.db.QueryRow(`SELECT COUNT(*) FROM buses WHERE register_number = $1`, bus.RegisterNumber).Scan(&trashed)
+	if err != nil {
+		return err
+	}
+	if trashed > 0 {
+		return apperrors.AlreadyExists(models.EntityBus, "Bus already exists in trash")
+	}
+	if err := r.checkVIN(bus); err != nil {
+		return err
 	}
 	if strings.TrimSpace(bus.ID) == "" {
 		id, err := uuid.NewRandom()
@@ -81,15 +112,32 @@ func (r *SqliteBusRepository) Add(bus *models.Bus) error {
 		}
 		bus.ID = id.String()
 	}
-	_, err = r.db.Exec(`INSERT into buses (id, brand, bus_model, register_number, assembly_date, last_repair_date ) 
-VALUES ($1, $2, $3, $4, $5, $6)`, &bus.ID,
+	if bus.Status == "" {
+		bus.Status = models.BusActive
+	}
+	bus.Version = 1
+	bus.UpdatedAt = time.Now().UTC()
+	_, err = r.db.Exec(`INSERT into buses (id, brand, bus_model, register_number, assembly_date, last_repair_date, status,
+			seated_capacity, standing_capacity, class, length, fuel_type, low_floor, eco_class, vin, version, updated_at ) 
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`, &bus.ID,
 		&bus.Brand,
 		&bus.BusModel,
 		&bus.RegisterNumber,
 		&bus.AssemblyDate,
-		&bus.LastRepairDate)
+		&bus.LastRepairDate,
+		&bus.Status,
+		&bus.SeatedCapacity,
+		&bus.StandingCapacity,
+		&bus.Class,
+		&bus.Length,
+		&bus.FuelType,
+		&bus.LowFloor,
+		&bus.EcoClass,
+		&bus.VIN,
+		&bus.Version,
+		&bus.UpdatedAt)
 	if err != nil {
-		return err
+		return constraintError(err, models.EntityBus, "Bus")
 	}
 	return nil
 
@@ -98,8 +146,10 @@ VALUES ($1, $2, $3, $4, $5, $6)`, &bus.ID,
 func (r *SqliteBusRepository) GetAll() ([]models.Bus, error) {
 	var buses []models.Bus
 	rows, err := r.db.Query(`
-		SELECT id, brand, bus_model, register_number, assembly_date, last_repair_date 
+		SELECT id, brand, bus_model, register_number, assembly_date, last_repair_date, status,
+			seated_capacity, standing_capacity, class, length, fuel_type, low_floor, eco_class, vin, version, updated_at 
 		FROM buses 
+		WHERE deleted_at IS NULL
 		`)
 	if err != nil {
 		//if err == sql.ErrNoRows {
@@ -107,6 +157,7 @@ func (r *SqliteBusRepository) GetAll() ([]models.Bus, error) {
 		//}
 		return nil, err
 	}
+	defer rows.Close()
 	for rows.Next() {
 		bus := &models.Bus{}
 		err := rows.Scan(
@@ -116,6 +167,17 @@ func (r *SqliteBusRepository) GetAll() ([]models.Bus, error) {
 			&bus.RegisterNumber,
 			&bus.AssemblyDate,
 			&bus.LastRepairDate,
+			&bus.Status,
+			&bus.SeatedCapacity,
+			&bus.StandingCapacity,
+			&bus.Class,
+			&bus.Length,
+			&bus.FuelType,
+			&bus.LowFloor,
+			&bus.EcoClass,
+			&bus.VIN,
+			&bus.Version,
+			&bus.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -126,33 +188,258 @@ func (r *SqliteBusRepository) GetAll() ([]models.Bus, error) {
 	return buses, nil
 }
 
+// GetAllWithInvalidNumber returns the buses recorded by migration 17 whose
+// number has not been corrected since and is still not a valid plate.
+func (r *SqliteBusRepository) GetAllWithInvalidNumber() ([]models.Bus, error) {
+	buses := []models.Bus{}
+	rows, err := r.db.Query(`
+		SELECT b.id, b.brand, b.bus_model, b.register_number, b.assembly_date, b.last_repair_date, b.status,
+			b.seated_capacity, b.standing_capacity, b.class, b.length, b.fuel_type, b.low_floor, b.eco_class, b.vin, b.version, b.updated_at
+		FROM buses b
+		JOIN invalid_register_numbers i ON i.bus_id = b.id AND i.register_number = b.register_number
+		WHERE b.deleted_at IS NULL
+		ORDER BY b.register_number`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		bus := models.Bus{}
+		err := rows.Scan(
+			&bus.ID,
+			&bus.Brand,
+			&bus.BusModel,
+			&bus.RegisterNumber,
+			&bus.AssemblyDate,
+			&bus.LastRepairDate,
+			&bus.Status,
+			&bus.SeatedCapacity,
+			&bus.StandingCapacity,
+			&bus.Class,
+			&bus.Length,
+			&bus.FuelType,
+			&bus.LowFloor,
+			&bus.EcoClass,
+			&bus.VIN,
+			&bus.Version,
+			&bus.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		// The plate rules may have been relaxed since the migration ran.
+		if _, err := plates.Parse(bus.RegisterNumber); err == nil {
+			continue
+		}
+		buses = append(buses, bus)
+	}
+	return buses, rows.Err()
+}
+
+func (r *SqliteBusRepository) List(query models.ListQuery) (models.Page[models.Bus], error) {
+	return sqliteList(r.db, "buses", `id, brand, bus_model, register_number, assembly_date, last_repair_date, status,
+		seated_capacity, standing_capacity, class, length, fuel_type, low_floor, eco_class, vin, version, updated_at`, busFields, "RegisterNumber", query,
+		func(rows *sql.Rows) (models.Bus, error) {
+			var bus models.Bus
+			err := rows.Scan(
+				&bus.ID,
+				&bus.Brand,
+				&bus.BusModel,
+				&bus.RegisterNumber,
+				&bus.AssemblyDate,
+				&bus.LastRepairDate,
+				&bus.Status,
+				&bus.SeatedCapacity,
+				&bus.StandingCapacity,
+				&bus.Class,
+				&bus.Length,
+				&bus.FuelType,
+				&bus.LowFloor,
+				&bus.EcoClass,
+				&bus.VIN,
+				&bus.Version,
+				&bus.UpdatedAt,
+			)
+			return bus, err
+		})
+}
+
 func (r *SqliteBusRepository) DeleteById(id string) error {
 	exist, err := r.GetById(id)
 	if exist == nil {
-		return errors.New("Bus not found")
+		return apperrors.NotFound(models.EntityBus, "Bus not found")
 	}
 	if err != nil {
 		return err
 	}
-	_, err = r.db.Exec("DELETE FROM buses WHERE id = $1", id)
+	return trashEntity(r.db, models.EntityBus, id)
+}
+
+func (r *SqliteBusRepository) GetAllDeleted() ([]models.Trashed[models.Bus], error) {
+	var buses []models.Trashed[models.Bus]
+	rows, err := r.db.Query(`
+		SELECT id, brand, bus_model, register_number, assembly_date, last_repair_date, status,
+			seated_capacity, standing_capacity, class, length, fuel_type, low_floor, eco_class, vin, version, updated_at, deleted_at
+		FROM buses
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+		`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		bus := models.Trashed[models.Bus]{}
+		err := rows.Scan(
+			&bus.Item.ID,
+			&bus.Item.Brand,
+			&bus.Item.BusModel,
+			&bus.Item.RegisterNumber,
+			&bus.Item.AssemblyDate,
+			&bus.Item.LastRepairDate,
+			&bus.Item.Status,
+			&bus.Item.SeatedCapacity,
+			&bus.Item.StandingCapacity,
+			&bus.Item.Class,
+			&bus.Item.Length,
+			&bus.Item.FuelType,
+			&bus.Item.LowFloor,
+			&bus.Item.EcoClass,
+			&bus.Item.VIN,
+			&bus.Item.Version,
+			&bus.Item.UpdatedAt,
+			&bus.DeletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		buses = append(buses, bus)
+	}
+	return buses, nil
+}
+
+func (r *SqliteBusRepository) RestoreById(id string) error {
+	trashed, err := isTrashed(r.db, "buses", id)
+	if err != nil {
+		return err
+	}
+	if !trashed {
+		return apperrors.NotFound(models.EntityBus, "Bus not found")
+	}
+	return restoreTrashed(r.db, "buses", models.EntityBus, id)
+}
+
+func (r *SqliteBusRepository) PurgeById(id string) error {
+	trashed, err := isTrashed(r.db, "buses", id)
 	if err != nil {
 		return err
 	}
+	if !trashed {
+		return apperrors.NotFound(models.EntityBus, "Bus not found")
+	}
+	return purgeTrashed(r.db, "buses", models.EntityBus, id)
+}
+
+// checkVIN fails when another bus, in the trash or not, has the VIN of bus.
+func (r *SqliteBusRepository) checkVIN(bus *models.Bus) error {
+	if bus.VIN == "" {
+		return nil
+	}
+	var taken int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM buses WHERE vin = $1 AND id != $2`, bus.VIN, bus.ID).Scan(&taken); err != nil {
+		return err
+	}
+	if taken > 0 {
+		return apperrors.AlreadyExists(models.EntityBus, "Bus with this VIN already exists")
+	}
 	return nil
 }
 
 func (r *SqliteBusRepository) UpdateById(bus *models.Bus) error {
 	exist, err := r.GetById(bus.ID)
 	if exist == nil {
-		return errors.New("Bus not found")
+		return apperrors.NotFound(models.EntityBus, "Bus not found")
 	}
 	if err != nil {
 		return err
 	}
-	_, err = r.db.Exec("UPDATE buses SET brand = $1, bus_model = $2, register_number = $3, assembly_date = $4, last_repair_date = $5 WHERE id = $6", bus.Brand, bus.BusModel, bus.RegisterNumber, bus.AssemblyDate, bus.LastRepairDate, bus.ID)
-	
+	if exist.Version != bus.Version {
+		return apperrors.Conflict(models.EntityBus, "Bus was changed by someone else", exist)
+	}
+	if err := r.checkVIN(bus); err != nil {
+		return err
+	}
+	updatedAt := time.Now().UTC()
+	res, err := r.db.Exec(`UPDATE buses SET brand = $1, bus_model = $2, register_number = $3, assembly_date = $4, last_repair_date = $5, status = $6,
+		seated_capacity = $7, standing_capacity = $8, class = $9, length = $10, fuel_type = $11, low_floor = $12, eco_class = $13, vin = $14,
+		version = version + 1, updated_at = $15 WHERE id = $16 AND version = $17 AND deleted_at IS NULL`,
+		bus.Brand, bus.BusModel, bus.RegisterNumber, bus.AssemblyDate, bus.LastRepairDate, bus.Status,
+		bus.SeatedCapacity, bus.StandingCapacity, bus.Class, bus.Length, bus.FuelType, bus.LowFloor, bus.EcoClass, bus.VIN,
+		updatedAt, bus.ID, bus.Version)
 	if err != nil {
+		return constraintError(err, models.EntityBus, "Bus")
+	}
+	if n, err := res.RowsAffected(); err != nil {
 		return err
+	} else if n == 0 {
+		current, _ := r.GetById(bus.ID)
+		return apperrors.Conflict(models.EntityBus, "Bus was changed by someone else", current)
 	}
+	bus.Version++
+	bus.UpdatedAt = updatedAt
 	return nil
 }
+
+func (r *SqliteBusRepository) GetAllRoutesById(id string) ([]models.Route, error) {
+	return queryRoutes(r.db, `
+		SELECT r.id, r.number, r.length, r.min_capacity, r.bus_class, r.low_floor, r.version, r.updated_at
+		FROM routes r
+		JOIN routes_buses j ON r.id = j.route_id
+		WHERE j.bus_id = $1 AND r.deleted_at IS NULL
+		ORDER BY r.number
+	`, id)
+}
+
+func (r *SqliteBusRepository) AddStatusChange(change *models.BusStatusChange) error {
+	if strings.TrimSpace(change.ID) == "" {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return err
+		}
+		change.ID = id.String()
+	}
+	change.ChangedAt = change.ChangedAt.UTC()
+	_, err := r.db.Exec(`INSERT INTO bus_status_changes (id, bus_id, from_status, to_status, changed_at, reason) VALUES ($1, $2, $3, $4, $5, $6)`,
+		change.ID,
+		change.BusID,
+		change.From,
+		change.To,
+		change.ChangedAt,
+		change.Reason,
+	)
+	if err != nil {
+		return constraintError(err, models.EntityBus, "Bus")
+	}
+	return nil
+}
+
+func (r *SqliteBusRepository) GetStatusHistoryById(id string) ([]models.BusStatusChange, error) {
+	rows, err := r.db.Query(`
+		SELECT id, bus_id, from_status, to_status, changed_at, reason
+		FROM bus_status_changes
+		WHERE bus_id = $1
+		ORDER BY changed_at, rowid`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	changes := []models.BusStatusChange{}
+	for rows.Next() {
+		change := models.BusStatusChange{}
+		if err := rows.Scan(&change.ID, &change.BusID, &change.From, &change.To, &change.ChangedAt, &change.Reason); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
@@ -2,7 +2,6 @@ package repository
 
 import (
 	"busManager/models"
-	"database/sql"
 	"github.com/google/uuid"
 	_ "github.com/mattn/go-sqlite3"
 	"testing"
@@ -10,27 +9,7 @@ import (
 )
 
 func setupTestDB(t *testing.T) (*SqliteBusRepository, func()) {
-	// Use in-memory database for testing
-	db, err := sql.Open("sqlite3", ":memory:")
-	if err != nil {
-		t.Fatalf("Failed to create test database: %v", err)
-	}
-
-	// Create buses table
-	_, err = db.Exec(`
-		CREATE TABLE buses (
-			id TEXT PRIMARY KEY,
-			brand TEXT,
-			bus_model TEXT,
-			register_number TEXT UNIQUE,
-			assembly_date TIMESTAMP,
-			last_repair_date TIMESTAMP
-		)
-	`)
-	if err != nil {
-		t.Fatalf("Failed to create buses table: %v", err)
-	}
-
+	db := openTestDB(t)
 	repo := &SqliteBusRepository{db: db}
 	return repo, func() { db.Close() }
 }
@@ -316,6 +295,7 @@ func TestSqliteBusRepository_UpdateById(t *testing.T) {
 			RegisterNumber: "XYZ789",
 			AssemblyDate:   fixedTime,
 			LastRepairDate: fixedTime,
+			Version:        1,
 		}
 
 		err := repo.UpdateById(updatedBus)
@@ -357,3 +337,33 @@ func TestSqliteBusRepository_UpdateById(t *testing.T) {
 		}
 	})
 }
+
+func TestSqliteBusRepository_GetAllWithInvalidNumber(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	fixedTime, _ := time.Parse(time.RFC3339, "2022-11-11T11:11:11Z")
+	legacy := newTestBus("Б456ББ77")
+	corrected := newTestBus("Г012ГГ77")
+	// recorded when regions 01 to 09 were still refused
+	relaxed := newTestBus("А123ВС02")
+	for _, bus := range []*models.Bus{legacy, corrected, relaxed} {
+		bus.AssemblyDate, bus.LastRepairDate = fixedTime, fixedTime
+		if err := repo.Add(bus); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		_, err := repo.db.Exec(`INSERT INTO invalid_register_numbers (bus_id, register_number) VALUES (?, ?)`, bus.ID, bus.RegisterNumber)
+		if err != nil {
+			t.Fatalf("Failed to insert test data: %v", err)
+		}
+	}
+	corrected.RegisterNumber = "АА12377"
+	if err := repo.UpdateById(corrected); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	buses, err := repo.GetAllWithInvalidNumber()
+	if err != nil || len(buses) != 1 || buses[0].ID != legacy.ID {
+		t.Errorf("Expected only the uncorrected bus, got %+v (%v)", buses, err)
+	}
+}
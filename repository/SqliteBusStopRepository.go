@@ -1,40 +1,38 @@
 package repository
 
 import (
+	"busManager/apperrors"
 	"busManager/models"
 	"database/sql"
-	"errors"
 	"github.com/google/uuid"
 	"strings"
+	"time"
 )
 
 type SqliteBusStopRepository struct {
-	db *sql.DB
+	db Executor
 }
 
-func NewSqliteBusStopRepository(dbPath string) (*SqliteBusStopRepository, error) {
-	db, err := sql.Open("sqlite3", dbPath)
-	if err != nil {
-		return nil, err
-	}
-	repo := &SqliteBusStopRepository{db: db}
-	return repo, nil
+func NewSqliteBusStopRepository(db Executor) *SqliteBusStopRepository {
+	return &SqliteBusStopRepository{db: db}
 }
 
 func (r *SqliteBusStopRepository) GetById(id string) (*models.BusStop, error) {
 	stop := &models.BusStop{}
 	err := r.db.QueryRow(`
-		SELECT id, lat, long, name 
+		SELECT id, lat, long, name, version, updated_at 
 		FROM bus_stops 
-		WHERE id = $1`, id).Scan(
+		WHERE id = $1 AND deleted_at IS NULL`, id).Scan(
 		&stop.ID,
 		&stop.Lat,
 		&stop.Long,
 		&stop.Name,
+		&stop.Version,
+		&stop.UpdatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("Bus stop not found")
+			return nil, apperrors.NotFound(models.EntityBusStop, "Bus stop not found")
 		}
 		return nil, err
 	}
@@ -45,17 +43,19 @@ func (r *SqliteBusStopRepository) GetById(id string) (*models.BusStop, error) {
 func (r *SqliteBusStopRepository) GetByName(name string) (*models.BusStop, error) {
 	stop := &models.BusStop{}
 	err := r.db.QueryRow(`
-		SELECT id, lat, long, name 
+		SELECT id, lat, long, name, version, updated_at 
 		FROM bus_stops 
-		WHERE name = $4`, name).Scan(
+		WHERE name = $4 AND deleted_at IS NULL`, name).Scan(
 		&stop.ID,
 		&stop.Lat,
 		&stop.Long,
 		&stop.Name,
+		&stop.Version,
+		&stop.UpdatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("Bus stop not found")
+			return nil, apperrors.NotFound(models.EntityBusStop, "Bus stop not found")
 		}
 		return nil, err
 	}
@@ -66,7 +66,16 @@ func (r *SqliteBusStopRepository) GetByName(name string) (*models.BusStop, error
 func (r *SqliteBusStopRepository) Add(busStop *models.BusStop) error {
 	exist, err := r.GetByName(busStop.Name)
 	if exist != nil {
-		return errors.New("Bus stop already exists")
+		return apperrors.AlreadyExists(models.EntityBusStop, "Bus stop already exists")
+	}
+	var trashed int
+	err = r.db.QueryRow(`SELECT COUNT(*) FROM bus_stops WHERE deleted_at IS NOT NULL AND (name = $1 OR lat = $2 OR long = $3)`,
+		busStop.Name, busStop.Lat, busStop.Long).Scan(&trashed)
+	if err != nil {
+		return err
+	}
+	if trashed > 0 {
+		return apperrors.AlreadyExists(models.EntityBusStop, "Bus stop already exists in trash")
 	}
 	if strings.TrimSpace(busStop.ID) == "" {
 		id, err := uuid.NewRandom()
@@ -75,15 +84,19 @@ func (r *SqliteBusStopRepository) Add(busStop *models.BusStop) error {
 		}
 		busStop.ID = id.String()
 	}
+	busStop.Version = 1
+	busStop.UpdatedAt = time.Now().UTC()
 	_, err = r.db.Exec(`INSERT into bus_stops
-    (id, lat, long, name ) 
-VALUES ($1, $2, $3, $4)`,
+    (id, lat, long, name, version, updated_at ) 
+VALUES ($1, $2, $3, $4, $5, $6)`,
 		&busStop.ID,
 		&busStop.Lat,
 		&busStop.Long,
-		&busStop.Name)
+		&busStop.Name,
+		&busStop.Version,
+		&busStop.UpdatedAt)
 	if err != nil {
-		return err
+		return constraintError(err, models.EntityBusStop, "Bus stop")
 	}
 	return nil
 }
@@ -91,8 +104,9 @@ VALUES ($1, $2, $3, $4)`,
 func (r *SqliteBusStopRepository) GetAll() ([]models.BusStop, error) {
 	var busStops []models.BusStop
 	rows, err := r.db.Query(`
-		SELECT id, lat, long, name
+		SELECT id, lat, long, name, version, updated_at
 		FROM bus_stops 
+		WHERE deleted_at IS NULL
 		`)
 	if err != nil {
 		//if err == sql.ErrNoRows {
@@ -100,6 +114,7 @@ func (r *SqliteBusStopRepository) GetAll() ([]models.BusStop, error) {
 		//}
 		return nil, err
 	}
+	defer rows.Close()
 	for rows.Next() {
 		busStop := &models.BusStop{}
 		err := rows.Scan(
@@ -107,6 +122,8 @@ func (r *SqliteBusStopRepository) GetAll() ([]models.BusStop, error) {
 			&busStop.Lat,
 			&busStop.Long,
 			&busStop.Name,
+			&busStop.Version,
+			&busStop.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -117,37 +134,127 @@ func (r *SqliteBusStopRepository) GetAll() ([]models.BusStop, error) {
 	return busStops, nil
 }
 
+func (r *SqliteBusStopRepository) List(query models.ListQuery) (models.Page[models.BusStop], error) {
+	return sqliteList(r.db, "bus_stops", "id, lat, long, name, version, updated_at", busStopFields, "Name", query,
+		func(rows *sql.Rows) (models.BusStop, error) {
+			var busStop models.BusStop
+			err := rows.Scan(
+				&busStop.ID,
+				&busStop.Lat,
+				&busStop.Long,
+				&busStop.Name,
+				&busStop.Version,
+				&busStop.UpdatedAt,
+			)
+			return busStop, err
+		})
+}
+
 func (r *SqliteBusStopRepository) DeleteById(id string) error {
 	exist, err := r.GetById(id)
 	if exist == nil {
-		return errors.New("Bus stop not found")
+		return apperrors.NotFound(models.EntityBusStop, "Bus stop not found")
 	}
 	if err != nil {
 		return err
 	}
-	_, err = r.db.Exec("DELETE FROM bus_stops WHERE id = $1", id)
+	return trashEntity(r.db, models.EntityBusStop, id)
+}
+
+func (r *SqliteBusStopRepository) GetAllDeleted() ([]models.Trashed[models.BusStop], error) {
+	var busStops []models.Trashed[models.BusStop]
+	rows, err := r.db.Query(`
+		SELECT id, lat, long, name, version, updated_at, deleted_at
+		FROM bus_stops
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+		`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		busStop := models.Trashed[models.BusStop]{}
+		err := rows.Scan(
+			&busStop.Item.ID,
+			&busStop.Item.Lat,
+			&busStop.Item.Long,
+			&busStop.Item.Name,
+			&busStop.Item.Version,
+			&busStop.Item.UpdatedAt,
+			&busStop.DeletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		busStops = append(busStops, busStop)
+	}
+	return busStops, nil
+}
+
+func (r *SqliteBusStopRepository) RestoreById(id string) error {
+	trashed, err := isTrashed(r.db, "bus_stops", id)
 	if err != nil {
 		return err
 	}
-	return nil
+	if !trashed {
+		return apperrors.NotFound(models.EntityBusStop, "Bus stop not found")
+	}
+	return restoreTrashed(r.db, "bus_stops", models.EntityBusStop, id)
+}
+
+func (r *SqliteBusStopRepository) PurgeById(id string) error {
+	trashed, err := isTrashed(r.db, "bus_stops", id)
+	if err != nil {
+		return err
+	}
+	if !trashed {
+		return apperrors.NotFound(models.EntityBusStop, "Bus stop not found")
+	}
+	return purgeTrashed(r.db, "bus_stops", models.EntityBusStop, id)
 }
 
 func (r *SqliteBusStopRepository) UpdateById(busStop *models.BusStop) error {
 	exist, err := r.GetById(busStop.ID)
 	if exist == nil {
-		return errors.New("Bus stop not found")
+		return apperrors.NotFound(models.EntityBusStop, "Bus stop not found")
 	}
 	if err != nil {
 		return err
 	}
-	_, err = r.db.Exec(`UPDATE bus_stops SET lat = $1, long = $2, name = $3 WHERE id = $4`,
+	if exist.Version != busStop.Version {
+		return apperrors.Conflict(models.EntityBusStop, "Bus stop was changed by someone else", exist)
+	}
+	updatedAt := time.Now().UTC()
+	res, err := r.db.Exec(`UPDATE bus_stops SET lat = $1, long = $2, name = $3, version = version + 1, updated_at = $4
+WHERE id = $5 AND version = $6 AND deleted_at IS NULL`,
 		busStop.Lat,
 		busStop.Long,
 		busStop.Name,
+		updatedAt,
 		busStop.ID,
+		busStop.Version,
 	)
 	if err != nil {
+		return constraintError(err, models.EntityBusStop, "Bus stop")
+	}
+	if n, err := res.RowsAffected(); err != nil {
 		return err
+	} else if n == 0 {
+		current, _ := r.GetById(busStop.ID)
+		return apperrors.Conflict(models.EntityBusStop, "Bus stop was changed by someone else", current)
 	}
+	busStop.Version++
+	busStop.UpdatedAt = updatedAt
 	return nil
 }
+
+func (r *SqliteBusStopRepository) GetAllRoutesById(id string) ([]models.Route, error) {
+	return queryRoutes(r.db, `
+		SELECT r.id, r.number, r.length, r.min_capacity, r.bus_class, r.low_floor, r.version, r.updated_at
+		FROM routes r
+		JOIN routes_bus_stops j ON r.id = j.route_id
+		WHERE j.bus_stop_id = $1 AND r.deleted_at IS NULL
+		ORDER BY r.number
+	`, id)
+}
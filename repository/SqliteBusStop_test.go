@@ -2,29 +2,12 @@ package repository
 
 import (
 	"busManager/models"
-	"database/sql"
 	"github.com/google/uuid"
 	"testing"
 )
 
 func setupTestDBBusStop(t *testing.T) (*SqliteBusStopRepository, func()) {
-	db, err := sql.Open("sqlite3", ":memory:")
-	if err != nil {
-		t.Fatalf("Failed to create test database: %v", err)
-	}
-
-	_, err = db.Exec(`
-		CREATE TABLE bus_stops (
-			id TEXT PRIMARY KEY,
-			lat REAL NOT NULL,
-			long REAL NOT NULL,
-			name TEXT NOT NULL
-		)
-	`)
-	if err != nil {
-		t.Fatalf("Failed to create bus_stops table: %v", err)
-	}
-
+	db := openTestDB(t)
 	repo := &SqliteBusStopRepository{db: db}
 	return repo, func() { db.Close() }
 }
@@ -260,10 +243,11 @@ func TestSqliteBusStopRepository_UpdateById(t *testing.T) {
 
 	t.Run("Update existing bus stop", func(t *testing.T) {
 		updatedBusStop := &models.BusStop{
-			ID:   busStop.ID,
-			Lat:  55.7522,
-			Long: 37.6156,
-			Name: "Stop B",
+			ID:      busStop.ID,
+			Lat:     55.7522,
+			Long:    37.6156,
+			Name:    "Stop B",
+			Version: 1,
 		}
 
 		err := repo.UpdateById(updatedBusStop)
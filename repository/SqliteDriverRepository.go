@@ -1,33 +1,58 @@
 package repository
 
 import (
+	"busManager/apperrors"
 	"busManager/models"
 	"database/sql"
-	"errors"
 	"github.com/google/uuid"
 	"strings"
+	"time"
 )
 
 type SqliteDriverRepository struct {
-	db *sql.DB
+	db Executor
 }
 
-func NewSqliteDriverRepository(dbPath string) (*SqliteDriverRepository, error) {
-	db, err := sql.Open("sqlite3", dbPath+"?parseTime=true")
-	//defer db.Close()
+func NewSqliteDriverRepository(db Executor) *SqliteDriverRepository {
+	return &SqliteDriverRepository{db: db}
+}
+
+func (r *SqliteDriverRepository) GetById(id string) (*models.Driver, error) {
+	driver := &models.Driver{}
+	err := r.db.QueryRow(`
+		SELECT id, name, surname, patronymic, birth_date, passport_series, snils, license_series, license_categories, license_issue_date, license_expiry_date, version, updated_at 
+		FROM drivers 
+		WHERE id = $1 AND deleted_at IS NULL`, id).Scan(
+		&driver.ID,
+		&driver.Name,
+		&driver.Surname,
+		&driver.Patronymic,
+		&driver.BirthDate,
+		&driver.PassportSeries,
+		&driver.Snils,
+		&driver.LicenseSeries,
+		&driver.LicenseCategories,
+		&driver.LicenseIssueDate,
+		&driver.LicenseExpiryDate,
+		&driver.Version,
+		&driver.UpdatedAt,
+	)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.NotFound(models.EntityDriver, "Driver not found")
+		}
 		return nil, err
 	}
-	repo := &SqliteDriverRepository{db: db}
-	return repo, nil
+
+	return driver, nil
 }
 
-func (r *SqliteDriverRepository) GetById(id string) (*models.Driver, error) {
+func (r *SqliteDriverRepository) GetByPassportSeries(series string) (*models.Driver, error) {
 	driver := &models.Driver{}
 	err := r.db.QueryRow(`
-		SELECT id, name, surname, patronymic, birth_date, passport_series, snils, license_series 
+		SELECT id, name, surname, patronymic, birth_date, passport_series, snils, license_series, license_categories, license_issue_date, license_expiry_date, version, updated_at
 		FROM drivers 
-		WHERE id = $1`, id).Scan(
+		WHERE passport_series = $1 AND deleted_at IS NULL`, series).Scan(
 		&driver.ID,
 		&driver.Name,
 		&driver.Surname,
@@ -36,10 +61,15 @@ func (r *SqliteDriverRepository) GetById(id string) (*models.Driver, error) {
 		&driver.PassportSeries,
 		&driver.Snils,
 		&driver.LicenseSeries,
+		&driver.LicenseCategories,
+		&driver.LicenseIssueDate,
+		&driver.LicenseExpiryDate,
+		&driver.Version,
+		&driver.UpdatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("Driver not found")
+			return nil, apperrors.NotFound(models.EntityDriver, "Driver not found")
 		}
 		return nil, err
 	}
@@ -47,12 +77,12 @@ func (r *SqliteDriverRepository) GetById(id string) (*models.Driver, error) {
 	return driver, nil
 }
 
-func (r *SqliteDriverRepository) GetByPassportSeries(series string) (*models.Driver, error) {
+func (r *SqliteDriverRepository) GetBySnils(snils string) (*models.Driver, error) {
 	driver := &models.Driver{}
 	err := r.db.QueryRow(`
-		SELECT id, name, surname, patronymic, birth_date, passport_series, snils, license_series
+		SELECT id, name, surname, patronymic, birth_date, passport_series, snils, license_series, license_categories, license_issue_date, license_expiry_date, version, updated_at
 		FROM drivers 
-		WHERE passport_series = $1`, series).Scan(
+		WHERE snils = $1 AND deleted_at IS NULL`, snils).Scan(
 		&driver.ID,
 		&driver.Name,
 		&driver.Surname,
@@ -61,10 +91,15 @@ func (r *SqliteDriverRepository) GetByPassportSeries(series string) (*models.Dri
 		&driver.PassportSeries,
 		&driver.Snils,
 		&driver.LicenseSeries,
+		&driver.LicenseCategories,
+		&driver.LicenseIssueDate,
+		&driver.LicenseExpiryDate,
+		&driver.Version,
+		&driver.UpdatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("Driver not found")
+			return nil, apperrors.NotFound(models.EntityDriver, "Driver not found")
 		}
 		return nil, err
 	}
@@ -72,10 +107,37 @@ func (r *SqliteDriverRepository) GetByPassportSeries(series string) (*models.Dri
 	return driver, nil
 }
 
+// findDuplicate returns the error for another live driver sharing a document
+// with driver, or nil.
+func (r *SqliteDriverRepository) findDuplicate(driver *models.Driver) error {
+	var other models.Driver
+	err := r.db.QueryRow(`
+		SELECT passport_series, snils, license_series
+		FROM drivers
+		WHERE id <> $1 AND deleted_at IS NULL AND (passport_series = $2 OR snils = $3 OR license_series = $4)
+		LIMIT 1`, driver.ID, driver.PassportSeries, driver.Snils, driver.LicenseSeries).Scan(
+		&other.PassportSeries, &other.Snils, &other.LicenseSeries)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return duplicateDriver(other, *driver)
+}
+
 func (r *SqliteDriverRepository) Add(driver *models.Driver) error {
-	exist, err := r.GetByPassportSeries(driver.PassportSeries)
-	if exist != nil {
-		return errors.New("Driver already exists")
+	if err := r.findDuplicate(driver); err != nil {
+		return err
+	}
+	var trashed int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM drivers WHERE deleted_at IS NOT NULL AND (passport_series = $1 OR snils = $2 OR license_series = $3)`,
+		driver.PassportSeries, driver.Snils, driver.LicenseSeries).Scan(&trashed)
+	if err != nil {
+		return err
+	}
+	if trashed > 0 {
+		return apperrors.AlreadyExists(models.EntityDriver, "Driver already exists in trash")
 	}
 	if strings.TrimSpace(driver.ID) == "" {
 		id, err := uuid.NewRandom()
@@ -84,17 +146,24 @@ func (r *SqliteDriverRepository) Add(driver *models.Driver) error {
 		}
 		driver.ID = id.String()
 	}
-	_, err = r.db.Exec(`INSERT into drivers (id, name, surname, patronymic, birth_date, passport_series, snils, license_series ) 
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`, &driver.ID,
+	driver.Version = 1
+	driver.UpdatedAt = time.Now().UTC()
+	_, err = r.db.Exec(`INSERT into drivers (id, name, surname, patronymic, birth_date, passport_series, snils, license_series, license_categories, license_issue_date, license_expiry_date, version, updated_at ) 
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`, &driver.ID,
 		&driver.Name,
 		&driver.Surname,
 		&driver.Patronymic,
 		&driver.BirthDate,
 		&driver.PassportSeries,
 		&driver.Snils,
-		&driver.LicenseSeries)
+		&driver.LicenseSeries,
+		&driver.LicenseCategories,
+		&driver.LicenseIssueDate,
+		&driver.LicenseExpiryDate,
+		&driver.Version,
+		&driver.UpdatedAt)
 	if err != nil {
-		return err
+		return constraintError(err, models.EntityDriver, "Driver")
 	}
 	return nil
 
@@ -103,8 +172,9 @@ VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`, &driver.ID,
 func (r *SqliteDriverRepository) GetAll() ([]models.Driver, error) {
 	var drivers []models.Driver
 	rows, err := r.db.Query(`
-		SELECT id, name, surname, patronymic, birth_date, passport_series, snils, license_series
+		SELECT id, name, surname, patronymic, birth_date, passport_series, snils, license_series, license_categories, license_issue_date, license_expiry_date, version, updated_at
 		FROM drivers 
+		WHERE deleted_at IS NULL
 		`)
 	if err != nil {
 		//if err == sql.ErrNoRows {
@@ -112,6 +182,7 @@ func (r *SqliteDriverRepository) GetAll() ([]models.Driver, error) {
 		//}
 		return nil, err
 	}
+	defer rows.Close()
 	for rows.Next() {
 		driver := &models.Driver{}
 		err := rows.Scan(
@@ -123,6 +194,11 @@ func (r *SqliteDriverRepository) GetAll() ([]models.Driver, error) {
 			&driver.PassportSeries,
 			&driver.Snils,
 			&driver.LicenseSeries,
+			&driver.LicenseCategories,
+			&driver.LicenseIssueDate,
+			&driver.LicenseExpiryDate,
+			&driver.Version,
+			&driver.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -133,33 +209,174 @@ func (r *SqliteDriverRepository) GetAll() ([]models.Driver, error) {
 	return drivers, nil
 }
 
+func (r *SqliteDriverRepository) GetAllByLicenseExpiryBefore(date time.Time) ([]models.Driver, error) {
+	var drivers []models.Driver
+	rows, err := r.db.Query(`
+		SELECT id, name, surname, patronymic, birth_date, passport_series, snils, license_series, license_categories, license_issue_date, license_expiry_date, version, updated_at
+		FROM drivers
+		WHERE deleted_at IS NULL AND license_expiry_date > $1 AND license_expiry_date < $2
+		ORDER BY license_expiry_date, id
+		`, time.Time{}, date.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		driver := models.Driver{}
+		err := rows.Scan(
+			&driver.ID,
+			&driver.Name,
+			&driver.Surname,
+			&driver.Patronymic,
+			&driver.BirthDate,
+			&driver.PassportSeries,
+			&driver.Snils,
+			&driver.LicenseSeries,
+			&driver.LicenseCategories,
+			&driver.LicenseIssueDate,
+			&driver.LicenseExpiryDate,
+			&driver.Version,
+			&driver.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		drivers = append(drivers, driver)
+	}
+	return drivers, rows.Err()
+}
+
+func (r *SqliteDriverRepository) List(query models.ListQuery) (models.Page[models.Driver], error) {
+	return sqliteList(r.db, "drivers", "id, name, surname, patronymic, birth_date, passport_series, snils, license_series, license_categories, license_issue_date, license_expiry_date, version, updated_at", driverFields, "Surname", query,
+		func(rows *sql.Rows) (models.Driver, error) {
+			var driver models.Driver
+			err := rows.Scan(
+				&driver.ID,
+				&driver.Name,
+				&driver.Surname,
+				&driver.Patronymic,
+				&driver.BirthDate,
+				&driver.PassportSeries,
+				&driver.Snils,
+				&driver.LicenseSeries,
+				&driver.LicenseCategories,
+				&driver.LicenseIssueDate,
+				&driver.LicenseExpiryDate,
+				&driver.Version,
+				&driver.UpdatedAt,
+			)
+			return driver, err
+		})
+}
+
 func (r *SqliteDriverRepository) DeleteById(id string) error {
 	exist, err := r.GetById(id)
 	if exist == nil {
-		return errors.New("Driver not found")
+		return apperrors.NotFound(models.EntityDriver, "Driver not found")
 	}
 	if err != nil {
 		return err
 	}
-	_, err = r.db.Exec("DELETE FROM drivers WHERE id = $1", id)
+	return trashEntity(r.db, models.EntityDriver, id)
+}
+
+func (r *SqliteDriverRepository) GetAllDeleted() ([]models.Trashed[models.Driver], error) {
+	var drivers []models.Trashed[models.Driver]
+	rows, err := r.db.Query(`
+		SELECT id, name, surname, patronymic, birth_date, passport_series, snils, license_series, license_categories, license_issue_date, license_expiry_date, version, updated_at, deleted_at
+		FROM drivers
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+		`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		driver := models.Trashed[models.Driver]{}
+		err := rows.Scan(
+			&driver.Item.ID,
+			&driver.Item.Name,
+			&driver.Item.Surname,
+			&driver.Item.Patronymic,
+			&driver.Item.BirthDate,
+			&driver.Item.PassportSeries,
+			&driver.Item.Snils,
+			&driver.Item.LicenseSeries,
+			&driver.Item.LicenseCategories,
+			&driver.Item.LicenseIssueDate,
+			&driver.Item.LicenseExpiryDate,
+			&driver.Item.Version,
+			&driver.Item.UpdatedAt,
+			&driver.DeletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		drivers = append(drivers, driver)
+	}
+	return drivers, nil
+}
+
+func (r *SqliteDriverRepository) RestoreById(id string) error {
+	trashed, err := isTrashed(r.db, "drivers", id)
 	if err != nil {
 		return err
 	}
-	return nil
+	if !trashed {
+		return apperrors.NotFound(models.EntityDriver, "Driver not found")
+	}
+	return restoreTrashed(r.db, "drivers", models.EntityDriver, id)
+}
+
+func (r *SqliteDriverRepository) PurgeById(id string) error {
+	trashed, err := isTrashed(r.db, "drivers", id)
+	if err != nil {
+		return err
+	}
+	if !trashed {
+		return apperrors.NotFound(models.EntityDriver, "Driver not found")
+	}
+	return purgeTrashed(r.db, "drivers", models.EntityDriver, id)
 }
 
 func (r *SqliteDriverRepository) UpdateById(driver *models.Driver) error {
 	exist, err := r.GetById(driver.ID)
 	if exist == nil {
-		return errors.New("Driver not found")
+		return apperrors.NotFound(models.EntityDriver, "Driver not found")
 	}
 	if err != nil {
 		return err
 	}
-	_, err = r.db.Exec("UPDATE drivers SET name = $1, surname = $2, patronymic = $3, birth_date = $4, passport_series = $5, snils = $6, license_series = $7 WHERE id = $8",
-		driver.Name, driver.Surname, driver.Patronymic, driver.BirthDate, driver.PassportSeries, driver.Snils, driver.LicenseSeries, driver.ID)
+	if exist.Version != driver.Version {
+		return apperrors.Conflict(models.EntityDriver, "Driver was changed by someone else", exist)
+	}
+	if err := r.findDuplicate(driver); err != nil {
+		return err
+	}
+	updatedAt := time.Now().UTC()
+	res, err := r.db.Exec("UPDATE drivers SET name = $1, surname = $2, patronymic = $3, birth_date = $4, passport_series = $5, snils = $6, license_series = $7, license_categories = $8, license_issue_date = $9, license_expiry_date = $10, version = version + 1, updated_at = $11 WHERE id = $12 AND version = $13 AND deleted_at IS NULL",
+		driver.Name, driver.Surname, driver.Patronymic, driver.BirthDate, driver.PassportSeries, driver.Snils, driver.LicenseSeries, driver.LicenseCategories, driver.LicenseIssueDate, driver.LicenseExpiryDate, updatedAt, driver.ID, driver.Version)
 	if err != nil {
+		return constraintError(err, models.EntityDriver, "Driver")
+	}
+	if n, err := res.RowsAffected(); err != nil {
 		return err
+	} else if n == 0 {
+		current, _ := r.GetById(driver.ID)
+		return apperrors.Conflict(models.EntityDriver, "Driver was changed by someone else", current)
 	}
+	driver.Version++
+	driver.UpdatedAt = updatedAt
 	return nil
 }
+
+func (r *SqliteDriverRepository) GetAllRoutesById(id string) ([]models.Route, error) {
+	return queryRoutes(r.db, `
+		SELECT r.id, r.number, r.length, r.min_capacity, r.bus_class, r.low_floor, r.version, r.updated_at
+		FROM routes r
+		JOIN routes_drivers j ON r.id = j.route_id
+		WHERE j.driver_id = $1 AND r.deleted_at IS NULL
+		ORDER BY r.number
+	`, id)
+}
@@ -2,36 +2,13 @@ package repository
 
 import (
 	"busManager/models"
-	"database/sql"
 	"github.com/google/uuid"
 	"testing"
 	"time"
 )
 
 func setupTestDBDriver(t *testing.T) (*SqliteDriverRepository, func()) {
-	// Use in-memory database for testing
-	db, err := sql.Open("sqlite3", ":memory:?parseTime=true")
-	if err != nil {
-		t.Fatalf("Failed to create test database: %v", err)
-	}
-
-	// Create drivers table
-	_, err = db.Exec(`
-		CREATE TABLE drivers (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL,
-			surname TEXT NOT NULL,
-			patronymic TEXT NOT NULL,
-			birth_date TIMESTAMP NOT NULL,
-			passport_series TEXT NOT NULL UNIQUE,
-			snils TEXT NOT NULL UNIQUE,
-			license_series TEXT NOT NULL UNIQUE
-		)
-	`)
-	if err != nil {
-		t.Fatalf("Failed to create drivers table: %v", err)
-	}
-
+	db := openTestDB(t)
 	repo := &SqliteDriverRepository{db: db}
 	return repo, func() { db.Close() }
 }
@@ -332,6 +309,7 @@ func TestSqliteDriverRepository_UpdateById(t *testing.T) {
 			PassportSeries: "XY789012",
 			Snils:          "987-654-321 00",
 			LicenseSeries:  "EF345678",
+			Version:        1,
 		}
 
 		err := repo.UpdateById(updatedDriver)
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"busManager/apperrors"
+	"errors"
+	"github.com/mattn/go-sqlite3"
+)
+
+// constraintError maps a violated sqlite constraint to the error the memory
+// repositories report for the same situation: a taken unique value is
+// "already exists", a missing referenced row "not found".
+func constraintError(err error, entityType, name string) error {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) || sqliteErr.Code != sqlite3.ErrConstraint {
+		return err
+	}
+	switch sqliteErr.ExtendedCode {
+	case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+		return apperrors.AlreadyExists(entityType, name+" already exists").Wrap(err)
+	case sqlite3.ErrConstraintForeignKey:
+		return apperrors.NotFound(entityType, name+" not found").Wrap(err)
+	}
+	return apperrors.Integrity(entityType, err.Error(), nil).Wrap(err)
+}
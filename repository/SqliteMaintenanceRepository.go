@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"github.com/google/uuid"
+	"strings"
+)
+
+type SqliteMaintenanceRepository struct {
+	db Executor
+}
+
+func NewSqliteMaintenanceRepository(db Executor) *SqliteMaintenanceRepository {
+	return &SqliteMaintenanceRepository{db: db}
+}
+
+func (r *SqliteMaintenanceRepository) AddInterval(interval *models.MaintenanceInterval) error {
+	if strings.TrimSpace(interval.ID) == "" {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return err
+		}
+		interval.ID = id.String()
+	}
+	_, err := r.db.Exec(`INSERT INTO maintenance_intervals (id, brand, bus_model, kind, days, kilometers)
+VALUES ($1, $2, $3, $4, $5, $6)`,
+		interval.ID,
+		interval.Brand,
+		interval.BusModel,
+		interval.Kind,
+		interval.Days,
+		interval.Kilometers,
+	)
+	if err != nil {
+		return constraintError(err, models.EntityMaintenanceInterval, "Maintenance interval")
+	}
+	return nil
+}
+
+func (r *SqliteMaintenanceRepository) GetIntervalById(id string) (*models.MaintenanceInterval, error) {
+	intervals, err := r.queryIntervals(`WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(intervals) == 0 {
+		return nil, apperrors.NotFound(models.EntityMaintenanceInterval, "Maintenance interval not found")
+	}
+	return &intervals[0], nil
+}
+
+func (r *SqliteMaintenanceRepository) GetAllIntervals() ([]models.MaintenanceInterval, error) {
+	return r.queryIntervals(``)
+}
+
+func (r *SqliteMaintenanceRepository) UpdateInterval(interval *models.MaintenanceInterval) error {
+	res, err := r.db.Exec(`UPDATE maintenance_intervals SET brand = $1, bus_model = $2, kind = $3, days = $4, kilometers = $5
+WHERE id = $6`,
+		interval.Brand,
+		interval.BusModel,
+		interval.Kind,
+		interval.Days,
+		interval.Kilometers,
+		interval.ID,
+	)
+	if err != nil {
+		return constraintError(err, models.EntityMaintenanceInterval, "Maintenance interval")
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return apperrors.NotFound(models.EntityMaintenanceInterval, "Maintenance interval not found")
+	}
+	return nil
+}
+
+func (r *SqliteMaintenanceRepository) DeleteIntervalById(id string) error {
+	res, err := r.db.Exec(`DELETE FROM maintenance_intervals WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return apperrors.NotFound(models.EntityMaintenanceInterval, "Maintenance interval not found")
+	}
+	return nil
+}
+
+// queryIntervals selects the intervals matching where, sorted by brand,
+// model and kind.
+func (r *SqliteMaintenanceRepository) queryIntervals(where string, args ...any) ([]models.MaintenanceInterval, error) {
+	rows, err := r.db.Query(`
+		SELECT id, brand, bus_model, kind, days, kilometers
+		FROM maintenance_intervals
+		`+where+`
+		ORDER BY brand, bus_model, kind`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	intervals := []models.MaintenanceInterval{}
+	for rows.Next() {
+		interval := models.MaintenanceInterval{}
+		err := rows.Scan(
+			&interval.ID,
+			&interval.Brand,
+			&interval.BusModel,
+			&interval.Kind,
+			&interval.Days,
+			&interval.Kilometers,
+		)
+		if err != nil {
+			return nil, err
+		}
+		intervals = append(intervals, interval)
+	}
+	return intervals, rows.Err()
+}
+
+func (r *SqliteMaintenanceRepository) AddRecord(record *models.MaintenanceRecord) error {
+	var buses int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM buses WHERE id = $1`, record.BusID).Scan(&buses); err != nil {
+		return err
+	}
+	if buses == 0 {
+		return apperrors.NotFound(models.EntityBus, "Bus not found")
+	}
+	if strings.TrimSpace(record.ID) == "" {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return err
+		}
+		record.ID = id.String()
+	}
+	record.PerformedAt = record.PerformedAt.UTC()
+	_, err := r.db.Exec(`INSERT INTO maintenance_records (id, bus_id, kind, performed_at, mileage, mechanic, notes)
+VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		record.ID,
+		record.BusID,
+		record.Kind,
+		record.PerformedAt,
+		record.Mileage,
+		record.Mechanic,
+		record.Notes,
+	)
+	if err != nil {
+		return constraintError(err, models.EntityMaintenance, "Maintenance record")
+	}
+	return nil
+}
+
+func (r *SqliteMaintenanceRepository) GetAllRecordsByBusId(busId string) ([]models.MaintenanceRecord, error) {
+	return r.queryRecords(`WHERE bus_id = $1`, busId)
+}
+
+func (r *SqliteMaintenanceRepository) GetAllRecords() ([]models.MaintenanceRecord, error) {
+	return r.queryRecords(``)
+}
+
+// queryRecords selects the records matching where, newest first.
+func (r *SqliteMaintenanceRepository) queryRecords(where string, args ...any) ([]models.MaintenanceRecord, error) {
+	rows, err := r.db.Query(`
+		SELECT id, bus_id, kind, performed_at, mileage, mechanic, notes
+		FROM maintenance_records
+		`+where+`
+		ORDER BY performed_at DESC, rowid DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	records := []models.MaintenanceRecord{}
+	for rows.Next() {
+		record := models.MaintenanceRecord{}
+		err := rows.Scan(
+			&record.ID,
+			&record.BusID,
+			&record.Kind,
+			&record.PerformedAt,
+			&record.Mileage,
+			&record.Mechanic,
+			&record.Notes,
+		)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+type SqliteMedicalCheckRepository struct {
+	db Executor
+}
+
+func NewSqliteMedicalCheckRepository(db Executor) *SqliteMedicalCheckRepository {
+	return &SqliteMedicalCheckRepository{db: db}
+}
+
+func (r *SqliteMedicalCheckRepository) Add(check *models.MedicalCheck) error {
+	var drivers int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM drivers WHERE id = $1`, check.DriverID).Scan(&drivers); err != nil {
+		return err
+	}
+	if drivers == 0 {
+		return apperrors.NotFound(models.EntityDriver, "Driver not found")
+	}
+	if strings.TrimSpace(check.ID) == "" {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return err
+		}
+		check.ID = id.String()
+	}
+	check.CheckedAt = check.CheckedAt.UTC()
+	_, err := r.db.Exec(`INSERT INTO medical_checks (id, driver_id, checked_at, examiner, result, systolic, diastolic, alcohol, notes)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		check.ID,
+		check.DriverID,
+		check.CheckedAt,
+		check.Examiner,
+		check.Result,
+		check.Systolic,
+		check.Diastolic,
+		check.Alcohol,
+		check.Notes,
+	)
+	if err != nil {
+		return constraintError(err, models.EntityMedicalCheck, "Medical check")
+	}
+	return nil
+}
+
+func (r *SqliteMedicalCheckRepository) GetById(id string) (*models.MedicalCheck, error) {
+	checks, err := r.query(`WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(checks) == 0 {
+		return nil, apperrors.NotFound(models.EntityMedicalCheck, "Medical check not found")
+	}
+	return &checks[0], nil
+}
+
+func (r *SqliteMedicalCheckRepository) GetAllByDriverId(driverId string) ([]models.MedicalCheck, error) {
+	return r.query(`WHERE driver_id = $1`, driverId)
+}
+
+func (r *SqliteMedicalCheckRepository) GetAllByTimeRange(from, to time.Time) ([]models.MedicalCheck, error) {
+	return r.query(`WHERE checked_at >= $1 AND checked_at < $2`, from.UTC(), to.UTC())
+}
+
+// query selects the checks matching where, newest first.
+func (r *SqliteMedicalCheckRepository) query(where string, args ...any) ([]models.MedicalCheck, error) {
+	rows, err := r.db.Query(`
+		SELECT id, driver_id, checked_at, examiner, result, systolic, diastolic, alcohol, notes
+		FROM medical_checks
+		`+where+`
+		ORDER BY checked_at DESC, rowid DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	checks := []models.MedicalCheck{}
+	for rows.Next() {
+		check := models.MedicalCheck{}
+		err := rows.Scan(
+			&check.ID,
+			&check.DriverID,
+			&check.CheckedAt,
+			&check.Examiner,
+			&check.Result,
+			&check.Systolic,
+			&check.Diastolic,
+			&check.Alcohol,
+			&check.Notes,
+		)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, check)
+	}
+	return checks, rows.Err()
+}
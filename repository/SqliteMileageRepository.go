@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+type SqliteMileageRepository struct {
+	db Executor
+}
+
+func NewSqliteMileageRepository(db Executor) *SqliteMileageRepository {
+	return &SqliteMileageRepository{db: db}
+}
+
+// exists reports whether a row with id is in table, in the trash or not.
+func (r *SqliteMileageRepository) exists(table, id string) (bool, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM `+table+` WHERE id = $1`, id).Scan(&count)
+	return count > 0, err
+}
+
+func (r *SqliteMileageRepository) AddReading(reading *models.OdometerReading) error {
+	if ok, err := r.exists("buses", reading.BusID); err != nil {
+		return err
+	} else if !ok {
+		return apperrors.NotFound(models.EntityBus, "Bus not found")
+	}
+	if strings.TrimSpace(reading.ID) == "" {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return err
+		}
+		reading.ID = id.String()
+	}
+	reading.ReadAt = reading.ReadAt.UTC()
+	_, err := r.db.Exec(`INSERT INTO odometer_readings (id, bus_id, read_at, kilometers, notes) VALUES ($1, $2, $3, $4, $5)`,
+		reading.ID,
+		reading.BusID,
+		reading.ReadAt,
+		reading.Kilometers,
+		reading.Notes,
+	)
+	if err != nil {
+		return constraintError(err, models.EntityOdometerReading, "Odometer reading")
+	}
+	return nil
+}
+
+func (r *SqliteMileageRepository) GetAllReadingsByBusId(busId string) ([]models.OdometerReading, error) {
+	rows, err := r.db.Query(`
+		SELECT id, bus_id, read_at, kilometers, notes
+		FROM odometer_readings
+		WHERE bus_id = $1
+		ORDER BY read_at, rowid`, busId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	readings := []models.OdometerReading{}
+	for rows.Next() {
+		reading := models.OdometerReading{}
+		if err := rows.Scan(&reading.ID, &reading.BusID, &reading.ReadAt, &reading.Kilometers, &reading.Notes); err != nil {
+			return nil, err
+		}
+		readings = append(readings, reading)
+	}
+	return readings, rows.Err()
+}
+
+func (r *SqliteMileageRepository) AddTrips(trips *models.OperatedTrips) error {
+	if ok, err := r.exists("buses", trips.BusID); err != nil {
+		return err
+	} else if !ok {
+		return apperrors.NotFound(models.EntityBus, "Bus not found")
+	}
+	if ok, err := r.exists("routes", trips.RouteID); err != nil {
+		return err
+	} else if !ok {
+		return apperrors.NotFound(models.EntityRoute, "Route not found")
+	}
+	if strings.TrimSpace(trips.ID) == "" {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return err
+		}
+		trips.ID = id.String()
+	}
+	trips.Day = trips.Day.UTC()
+	_, err := r.db.Exec(`INSERT INTO operated_trips (id, bus_id, route_id, day, count) VALUES ($1, $2, $3, $4, $5)`,
+		trips.ID,
+		trips.BusID,
+		trips.RouteID,
+		trips.Day,
+		trips.Count,
+	)
+	if err != nil {
+		return constraintError(err, models.EntityOperatedTrips, "Trip record")
+	}
+	return nil
+}
+
+func (r *SqliteMileageRepository) GetAllTripsByBusId(busId string, from, to time.Time) ([]models.OperatedTrips, error) {
+	rows, err := r.db.Query(`
+		SELECT id, bus_id, route_id, day, count
+		FROM operated_trips
+		WHERE bus_id = $1 AND day >= $2 AND day < $3
+		ORDER BY day, rowid`, busId, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	all := []models.OperatedTrips{}
+	for rows.Next() {
+		trips := models.OperatedTrips{}
+		if err := rows.Scan(&trips.ID, &trips.BusID, &trips.RouteID, &trips.Day, &trips.Count); err != nil {
+			return nil, err
+		}
+		all = append(all, trips)
+	}
+	return all, rows.Err()
+}
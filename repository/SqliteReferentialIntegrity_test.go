@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"busManager/models"
+	"testing"
+	"time"
+)
+
+func seedAssignments(t *testing.T, repos Repositories) (*models.Route, *models.Bus, *models.Driver, *models.BusStop) {
+	fixedTime, _ := time.Parse(time.RFC3339, "2022-11-11T11:11:11Z")
+	route := &models.Route{Number: "12"}
+	bus := &models.Bus{Brand: "Volvo", BusModel: "B7R", RegisterNumber: "ABC123", AssemblyDate: fixedTime, LastRepairDate: fixedTime}
+	driver := &models.Driver{Name: "John", Surname: "Doe", Patronymic: "Ivanovich", BirthDate: fixedTime,
+		PassportSeries: "AB123456", Snils: "123-456-789 00", LicenseSeries: "CD789012"}
+	busStop := &models.BusStop{Lat: 55.7558, Long: 37.6173, Name: "Stop A"}
+	if err := repos.Routes.Add(route); err != nil {
+		t.Fatalf("Failed to add route: %v", err)
+	}
+	if err := repos.Buses.Add(bus); err != nil {
+		t.Fatalf("Failed to add bus: %v", err)
+	}
+	if err := repos.Drivers.Add(driver); err != nil {
+		t.Fatalf("Failed to add driver: %v", err)
+	}
+	if err := repos.BusStops.Add(busStop); err != nil {
+		t.Fatalf("Failed to add bus stop: %v", err)
+	}
+	if err := repos.Routes.AssignBus(route.ID, bus.ID); err != nil {
+		t.Fatalf("Failed to assign bus: %v", err)
+	}
+	if err := repos.Routes.AssignDriver(route.ID, driver.ID); err != nil {
+		t.Fatalf("Failed to assign driver: %v", err)
+	}
+	if err := repos.Routes.AssignBusStop(route.ID, busStop.ID); err != nil {
+		t.Fatalf("Failed to assign bus stop: %v", err)
+	}
+	return route, bus, driver, busStop
+}
+
+func countRows(t *testing.T, repos Repositories, table string) int {
+	var count int
+	db := repos.Routes.(*SqliteRouteRepository).db
+	if err := db.QueryRow(`SELECT COUNT(*) FROM ` + table).Scan(&count); err != nil {
+		t.Fatalf("Failed to count %s: %v", table, err)
+	}
+	return count
+}
+
+func TestSqliteRepositories_GetAllRoutesById(t *testing.T) {
+	db := openTestDBWithForeignKeys(t)
+	defer db.Close()
+	repos := NewSqliteRepositories(db)
+	route, bus, driver, busStop := seedAssignments(t, repos)
+
+	busRoutes, err := repos.Buses.GetAllRoutesById(bus.ID)
+	if err != nil || len(busRoutes) != 1 || busRoutes[0].ID != route.ID {
+		t.Errorf("Expected bus route %s, got %v (%v)", route.Number, busRoutes, err)
+	}
+	driverRoutes, err := repos.Drivers.GetAllRoutesById(driver.ID)
+	if err != nil || len(driverRoutes) != 1 || driverRoutes[0].ID != route.ID {
+		t.Errorf("Expected driver route %s, got %v (%v)", route.Number, driverRoutes, err)
+	}
+	stopRoutes, err := repos.BusStops.GetAllRoutesById(busStop.ID)
+	if err != nil || len(stopRoutes) != 1 || stopRoutes[0].ID != route.ID {
+		t.Errorf("Expected bus stop route %s, got %v (%v)", route.Number, stopRoutes, err)
+	}
+}
+
+func TestSqliteRepositories_DeleteCascadesAssignments(t *testing.T) {
+	t.Run("Delete bus, driver and bus stop", func(t *testing.T) {
+		db := openTestDBWithForeignKeys(t)
+		defer db.Close()
+		repos := NewSqliteRepositories(db)
+		_, bus, driver, busStop := seedAssignments(t, repos)
+
+		if err := repos.Buses.DeleteById(bus.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := repos.Drivers.DeleteById(driver.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := repos.BusStops.DeleteById(busStop.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for _, table := range []string{"routes_buses", "routes_drivers", "routes_bus_stops"} {
+			if count := countRows(t, repos, table); count != 0 {
+				t.Errorf("Expected no rows in %s, got %d", table, count)
+			}
+		}
+	})
+
+	t.Run("Delete route", func(t *testing.T) {
+		db := openTestDBWithForeignKeys(t)
+		defer db.Close()
+		repos := NewSqliteRepositories(db)
+		route, _, _, _ := seedAssignments(t, repos)
+
+		if err := repos.Routes.DeleteById(route.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for _, table := range []string{"routes_buses", "routes_drivers", "routes_bus_stops"} {
+			if count := countRows(t, repos, table); count != 0 {
+				t.Errorf("Expected no rows in %s, got %d", table, count)
+			}
+		}
+	})
+}
+
+func TestSqliteRouteRepository_AssignMissingEntity(t *testing.T) {
+	db := openTestDBWithForeignKeys(t)
+	defer db.Close()
+	repos := NewSqliteRepositories(db)
+	route, _, _, _ := seedAssignments(t, repos)
+
+	if err := repos.Routes.AssignBus(route.ID, "missing"); err == nil {
+		t.Errorf("Expected foreign key error assigning a missing bus")
+	}
+}
@@ -1,39 +1,40 @@
 package repository
 
 import (
+	"busManager/apperrors"
 	"busManager/models"
 	"database/sql"
-	"errors"
 	"github.com/google/uuid"
 	"strings"
+	"time"
 )
 
 type SqliteRouteRepository struct {
-	db *sql.DB
+	db Executor
 }
 
-func NewSqliteRouteRepository(dbPath string) (*SqliteRouteRepository, error) {
-	db, err := sql.Open("sqlite3", dbPath+"?parseTime=true")
-	//defer db.Close()
-	if err != nil {
-		return nil, err
-	}
-	repo := &SqliteRouteRepository{db: db}
-	return repo, nil
+func NewSqliteRouteRepository(db Executor) *SqliteRouteRepository {
+	return &SqliteRouteRepository{db: db}
 }
 
 func (r *SqliteRouteRepository) GetById(id string) (*models.Route, error) {
 	route := &models.Route{}
 	err := r.db.QueryRow(`
-		SELECT id, number 
+		SELECT id, number, length, min_capacity, bus_class, low_floor, version, updated_at 
 		FROM routes 
-		WHERE id = $1`, id).Scan(
+		WHERE id = $1 AND deleted_at IS NULL`, id).Scan(
 		&route.ID,
 		&route.Number,
+		&route.Length,
+		&route.MinCapacity,
+		&route.BusClass,
+		&route.LowFloor,
+		&route.Version,
+		&route.UpdatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("Route not found")
+			return nil, apperrors.NotFound(models.EntityRoute, "Route not found")
 		}
 		return nil, err
 	}
@@ -44,15 +45,21 @@ func (r *SqliteRouteRepository) GetById(id string) (*models.Route, error) {
 func (r *SqliteRouteRepository) GetByNumber(number string) (*models.Route, error) {
 	route := &models.Route{}
 	err := r.db.QueryRow(`
-		SELECT id, number
+		SELECT id, number, length, min_capacity, bus_class, low_floor, version, updated_at
 		FROM routes 
-		WHERE number = $1`, number).Scan(
+		WHERE number = $1 AND deleted_at IS NULL`, number).Scan(
 		&route.ID,
 		&route.Number,
+		&route.Length,
+		&route.MinCapacity,
+		&route.BusClass,
+		&route.LowFloor,
+		&route.Version,
+		&route.UpdatedAt,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("Route not found")
+			return nil, apperrors.NotFound(models.EntityRoute, "Route not found")
 		}
 		return nil, err
 	}
@@ -63,7 +70,15 @@ func (r *SqliteRouteRepository) GetByNumber(number string) (*models.Route, error
 func (r *SqliteRouteRepository) Add(route *models.Route) error {
 	exist, err := r.GetByNumber(route.Number)
 	if exist != nil {
-		return errors.New("Route already exists")
+		return apperrors.AlreadyExists(models.EntityRoute, "Route already exists")
+	}
+	var trashed int
+	err = r.db.QueryRow(`SELECT COUNT(*) FROM routes WHERE number = $1`, route.Number).Scan(&trashed)
+	if err != nil {
+		return err
+	}
+	if trashed > 0 {
+		return apperrors.AlreadyExists(models.EntityRoute, "Route already exists in trash")
 	}
 	if strings.TrimSpace(route.ID) == "" {
 		id, err := uuid.NewRandom()
@@ -72,22 +87,60 @@ func (r *SqliteRouteRepository) Add(route *models.Route) error {
 		}
 		route.ID = id.String()
 	}
-	_, err = r.db.Exec(`INSERT into routes (id, number) 
-VALUES ($1, $2)`, &route.ID,
+	route.Version = 1
+	route.UpdatedAt = time.Now().UTC()
+	_, err = r.db.Exec(`INSERT into routes (id, number, length, min_capacity, bus_class, low_floor, version, updated_at) 
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`, &route.ID,
 		&route.Number,
+		&route.Length,
+		&route.MinCapacity,
+		&route.BusClass,
+		&route.LowFloor,
+		&route.Version,
+		&route.UpdatedAt,
 	)
 	if err != nil {
-		return err
+		return constraintError(err, models.EntityRoute, "Route")
 	}
 	return nil
 
 }
 
+// queryRoutes runs a query selecting id, number, length, min_capacity,
+// bus_class, low_floor, version and updated_at of routes, in that order.
+func queryRoutes(db Executor, query string, args ...any) ([]models.Route, error) {
+	var routes []models.Route
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		route := &models.Route{}
+		err := rows.Scan(
+			&route.ID,
+			&route.Number,
+			&route.Length,
+			&route.MinCapacity,
+			&route.BusClass,
+			&route.LowFloor,
+			&route.Version,
+			&route.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, *route)
+	}
+	return routes, nil
+}
+
 func (r *SqliteRouteRepository) GetAll() ([]models.Route, error) {
 	var routes []models.Route
 	rows, err := r.db.Query(`
-		SELECT id, number
+		SELECT id, number, length, min_capacity, bus_class, low_floor, version, updated_at
 		FROM routes 
+		WHERE deleted_at IS NULL
 		`)
 	if err != nil {
 		//if err == sql.ErrNoRows {
@@ -95,11 +148,18 @@ func (r *SqliteRouteRepository) GetAll() ([]models.Route, error) {
 		//}
 		return nil, err
 	}
+	defer rows.Close()
 	for rows.Next() {
 		route := &models.Route{}
 		err := rows.Scan(
 			&route.ID,
 			&route.Number,
+			&route.Length,
+			&route.MinCapacity,
+			&route.BusClass,
+			&route.LowFloor,
+			&route.Version,
+			&route.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -109,42 +169,124 @@ func (r *SqliteRouteRepository) GetAll() ([]models.Route, error) {
 	return routes, nil
 }
 
+func (r *SqliteRouteRepository) List(query models.ListQuery) (models.Page[models.Route], error) {
+	return sqliteList(r.db, "routes", "id, number, length, min_capacity, bus_class, low_floor, version, updated_at", routeFields, "Number", query,
+		func(rows *sql.Rows) (models.Route, error) {
+			var route models.Route
+			err := rows.Scan(
+				&route.ID,
+				&route.Number,
+				&route.Length,
+				&route.MinCapacity,
+				&route.BusClass,
+				&route.LowFloor,
+				&route.Version,
+				&route.UpdatedAt,
+			)
+			return route, err
+		})
+}
+
 func (r *SqliteRouteRepository) DeleteById(id string) error {
 	exist, err := r.GetById(id)
 	if exist == nil {
-		return errors.New("Route not found")
+		return apperrors.NotFound(models.EntityRoute, "Route not found")
+	}
+	if err != nil {
+		return err
+	}
+	return trashRoute(r.db, id)
+}
+
+func (r *SqliteRouteRepository) GetAllDeleted() ([]models.Trashed[models.Route], error) {
+	var routes []models.Trashed[models.Route]
+	rows, err := r.db.Query(`
+		SELECT id, number, length, min_capacity, bus_class, low_floor, version, updated_at, deleted_at
+		FROM routes
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
+		`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		route := models.Trashed[models.Route]{}
+		err := rows.Scan(
+			&route.Item.ID,
+			&route.Item.Number,
+			&route.Item.Length,
+			&route.Item.MinCapacity,
+			&route.Item.BusClass,
+			&route.Item.LowFloor,
+			&route.Item.Version,
+			&route.Item.UpdatedAt,
+			&route.DeletedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
 	}
+	return routes, nil
+}
+
+func (r *SqliteRouteRepository) RestoreById(id string) error {
+	trashed, err := isTrashed(r.db, "routes", id)
 	if err != nil {
 		return err
 	}
-	_, err = r.db.Exec("DELETE FROM routes WHERE id = $1", id)
+	if !trashed {
+		return apperrors.NotFound(models.EntityRoute, "Route not found")
+	}
+	return restoreTrashed(r.db, "routes", models.EntityRoute, id)
+}
+
+func (r *SqliteRouteRepository) PurgeById(id string) error {
+	trashed, err := isTrashed(r.db, "routes", id)
 	if err != nil {
 		return err
 	}
-	return nil
+	if !trashed {
+		return apperrors.NotFound(models.EntityRoute, "Route not found")
+	}
+	return purgeTrashed(r.db, "routes", models.EntityRoute, id)
 }
 
 func (r *SqliteRouteRepository) UpdateById(route *models.Route) error {
 
 	exist, err := r.GetById(route.ID)
 	if exist == nil {
-		return errors.New("Route not found")
+		return apperrors.NotFound(models.EntityRoute, "Route not found")
 	}
 	if err != nil {
 		return err
 	}
-	_, err = r.db.Exec("UPDATE routes SET number = $1 WHERE id = $2",
-		route.Number, route.ID)
+	if exist.Version != route.Version {
+		return apperrors.Conflict(models.EntityRoute, "Route was changed by someone else", exist)
+	}
+	updatedAt := time.Now().UTC()
+	res, err := r.db.Exec(`UPDATE routes SET number = $1, length = $2, min_capacity = $3, bus_class = $4, low_floor = $5,
+		version = version + 1, updated_at = $6 WHERE id = $7 AND version = $8 AND deleted_at IS NULL`,
+		route.Number, route.Length, route.MinCapacity, route.BusClass, route.LowFloor, updatedAt, route.ID, route.Version)
 	if err != nil {
+		return constraintError(err, models.EntityRoute, "Route")
+	}
+	if n, err := res.RowsAffected(); err != nil {
 		return err
+	} else if n == 0 {
+		current, _ := r.GetById(route.ID)
+		return apperrors.Conflict(models.EntityRoute, "Route was changed by someone else", current)
 	}
+	route.Version++
+	route.UpdatedAt = updatedAt
 	return nil
 }
 
 func (r *SqliteRouteRepository) AssignDriver(routeId, driverId string) error {
 	exist, err := r.GetById(routeId)
 	if exist == nil {
-		return errors.New("Route not found")
+		return apperrors.NotFound(models.EntityRoute, "Route not found")
 	}
 	var count int
 	err = r.db.QueryRow(`SELECT COUNT(*) FROM routes_drivers WHERE route_id = $1 AND driver_id = $2`, routeId, driverId).Scan(&count)
@@ -152,14 +294,22 @@ func (r *SqliteRouteRepository) AssignDriver(routeId, driverId string) error {
 		return err
 	}
 	if count > 0 {
-		return errors.New("Pair route_id and driver_id already exists")
+		return apperrors.AlreadyExists(models.EntityRoute, "Pair route_id and driver_id already exists")
+	}
+	// missing rows are left to the foreign key, trashed ones are hidden here
+	trashed, err := isTrashed(r.db, "drivers", driverId)
+	if err != nil {
+		return err
+	}
+	if trashed {
+		return apperrors.NotFound(models.EntityDriver, "Driver not found")
 	}
 	_, err = r.db.Exec(`INSERT into routes_drivers (route_id, driver_id) 
 VALUES ($1, $2)`, routeId,
 		driverId,
 	)
 	if err != nil {
-		return err
+		return constraintError(err, models.EntityDriver, "Driver")
 	}
 	return nil
 }
@@ -167,7 +317,7 @@ VALUES ($1, $2)`, routeId,
 func (r *SqliteRouteRepository) AssignBusStop(routeId, busStopId string) error {
 	exist, err := r.GetById(routeId)
 	if exist == nil {
-		return errors.New("Route not found")
+		return apperrors.NotFound(models.EntityRoute, "Route not found")
 	}
 	var count int
 	err = r.db.QueryRow(`SELECT COUNT(*) FROM routes_bus_stops WHERE route_id = $1 AND bus_stop_id = $2`, routeId, busStopId).Scan(&count)
@@ -175,14 +325,21 @@ func (r *SqliteRouteRepository) AssignBusStop(routeId, busStopId string) error {
 		return err
 	}
 	if count > 0 {
-		return errors.New("Pair route_id and bus_stop_id already exists")
+		return apperrors.AlreadyExists(models.EntityRoute, "Pair route_id and bus_stop_id already exists")
+	}
+	trashed, err := isTrashed(r.db, "bus_stops", busStopId)
+	if err != nil {
+		return err
+	}
+	if trashed {
+		return apperrors.NotFound(models.EntityBusStop, "Bus stop not found")
 	}
 	_, err = r.db.Exec(`INSERT into routes_bus_stops (route_id, bus_stop_id) 
 VALUES ($1, $2)`, routeId,
 		busStopId,
 	)
 	if err != nil {
-		return err
+		return constraintError(err, models.EntityBusStop, "Bus stop")
 	}
 	return nil
 }
@@ -190,7 +347,7 @@ VALUES ($1, $2)`, routeId,
 func (r *SqliteRouteRepository) AssignBus(routeId, busId string) error {
 	exist, err := r.GetById(routeId)
 	if exist == nil {
-		return errors.New("Route not found")
+		return apperrors.NotFound(models.EntityRoute, "Route not found")
 	}
 	var count int
 	err = r.db.QueryRow(`SELECT COUNT(*) FROM routes_buses WHERE route_id = $1 AND bus_id = $2`, routeId, busId).Scan(&count)
@@ -198,14 +355,21 @@ func (r *SqliteRouteRepository) AssignBus(routeId, busId string) error {
 		return err
 	}
 	if count > 0 {
-		return errors.New("Pair route_id and bus_id already exists")
+		return apperrors.AlreadyExists(models.EntityRoute, "Pair route_id and bus_id already exists")
+	}
+	trashed, err := isTrashed(r.db, "buses", busId)
+	if err != nil {
+		return err
+	}
+	if trashed {
+		return apperrors.NotFound(models.EntityBus, "Bus not found")
 	}
 	_, err = r.db.Exec(`INSERT into routes_buses (route_id, bus_id) 
 VALUES ($1, $2)`, routeId,
 		busId,
 	)
 	if err != nil {
-		return err
+		return constraintError(err, models.EntityBus, "Bus")
 	}
 	return nil
 }
@@ -213,7 +377,7 @@ VALUES ($1, $2)`, routeId,
 func (r *SqliteRouteRepository) UnassignBusStop(routeId, busStopId string) error {
 	exist, err := r.GetById(routeId)
 	if exist == nil {
-		return errors.New("Route not found")
+		return apperrors.NotFound(models.EntityRoute, "Route not found")
 	}
 	_, err = r.db.Exec(`DELETE FROM routes_bus_stops WHERE route_id = $1 AND bus_stop_id = $2`, routeId, busStopId)
 	if err != nil {
@@ -225,7 +389,7 @@ func (r *SqliteRouteRepository) UnassignBusStop(routeId, busStopId string) error
 func (r *SqliteRouteRepository) UnassignBus(routeId, busId string) error {
 	exist, err := r.GetById(routeId)
 	if exist == nil {
-		return errors.New("Route not found")
+		return apperrors.NotFound(models.EntityRoute, "Route not found")
 	}
 	_, err = r.db.Exec(`DELETE FROM routes_buses WHERE route_id = $1 AND bus_id = $2`, routeId, busId)
 	if err != nil {
@@ -237,7 +401,7 @@ func (r *SqliteRouteRepository) UnassignBus(routeId, busId string) error {
 func (r *SqliteRouteRepository) UnassignDriver(routeId, driverId string) error {
 	exist, err := r.GetById(routeId)
 	if exist == nil {
-		return errors.New("Route not found")
+		return apperrors.NotFound(models.EntityRoute, "Route not found")
 	}
 	_, err = r.db.Exec(`DELETE FROM routes_drivers WHERE route_id = $1 AND driver_id = $2`, routeId, driverId)
 	if err != nil {
@@ -250,20 +414,21 @@ func (r *SqliteRouteRepository) GetAllDriversById(routeId string) ([]models.Driv
 	var drivers []models.Driver
 	exist, err := r.GetById(routeId)
 	if exist == nil {
-		return nil, errors.New("Route not found")
+		return nil, apperrors.NotFound(models.EntityRoute, "Route not found")
 	}
 	if err != nil {
 		return nil, err
 	}
 	rows, err := r.db.Query(`
-		SELECT d.id, d.name, d.surname, d.patronymic, d.birth_date, d.passport_series, d.snils, d.license_series
+		SELECT d.id, d.name, d.surname, d.patronymic, d.birth_date, d.passport_series, d.snils, d.license_series, d.license_categories, d.license_issue_date, d.license_expiry_date, d.version, d.updated_at
 		FROM drivers d 
 		JOIN routes_drivers rd ON d.id = rd.driver_id
-		WHERE rd.route_id=$1
+		WHERE rd.route_id=$1 AND d.deleted_at IS NULL
 	`, routeId)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 	for rows.Next() {
 		driver := &models.Driver{}
 		err := rows.Scan(
@@ -275,6 +440,11 @@ func (r *SqliteRouteRepository) GetAllDriversById(routeId string) ([]models.Driv
 			&driver.PassportSeries,
 			&driver.Snils,
 			&driver.LicenseSeries,
+			&driver.LicenseCategories,
+			&driver.LicenseIssueDate,
+			&driver.LicenseExpiryDate,
+			&driver.Version,
+			&driver.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -288,20 +458,21 @@ func (r *SqliteRouteRepository) GetAllBusStopsById(routeId string) ([]models.Bus
 	var busStops []models.BusStop
 	exist, err := r.GetById(routeId)
 	if exist == nil {
-		return nil, errors.New("Route not found")
+		return nil, apperrors.NotFound(models.EntityRoute, "Route not found")
 	}
 	if err != nil {
 		return nil, err
 	}
 	rows, err := r.db.Query(`
-		SELECT d.id, d.lat, d.long, d.name
+		SELECT d.id, d.lat, d.long, d.name, d.version, d.updated_at
 		FROM bus_stops d 
 		JOIN routes_bus_stops rd ON d.id = rd.bus_stop_id
-		WHERE rd.route_id=$1
+		WHERE rd.route_id=$1 AND d.deleted_at IS NULL
 	`, routeId)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 	for rows.Next() {
 		busStop := &models.BusStop{}
 		err := rows.Scan(
@@ -309,6 +480,8 @@ func (r *SqliteRouteRepository) GetAllBusStopsById(routeId string) ([]models.Bus
 			&busStop.Lat,
 			&busStop.Long,
 			&busStop.Name,
+			&busStop.Version,
+			&busStop.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -322,20 +495,22 @@ func (r *SqliteRouteRepository) GetAllBusesById(routeId string) ([]models.Bus, e
 	var buses []models.Bus
 	exist, err := r.GetById(routeId)
 	if exist == nil {
-		return nil, errors.New("Route not found")
+		return nil, apperrors.NotFound(models.EntityRoute, "Route not found")
 	}
 	if err != nil {
 		return nil, err
 	}
 	rows, err := r.db.Query(`
-		SELECT d.id, d.brand, d.bus_model, d.register_number, d.assembly_date, d.last_repair_date
+		SELECT d.id, d.brand, d.bus_model, d.register_number, d.assembly_date, d.last_repair_date, d.status,
+			d.seated_capacity, d.standing_capacity, d.class, d.length, d.fuel_type, d.low_floor, d.eco_class, d.vin, d.version, d.updated_at
 		FROM buses d 
 		JOIN routes_buses rd ON d.id = rd.bus_id
-		WHERE rd.route_id=$1
+		WHERE rd.route_id=$1 AND d.deleted_at IS NULL
 	`, routeId)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 	for rows.Next() {
 		bus := &models.Bus{}
 		err := rows.Scan(
@@ -345,6 +520,17 @@ func (r *SqliteRouteRepository) GetAllBusesById(routeId string) ([]models.Bus, e
 			&bus.RegisterNumber,
 			&bus.AssemblyDate,
 			&bus.LastRepairDate,
+			&bus.Status,
+			&bus.SeatedCapacity,
+			&bus.StandingCapacity,
+			&bus.Class,
+			&bus.Length,
+			&bus.FuelType,
+			&bus.LowFloor,
+			&bus.EcoClass,
+			&bus.VIN,
+			&bus.Version,
+			&bus.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
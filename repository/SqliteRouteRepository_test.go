@@ -2,106 +2,12 @@ package repository
 
 import (
 	"busManager/models"
-	"database/sql"
 	"github.com/google/uuid"
 	"testing"
 )
 
 func setupTestDBRoute(t *testing.T) (*SqliteRouteRepository, func()) {
-	db, err := sql.Open("sqlite3", ":memory:?parseTime=true")
-	if err != nil {
-		t.Fatalf("Failed to create test database: %v", err)
-	}
-
-	_, err = db.Exec(`
-        CREATE TABLE routes (
-            id TEXT PRIMARY KEY,
-            number TEXT NOT NULL
-        )
-    `)
-	if err != nil {
-		t.Fatalf("Failed to create routes table: %v", err)
-	}
-
-	_, err = db.Exec(`
-        CREATE TABLE routes_drivers (
-            route_id TEXT NOT NULL,
-            driver_id TEXT NOT NULL,
-            PRIMARY KEY (route_id, driver_id),
-            FOREIGN KEY (route_id) REFERENCES routes(id)
-        )
-    `)
-	if err != nil {
-		t.Fatalf("Failed to create routes_drivers table: %v", err)
-	}
-
-	_, err = db.Exec(`
-        CREATE TABLE routes_bus_stops (
-            route_id TEXT NOT NULL,
-            bus_stop_id TEXT NOT NULL,
-            PRIMARY KEY (route_id, bus_stop_id),
-            FOREIGN KEY (route_id) REFERENCES routes(id)
-        )
-    `)
-	if err != nil {
-		t.Fatalf("Failed to create routes_bus_stops table: %v", err)
-	}
-
-	_, err = db.Exec(`
-        CREATE TABLE routes_buses (
-            route_id TEXT NOT NULL,
-            bus_id TEXT NOT NULL,
-            PRIMARY KEY (route_id, bus_id),
-            FOREIGN KEY (route_id) REFERENCES routes(id)
-        )
-    `)
-	if err != nil {
-		t.Fatalf("Failed to create routes_buses table: %v", err)
-	}
-
-	// Добавляем таблицы для новых методов
-	_, err = db.Exec(`
-        CREATE TABLE drivers (
-            id TEXT PRIMARY KEY,
-            name TEXT NOT NULL,
-            surname TEXT,
-            patronymic TEXT,
-            birth_date DATETIME,
-            passport_series TEXT,
-            snils TEXT,
-            license_series TEXT
-        )
-    `)
-	if err != nil {
-		t.Fatalf("Failed to create drivers table: %v", err)
-	}
-
-	_, err = db.Exec(`
-        CREATE TABLE bus_stops (
-            id TEXT PRIMARY KEY,
-            lat REAL,
-            long REAL,
-            name TEXT
-        )
-    `)
-	if err != nil {
-		t.Fatalf("Failed to create bus_stops table: %v", err)
-	}
-
-	_, err = db.Exec(`
-        CREATE TABLE buses (
-            id TEXT PRIMARY KEY,
-            brand TEXT,
-            bus_model TEXT,
-            register_number TEXT,
-            assembly_date DATETIME,
-            last_repair_date DATETIME
-        )
-    `)
-	if err != nil {
-		t.Fatalf("Failed to create buses table: %v", err)
-	}
-
+	db := openTestDB(t)
 	repo := &SqliteRouteRepository{db: db}
 	return repo, func() { db.Close() }
 }
@@ -319,8 +225,9 @@ func TestSqliteRouteRepository_UpdateById(t *testing.T) {
 
 	t.Run("Update existing route", func(t *testing.T) {
 		updatedRoute := &models.Route{
-			ID:     route.ID,
-			Number: "102",
+			ID:      route.ID,
+			Number:  "102",
+			Version: 1,
 		}
 
 		err := repo.UpdateById(updatedRoute)
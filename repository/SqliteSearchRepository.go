@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"busManager/models"
+	"busManager/plates"
+	"strings"
+	"unicode"
+)
+
+// SqliteSearchRepository searches the search_documents table, which triggers
+// keep in sync with the entities, through the FTS5 index search_index of
+// migration 0018.
+type SqliteSearchRepository struct {
+	db Executor
+}
+
+func NewSqliteSearchRepository(db Executor) *SqliteSearchRepository {
+	return &SqliteSearchRepository{db: db}
+}
+
+// searchTerms splits query into lower case words, folding ё into е.
+func searchTerms(query string) []string {
+	query = strings.NewReplacer("ё", "е", "Ё", "е").Replace(strings.ToLower(query))
+	return strings.FieldsFunc(query, func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+}
+
+// plateTerm returns s normalized like a stored register number when it
+// reads as a plate or the start of one: plate letters, Latin or Cyrillic,
+// and at least one digit.
+func plateTerm(s string) (string, bool) {
+	n := plates.Normalize(s)
+	letters, digits := 0, 0
+	for _, c := range n {
+		switch {
+		case unicode.IsDigit(c):
+			digits++
+		case strings.ContainsRune("АВЕКМНОРСТУХ", c):
+			letters++
+		default:
+			return "", false
+		}
+	}
+	return strings.ToLower(n), letters > 0 && digits > 0
+}
+
+// queryTerms returns the words to look for. A query that reads as a plate
+// is one word however it is spaced, so "а 123" and "A123AA 77" find
+// А123АА77; other words that read as plates are normalized alone.
+func queryTerms(query string) []string {
+	if term, ok := plateTerm(query); ok {
+		return []string{term}
+	}
+	terms := searchTerms(query)
+	for i, term := range terms {
+		if plate, ok := plateTerm(term); ok {
+			terms[i] = plate
+		}
+	}
+	return terms
+}
+
+func (r *SqliteSearchRepository) Search(query string, limit int) ([]models.SearchHit, error) {
+	terms := queryTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	// every term is a prefix; plain words need no quoting
+	match := strings.Join(terms, "* ") + "*"
+	rows, err := r.db.Query(`
+		SELECT d.entity_type, d.entity_id, d.title, d.body
+		FROM search_index
+		JOIN search_documents d ON d.id = search_index.rowid
+		WHERE search_index MATCH $1
+		ORDER BY bm25(search_index, 10.0, 1.0), d.title
+		LIMIT $2`, match, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var hits []models.SearchHit
+	for rows.Next() {
+		hit := models.SearchHit{}
+		if err := rows.Scan(&hit.Type, &hit.ID, &hit.Title, &hit.Subtitle); err != nil {
+			return nil, err
+		}
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
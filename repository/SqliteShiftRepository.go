@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+type SqliteShiftRepository struct {
+	db Executor
+}
+
+func NewSqliteShiftRepository(db Executor) *SqliteShiftRepository {
+	return &SqliteShiftRepository{db: db}
+}
+
+func (r *SqliteShiftRepository) Add(shift *models.Shift) error {
+	var count int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM drivers WHERE id = $1`, shift.DriverID).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		return apperrors.NotFound(models.EntityDriver, "Driver not found")
+	}
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM routes WHERE id = $1`, shift.RouteID).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		return apperrors.NotFound(models.EntityRoute, "Route not found")
+	}
+	if strings.TrimSpace(shift.ID) == "" {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return err
+		}
+		shift.ID = id.String()
+	}
+	shift.Start = shift.Start.UTC()
+	shift.End = shift.End.UTC()
+	for i := range shift.Breaks {
+		shift.Breaks[i].Start = shift.Breaks[i].Start.UTC()
+		shift.Breaks[i].End = shift.Breaks[i].End.UTC()
+	}
+	_, err := r.db.Exec(`INSERT INTO shifts (id, driver_id, route_id, start_at, end_at, breaks)
+VALUES ($1, $2, $3, $4, $5, $6)`,
+		shift.ID,
+		shift.DriverID,
+		shift.RouteID,
+		shift.Start,
+		shift.End,
+		shift.Breaks,
+	)
+	if err != nil {
+		return constraintError(err, models.EntityShift, "Shift")
+	}
+	return nil
+}
+
+func (r *SqliteShiftRepository) GetById(id string) (*models.Shift, error) {
+	shifts, err := r.query(`WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(shifts) == 0 {
+		return nil, apperrors.NotFound(models.EntityShift, "Shift not found")
+	}
+	return &shifts[0], nil
+}
+
+func (r *SqliteShiftRepository) GetAllByDriverId(driverId string, from, to time.Time) ([]models.Shift, error) {
+	return r.query(`WHERE driver_id = $1 AND end_at > $2 AND start_at < $3`, driverId, from.UTC(), to.UTC())
+}
+
+func (r *SqliteShiftRepository) DeleteById(id string) error {
+	res, err := r.db.Exec(`DELETE FROM shifts WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return apperrors.NotFound(models.EntityShift, "Shift not found")
+	}
+	return nil
+}
+
+// query selects the shifts matching where, ordered by start.
+func (r *SqliteShiftRepository) query(where string, args ...any) ([]models.Shift, error) {
+	rows, err := r.db.Query(`
+		SELECT id, driver_id, COALESCE(route_id, ''), start_at, end_at, breaks
+		FROM shifts
+		`+where+`
+		ORDER BY start_at, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	shifts := []models.Shift{}
+	for rows.Next() {
+		shift := models.Shift{}
+		err := rows.Scan(
+			&shift.ID,
+			&shift.DriverID,
+			&shift.RouteID,
+			&shift.Start,
+			&shift.End,
+			&shift.Breaks,
+		)
+		if err != nil {
+			return nil, err
+		}
+		shifts = append(shifts, shift)
+	}
+	return shifts, rows.Err()
+}
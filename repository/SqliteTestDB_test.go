@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"busManager/migrations"
+	"database/sql"
+	_ "github.com/mattn/go-sqlite3"
+	"testing"
+)
+
+// openTestDB returns an in-memory database migrated to the current schema.
+func openTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	// every connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+	m, err := migrations.NewMigrator(db)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+// openTestDBWithForeignKeys is openTestDB with foreign key enforcement, as
+// configured by database.Open.
+func openTestDBWithForeignKeys(t *testing.T) *sql.DB {
+	db := openTestDB(t)
+	if _, err := db.Exec(`PRAGMA foreign_keys = ON`); err != nil {
+		t.Fatalf("Failed to enable foreign keys: %v", err)
+	}
+	return db
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"busManager/models"
+	"time"
+)
+
+// routeLink describes a route junction table.
+type routeLink struct {
+	entityType  string
+	entityTable string
+	table       string
+	column      string
+}
+
+var routeLinks = []routeLink{
+	{models.EntityBus, "buses", "routes_buses", "bus_id"},
+	{models.EntityDriver, "drivers", "routes_drivers", "driver_id"},
+	{models.EntityBusStop, "bus_stops", "routes_bus_stops", "bus_stop_id"},
+}
+
+func routeLinkOf(entityType string) routeLink {
+	for _, link := range routeLinks {
+		if link.entityType == entityType {
+			return link
+		}
+	}
+	panic("unknown route link " + entityType)
+}
+
+// trashEntity marks a bus, driver or bus stop as deleted and moves its route
+// assignments into trash_route_links.
+func trashEntity(db Executor, entityType, id string) error {
+	link := routeLinkOf(entityType)
+	_, err := db.Exec(`UPDATE `+link.entityTable+` SET deleted_at = $1 WHERE id = $2`, time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`INSERT OR IGNORE INTO trash_route_links (owner_type, owner_id, route_id, entity_type, entity_id)
+		SELECT $1, $2, route_id, $1, $2 FROM `+link.table+` WHERE `+link.column+` = $2`, entityType, id)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`DELETE FROM `+link.table+` WHERE `+link.column+` = $1`, id)
+	return err
+}
+
+// trashRoute marks a route as deleted and moves all its assignments into
+// trash_route_links.
+func trashRoute(db Executor, id string) error {
+	_, err := db.Exec(`UPDATE routes SET deleted_at = $1 WHERE id = $2`, time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+	for _, link := range routeLinks {
+		_, err = db.Exec(`INSERT OR IGNORE INTO trash_route_links (owner_type, owner_id, route_id, entity_type, entity_id)
+			SELECT $1, $2, route_id, $3, `+link.column+` FROM `+link.table+` WHERE route_id = $2`, models.EntityRoute, id, link.entityType)
+		if err != nil {
+			return err
+		}
+		if _, err = db.Exec(`DELETE FROM `+link.table+` WHERE route_id = $1`, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreTrashed clears deleted_at and re-creates the remembered assignments
+// whose route and entity are both live.
+func restoreTrashed(db Executor, table, ownerType, id string) error {
+	_, err := db.Exec(`UPDATE `+table+` SET deleted_at = NULL WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	for _, link := range routeLinks {
+		_, err = db.Exec(`INSERT OR IGNORE INTO `+link.table+` (route_id, `+link.column+`)
+			SELECT t.route_id, t.entity_id
+			FROM trash_route_links t
+			JOIN routes r ON r.id = t.route_id AND r.deleted_at IS NULL
+			JOIN `+link.entityTable+` e ON e.id = t.entity_id AND e.deleted_at IS NULL
+			WHERE t.owner_type = $1 AND t.owner_id = $2 AND t.entity_type = $3`, ownerType, id, link.entityType)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = db.Exec(`DELETE FROM trash_route_links WHERE owner_type = $1 AND owner_id = $2`, ownerType, id)
+	return err
+}
+
+// purgeTrashed permanently deletes a trashed row and every remembered
+// assignment that refers to it.
+func purgeTrashed(db Executor, table, entityType, id string) error {
+	_, err := db.Exec(`DELETE FROM `+table+` WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return err
+	}
+	if entityType == models.EntityRoute {
+		_, err = db.Exec(`DELETE FROM trash_route_links WHERE (owner_type = $1 AND owner_id = $2) OR route_id = $2`, entityType, id)
+		return err
+	}
+	_, err = db.Exec(`DELETE FROM trash_route_links WHERE (owner_type = $1 AND owner_id = $2) OR (entity_type = $1 AND entity_id = $2)`, entityType, id)
+	return err
+}
+
+// isTrashed reports whether id is in the trash of table.
+func isTrashed(db Executor, table, id string) (bool, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM `+table+` WHERE id = $1 AND deleted_at IS NOT NULL`, id).Scan(&count)
+	return count > 0, err
+}
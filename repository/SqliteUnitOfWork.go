@@ -0,0 +1,45 @@
+package repository
+
+import "database/sql"
+
+type SqliteUnitOfWork struct {
+	db *sql.DB
+}
+
+func NewSqliteUnitOfWork(db *sql.DB) *SqliteUnitOfWork {
+	return &SqliteUnitOfWork{db: db}
+}
+
+// NewSqliteRepositories builds all sqlite repositories on top of one executor.
+func NewSqliteRepositories(db Executor) Repositories {
+	return Repositories{
+		Buses:         NewSqliteBusRepository(db),
+		Drivers:       NewSqliteDriverRepository(db),
+		BusStops:      NewSqliteBusStopRepository(db),
+		Routes:        NewSqliteRouteRepository(db),
+		Audit:         NewSqliteAuditRepository(db),
+		MedicalChecks: NewSqliteMedicalCheckRepository(db),
+		Shifts:        NewSqliteShiftRepository(db),
+		Maintenance:   NewSqliteMaintenanceRepository(db),
+		WorkOrders:    NewSqliteWorkOrderRepository(db),
+		Mileage:       NewSqliteMileageRepository(db),
+	}
+}
+
+func (u *SqliteUnitOfWork) Do(fn func(repos Repositories) error) error {
+	tx, err := u.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+	if err := fn(NewSqliteRepositories(tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"busManager/models"
+	"errors"
+	"testing"
+)
+
+func TestSqliteUnitOfWork_Do(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+	uow := NewSqliteUnitOfWork(db)
+	repos := NewSqliteRepositories(db)
+
+	t.Run("Commit on success", func(t *testing.T) {
+		err := uow.Do(func(tx Repositories) error {
+			if err := tx.Routes.Add(&models.Route{Number: "101"}); err != nil {
+				return err
+			}
+			return tx.Routes.Add(&models.Route{Number: "102"})
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		routes, err := repos.Routes.GetAll()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(routes) != 2 {
+			t.Errorf("Expected 2 routes, got %d", len(routes))
+		}
+	})
+
+	t.Run("Rollback on error", func(t *testing.T) {
+		err := uow.Do(func(tx Repositories) error {
+			if err := tx.Routes.Add(&models.Route{Number: "103"}); err != nil {
+				return err
+			}
+			return errors.New("Database error")
+		})
+		if err == nil || err.Error() != "Database error" {
+			t.Errorf("Expected 'Database error', got %v", err)
+		}
+		_, err = repos.Routes.GetByNumber("103")
+		if err == nil || err.Error() != "Route not found" {
+			t.Errorf("Expected 'Route not found' error, got %v", err)
+		}
+	})
+
+	t.Run("Rollback on panic", func(t *testing.T) {
+		func() {
+			defer func() { recover() }()
+			uow.Do(func(tx Repositories) error {
+				tx.Routes.Add(&models.Route{Number: "104"})
+				panic("boom")
+			})
+		}()
+		_, err := repos.Routes.GetByNumber("104")
+		if err == nil || err.Error() != "Route not found" {
+			t.Errorf("Expected 'Route not found' error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"database/sql"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+type SqliteWorkOrderRepository struct {
+	db Executor
+}
+
+func NewSqliteWorkOrderRepository(db Executor) *SqliteWorkOrderRepository {
+	return &SqliteWorkOrderRepository{db: db}
+}
+
+// closedAt stores a zero ClosedAt as NULL.
+func closedAt(order *models.WorkOrder) sql.NullTime {
+	return sql.NullTime{Time: order.ClosedAt.UTC(), Valid: !order.ClosedAt.IsZero()}
+}
+
+func (r *SqliteWorkOrderRepository) Add(order *models.WorkOrder) error {
+	var buses int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM buses WHERE id = $1`, order.BusID).Scan(&buses); err != nil {
+		return err
+	}
+	if buses == 0 {
+		return apperrors.NotFound(models.EntityBus, "Bus not found")
+	}
+	if strings.TrimSpace(order.ID) == "" {
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return err
+		}
+		order.ID = id.String()
+	}
+	order.OpenedAt = order.OpenedAt.UTC()
+	order.Version = 1
+	order.UpdatedAt = time.Now().UTC()
+	_, err := r.db.Exec(`INSERT INTO work_orders (id, bus_id, status, opened_at, closed_at, defect, mechanic, parts, labour_hours, labour_cost, cost, version, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		order.ID,
+		order.BusID,
+		order.Status,
+		order.OpenedAt,
+		closedAt(order),
+		order.Defect,
+		order.Mechanic,
+		order.Parts,
+		order.LabourHours,
+		order.LabourCost,
+		order.Cost,
+		order.Version,
+		order.UpdatedAt,
+	)
+	if err != nil {
+		return constraintError(err, models.EntityWorkOrder, "Work order")
+	}
+	return nil
+}
+
+func (r *SqliteWorkOrderRepository) GetById(id string) (*models.WorkOrder, error) {
+	orders, err := r.query(`WHERE id = $1`, ``, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, apperrors.NotFound(models.EntityWorkOrder, "Work order not found")
+	}
+	return &orders[0], nil
+}
+
+func (r *SqliteWorkOrderRepository) GetAllByBusId(busId string) ([]models.WorkOrder, error) {
+	return r.query(`WHERE bus_id = $1`, `opened_at DESC, rowid DESC`, busId)
+}
+
+func (r *SqliteWorkOrderRepository) GetAllActive() ([]models.WorkOrder, error) {
+	return r.query(`WHERE status IN ($1, $2)`, `opened_at, rowid`, models.WorkOrderOpen, models.WorkOrderInProgress)
+}
+
+func (r *SqliteWorkOrderRepository) GetAllClosedBetween(from, to time.Time) ([]models.WorkOrder, error) {
+	return r.query(`WHERE status = $1 AND closed_at >= $2 AND closed_at < $3`, `closed_at, rowid`,
+		models.WorkOrderClosed, from.UTC(), to.UTC())
+}
+
+func (r *SqliteWorkOrderRepository) UpdateById(order *models.WorkOrder) error {
+	exist, err := r.GetById(order.ID)
+	if err != nil {
+		return err
+	}
+	if exist.Version != order.Version {
+		return apperrors.Conflict(models.EntityWorkOrder, "Work order was changed by someone else", exist)
+	}
+	updatedAt := time.Now().UTC()
+	res, err := r.db.Exec(`UPDATE work_orders SET status = $1, closed_at = $2, defect = $3, mechanic = $4, parts = $5,
+labour_hours = $6, labour_cost = $7, cost = $8, version = version + 1, updated_at = $9
+WHERE id = $10 AND version = $11`,
+		order.Status,
+		closedAt(order),
+		order.Defect,
+		order.Mechanic,
+		order.Parts,
+		order.LabourHours,
+		order.LabourCost,
+		order.Cost,
+		updatedAt,
+		order.ID,
+		order.Version,
+	)
+	if err != nil {
+		return constraintError(err, models.EntityWorkOrder, "Work order")
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		current, _ := r.GetById(order.ID)
+		return apperrors.Conflict(models.EntityWorkOrder, "Work order was changed by someone else", current)
+	}
+	order.ClosedAt = order.ClosedAt.UTC()
+	order.Version++
+	order.UpdatedAt = updatedAt
+	return nil
+}
+
+// query selects the orders matching where in the given order.
+func (r *SqliteWorkOrderRepository) query(where, orderBy string, args ...any) ([]models.WorkOrder, error) {
+	if orderBy != "" {
+		orderBy = "ORDER BY " + orderBy
+	}
+	rows, err := r.db.Query(`
+		SELECT id, COALESCE(bus_id, ''), status, opened_at, closed_at, defect, mechanic, parts, labour_hours, labour_cost, cost, version, updated_at
+		FROM work_orders
+		`+where+`
+		`+orderBy, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	orders := []models.WorkOrder{}
+	for rows.Next() {
+		order := models.WorkOrder{}
+		var closed sql.NullTime
+		err := rows.Scan(
+			&order.ID,
+			&order.BusID,
+			&order.Status,
+			&order.OpenedAt,
+			&closed,
+			&order.Defect,
+			&order.Mechanic,
+			&order.Parts,
+			&order.LabourHours,
+			&order.LabourCost,
+			&order.Cost,
+			&order.Version,
+			&order.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		order.ClosedAt = closed.Time
+		orders = append(orders, order)
+	}
+	return orders, rows.Err()
+}
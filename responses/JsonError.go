@@ -1,13 +1,29 @@
 package responses
 
-import "encoding/json"
+import (
+	"busManager/apperrors"
+	"errors"
+)
 
+// JsonError is the error of a failed call. Code is one of the apperrors
+// codes; Entity, Fields and Details are set when the error carries them.
 type JsonError struct {
-	Error string
+	Error   string
+	Code    string
+	Entity  string            `json:",omitempty"`
+	Fields  map[string]string `json:",omitempty"`
+	Details any               `json:",omitempty"`
 }
 
-func NewJsonError(err error) string {
-	jsonError := &JsonError{err.Error()}
-	data, _ := json.MarshalIndent(jsonError, "", "    ")
-	return string(data)
+// FormatError is the Wails error formatter: a failed call of a typed router
+// method rejects its promise with the JsonError of the error.
+func FormatError(err error) any {
+	jsonError := &JsonError{Error: err.Error(), Code: apperrors.CodeOf(err)}
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) {
+		jsonError.Entity = appErr.Entity
+		jsonError.Fields = appErr.Fields
+		jsonError.Details = appErr.Details
+	}
+	return jsonError
 }
@@ -0,0 +1,49 @@
+package routers
+
+import (
+	"busManager/controller"
+	"busManager/models"
+	"busManager/service"
+	"context"
+	"time"
+)
+
+type AuditRouter struct {
+	ctx             context.Context
+	AuditController controller.AuditController
+}
+
+func NewAuditRouter(backend *Backend) (*AuditRouter, error) {
+	router := &AuditRouter{}
+	service := service.NewAuditService(backend.Repos.Audit)
+	router.AuditController = *controller.NewAuditController(*service)
+	return router, nil
+}
+
+func (a *AuditRouter) Startup(ctx context.Context) {
+	a.ctx = ctx
+}
+
+// Query returns the entries matching any of EntityType, EntityId, RouteId,
+// User, Action, From, To and Limit, newest first.
+func (a *AuditRouter) Query(filter models.AuditFilter) ([]models.AuditEntry, error) {
+	return a.AuditController.Query(filter)
+}
+
+// GetByEntity returns the history of an entity; entityType is one of "bus",
+// "driver", "bus_stop", "route", "medical_check", "shift",
+// "maintenance_interval", "maintenance", "work_order", "odometer_reading"
+// or "operated_trips".
+func (a *AuditRouter) GetByEntity(entityType, id string) ([]models.AuditEntry, error) {
+	return a.AuditController.GetByEntity(entityType, id)
+}
+
+func (a *AuditRouter) GetByRoute(routeId string) ([]models.AuditEntry, error) {
+	return a.AuditController.GetByRoute(routeId)
+}
+
+// GetByTimeRange returns the entries between from and to; a zero bound is
+// open.
+func (a *AuditRouter) GetByTimeRange(from, to time.Time) ([]models.AuditEntry, error) {
+	return a.AuditController.GetByTimeRange(from, to)
+}
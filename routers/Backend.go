@@ -0,0 +1,53 @@
+package routers
+
+import (
+	"busManager/config"
+	"busManager/database"
+	"busManager/repository"
+)
+
+// Backend is the storage shared by all routers: one set of repositories on a
+// single connection plus the unit of work for multi-step operations.
+type Backend struct {
+	Repos      repository.Repositories
+	UnitOfWork repository.IUnitOfWork
+	Backups    repository.IBackupRepository // nil when the storage cannot be backed up
+	Search     repository.ISearchRepository
+	Config     *config.Config
+	close      func() error
+}
+
+func NewSqliteBackend(db *database.Database, cfg *config.Config) *Backend {
+	return &Backend{
+		Repos:      repository.NewSqliteRepositories(db.DB()),
+		UnitOfWork: repository.NewSqliteUnitOfWork(db.DB()),
+		Backups:    repository.NewSqliteBackupRepository(db.DB()),
+		Search:     repository.NewSqliteSearchRepository(db.DB()),
+		Config:     cfg,
+		close:      db.Close,
+	}
+}
+
+// NewMemoryBackend keeps everything in memory and seeds it with sample data.
+// Nothing is persisted; it is used for the demo mode.
+func NewMemoryBackend(cfg *config.Config) (*Backend, error) {
+	store := repository.NewMemoryStore()
+	backend := &Backend{
+		Repos:      store.Repositories(),
+		UnitOfWork: repository.NewMemoryUnitOfWork(store),
+		Search:     repository.NewMemorySearchRepository(store),
+		Config:     cfg,
+	}
+	if err := seedDemoData(backend.Repos); err != nil {
+		return nil, err
+	}
+	return backend, nil
+}
+
+// Close releases the storage; it is safe to call more than once.
+func (b *Backend) Close() error {
+	if b.close == nil {
+		return nil
+	}
+	return b.close()
+}
@@ -0,0 +1,90 @@
+package routers
+
+import (
+	"busManager/apperrors"
+	"busManager/config"
+	"busManager/models"
+	"busManager/responses"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewMemoryBackend(t *testing.T) {
+	backend, err := NewMemoryBackend(config.Default())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer backend.Close()
+
+	routes, err := backend.Repos.Routes.GetAll()
+	if err != nil || len(routes) == 0 {
+		t.Fatalf("Expected demo routes, got %d (%v)", len(routes), err)
+	}
+	buses, err := backend.Repos.Routes.GetAllBusesById(routes[0].ID)
+	if err != nil || len(buses) == 0 {
+		t.Errorf("Expected buses on demo route, got %d (%v)", len(buses), err)
+	}
+
+	for _, build := range []func(*Backend) error{
+		func(b *Backend) error { _, err := NewBusRouter(b); return err },
+		func(b *Backend) error { _, err := NewDriverRouter(b); return err },
+		func(b *Backend) error { _, err := NewBusStopRouter(b); return err },
+		func(b *Backend) error { _, err := NewRouteRouter(b); return err },
+		func(b *Backend) error { _, err := NewBackupRouter(b); return err },
+		func(b *Backend) error { _, err := NewAuditRouter(b); return err },
+	} {
+		if err := build(backend); err != nil {
+			t.Errorf("Expected router to build on demo backend, got %v", err)
+		}
+	}
+}
+
+func TestBackupRouter_Demo(t *testing.T) {
+	backend, err := NewMemoryBackend(config.Default())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	router, err := NewBackupRouter(backend)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := router.ListBackups(); err == nil || !strings.Contains(err.Error(), "not available in demo mode") {
+		t.Errorf("Expected demo mode error, got %v", err)
+	}
+}
+
+func TestBusRouter_Typed(t *testing.T) {
+	backend, err := NewMemoryBackend(config.Default())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	router, err := NewBusRouter(backend)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	bus, err := router.Add(models.Bus{Brand: "ЛиАЗ", BusModel: "5292", RegisterNumber: "ВС 321 77",
+		AssemblyDate: time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC), LastRepairDate: time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if bus.ID == "" || bus.RegisterNumber != "ВС32177" {
+		t.Errorf("Expected stored bus with ID and canonical number, got %+v", bus)
+	}
+	page, err := router.List(models.ListQuery{Limit: 2})
+	if err != nil || len(page.Items) != 2 || page.Total != 4 {
+		t.Errorf("Expected 2 of 4 buses, got %+v (%v)", page, err)
+	}
+	if err := router.DeleteById(bus.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	_, err = router.GetById(bus.ID)
+	if !errors.Is(err, apperrors.ErrNotFound) {
+		t.Fatalf("Expected not found error, got %v", err)
+	}
+	if formatted := responses.FormatError(err).(*responses.JsonError); formatted.Code != apperrors.CodeNotFound || formatted.Entity != models.EntityBus {
+		t.Errorf("Expected formatted not found error for bus, got %+v", formatted)
+	}
+}
@@ -0,0 +1,67 @@
+package routers
+
+import (
+	"busManager/controller"
+	"busManager/models"
+	"busManager/service"
+	"context"
+	"errors"
+	"time"
+)
+
+var errBackupsUnavailable = errors.New("Backups are not available in demo mode")
+
+type BackupRouter struct {
+	ctx              context.Context
+	BackupController *controller.BackupController
+	service          *service.BackupService
+	interval         time.Duration
+}
+
+// NewBackupRouter returns a router whose methods report an error when the
+// backend cannot be backed up, e.g. the in-memory demo store.
+func NewBackupRouter(backend *Backend) (*BackupRouter, error) {
+	router := &BackupRouter{}
+	if backend.Backups == nil {
+		return router, nil
+	}
+	interval, err := backend.Config.BackupInterval()
+	if err != nil {
+		return nil, err
+	}
+	router.interval = interval
+	router.service = service.NewBackupService(backend.Backups, backend.Config.BackupDir(), backend.Config.Backup.Keep)
+	router.BackupController = controller.NewBackupController(*router.service)
+	return router, nil
+}
+
+// Startup starts the backup schedule, which stops with ctx.
+func (a *BackupRouter) Startup(ctx context.Context) {
+	a.ctx = ctx
+	if a.service != nil && a.interval > 0 {
+		go a.service.Run(ctx, a.interval)
+	}
+}
+
+func (a *BackupRouter) CreateBackup() (*models.Backup, error) {
+	if a.BackupController == nil {
+		return nil, errBackupsUnavailable
+	}
+	return a.BackupController.Create()
+}
+
+func (a *BackupRouter) ListBackups() ([]models.Backup, error) {
+	if a.BackupController == nil {
+		return nil, errBackupsUnavailable
+	}
+	return a.BackupController.List()
+}
+
+// RestoreBackup replaces the live database with the named backup. The
+// current data is backed up first.
+func (a *BackupRouter) RestoreBackup(name string) error {
+	if a.BackupController == nil {
+		return errBackupsUnavailable
+	}
+	return a.BackupController.Restore(name)
+}
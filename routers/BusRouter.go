@@ -2,24 +2,36 @@ package routers
 
 import (
 	"busManager/controller"
-	"busManager/repository"
+	"busManager/models"
 	"busManager/service"
 	"context"
 )
 
 type BusRouter struct {
-	ctx           context.Context
-	BusController controller.BusController
+	ctx                   context.Context
+	BusController         controller.BusController
+	MaintenanceController controller.MaintenanceController
+	MileageController     controller.MileageController
 }
 
-func NewBusRouter() (*BusRouter, error) {
+func NewBusRouter(backend *Backend) (*BusRouter, error) {
 	router := &BusRouter{}
-	repo, err := repository.NewSqliteBusRepository("db.db")
+	repo := backend.Repos.Buses
+	policy, err := service.ParseDeletePolicy(backend.Config.DeletePolicies.Bus)
 	if err != nil {
 		return nil, err
 	}
-	service := service.NewBusService(repo)
+	service := service.NewBusService(repo).
+		WithUnitOfWork(backend.UnitOfWork).
+		WithDeletePolicy(policy).
+		WithAudit(backend.Repos.Audit, backend.Config.AuditUser())
 	router.BusController = *controller.NewBusController(*service)
+	router.MaintenanceController = *controller.NewMaintenanceController(*newMaintenanceService(backend))
+	mileage, err := newMileageService(backend)
+	if err != nil {
+		return nil, err
+	}
+	router.MileageController = *controller.NewMileageController(*mileage)
 	return router, nil
 }
 
@@ -27,31 +39,93 @@ func (a *BusRouter) Startup(ctx context.Context) {
 	a.ctx = ctx
 }
 
-func (a *BusRouter) GetById(id string) string {
-	data := a.BusController.GetById(id)
-	return data
+func (a *BusRouter) GetById(id string) (*models.Bus, error) {
+	return a.BusController.GetById(id)
 }
 
-func (a *BusRouter) GetByNumber(number string) string {
-	data := a.BusController.GetByNumber(number)
-	return data
+func (a *BusRouter) GetByNumber(number string) (*models.Bus, error) {
+	return a.BusController.GetByNumber(number)
 }
 
-func (a *BusRouter) GetAll() string {
-	data := a.BusController.GetAll()
-	return data
+func (a *BusRouter) GetAll() ([]models.Bus, error) {
+	return a.BusController.GetAll()
 }
 
-func (a *BusRouter) Add(busData string) string {
-	data := a.BusController.Add(busData)
-	return data
+// GetAllWithInvalidNumber lists the buses whose register number, kept from
+// before plates were checked, has to be corrected.
+func (a *BusRouter) GetAllWithInvalidNumber() ([]models.Bus, error) {
+	return a.BusController.GetAllWithInvalidNumber()
 }
 
-func (a *BusRouter) DeleteById(id string) string {
+// Add returns the stored bus with its generated ID.
+func (a *BusRouter) Add(bus models.Bus) (*models.Bus, error) {
+	return a.BusController.Add(bus)
+}
 
+func (a *BusRouter) DeleteById(id string) error {
 	return a.BusController.DeleteById(id)
 }
 
-func (a *BusRouter) UpdateById(busData string) string {
-	return a.BusController.UpdateById(busData)
+// List returns a page of buses; a zero query lists the first page.
+func (a *BusRouter) List(query models.ListQuery) (models.Page[models.Bus], error) {
+	return a.BusController.List(query)
+}
+
+// GetAllDeleted lists the trash.
+func (a *BusRouter) GetAllDeleted() ([]models.Trashed[models.Bus], error) {
+	return a.BusController.GetAllDeleted()
+}
+
+// RestoreById takes an entry out of the trash, with its route assignments.
+func (a *BusRouter) RestoreById(id string) error {
+	return a.BusController.RestoreById(id)
+}
+
+// PurgeById permanently deletes an entry in the trash.
+func (a *BusRouter) PurgeById(id string) error {
+	return a.BusController.PurgeById(id)
+}
+
+// DeleteByIdWithPolicy deletes with an explicit policy: "restrict" or "cascade".
+func (a *BusRouter) DeleteByIdWithPolicy(id, policy string) error {
+	return a.BusController.DeleteByIdWithPolicy(id, policy)
+}
+
+func (a *BusRouter) GetAllRoutesById(id string) ([]models.Route, error) {
+	return a.BusController.GetAllRoutesById(id)
+}
+
+// UpdateById returns the stored entity with its new version.
+func (a *BusRouter) UpdateById(bus models.Bus) (*models.Bus, error) {
+	return a.BusController.UpdateById(bus)
+}
+
+// ChangeStatus moves the bus to status: "active", "reserve",
+// "awaiting_inspection" or "decommissioned"; "in_repair" is set and cleared
+// by work orders. reason is required and kept in the status history.
+func (a *BusRouter) ChangeStatus(id, status, reason string) (*models.Bus, error) {
+	return a.BusController.ChangeStatus(id, status, reason)
+}
+
+// GetStatusHistoryById returns the status changes of the bus, oldest first.
+func (a *BusRouter) GetStatusHistoryById(id string) ([]models.BusStatusChange, error) {
+	return a.BusController.GetStatusHistoryById(id)
+}
+
+// GetMaintenancePlanById returns the next service of each kind the bus
+// needs by the intervals of its brand and model.
+func (a *BusRouter) GetMaintenancePlanById(id string) ([]models.MaintenanceDue, error) {
+	return a.MaintenanceController.GetPlanByBusId(id)
+}
+
+// GetMaintenanceDue lists the services of all buses that are overdue or fall
+// due within days or kilometers, overdue ones first.
+func (a *BusRouter) GetMaintenanceDue(days, kilometers int) ([]models.MaintenanceDue, error) {
+	return a.MaintenanceController.GetDue(days, kilometers)
+}
+
+// GetMileageById returns the last odometer reading of the bus and the
+// mileage estimated from the trips it operated since.
+func (a *BusRouter) GetMileageById(id string) (*models.BusMileage, error) {
+	return a.MileageController.GetByBusId(id)
 }
@@ -2,7 +2,7 @@ package routers
 
 import (
 	"busManager/controller"
-	"busManager/repository"
+	"busManager/models"
 	"busManager/service"
 	"context"
 )
@@ -12,13 +12,17 @@ type BusStopRouter struct {
 	BusStopController controller.BusStopController
 }
 
-func NewBusStopRouter() (*BusStopRouter, error) {
+func NewBusStopRouter(backend *Backend) (*BusStopRouter, error) {
 	router := &BusStopRouter{}
-	repo, err := repository.NewSqliteBusStopRepository("db.db")
+	repo := backend.Repos.BusStops
+	policy, err := service.ParseDeletePolicy(backend.Config.DeletePolicies.BusStop)
 	if err != nil {
 		return nil, err
 	}
-	srv := service.NewBusStopService(repo)
+	srv := service.NewBusStopService(repo).
+		WithUnitOfWork(backend.UnitOfWork).
+		WithDeletePolicy(policy).
+		WithAudit(backend.Repos.Audit, backend.Config.AuditUser())
 	router.BusStopController = *controller.NewBusStopController(*srv)
 	return router, nil
 }
@@ -27,31 +31,57 @@ func (a *BusStopRouter) Startup(ctx context.Context) {
 	a.ctx = ctx
 }
 
-func (a *BusStopRouter) GetById(id string) string {
-	data := a.BusStopController.GetById(id)
-	return data
+func (a *BusStopRouter) GetById(id string) (*models.BusStop, error) {
+	return a.BusStopController.GetById(id)
 }
 
-func (a *BusStopRouter) GetByName(name string) string {
-	data := a.BusStopController.GetByName(name)
-	return data
+func (a *BusStopRouter) GetByName(name string) (*models.BusStop, error) {
+	return a.BusStopController.GetByName(name)
 }
 
-func (a *BusStopRouter) GetAll() string {
-	data := a.BusStopController.GetAll()
-	return data
+func (a *BusStopRouter) GetAll() ([]models.BusStop, error) {
+	return a.BusStopController.GetAll()
 }
 
-func (a *BusStopRouter) Add(busStopData string) string {
-	data := a.BusStopController.Add(busStopData)
-	return data
+// Add returns the stored bus stop with its generated ID.
+func (a *BusStopRouter) Add(busStop models.BusStop) (*models.BusStop, error) {
+	return a.BusStopController.Add(busStop)
 }
 
-func (a *BusStopRouter) DeleteById(id string) string {
-
+func (a *BusStopRouter) DeleteById(id string) error {
 	return a.BusStopController.DeleteById(id)
 }
 
-func (a *BusStopRouter) UpdateById(busStopData string) string {
-	return a.BusStopController.UpdateById(busStopData)
+// List returns a page of bus stops; a zero query lists the first page.
+func (a *BusStopRouter) List(query models.ListQuery) (models.Page[models.BusStop], error) {
+	return a.BusStopController.List(query)
+}
+
+// GetAllDeleted lists the trash.
+func (a *BusStopRouter) GetAllDeleted() ([]models.Trashed[models.BusStop], error) {
+	return a.BusStopController.GetAllDeleted()
+}
+
+// RestoreById takes an entry out of the trash, with its route assignments.
+func (a *BusStopRouter) RestoreById(id string) error {
+	return a.BusStopController.RestoreById(id)
+}
+
+// PurgeById permanently deletes an entry in the trash.
+func (a *BusStopRouter) PurgeById(id string) error {
+	return a.BusStopController.PurgeById(id)
+}
+
+// DeleteByIdWithPolicy deletes with an explicit policy: "restrict" or "cascade".
+func (a *BusStopRouter) DeleteByIdWithPolicy(id, policy string) error {
+	return a.BusStopController.DeleteByIdWithPolicy(id, policy)
+}
+
+func (a *BusStopRouter) GetAllRoutesById(id string) ([]models.Route, error) {
+	return a.BusStopController.GetAllRoutesById(id)
+}
+
+// UpdateById returns the stored entity with its new version.
+func (a *BusStopRouter) UpdateById(busStop models.BusStop) (*models.BusStop, error) {
+	return a.BusStopController.UpdateById(busStop)
 }
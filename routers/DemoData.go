@@ -0,0 +1,95 @@
+package routers
+
+import (
+	"busManager/models"
+	"busManager/repository"
+	"time"
+)
+
+func seedDemoData(repos repository.Repositories) error {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	buses := []models.Bus{
+		{Brand: "ЛиАЗ", BusModel: "5292", RegisterNumber: "АА12377", AssemblyDate: date(2018, 3, 1), LastRepairDate: date(2024, 5, 12),
+			SeatedCapacity: 25, StandingCapacity: 83, Class: models.BusClassLarge, Length: 12.4, FuelType: models.FuelDiesel, LowFloor: true, EcoClass: 5,
+			VIN: "XTY529222J0012345"},
+		{Brand: "ПАЗ", BusModel: "3205", RegisterNumber: "ВЕ45677", AssemblyDate: date(2015, 7, 20), LastRepairDate: date(2023, 11, 2), Status: models.BusReserve,
+			SeatedCapacity: 23, StandingCapacity: 18, Class: models.BusClassSmall, Length: 7, FuelType: models.FuelPetrol, EcoClass: 4},
+		{Brand: "МАЗ", BusModel: "203", RegisterNumber: "КМ78950", AssemblyDate: date(2020, 1, 15), LastRepairDate: date(2024, 9, 30),
+			SeatedCapacity: 28, StandingCapacity: 72, Class: models.BusClassLarge, Length: 12, FuelType: models.FuelDiesel, LowFloor: true, EcoClass: 5},
+	}
+	drivers := []models.Driver{
+		{Name: "Иван", Surname: "Петров", Patronymic: "Сергеевич", BirthDate: date(1980, 4, 11),
+			PassportSeries: "4510 123456", Snils: "112-233-445 95", LicenseSeries: "77 12 345678",
+			LicenseCategories: models.LicenseCategories{"B", "C", "D"}, LicenseIssueDate: date(2019, 6, 3), LicenseExpiryDate: date(2029, 6, 3)},
+		{Name: "Ольга", Surname: "Смирнова", Patronymic: "Андреевна", BirthDate: date(1987, 9, 2),
+			PassportSeries: "4512 654321", Snils: "123-456-789 64", LicenseSeries: "77 15 876543",
+			LicenseCategories: models.LicenseCategories{"B", "D"}, LicenseIssueDate: date(2021, 2, 17), LicenseExpiryDate: date(2031, 2, 17)},
+	}
+	busStops := []models.BusStop{
+		{Name: "Красная площадь", Lat: 55.7539, Long: 37.6208},
+		{Name: "Парк Горького", Lat: 55.7298, Long: 37.6011},
+		{Name: "Лужники", Lat: 55.7158, Long: 37.5537},
+	}
+	routes := []models.Route{{Number: "12", Length: 18.4, MinCapacity: 80, LowFloor: true}, {Number: "40", Length: 24.7}}
+	intervals := []models.MaintenanceInterval{
+		{Brand: "ЛиАЗ", BusModel: "5292", Kind: "ТО-1", Days: 90, Kilometers: 10000},
+		{Brand: "ЛиАЗ", BusModel: "5292", Kind: "ТО-2", Days: 365, Kilometers: 40000},
+		{Brand: "ПАЗ", BusModel: "3205", Kind: "ТО-1", Days: 60, Kilometers: 5000},
+		{Brand: "МАЗ", BusModel: "203", Kind: "ТО-1", Days: 90, Kilometers: 15000},
+	}
+	mileages := []int{152000, 310500, 87400}
+
+	for i := range buses {
+		if err := repos.Buses.Add(&buses[i]); err != nil {
+			return err
+		}
+	}
+	for i := range intervals {
+		if err := repos.Maintenance.AddInterval(&intervals[i]); err != nil {
+			return err
+		}
+	}
+	for i, bus := range buses {
+		record := models.MaintenanceRecord{BusID: bus.ID, Kind: "ТО-1", PerformedAt: bus.LastRepairDate, Mileage: mileages[i]}
+		if err := repos.Maintenance.AddRecord(&record); err != nil {
+			return err
+		}
+		reading := models.OdometerReading{BusID: bus.ID, ReadAt: bus.LastRepairDate, Kilometers: mileages[i]}
+		if err := repos.Mileage.AddReading(&reading); err != nil {
+			return err
+		}
+	}
+	for i := range drivers {
+		if err := repos.Drivers.Add(&drivers[i]); err != nil {
+			return err
+		}
+	}
+	for i := range busStops {
+		if err := repos.BusStops.Add(&busStops[i]); err != nil {
+			return err
+		}
+	}
+	for i := range routes {
+		if err := repos.Routes.Add(&routes[i]); err != nil {
+			return err
+		}
+	}
+	assignments := []func() error{
+		func() error { return repos.Routes.AssignBus(routes[0].ID, buses[0].ID) },
+		func() error { return repos.Routes.AssignBus(routes[1].ID, buses[1].ID) },
+		func() error { return repos.Routes.AssignDriver(routes[0].ID, drivers[0].ID) },
+		func() error { return repos.Routes.AssignDriver(routes[1].ID, drivers[1].ID) },
+		func() error { return repos.Routes.AssignBusStop(routes[0].ID, busStops[0].ID) },
+		func() error { return repos.Routes.AssignBusStop(routes[0].ID, busStops[1].ID) },
+		func() error { return repos.Routes.AssignBusStop(routes[1].ID, busStops[1].ID) },
+		func() error { return repos.Routes.AssignBusStop(routes[1].ID, busStops[2].ID) },
+	}
+	for _, assign := range assignments {
+		if err := assign(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
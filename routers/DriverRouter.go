@@ -2,7 +2,7 @@ package routers
 
 import (
 	"busManager/controller"
-	"busManager/repository"
+	"busManager/models"
 	"busManager/service"
 	"context"
 )
@@ -12,13 +12,17 @@ type DriverRouter struct {
 	DriverController controller.DriverController
 }
 
-func NewDriverRouter() (*DriverRouter, error) {
+func NewDriverRouter(backend *Backend) (*DriverRouter, error) {
 	router := &DriverRouter{}
-	repo, err := repository.NewSqliteDriverRepository("db.db")
+	repo := backend.Repos.Drivers
+	policy, err := service.ParseDeletePolicy(backend.Config.DeletePolicies.Driver)
 	if err != nil {
 		return nil, err
 	}
-	srv := service.NewDriverService(repo)
+	srv := service.NewDriverService(repo).
+		WithUnitOfWork(backend.UnitOfWork).
+		WithDeletePolicy(policy).
+		WithAudit(backend.Repos.Audit, backend.Config.AuditUser())
 	router.DriverController = *controller.NewDriverController(*srv)
 	return router, nil
 }
@@ -27,31 +31,73 @@ func (a *DriverRouter) Startup(ctx context.Context) {
 	a.ctx = ctx
 }
 
-func (a *DriverRouter) GetById(id string) string {
-	data := a.DriverController.GetById(id)
-	return data
+func (a *DriverRouter) GetById(id string) (*models.Driver, error) {
+	return a.DriverController.GetById(id)
 }
 
-func (a *DriverRouter) GetByPassportSeries(number string) string {
-	data := a.DriverController.GetByPassportSeries(number)
-	return data
+func (a *DriverRouter) GetByPassportSeries(series string) (*models.Driver, error) {
+	return a.DriverController.GetByPassportSeries(series)
 }
 
-func (a *DriverRouter) GetAll() string {
-	data := a.DriverController.GetAll()
-	return data
+func (a *DriverRouter) GetBySnils(snils string) (*models.Driver, error) {
+	return a.DriverController.GetBySnils(snils)
 }
 
-func (a *DriverRouter) Add(driverData string) string {
-	data := a.DriverController.Add(driverData)
-	return data
+func (a *DriverRouter) GetAll() ([]models.Driver, error) {
+	return a.DriverController.GetAll()
 }
 
-func (a *DriverRouter) DeleteById(id string) string {
+// GetAllWithInvalidDocuments lists the drivers whose document numbers, kept
+// from before they were checked, have to be corrected.
+func (a *DriverRouter) GetAllWithInvalidDocuments() ([]models.Driver, error) {
+	return a.DriverController.GetAllWithInvalidDocuments()
+}
+
+// Add returns the stored driver with its generated ID.
+func (a *DriverRouter) Add(driver models.Driver) (*models.Driver, error) {
+	return a.DriverController.Add(driver)
+}
 
+func (a *DriverRouter) DeleteById(id string) error {
 	return a.DriverController.DeleteById(id)
 }
 
-func (a *DriverRouter) UpdateById(driverData string) string {
-	return a.DriverController.UpdateById(driverData)
+// List returns a page of drivers; a zero query lists the first page.
+func (a *DriverRouter) List(query models.ListQuery) (models.Page[models.Driver], error) {
+	return a.DriverController.List(query)
+}
+
+// GetAllDeleted lists the trash.
+func (a *DriverRouter) GetAllDeleted() ([]models.Trashed[models.Driver], error) {
+	return a.DriverController.GetAllDeleted()
+}
+
+// RestoreById takes an entry out of the trash, with its route assignments.
+func (a *DriverRouter) RestoreById(id string) error {
+	return a.DriverController.RestoreById(id)
+}
+
+// PurgeById permanently deletes an entry in the trash.
+func (a *DriverRouter) PurgeById(id string) error {
+	return a.DriverController.PurgeById(id)
+}
+
+// DeleteByIdWithPolicy deletes with an explicit policy: "restrict" or "cascade".
+func (a *DriverRouter) DeleteByIdWithPolicy(id, policy string) error {
+	return a.DriverController.DeleteByIdWithPolicy(id, policy)
+}
+
+func (a *DriverRouter) GetAllRoutesById(id string) ([]models.Route, error) {
+	return a.DriverController.GetAllRoutesById(id)
+}
+
+// GetAllLicensesExpiringWithin returns the drivers whose licence expires
+// within days, including expired ones, soonest first.
+func (a *DriverRouter) GetAllLicensesExpiringWithin(days int) ([]models.Driver, error) {
+	return a.DriverController.GetAllLicensesExpiringWithin(days)
+}
+
+// UpdateById returns the stored entity with its new version.
+func (a *DriverRouter) UpdateById(driver models.Driver) (*models.Driver, error) {
+	return a.DriverController.UpdateById(driver)
 }
@@ -0,0 +1,60 @@
+package routers
+
+import (
+	"busManager/controller"
+	"busManager/models"
+	"busManager/service"
+	"context"
+)
+
+type MaintenanceRouter struct {
+	ctx                   context.Context
+	MaintenanceController controller.MaintenanceController
+}
+
+// newMaintenanceService is shared by the maintenance and bus routers.
+func newMaintenanceService(backend *Backend) *service.MaintenanceService {
+	return service.NewMaintenanceService(backend.Repos.Maintenance, backend.Repos.Buses).
+		WithMileage(backend.Repos.Mileage).
+		WithUnitOfWork(backend.UnitOfWork).
+		WithAudit(backend.Repos.Audit, backend.Config.AuditUser())
+}
+
+func NewMaintenanceRouter(backend *Backend) (*MaintenanceRouter, error) {
+	router := &MaintenanceRouter{}
+	router.MaintenanceController = *controller.NewMaintenanceController(*newMaintenanceService(backend))
+	return router, nil
+}
+
+func (a *MaintenanceRouter) Startup(ctx context.Context) {
+	a.ctx = ctx
+}
+
+// AddInterval returns the stored interval with its generated ID.
+func (a *MaintenanceRouter) AddInterval(interval models.MaintenanceInterval) (*models.MaintenanceInterval, error) {
+	return a.MaintenanceController.AddInterval(interval)
+}
+
+// GetAllIntervals returns the intervals sorted by brand, model and kind.
+func (a *MaintenanceRouter) GetAllIntervals() ([]models.MaintenanceInterval, error) {
+	return a.MaintenanceController.GetAllIntervals()
+}
+
+func (a *MaintenanceRouter) UpdateInterval(interval models.MaintenanceInterval) (*models.MaintenanceInterval, error) {
+	return a.MaintenanceController.UpdateInterval(interval)
+}
+
+func (a *MaintenanceRouter) DeleteIntervalById(id string) error {
+	return a.MaintenanceController.DeleteIntervalById(id)
+}
+
+// AddRecord returns the stored record with its generated ID. The
+// LastRepairDate of the bus moves forward to the record.
+func (a *MaintenanceRouter) AddRecord(record models.MaintenanceRecord) (*models.MaintenanceRecord, error) {
+	return a.MaintenanceController.AddRecord(record)
+}
+
+// GetAllRecordsByBusId returns the maintenance history of a bus, newest first.
+func (a *MaintenanceRouter) GetAllRecordsByBusId(busId string) ([]models.MaintenanceRecord, error) {
+	return a.MaintenanceController.GetAllRecordsByBusId(busId)
+}
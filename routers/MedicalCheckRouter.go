@@ -0,0 +1,61 @@
+package routers
+
+import (
+	"busManager/controller"
+	"busManager/models"
+	"busManager/service"
+	"context"
+	"time"
+)
+
+type MedicalCheckRouter struct {
+	ctx                    context.Context
+	MedicalCheckController controller.MedicalCheckController
+}
+
+func NewMedicalCheckRouter(backend *Backend) (*MedicalCheckRouter, error) {
+	router := &MedicalCheckRouter{}
+	loc, err := backend.Config.Location()
+	if err != nil {
+		return nil, err
+	}
+	srv := service.NewMedicalCheckService(backend.Repos.MedicalChecks, backend.Repos.Drivers).
+		WithUnitOfWork(backend.UnitOfWork).
+		WithAudit(backend.Repos.Audit, backend.Config.AuditUser()).
+		WithLocation(loc)
+	router.MedicalCheckController = *controller.NewMedicalCheckController(*srv)
+	return router, nil
+}
+
+func (a *MedicalCheckRouter) Startup(ctx context.Context) {
+	a.ctx = ctx
+}
+
+// Add returns the stored check with its generated ID.
+func (a *MedicalCheckRouter) Add(check models.MedicalCheck) (*models.MedicalCheck, error) {
+	return a.MedicalCheckController.Add(check)
+}
+
+func (a *MedicalCheckRouter) GetById(id string) (*models.MedicalCheck, error) {
+	return a.MedicalCheckController.GetById(id)
+}
+
+// GetAllByDriverId returns the checks of a driver, newest first.
+func (a *MedicalCheckRouter) GetAllByDriverId(driverId string) ([]models.MedicalCheck, error) {
+	return a.MedicalCheckController.GetAllByDriverId(driverId)
+}
+
+// IsClearedToday reports whether the last check of the driver today passed.
+func (a *MedicalCheckRouter) IsClearedToday(driverId string) (bool, error) {
+	return a.MedicalCheckController.IsClearedToday(driverId)
+}
+
+func (a *MedicalCheckRouter) GetClearedToday() ([]models.Driver, error) {
+	return a.MedicalCheckController.GetClearedToday()
+}
+
+// GetDailyReport lists the drivers assigned to routes who were not cleared
+// on day, today when day is zero.
+func (a *MedicalCheckRouter) GetDailyReport(day time.Time) ([]models.UnclearedDriver, error) {
+	return a.MedicalCheckController.GetDailyReport(day)
+}
@@ -0,0 +1,71 @@
+package routers
+
+import (
+	"busManager/controller"
+	"busManager/models"
+	"busManager/service"
+	"context"
+	"time"
+)
+
+type MileageRouter struct {
+	ctx               context.Context
+	MileageController controller.MileageController
+}
+
+// newMileageService is shared by the mileage and bus routers.
+func newMileageService(backend *Backend) (*service.MileageService, error) {
+	loc, err := backend.Config.Location()
+	if err != nil {
+		return nil, err
+	}
+	return service.NewMileageService(backend.Repos.Mileage, backend.Repos.Buses, backend.Repos.Routes).
+		WithUnitOfWork(backend.UnitOfWork).
+		WithAudit(backend.Repos.Audit, backend.Config.AuditUser()).
+		WithLocation(loc), nil
+}
+
+func NewMileageRouter(backend *Backend) (*MileageRouter, error) {
+	router := &MileageRouter{}
+	mileage, err := newMileageService(backend)
+	if err != nil {
+		return nil, err
+	}
+	router.MileageController = *controller.NewMileageController(*mileage)
+	return router, nil
+}
+
+func (a *MileageRouter) Startup(ctx context.Context) {
+	a.ctx = ctx
+}
+
+// AddReading returns the stored reading with its generated ID. Readings
+// must not go back against earlier ones or exceed later ones.
+func (a *MileageRouter) AddReading(reading models.OdometerReading) (*models.OdometerReading, error) {
+	return a.MileageController.AddReading(reading)
+}
+
+// GetAllReadingsByBusId returns the odometer readings of a bus, oldest first.
+func (a *MileageRouter) GetAllReadingsByBusId(busId string) ([]models.OdometerReading, error) {
+	return a.MileageController.GetAllReadingsByBusId(busId)
+}
+
+// AddTrips returns the stored trip record with its generated ID and its Day
+// truncated to the UTC date.
+func (a *MileageRouter) AddTrips(trips models.OperatedTrips) (*models.OperatedTrips, error) {
+	return a.MileageController.AddTrips(trips)
+}
+
+func (a *MileageRouter) GetAllTripsByBusId(busId string, from, to time.Time) ([]models.OperatedTrips, error) {
+	return a.MileageController.GetAllTripsByBusId(busId, from, to)
+}
+
+// GetDailyMileage returns the mileage of a bus per UTC day; days without
+// odometer readings around them are estimated from the operated trips.
+func (a *MileageRouter) GetDailyMileage(busId string, from, to time.Time) ([]models.Mileage, error) {
+	return a.MileageController.GetDailyMileage(busId, from, to)
+}
+
+func (a *MileageRouter) GetMonthlyMileage(busId string, from, to time.Time) ([]models.Mileage, error) {
+	return a.MileageController.GetMonthlyMileage(busId, from, to)
+}
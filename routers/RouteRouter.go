@@ -2,7 +2,7 @@ package routers
 
 import (
 	"busManager/controller"
-	"busManager/repository"
+	"busManager/models"
 	"busManager/service"
 	"context"
 )
@@ -12,94 +12,105 @@ type RouteRouter struct {
 	RouteController controller.RouteController
 }
 
-func NewRouteRouter() (*RouteRouter, error) {
+func NewRouteRouter(backend *Backend) (*RouteRouter, error) {
 	router := &RouteRouter{}
-	busRepo, err := repository.NewSqliteBusRepository("db.db")
-	if err != nil {
-		return nil, err
-	}
-	busStopRepo, err := repository.NewSqliteBusStopRepository("db.db")
-	if err != nil {
-		return nil, err
-	}
-	driverRepo, err := repository.NewSqliteDriverRepository("db.db")
-	if err != nil {
-		return nil, err
-	}
-	routeRepo, err := repository.NewSqliteRouteRepository("db.db")
-	if err != nil {
-		return nil, err
-	}
-	routeService := service.NewRouteService(routeRepo, driverRepo, busRepo, busStopRepo)
+	repos := backend.Repos
+	routeService := service.NewRouteService(repos.Routes, repos.Drivers, repos.Buses, repos.BusStops).
+		WithUnitOfWork(backend.UnitOfWork).
+		WithAudit(repos.Audit, backend.Config.AuditUser())
 	router.RouteController = *controller.NewRouteController(routeService)
-	return router, err
+	return router, nil
 }
 
 func (a *RouteRouter) Startup(ctx context.Context) {
 	a.ctx = ctx
 }
 
-func (a *RouteRouter) GetById(id string) string {
-	data := a.RouteController.GetById(id)
-	return data
+func (a *RouteRouter) GetById(id string) (*models.Route, error) {
+	return a.RouteController.GetById(id)
 }
 
-func (a *RouteRouter) GetByNumber(number string) string {
-	data := a.RouteController.GetByNumber(number)
-	return data
+func (a *RouteRouter) GetByNumber(number string) (*models.Route, error) {
+	return a.RouteController.GetByNumber(number)
 }
 
-func (a *RouteRouter) GetAll() string {
-	data := a.RouteController.GetAll()
-	return data
+func (a *RouteRouter) GetAll() ([]models.Route, error) {
+	return a.RouteController.GetAll()
 }
 
-func (a *RouteRouter) Add(routeData string) string {
-	data := a.RouteController.Add(routeData)
-	return data
+// Add returns the stored route with its generated ID.
+func (a *RouteRouter) Add(route models.Route) (*models.Route, error) {
+	return a.RouteController.Add(route)
 }
 
-func (a *RouteRouter) DeleteById(id string) string {
-
+func (a *RouteRouter) DeleteById(id string) error {
 	return a.RouteController.DeleteById(id)
 }
 
-func (a *RouteRouter) UpdateById(routeData string) string {
-	return a.RouteController.UpdateById(routeData)
+// List returns a page of routes; a zero query lists the first page.
+func (a *RouteRouter) List(query models.ListQuery) (models.Page[models.Route], error) {
+	return a.RouteController.List(query)
+}
+
+// GetAllDeleted lists the trash.
+func (a *RouteRouter) GetAllDeleted() ([]models.Trashed[models.Route], error) {
+	return a.RouteController.GetAllDeleted()
 }
 
-func (a *RouteRouter) AssignDriver(routeId, driverId string) string {
+// RestoreById takes an entry out of the trash, with its route assignments.
+func (a *RouteRouter) RestoreById(id string) error {
+	return a.RouteController.RestoreById(id)
+}
+
+// PurgeById permanently deletes an entry in the trash.
+func (a *RouteRouter) PurgeById(id string) error {
+	return a.RouteController.PurgeById(id)
+}
+
+// UpdateById returns the stored entity with its new version.
+func (a *RouteRouter) UpdateById(route models.Route) (*models.Route, error) {
+	return a.RouteController.UpdateById(route)
+}
+
+func (a *RouteRouter) AssignDriver(routeId, driverId string) error {
 	return a.RouteController.AssignDriver(routeId, driverId)
 }
 
-func (a *RouteRouter) AssignBusStop(routeId, busStopId string) string {
+func (a *RouteRouter) AssignBusStop(routeId, busStopId string) error {
 	return a.RouteController.AssignBusStop(routeId, busStopId)
 }
 
-func (a *RouteRouter) AssignBus(routeId, busId string) string {
+func (a *RouteRouter) AssignBus(routeId, busId string) error {
 	return a.RouteController.AssignBus(routeId, busId)
 }
 
-func (a *RouteRouter) UnassignDriver(routeId, driverId string) string {
+func (a *RouteRouter) UnassignDriver(routeId, driverId string) error {
 	return a.RouteController.UnassignDriver(routeId, driverId)
 }
 
-func (a *RouteRouter) UnassignBusStop(routeId, busStopId string) string {
+func (a *RouteRouter) UnassignBusStop(routeId, busStopId string) error {
 	return a.RouteController.UnassignBusStop(routeId, busStopId)
 }
 
-func (a *RouteRouter) UnassignBus(routeId, busId string) string {
+func (a *RouteRouter) UnassignBus(routeId, busId string) error {
 	return a.RouteController.UnassignBus(routeId, busId)
 }
 
-func (a *RouteRouter) GetAllDriversById(routeId string) string {
+func (a *RouteRouter) GetAllDriversById(routeId string) ([]models.Driver, error) {
 	return a.RouteController.GetAllDriversById(routeId)
 }
 
-func (a *RouteRouter) GetAllBusesById(routeId string) string {
+func (a *RouteRouter) GetAllBusesById(routeId string) ([]models.Bus, error) {
 	return a.RouteController.GetAllBusesById(routeId)
 }
 
-func (a *RouteRouter) GetAllBusStopsById(routeId string) string {
+// ListSuitableBuses returns a page of the buses that may be assigned to the
+// route: not in repair and meeting its capacity, class and low-floor
+// requirements. query filters them further like BusRouter.List.
+func (a *RouteRouter) ListSuitableBuses(routeId string, query models.ListQuery) (models.Page[models.Bus], error) {
+	return a.RouteController.ListSuitableBuses(routeId, query)
+}
+
+func (a *RouteRouter) GetAllBusStopsById(routeId string) ([]models.BusStop, error) {
 	return a.RouteController.GetAllBusStopsById(routeId)
 }
@@ -0,0 +1,30 @@
+package routers
+
+import (
+	"busManager/controller"
+	"busManager/models"
+	"busManager/service"
+	"context"
+)
+
+type SearchRouter struct {
+	ctx              context.Context
+	SearchController controller.SearchController
+}
+
+func NewSearchRouter(backend *Backend) (*SearchRouter, error) {
+	router := &SearchRouter{}
+	service := service.NewSearchService(backend.Search)
+	router.SearchController = *controller.NewSearchController(*service)
+	return router, nil
+}
+
+func (a *SearchRouter) Startup(ctx context.Context) {
+	a.ctx = ctx
+}
+
+// Search returns the buses, drivers, bus stops and routes matching the text
+// typed into the search box, best matches first.
+func (a *SearchRouter) Search(query string) ([]models.SearchHit, error) {
+	return a.SearchController.Search(query)
+}
@@ -0,0 +1,59 @@
+package routers
+
+import (
+	"busManager/controller"
+	"busManager/models"
+	"busManager/service"
+	"context"
+	"time"
+)
+
+type ShiftRouter struct {
+	ctx             context.Context
+	ShiftController controller.ShiftController
+}
+
+func NewShiftRouter(backend *Backend) (*ShiftRouter, error) {
+	router := &ShiftRouter{}
+	loc, err := backend.Config.Location()
+	if err != nil {
+		return nil, err
+	}
+	srv := service.NewShiftService(backend.Repos.Shifts, backend.Repos.Drivers, backend.Repos.Routes).
+		WithUnitOfWork(backend.UnitOfWork).
+		WithAudit(backend.Repos.Audit, backend.Config.AuditUser()).
+		WithLocation(loc)
+	router.ShiftController = *controller.NewShiftController(*srv)
+	return router, nil
+}
+
+func (a *ShiftRouter) Startup(ctx context.Context) {
+	a.ctx = ctx
+}
+
+// Add returns the stored shift with its generated ID. A shift that overlaps
+// another shift of the driver or breaks a working time limit is rejected
+// with a validation error listing the violations.
+func (a *ShiftRouter) Add(shift models.Shift) (*models.Shift, error) {
+	return a.ShiftController.Add(shift)
+}
+
+func (a *ShiftRouter) GetById(id string) (*models.Shift, error) {
+	return a.ShiftController.GetById(id)
+}
+
+// GetAllByDriverId returns the shifts of a driver overlapping from until to,
+// ordered by start.
+func (a *ShiftRouter) GetAllByDriverId(driverId string, from, to time.Time) ([]models.Shift, error) {
+	return a.ShiftController.GetAllByDriverId(driverId, from, to)
+}
+
+func (a *ShiftRouter) DeleteById(id string) error {
+	return a.ShiftController.DeleteById(id)
+}
+
+// GetComplianceReport returns the shifts of a driver starting from from
+// until to, their driving time and the working time violations at them.
+func (a *ShiftRouter) GetComplianceReport(driverId string, from, to time.Time) (*models.ComplianceReport, error) {
+	return a.ShiftController.GetComplianceReport(driverId, from, to)
+}
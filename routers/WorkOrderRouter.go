@@ -0,0 +1,77 @@
+package routers
+
+import (
+	"busManager/controller"
+	"busManager/models"
+	"busManager/service"
+	"context"
+	"time"
+)
+
+type WorkOrderRouter struct {
+	ctx                 context.Context
+	WorkOrderController controller.WorkOrderController
+}
+
+func NewWorkOrderRouter(backend *Backend) (*WorkOrderRouter, error) {
+	router := &WorkOrderRouter{}
+	srv := service.NewWorkOrderService(backend.Repos.WorkOrders, backend.Repos.Buses).
+		WithUnitOfWork(backend.UnitOfWork).
+		WithAudit(backend.Repos.Audit, backend.Config.AuditUser())
+	router.WorkOrderController = *controller.NewWorkOrderController(*srv)
+	return router, nil
+}
+
+func (a *WorkOrderRouter) Startup(ctx context.Context) {
+	a.ctx = ctx
+}
+
+// Open returns the stored order with its generated ID; the bus is in repair
+// until the order is closed or cancelled.
+func (a *WorkOrderRouter) Open(order models.WorkOrder) (*models.WorkOrder, error) {
+	return a.WorkOrderController.Open(order)
+}
+
+func (a *WorkOrderRouter) GetById(id string) (*models.WorkOrder, error) {
+	return a.WorkOrderController.GetById(id)
+}
+
+// GetAllByBusId returns the orders of a bus, latest opened first.
+func (a *WorkOrderRouter) GetAllByBusId(busId string) ([]models.WorkOrder, error) {
+	return a.WorkOrderController.GetAllByBusId(busId)
+}
+
+// GetAllActive returns the open and in progress orders, oldest first.
+func (a *WorkOrderRouter) GetAllActive() ([]models.WorkOrder, error) {
+	return a.WorkOrderController.GetAllActive()
+}
+
+// UpdateById saves the defect, mechanic, parts and labour of an active order
+// and returns it with its new version and cost.
+func (a *WorkOrderRouter) UpdateById(order models.WorkOrder) (*models.WorkOrder, error) {
+	return a.WorkOrderController.UpdateById(order)
+}
+
+func (a *WorkOrderRouter) Start(id string) (*models.WorkOrder, error) {
+	return a.WorkOrderController.Start(id)
+}
+
+func (a *WorkOrderRouter) Close(id string) (*models.WorkOrder, error) {
+	return a.WorkOrderController.Close(id)
+}
+
+func (a *WorkOrderRouter) Cancel(id string) (*models.WorkOrder, error) {
+	return a.WorkOrderController.Cancel(id)
+}
+
+// GetCostByBus sums up the orders closed in the period per bus, most
+// expensive first.
+func (a *WorkOrderRouter) GetCostByBus(from, to time.Time) ([]models.RepairCost, error) {
+	return a.WorkOrderController.GetCostByBus(from, to)
+}
+
+// GetCostByModel sums up the orders closed in the period per brand and
+// model, most expensive first.
+func (a *WorkOrderRouter) GetCostByModel(from, to time.Time) ([]models.RepairCost, error) {
+	return a.WorkOrderController.GetCostByModel(from, to)
+}
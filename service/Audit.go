@@ -0,0 +1,75 @@
+package service
+
+import (
+	"busManager/models"
+	"busManager/repository"
+	"encoding/json"
+	"time"
+)
+
+// auditor records changes made through a service. The zero value records
+// nothing, so services work unchanged when auditing is not configured.
+type auditor struct {
+	repo repository.IAuditRepository
+	user string
+	now  func() time.Time
+}
+
+func newAuditor(repo repository.IAuditRepository, user string) auditor {
+	return auditor{repo: repo, user: user, now: time.Now}
+}
+
+func (a auditor) enabled() bool {
+	return a.repo != nil
+}
+
+// record writes an entry through repos.Audit, which is bound to the caller's
+// transaction when a unit of work is used. before and after are stored as
+// JSON; pass an untyped nil when there is no snapshot.
+func (a auditor) record(repos repository.Repositories, action, entityType, entityId, routeId string, before, after any) error {
+	if !a.enabled() {
+		return nil
+	}
+	audit := repos.Audit
+	if audit == nil {
+		audit = a.repo
+	}
+	entry := &models.AuditEntry{
+		Time:       a.now(),
+		User:       a.user,
+		Action:     action,
+		EntityType: entityType,
+		EntityId:   entityId,
+		RouteId:    routeId,
+	}
+	var err error
+	if entry.Before, err = snapshot(before); err != nil {
+		return err
+	}
+	if entry.After, err = snapshot(after); err != nil {
+		return err
+	}
+	return audit.Add(entry)
+}
+
+// recordDelete records the removal of the entity from each of its routes
+// followed by the delete itself.
+func (a auditor) recordDelete(repos repository.Repositories, entityType, entityId string, before any, routes []models.Route) error {
+	for _, route := range routes {
+		if err := a.record(repos, models.AuditUnassign, entityType, entityId, route.ID, before, nil); err != nil {
+			return err
+		}
+	}
+	return a.record(repos, models.AuditDelete, entityType, entityId, "", before, nil)
+}
+
+func snapshot(v any) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
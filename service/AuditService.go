@@ -0,0 +1,48 @@
+package service
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"busManager/repository"
+	"time"
+)
+
+type AuditService struct {
+	repo repository.IAuditRepository
+}
+
+func NewAuditService(r repository.IAuditRepository) *AuditService {
+	return &AuditService{repo: r}
+}
+
+func (as AuditService) Query(filter models.AuditFilter) ([]models.AuditEntry, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		message := "Time range end is before its start"
+		return nil, apperrors.Validation("", message, map[string]string{"To": message})
+	}
+	return as.repo.Query(filter)
+}
+
+// GetByEntity returns the history of one bus, driver, bus stop, route,
+// medical check, shift, maintenance interval, maintenance record, work
+// order, odometer reading or trip record.
+func (as AuditService) GetByEntity(entityType, id string) ([]models.AuditEntry, error) {
+	switch entityType {
+	case models.EntityBus, models.EntityDriver, models.EntityBusStop, models.EntityRoute, models.EntityMedicalCheck, models.EntityShift,
+		models.EntityMaintenanceInterval, models.EntityMaintenance, models.EntityWorkOrder,
+		models.EntityOdometerReading, models.EntityOperatedTrips:
+	default:
+		message := "Unknown entity type: " + entityType
+		return nil, apperrors.Validation("", message, map[string]string{"EntityType": message})
+	}
+	return as.Query(models.AuditFilter{EntityType: entityType, EntityId: id})
+}
+
+// GetByRoute returns changes of the route and of its assignments.
+func (as AuditService) GetByRoute(routeId string) ([]models.AuditEntry, error) {
+	return as.Query(models.AuditFilter{RouteId: routeId})
+}
+
+func (as AuditService) GetByTimeRange(from, to time.Time) ([]models.AuditEntry, error) {
+	return as.Query(models.AuditFilter{From: from, To: to})
+}
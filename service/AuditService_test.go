@@ -0,0 +1,126 @@
+package service
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"busManager/repository"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAudit_Memory(t *testing.T) {
+	store := repository.NewMemoryStore()
+	repos := store.Repositories()
+	uow := repository.NewMemoryUnitOfWork(store)
+	rs := NewRouteService(repos.Routes, repos.Drivers, repos.Buses, repos.BusStops).
+		WithUnitOfWork(uow).
+		WithAudit(repos.Audit, "dispatcher")
+	bs := NewBusService(repos.Buses).
+		WithUnitOfWork(uow).
+		WithAudit(repos.Audit, "mechanic")
+	as := NewAuditService(repos.Audit)
+
+	route := &models.Route{Number: "12"}
+	bus := &models.Bus{Brand: "Volvo", BusModel: "B7R", RegisterNumber: "А123ВС77",
+		AssemblyDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), LastRepairDate: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)}
+	if err := rs.Add(route); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := bs.Add(bus); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := rs.AssignBus(route.ID, bus.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := rs.UnassignBus(route.ID, bus.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	updated := *bus
+	updated.Brand = "Mercedes"
+	if err := bs.UpdateById(&updated); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("Who took the bus off the route", func(t *testing.T) {
+		entries, err := as.Query(models.AuditFilter{EntityType: models.EntityBus, EntityId: bus.ID, RouteId: route.ID, Action: models.AuditUnassign})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(entries) != 1 || entries[0].User != "dispatcher" {
+			t.Fatalf("Expected one unassign by dispatcher, got %v", entries)
+		}
+	})
+
+	t.Run("Update keeps before and after", func(t *testing.T) {
+		entries, _ := as.GetByEntity(models.EntityBus, bus.ID)
+		if len(entries) != 4 || entries[0].Action != models.AuditUpdate {
+			t.Fatalf("Expected 4 entries with the update first, got %v", entries)
+		}
+		var before, after models.Bus
+		json.Unmarshal([]byte(entries[0].Before), &before)
+		json.Unmarshal([]byte(entries[0].After), &after)
+		if before.Brand != "Volvo" || after.Brand != "Mercedes" {
+			t.Errorf("Expected Volvo -> Mercedes, got %s -> %s", before.Brand, after.Brand)
+		}
+	})
+
+	t.Run("Per route", func(t *testing.T) {
+		entries, _ := as.GetByRoute(route.ID)
+		if len(entries) != 3 {
+			t.Errorf("Expected add, assign and unassign for the route, got %d", len(entries))
+		}
+	})
+
+	t.Run("Cascade delete records removed assignments", func(t *testing.T) {
+		if err := rs.AssignBus(route.ID, bus.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := bs.DeleteByIdWithPolicy(bus.ID, DeleteCascade); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		entries, _ := as.GetByEntity(models.EntityBus, bus.ID)
+		if entries[0].Action != models.AuditDelete || entries[1].Action != models.AuditUnassign || entries[1].RouteId != route.ID {
+			t.Errorf("Expected unassign then delete, got %v", entries[:2])
+		}
+	})
+
+	t.Run("Restore records re-created assignments", func(t *testing.T) {
+		if err := bs.RestoreById(bus.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		entries, _ := as.GetByEntity(models.EntityBus, bus.ID)
+		if entries[0].Action != models.AuditAssign || entries[0].RouteId != route.ID || entries[1].Action != models.AuditRestore {
+			t.Errorf("Expected restore then assign, got %v", entries[:2])
+		}
+		buses, _ := rs.GetAllBusesById(route.ID)
+		if len(buses) != 1 {
+			t.Errorf("Expected restored bus on the route, got %d", len(buses))
+		}
+	})
+
+	t.Run("Failed change is not recorded", func(t *testing.T) {
+		before, _ := as.Query(models.AuditFilter{})
+		if err := bs.Add(newValidBus("ХУ 789 77")); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := bs.Add(newValidBus("xy78977")); !errors.Is(err, apperrors.ErrAlreadyExists) {
+			t.Fatalf("Expected duplicate error")
+		}
+		after, _ := as.Query(models.AuditFilter{})
+		if len(after) != len(before)+1 {
+			t.Errorf("Expected only the successful add to be recorded, got %d new entries", len(after)-len(before))
+		}
+	})
+
+	t.Run("Invalid queries", func(t *testing.T) {
+		if _, err := as.GetByEntity("truck", bus.ID); err == nil {
+			t.Errorf("Expected error for unknown entity type")
+		}
+		now := time.Now()
+		if _, err := as.GetByTimeRange(now, now.Add(-time.Hour)); err == nil {
+			t.Errorf("Expected error for reversed time range")
+		}
+	})
+}
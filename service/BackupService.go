@@ -0,0 +1,225 @@
+package service
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"busManager/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	backupPrefix     = "busManager-"
+	backupExt        = ".db"
+	backupTimeLayout = "20060102-150405"
+)
+
+// BackupService keeps timestamped copies of the database in dir. Only the
+// newest keep backups are retained.
+type BackupService struct {
+	repo repository.IBackupRepository
+	dir  string
+	keep int
+	now  func() time.Time
+}
+
+func NewBackupService(r repository.IBackupRepository, dir string, keep int) *BackupService {
+	if keep < 1 {
+		keep = 1
+	}
+	return &BackupService{repo: r, dir: dir, keep: keep, now: time.Now}
+}
+
+// Create takes a backup of the live database. The copy is written under a
+// temporary name and only becomes visible once its integrity is verified.
+func (bs BackupService) Create() (*models.Backup, error) {
+	backup, err := bs.create()
+	if err != nil {
+		return nil, err
+	}
+	if err := bs.Rotate(); err != nil {
+		return nil, err
+	}
+	return backup, nil
+}
+
+func (bs BackupService) create() (*models.Backup, error) {
+	if err := os.MkdirAll(bs.dir, 0o755); err != nil {
+		return nil, err
+	}
+	created := bs.now()
+	name := bs.freeName(created)
+	path := filepath.Join(bs.dir, name)
+	tmp := path + ".tmp"
+	os.Remove(tmp)
+	if err := bs.repo.Backup(tmp); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	if err := bs.repo.Verify(tmp); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Backup{Name: name, CreatedAt: created, Size: info.Size()}, nil
+}
+
+// freeName returns a file name for a backup made at t that is not taken yet.
+func (bs BackupService) freeName(t time.Time) string {
+	base := backupPrefix + t.Format(backupTimeLayout)
+	name := base + backupExt
+	for i := 1; ; i++ {
+		if _, err := os.Stat(filepath.Join(bs.dir, name)); errors.Is(err, os.ErrNotExist) {
+			return name
+		}
+		name = fmt.Sprintf("%s-%d%s", base, i, backupExt)
+	}
+}
+
+// List returns the backups in dir, newest first.
+func (bs BackupService) List() ([]models.Backup, error) {
+	entries, err := os.ReadDir(bs.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []models.Backup{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	backups := []models.Backup{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupExt) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, models.Backup{Name: name, CreatedAt: backupTime(name, info.ModTime()), Size: info.Size()})
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		if backups[i].CreatedAt.Equal(backups[j].CreatedAt) {
+			// same second: suffixes count up, so a longer name or a larger
+			// suffix of the same length is newer
+			a, b := strings.TrimSuffix(backups[i].Name, backupExt), strings.TrimSuffix(backups[j].Name, backupExt)
+			if len(a) != len(b) {
+				return len(a) > len(b)
+			}
+			return a > b
+		}
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// backupTime reads the timestamp from a backup file name, falling back to
+// the modification time for files renamed by hand.
+func backupTime(name string, modTime time.Time) time.Time {
+	stamp := strings.TrimPrefix(strings.TrimSuffix(name, backupExt), backupPrefix)
+	if len(stamp) >= len(backupTimeLayout) {
+		if t, err := time.ParseInLocation(backupTimeLayout, stamp[:len(backupTimeLayout)], time.Local); err == nil {
+			return t
+		}
+	}
+	return modTime
+}
+
+// Rotate removes all but the newest keep backups.
+func (bs BackupService) Rotate() error {
+	backups, err := bs.List()
+	if err != nil {
+		return err
+	}
+	for i := bs.keep; i < len(backups); i++ {
+		if err := os.Remove(filepath.Join(bs.dir, backups[i].Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Restore replaces the live database with the named backup after checking
+// it. The current state is backed up first so a restore can be undone.
+func (bs BackupService) Restore(name string) error {
+	if name == "" || name != filepath.Base(name) || !strings.HasSuffix(name, backupExt) {
+		return apperrors.Validation("", "Invalid backup name", map[string]string{"Name": "Invalid backup name"})
+	}
+	path := filepath.Join(bs.dir, name)
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return apperrors.NotFound("", "Backup not found")
+		}
+		return err
+	}
+	if err := bs.repo.Verify(path); err != nil {
+		return fmt.Errorf("Backup %s is not valid: %w", name, err)
+	}
+	// rotation waits until the restore is done so it cannot remove the
+	// backup being restored
+	if _, err := bs.create(); err != nil {
+		return fmt.Errorf("Failed to back up current database before restore: %w", err)
+	}
+	if err := bs.repo.Restore(path); err != nil {
+		return err
+	}
+	return bs.Rotate()
+}
+
+// Run creates a backup whenever interval has passed since the newest one,
+// until ctx is cancelled. The schedule follows the backups on disk rather
+// than the uptime, so a backup that fell due while the application was
+// closed is taken right away. After a failure it waits a full interval.
+func (bs BackupService) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	for ctx.Err() == nil {
+		wait := bs.untilNext(interval)
+		if wait == 0 {
+			backup, err := bs.Create()
+			if err == nil {
+				slog.Info("Scheduled backup created", "name", backup.Name)
+				continue
+			}
+			slog.Error("Scheduled backup failed", "error", err)
+			wait = interval
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// untilNext returns how long after now the next scheduled backup is due:
+// interval after the newest backup, at once when there is none.
+func (bs BackupService) untilNext(interval time.Duration) time.Duration {
+	backups, err := bs.List()
+	if err != nil {
+		slog.Error("Failed to list backups", "error", err)
+		return 0
+	}
+	if len(backups) == 0 {
+		return 0
+	}
+	// a backup dated in the future, e.g. after the clock was set back,
+	// delays the next one by one interval at most
+	wait := min(backups[0].CreatedAt.Add(interval).Sub(bs.now()), interval)
+	return max(wait, 0)
+}
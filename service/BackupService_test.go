@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type MockBackupRepository struct {
+	verifyErr  error
+	restoreErr error
+	restored   string
+}
+
+func (m *MockBackupRepository) Backup(path string) error {
+	return os.WriteFile(path, []byte("backup"), 0o644)
+}
+
+func (m *MockBackupRepository) Verify(path string) error {
+	return m.verifyErr
+}
+
+func (m *MockBackupRepository) Restore(path string) error {
+	m.restored = path
+	return m.restoreErr
+}
+
+// newTestBackupService returns a service whose clock advances a minute per call.
+func newTestBackupService(t *testing.T, repo *MockBackupRepository, keep int) *BackupService {
+	service := NewBackupService(repo, t.TempDir(), keep)
+	clock, _ := time.Parse(time.RFC3339, "2022-11-11T11:11:11Z")
+	service.now = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+	return service
+}
+
+func TestBackupService_Create(t *testing.T) {
+	t.Run("Create and list backups", func(t *testing.T) {
+		service := newTestBackupService(t, &MockBackupRepository{}, 7)
+
+		first, err := service.Create()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if first.Name != "busManager-20221111-111211.db" {
+			t.Errorf("Expected timestamped name, got %s", first.Name)
+		}
+		second, _ := service.Create()
+
+		backups, err := service.List()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(backups) != 2 || backups[0].Name != second.Name {
+			t.Errorf("Expected newest backup first, got %v", backups)
+		}
+		if _, err := os.Stat(filepath.Join(service.dir, first.Name+".tmp")); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected temporary file to be removed")
+		}
+	})
+
+	t.Run("Invalid backup is discarded", func(t *testing.T) {
+		service := newTestBackupService(t, &MockBackupRepository{verifyErr: errors.New("Backup integrity check failed")}, 7)
+
+		if _, err := service.Create(); err == nil {
+			t.Errorf("Expected verify error")
+		}
+		entries, _ := os.ReadDir(service.dir)
+		if len(entries) != 0 {
+			t.Errorf("Expected no files left, got %d", len(entries))
+		}
+	})
+
+	t.Run("Same second gets a suffix", func(t *testing.T) {
+		service := newTestBackupService(t, &MockBackupRepository{}, 7)
+		fixed := service.now()
+		service.now = func() time.Time { return fixed }
+
+		first, _ := service.Create()
+		second, err := service.Create()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if first.Name == second.Name {
+			t.Errorf("Expected distinct names, got %s twice", first.Name)
+		}
+		backups, _ := service.List()
+		if len(backups) != 2 || backups[0].Name != second.Name {
+			t.Errorf("Expected %s listed first, got %v", second.Name, backups)
+		}
+	})
+}
+
+func TestBackupService_Rotate(t *testing.T) {
+	service := newTestBackupService(t, &MockBackupRepository{}, 3)
+	var last string
+	for i := 0; i < 5; i++ {
+		backup, err := service.Create()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		last = backup.Name
+	}
+
+	backups, _ := service.List()
+	if len(backups) != 3 {
+		t.Fatalf("Expected 3 backups to be kept, got %d", len(backups))
+	}
+	if backups[0].Name != last {
+		t.Errorf("Expected newest backup %s to be kept, got %s", last, backups[0].Name)
+	}
+}
+
+func TestBackupService_Run(t *testing.T) {
+	now := time.Date(2022, 11, 11, 11, 11, 11, 0, time.Local)
+	// newService returns a service whose clock stands at now, with a backup
+	// made age ago already on disk.
+	newService := func(t *testing.T, age time.Duration) *BackupService {
+		service := NewBackupService(&MockBackupRepository{}, t.TempDir(), 7)
+		service.now = func() time.Time { return now }
+		old := backupPrefix + now.Add(-age).Format(backupTimeLayout) + backupExt
+		if err := os.WriteFile(filepath.Join(service.dir, old), []byte("backup"), 0o644); err != nil {
+			t.Fatalf("Failed to write backup: %v", err)
+		}
+		return service
+	}
+
+	t.Run("Overdue backup is taken at startup", func(t *testing.T) {
+		service := newService(t, 3*24*time.Hour)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			service.Run(ctx, 24*time.Hour)
+			close(done)
+		}()
+		deadline := time.Now().Add(5 * time.Second)
+		backups, _ := service.List()
+		for len(backups) < 2 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+			backups, _ = service.List()
+		}
+		cancel()
+		<-done
+		if len(backups) != 2 || !backups[0].CreatedAt.Equal(now) {
+			t.Errorf("Expected a backup taken at startup, got %v", backups)
+		}
+	})
+
+	t.Run("Next backup is due an interval after the newest", func(t *testing.T) {
+		service := newService(t, 30*time.Minute)
+		if wait := service.untilNext(time.Hour); wait != 30*time.Minute {
+			t.Errorf("Expected 30m until the next backup, got %v", wait)
+		}
+		if wait := service.untilNext(10 * time.Minute); wait != 0 {
+			t.Errorf("Expected an overdue backup to be due at once, got %v", wait)
+		}
+		service.now = func() time.Time { return now.Add(-2 * time.Hour) }
+		if wait := service.untilNext(time.Hour); wait != time.Hour {
+			t.Errorf("Expected a backup from the future to delay one interval at most, got %v", wait)
+		}
+	})
+
+	t.Run("Fresh backup is not repeated", func(t *testing.T) {
+		service := newService(t, time.Minute)
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		service.Run(ctx, time.Hour)
+		if backups, _ := service.List(); len(backups) != 1 {
+			t.Errorf("Expected no backup before the interval has passed, got %v", backups)
+		}
+	})
+
+	t.Run("Empty directory gets a first backup", func(t *testing.T) {
+		service := NewBackupService(&MockBackupRepository{}, t.TempDir(), 7)
+		service.now = func() time.Time { return now }
+		if wait := service.untilNext(time.Hour); wait != 0 {
+			t.Errorf("Expected the first backup to be due at once, got %v", wait)
+		}
+	})
+}
+
+func TestBackupService_Restore(t *testing.T) {
+	t.Run("Restore existing backup", func(t *testing.T) {
+		repo := &MockBackupRepository{}
+		service := newTestBackupService(t, repo, 1)
+		backup, _ := service.Create()
+
+		if err := service.Restore(backup.Name); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if repo.restored != filepath.Join(service.dir, backup.Name) {
+			t.Errorf("Expected %s to be restored, got %s", backup.Name, repo.restored)
+		}
+		backups, _ := service.List()
+		if len(backups) != 1 || backups[0].Name == backup.Name {
+			t.Errorf("Expected only the pre-restore backup to be kept, got %v", backups)
+		}
+	})
+
+	t.Run("Path outside backup dir", func(t *testing.T) {
+		service := newTestBackupService(t, &MockBackupRepository{}, 7)
+
+		if err := service.Restore("../db.db"); err == nil || err.Error() != "Invalid backup name" {
+			t.Errorf("Expected 'Invalid backup name' error, got %v", err)
+		}
+	})
+
+	t.Run("Missing backup", func(t *testing.T) {
+		service := newTestBackupService(t, &MockBackupRepository{}, 7)
+
+		if err := service.Restore("busManager-20221111-111111.db"); err == nil || err.Error() != "Backup not found" {
+			t.Errorf("Expected 'Backup not found' error, got %v", err)
+		}
+	})
+
+	t.Run("Invalid backup is not restored", func(t *testing.T) {
+		repo := &MockBackupRepository{}
+		service := newTestBackupService(t, repo, 7)
+		backup, _ := service.Create()
+		repo.verifyErr = errors.New("Backup integrity check failed")
+
+		if err := service.Restore(backup.Name); err == nil {
+			t.Errorf("Expected verify error")
+		}
+		if repo.restored != "" {
+			t.Errorf("Expected no restore, got %s", repo.restored)
+		}
+	})
+}
@@ -1,20 +1,47 @@
 package service
 
 import (
+	"busManager/apperrors"
 	"busManager/models"
+	"busManager/plates"
 	"busManager/repository"
 	"errors"
+	"strings"
 )
 
 type BusService struct {
-	repo repository.IBusRepository
+	repo         repository.IBusRepository
+	uow          repository.IUnitOfWork
+	deletePolicy DeletePolicy
+	audit        auditor
 }
 
 func NewBusService(r repository.IBusRepository) *BusService {
-	b := &BusService{r}
+	b := &BusService{repo: r, deletePolicy: DeleteRestrict}
 	return b
 }
 
+func (bs *BusService) WithUnitOfWork(uow repository.IUnitOfWork) *BusService {
+	bs.uow = uow
+	return bs
+}
+
+// WithAudit records every change made through the service in repo as user.
+func (bs *BusService) WithAudit(repo repository.IAuditRepository, user string) *BusService {
+	bs.audit = newAuditor(repo, user)
+	return bs
+}
+
+// WithDeletePolicy sets the policy used by DeleteById.
+func (bs *BusService) WithDeletePolicy(policy DeletePolicy) *BusService {
+	bs.deletePolicy = policy
+	return bs
+}
+
+func (bs BusService) transact(fn func(repos repository.Repositories) error) error {
+	return transact(bs.uow, repository.Repositories{Buses: bs.repo, Audit: bs.audit.repo}, fn)
+}
+
 func (bs BusService) GetById(id string) (*models.Bus, error) {
 
 	bus, err := bs.repo.GetById(id)
@@ -22,40 +49,238 @@ func (bs BusService) GetById(id string) (*models.Bus, error) {
 		return nil, err
 	}
 	if bus == nil {
-		return nil, errors.New("Bus not found")
+		return nil, apperrors.NotFound(models.EntityBus, "Bus not found")
 	}
 	return bus, nil
 }
 
+// canonicalPlate checks the register number of bus and replaces it with its
+// canonical form, so that lookups and the duplicate check compare like with like.
+func canonicalPlate(bus *models.Bus) error {
+	plate, err := plates.Parse(bus.RegisterNumber)
+	if err != nil {
+		return apperrors.Validation(models.EntityBus, err.Error(), map[string]string{"RegisterNumber": err.Error()}).Wrap(err)
+	}
+	bus.RegisterNumber = plate.Canonical()
+	return nil
+}
+
+// GetByNumber accepts the register number in any spacing, case or mix of
+// Cyrillic and Latin letters. A number stored before plates were checked
+// that is not a valid plate is found as it is stored.
 func (bs BusService) GetByNumber(number string) (*models.Bus, error) {
-	bus, err := bs.repo.GetByNumber(number)
+	bus, err := bs.repo.GetByNumber(plates.Normalize(number))
+	if errors.Is(err, apperrors.ErrNotFound) && plates.Normalize(number) != number {
+		bus, err = bs.repo.GetByNumber(number)
+	}
 	if err != nil {
 		return nil, err
 	}
 	if bus == nil {
-		return nil, errors.New("Bus not found")
+		return nil, apperrors.NotFound(models.EntityBus, "Bus not found")
 	}
 	return bus, nil
 }
 
+// Add stores a new bus, which is always active. The VIN is accepted in any
+// case.
 func (bs BusService) Add(bus *models.Bus) error {
-	err := bs.repo.Add(bus)
-	return err
+	bus.Status = models.BusActive
+	bus.VIN = strings.ToUpper(strings.TrimSpace(bus.VIN))
+	if err := models.BusRules.Validate(models.EntityBus, *bus); err != nil {
+		return err
+	}
+	if err := canonicalPlate(bus); err != nil {
+		return err
+	}
+	return bs.transact(func(repos repository.Repositories) error {
+		if err := repos.Buses.Add(bus); err != nil {
+			return err
+		}
+		return bs.audit.record(repos, models.AuditAdd, models.EntityBus, bus.ID, "", nil, bus)
+	})
 }
 
-func (bs BusService) GetAll() []models.Bus {
-	var m []models.Bus
-	m, _ = bs.repo.GetAll()
-	return m
-
+func (bs BusService) GetAll() ([]models.Bus, error) {
+	return bs.repo.GetAll()
 }
 
 func (bs BusService) DeleteById(id string) error {
-	err := bs.repo.DeleteById(id)
-	return err
+	return bs.DeleteByIdWithPolicy(id, bs.deletePolicy)
+}
+
+// DeleteByIdWithPolicy deletes the bus. With DeleteRestrict it fails with an
+// integrity error listing the routes while the bus is assigned to any; with
+// DeleteCascade the assignments are removed in the same transaction.
+func (bs BusService) DeleteByIdWithPolicy(id string, policy DeletePolicy) error {
+	return bs.transact(func(repos repository.Repositories) error {
+		routes, err := repos.Buses.GetAllRoutesById(id)
+		if err != nil {
+			return err
+		}
+		if len(routes) > 0 && policy != DeleteCascade {
+			return inUseError(models.EntityBus, "Bus", routes)
+		}
+		var before *models.Bus
+		if bs.audit.enabled() {
+			if before, err = repos.Buses.GetById(id); err != nil {
+				return err
+			}
+		}
+		if err := repos.Buses.DeleteById(id); err != nil {
+			return err
+		}
+		return bs.audit.recordDelete(repos, models.EntityBus, id, before, routes)
+	})
+}
+
+// withBusFilters returns query with its filters overridden by overrides and
+// the register number filter normalized. The filters of query are not
+// changed.
+func withBusFilters(query models.ListQuery, overrides map[string]string) models.ListQuery {
+	filters := make(map[string]string, len(query.Filters)+len(overrides))
+	for name, value := range query.Filters {
+		filters[name] = value
+	}
+	for name, value := range overrides {
+		filters[name] = value
+	}
+	if number, ok := filters["RegisterNumber"]; ok {
+		filters["RegisterNumber"] = plates.Normalize(number)
+	}
+	query.Filters = filters
+	return query
+}
+
+// List returns one page of buses, see models.ListQuery.
+func (bs BusService) List(query models.ListQuery) (models.Page[models.Bus], error) {
+	return bs.repo.List(withBusFilters(query, nil))
+}
+
+func (bs BusService) GetAllDeleted() ([]models.Trashed[models.Bus], error) {
+	return bs.repo.GetAllDeleted()
+}
+
+// RestoreById takes the bus out of the trash together with the route
+// assignments it had when it was deleted.
+func (bs BusService) RestoreById(id string) error {
+	return bs.transact(func(repos repository.Repositories) error {
+		if err := repos.Buses.RestoreById(id); err != nil {
+			return err
+		}
+		if !bs.audit.enabled() {
+			return nil
+		}
+		restored, err := repos.Buses.GetById(id)
+		if err != nil {
+			return err
+		}
+		if err := bs.audit.record(repos, models.AuditRestore, models.EntityBus, id, "", nil, restored); err != nil {
+			return err
+		}
+		routes, err := repos.Buses.GetAllRoutesById(id)
+		if err != nil {
+			return err
+		}
+		for _, route := range routes {
+			if err := bs.audit.record(repos, models.AuditAssign, models.EntityBus, id, route.ID, nil, restored); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// PurgeById permanently removes a bus that is in the trash.
+func (bs BusService) PurgeById(id string) error {
+	return bs.transact(func(repos repository.Repositories) error {
+		if err := repos.Buses.PurgeById(id); err != nil {
+			return err
+		}
+		return bs.audit.record(repos, models.AuditPurge, models.EntityBus, id, "", nil, nil)
+	})
+}
+
+func (bs BusService) GetAllRoutesById(id string) ([]models.Route, error) {
+	return bs.repo.GetAllRoutesById(id)
 }
 
+// UpdateById saves the bus. LastRepairDate and Status keep their stored
+// values: they are moved by the maintenance history, by work orders and by
+// ChangeStatus, see MaintenanceService and WorkOrderService. An unchanged
+// register number that is not a valid plate is kept, so that such a bus can
+// still be edited.
 func (bs BusService) UpdateById(bus *models.Bus) error {
-	err := bs.repo.UpdateById(bus)
-	return err
+	return bs.transact(func(repos repository.Repositories) error {
+		before, err := repos.Buses.GetById(bus.ID)
+		if err != nil {
+			return err
+		}
+		bus.LastRepairDate = before.LastRepairDate
+		bus.Status = before.Status
+		bus.VIN = strings.ToUpper(strings.TrimSpace(bus.VIN))
+		if err := models.BusRules.Validate(models.EntityBus, *bus); err != nil {
+			return err
+		}
+		if bus.RegisterNumber != before.RegisterNumber {
+			if err := canonicalPlate(bus); err != nil {
+				return err
+			}
+		}
+		if err := repos.Buses.UpdateById(bus); err != nil {
+			return err
+		}
+		return bs.audit.record(repos, models.AuditUpdate, models.EntityBus, bus.ID, "", before, bus)
+	})
+}
+
+// GetAllWithInvalidNumber lists the buses whose register number, stored
+// before plates were checked, is not a valid plate.
+func (bs BusService) GetAllWithInvalidNumber() ([]models.Bus, error) {
+	return bs.repo.GetAllWithInvalidNumber()
+}
+
+// ChangeStatus moves the bus to status along an allowed transition and adds
+// the change to its status history with reason. Buses go in and out of
+// repair only with their work orders, and a bus assigned to routes cannot
+// be decommissioned.
+func (bs BusService) ChangeStatus(id, status, reason string) (*models.Bus, error) {
+	change := models.BusStatusChange{BusID: id, To: status, Reason: reason}
+	if err := models.BusStatusChangeRules.Validate(models.EntityBus, change); err != nil {
+		return nil, err
+	}
+	var updated models.Bus
+	err := bs.transact(func(repos repository.Repositories) error {
+		bus, err := repos.Buses.GetById(id)
+		if err != nil {
+			return err
+		}
+		if bus.Status == status {
+			return statusError("Bus is already " + statusLabel(status))
+		}
+		if bus.Status == models.BusInRepair || status == models.BusInRepair {
+			return statusError("Buses go in and out of repair with their work orders")
+		}
+		if status == models.BusDecommissioned {
+			routes, err := repos.Buses.GetAllRoutesById(id)
+			if err != nil {
+				return err
+			}
+			if len(routes) > 0 {
+				return inUseError(models.EntityBus, "Bus", routes)
+			}
+		}
+		updated = *bus
+		updated.Status = status
+		return saveBus(repos, bs.audit, bus, &updated, strings.TrimSpace(reason))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// GetStatusHistoryById returns the status changes of the bus, oldest first.
+func (bs BusService) GetStatusHistoryById(id string) ([]models.BusStatusChange, error) {
+	return bs.repo.GetStatusHistoryById(id)
 }
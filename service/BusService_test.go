@@ -0,0 +1,227 @@
+package service
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"busManager/plates"
+	"busManager/repository"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newValidBus(number string) *models.Bus {
+	return &models.Bus{Brand: "ЛиАЗ", BusModel: "5292", RegisterNumber: number,
+		AssemblyDate: time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC), LastRepairDate: time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestBusService_Plates(t *testing.T) {
+	store := repository.NewMemoryStore()
+	repos := store.Repositories()
+	bs := NewBusService(repos.Buses).WithUnitOfWork(repository.NewMemoryUnitOfWork(store))
+
+	bus := newValidBus("a 123 bc 77")
+	if err := bs.Add(bus); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if bus.RegisterNumber != "А123ВС77" {
+		t.Errorf("Expected canonical register number, got %q", bus.RegisterNumber)
+	}
+	if found, err := bs.GetByNumber("А 123 вс 77"); err != nil || found.ID != bus.ID {
+		t.Errorf("Expected bus by look-alike number, got %v (%v)", found, err)
+	}
+	if err := bs.Add(newValidBus("A123BC77")); err == nil || err.Error() != "Bus already exists" {
+		t.Errorf("Expected 'Bus already exists' error, got %v", err)
+	}
+	if err := bs.Add(newValidBus("Б123ВС77")); !errors.Is(err, plates.ErrInvalid) || !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Expected invalid register number error, got %v", err)
+	}
+
+	updated := *bus
+	updated.RegisterNumber = "ao 456 77"
+	if err := bs.UpdateById(&updated); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	page, err := bs.List(models.ListQuery{Filters: map[string]string{"RegisterNumber": "ao456"}})
+	if err != nil || page.Total != 1 || page.Items[0].RegisterNumber != "АО45677" {
+		t.Errorf("Expected bus found by normalized filter, got %+v (%v)", page, err)
+	}
+}
+
+func TestBusService_Validation(t *testing.T) {
+	store := repository.NewMemoryStore()
+	repos := store.Repositories()
+	bs := NewBusService(repos.Buses)
+
+	bus := newValidBus("А123ВС77")
+	bus.LastRepairDate = bus.AssemblyDate.AddDate(0, 0, -1)
+	err := bs.Add(bus)
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) || appErr.Fields["LastRepairDate"] != "LastRepairDate must not be before AssemblyDate" {
+		t.Fatalf("Expected LastRepairDate error, got %v", err)
+	}
+	if buses, _ := repos.Buses.GetAll(); len(buses) != 0 {
+		t.Errorf("Expected invalid bus not to be stored, got %d buses", len(buses))
+	}
+
+	bus = newValidBus("А123ВС77")
+	if err := bs.Add(bus); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	updated := *bus
+	updated.Brand = ""
+	if err := bs.UpdateById(&updated); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("Expected validation error on update, got %v", err)
+	}
+
+	updated = *bus
+	updated.Class, updated.FuelType, updated.VIN = "huge", "coal", "XTY529222J001234O"
+	updated.SeatedCapacity, updated.EcoClass = -1, 7
+	err = bs.UpdateById(&updated)
+	if !errors.As(err, &appErr) || len(appErr.Fields) != 5 || appErr.Fields["VIN"] == "" || appErr.Fields["Class"] == "" {
+		t.Errorf("Expected errors for the technical attributes, got %v", err)
+	}
+	updated = *bus
+	updated.Class, updated.VIN = models.BusClassLarge, " xty529222j0012345 "
+	if err := bs.UpdateById(&updated); err != nil || updated.VIN != "XTY529222J0012345" {
+		t.Errorf("Expected the VIN in capitals, got %q (%v)", updated.VIN, err)
+	}
+}
+
+func TestBusService_Status(t *testing.T) {
+	store := repository.NewMemoryStore()
+	repos := store.Repositories()
+	uow := repository.NewMemoryUnitOfWork(store)
+	bs := NewBusService(repos.Buses).WithUnitOfWork(uow)
+	rs := NewRouteService(repos.Routes, repos.Drivers, repos.Buses, repos.BusStops).WithUnitOfWork(uow)
+	ws := NewWorkOrderService(repos.WorkOrders, repos.Buses).WithUnitOfWork(uow)
+	bus := newValidBus("А123ВС77")
+	if err := bs.Add(bus); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if bus.Status != models.BusActive {
+		t.Errorf("Expected a new bus to be active, got %q", bus.Status)
+	}
+	route := &models.Route{Number: "12"}
+	if err := repos.Routes.Add(route); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var appErr *apperrors.Error
+
+	for _, tc := range []struct{ status, reason, field, message string }{
+		{models.BusReserve, " ", "Reason", "Reason is required"},
+		{"broken", "Engine", "Status", "Status must be active, reserve, in_repair, awaiting_inspection or decommissioned"},
+		{models.BusActive, "Again", "Status", "Bus is already active"},
+		{models.BusInRepair, "Engine", "Status", "Buses go in and out of repair with their work orders"},
+	} {
+		if _, err := bs.ChangeStatus(bus.ID, tc.status, tc.reason); !errors.As(err, &appErr) || appErr.Fields[tc.field] != tc.message {
+			t.Errorf("Expected %q for %s, got %v", tc.message, tc.status, err)
+		}
+	}
+
+	reserve, err := bs.ChangeStatus(bus.ID, models.BusReserve, "  Spare for the depot  ")
+	if err != nil || reserve.Status != models.BusReserve {
+		t.Fatalf("Expected the bus in reserve, got %+v (%v)", reserve, err)
+	}
+	if err := rs.AssignBus(route.ID, bus.ID); err != nil {
+		t.Fatalf("Expected a reserve bus to be assigned, got %v", err)
+	}
+	if _, err := bs.ChangeStatus(bus.ID, models.BusDecommissioned, "Worn out"); !errors.Is(err, apperrors.ErrIntegrity) {
+		t.Errorf("Expected integrity error for a bus on a route, got %v", err)
+	}
+	if err := rs.UnassignBus(route.ID, bus.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	order := &models.WorkOrder{BusID: bus.ID, Defect: "Engine stalls", Mechanic: "Petrov"}
+	if err := ws.Open(order); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := bs.ChangeStatus(bus.ID, models.BusActive, "Fixed"); !errors.As(err, &appErr) || appErr.Fields["Status"] != "Buses go in and out of repair with their work orders" {
+		t.Errorf("Expected the bus kept in repair, got %v", err)
+	}
+	if _, err := ws.Close(order.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := bs.ChangeStatus(bus.ID, models.BusDecommissioned, "Not worth the repair"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := bs.ChangeStatus(bus.ID, models.BusActive, "Back"); !errors.As(err, &appErr) || appErr.Fields["Status"] != "Bus cannot go from decommissioned to active" {
+		t.Errorf("Expected decommissioned to be final, got %v", err)
+	}
+	if err := ws.Open(&models.WorkOrder{BusID: bus.ID, Defect: "Rust"}); !errors.As(err, &appErr) || appErr.Fields["BusID"] != "A decommissioned bus cannot get work orders" {
+		t.Errorf("Expected no work order for a decommissioned bus, got %v", err)
+	}
+	if orders, _ := ws.GetAllByBusId(bus.ID); len(orders) != 1 {
+		t.Errorf("Expected only the closed order, got %+v", orders)
+	}
+	if err := rs.AssignBus(route.ID, bus.ID); !errors.As(err, &appErr) || appErr.Fields["Status"] != "Bus is decommissioned" {
+		t.Errorf("Expected a decommissioned bus refused, got %v", err)
+	}
+
+	history, err := bs.GetStatusHistoryById(bus.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := []string{models.BusReserve, models.BusInRepair, models.BusAwaitingInspection, models.BusDecommissioned}
+	if len(history) != len(want) {
+		t.Fatalf("Expected %d status changes, got %+v", len(want), history)
+	}
+	for i, change := range history {
+		if change.To != want[i] || (i > 0 && change.From != want[i-1]) {
+			t.Errorf("Expected change %d to %s, got %+v", i, want[i], change)
+		}
+	}
+	if history[0].Reason != "Spare for the depot" || history[1].Reason != "Work order opened: Engine stalls" {
+		t.Errorf("Expected the reasons kept, got %+v", history)
+	}
+}
+
+func TestBusService_InvalidNumbers(t *testing.T) {
+	store := repository.NewMemoryStore()
+	repos := store.Repositories()
+	bs := NewBusService(repos.Buses).WithUnitOfWork(repository.NewMemoryUnitOfWork(store))
+	legacy := newValidBus("б456ББ77")
+	if err := repos.Buses.Add(legacy); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if found, err := bs.GetByNumber("б456ББ77"); err != nil || found.ID != legacy.ID {
+		t.Errorf("Expected the bus by its stored number, got %v (%v)", found, err)
+	}
+	if buses, err := bs.GetAllWithInvalidNumber(); err != nil || len(buses) != 1 || buses[0].ID != legacy.ID {
+		t.Errorf("Expected the bus listed with an invalid number, got %+v (%v)", buses, err)
+	}
+
+	legacy.Brand = "ПАЗ"
+	if err := bs.UpdateById(legacy); err != nil {
+		t.Fatalf("Expected the bus editable with its number unchanged, got %v", err)
+	}
+	legacy.RegisterNumber = "б 456 бб 78"
+	if err := bs.UpdateById(legacy); !errors.Is(err, plates.ErrInvalid) {
+		t.Errorf("Expected a changed number to be checked, got %v", err)
+	}
+	legacy.RegisterNumber = "в 456 вв 77"
+	if err := bs.UpdateById(legacy); err != nil || legacy.RegisterNumber != "В456ВВ77" {
+		t.Fatalf("Expected the corrected number stored canonical, got %q (%v)", legacy.RegisterNumber, err)
+	}
+	if buses, _ := bs.GetAllWithInvalidNumber(); len(buses) != 0 {
+		t.Errorf("Expected no bus left with an invalid number, got %+v", buses)
+	}
+}
+
+func TestBusService_GetAll(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		service := NewBusService(&MockBusRepository{getAllResp: []models.Bus{*newValidBus("А123ВС77")}})
+		if buses, err := service.GetAll(); err != nil || len(buses) != 1 {
+			t.Errorf("Expected 1 bus, got %d (%v)", len(buses), err)
+		}
+	})
+
+	t.Run("Get all with error from repo", func(t *testing.T) {
+		service := NewBusService(&MockBusRepository{getAllErr: errors.New("Database error")})
+		if _, err := service.GetAll(); err == nil || err.Error() != "Database error" {
+			t.Errorf("Expected 'Database error', got %v", err)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"busManager/repository"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// statusLabel returns a bus status as it reads in a message, e.g. "in repair".
+func statusLabel(status string) string {
+	return strings.ReplaceAll(status, "_", " ")
+}
+
+func statusError(message string) error {
+	return apperrors.Validation(models.EntityBus, message, map[string]string{"Status": message})
+}
+
+// saveBus saves updated, a changed copy of bus, unless it is unchanged. A
+// new status must be allowed after the stored one; it is added to the
+// status history of the bus with reason.
+func saveBus(repos repository.Repositories, audit auditor, bus, updated *models.Bus, reason string) error {
+	if *updated == *bus {
+		return nil
+	}
+	if updated.Status != bus.Status && !models.CanChangeStatus(bus.Status, updated.Status) {
+		return statusError(fmt.Sprintf("Bus cannot go from %s to %s", statusLabel(bus.Status), statusLabel(updated.Status)))
+	}
+	if err := repos.Buses.UpdateById(updated); err != nil {
+		return err
+	}
+	if err := audit.record(repos, models.AuditUpdate, models.EntityBus, bus.ID, "", bus, updated); err != nil {
+		return err
+	}
+	if updated.Status == bus.Status {
+		return nil
+	}
+	change := &models.BusStatusChange{BusID: bus.ID, From: bus.Status, To: updated.Status, ChangedAt: time.Now().UTC(), Reason: reason}
+	return repos.Buses.AddStatusChange(change)
+}
+
+// lastRepair returns the change that last put the bus in repair, or nil
+// when its history does not tell.
+func lastRepair(repos repository.Repositories, busId string) (*models.BusStatusChange, error) {
+	history, err := repos.Buses.GetStatusHistoryById(busId)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].To == models.BusInRepair {
+			return &history[i], nil
+		}
+	}
+	return nil, nil
+}
@@ -1,20 +1,44 @@
 package service
 
 import (
+	"busManager/apperrors"
 	"busManager/models"
 	"busManager/repository"
-	"errors"
 )
 
 type BusStopService struct {
-	repo repository.IBusStopRepository
+	repo         repository.IBusStopRepository
+	uow          repository.IUnitOfWork
+	deletePolicy DeletePolicy
+	audit        auditor
 }
 
 func NewBusStopService(r repository.IBusStopRepository) *BusStopService {
-	b := &BusStopService{r}
+	b := &BusStopService{repo: r, deletePolicy: DeleteRestrict}
 	return b
 }
 
+func (ds *BusStopService) WithUnitOfWork(uow repository.IUnitOfWork) *BusStopService {
+	ds.uow = uow
+	return ds
+}
+
+// WithAudit records every change made through the service in repo as user.
+func (ds *BusStopService) WithAudit(repo repository.IAuditRepository, user string) *BusStopService {
+	ds.audit = newAuditor(repo, user)
+	return ds
+}
+
+// WithDeletePolicy sets the policy used by DeleteById.
+func (ds *BusStopService) WithDeletePolicy(policy DeletePolicy) *BusStopService {
+	ds.deletePolicy = policy
+	return ds
+}
+
+func (ds BusStopService) transact(fn func(repos repository.Repositories) error) error {
+	return transact(ds.uow, repository.Repositories{BusStops: ds.repo, Audit: ds.audit.repo}, fn)
+}
+
 func (ds BusStopService) GetById(id string) (*models.BusStop, error) {
 
 	busStop, err := ds.repo.GetById(id)
@@ -22,7 +46,7 @@ func (ds BusStopService) GetById(id string) (*models.BusStop, error) {
 		return nil, err
 	}
 	if busStop == nil {
-		return nil, errors.New("Bus stop not found")
+		return nil, apperrors.NotFound(models.EntityBusStop, "Bus stop not found")
 	}
 	return busStop, nil
 }
@@ -33,14 +57,21 @@ func (ds BusStopService) GetByName(name string) (*models.BusStop, error) {
 		return nil, err
 	}
 	if busStop == nil {
-		return nil, errors.New("Bus stop not found")
+		return nil, apperrors.NotFound(models.EntityBusStop, "Bus stop not found")
 	}
 	return busStop, nil
 }
 
 func (ds BusStopService) Add(busStop *models.BusStop) error {
-	err := ds.repo.Add(busStop)
-	return err
+	if err := models.BusStopRules.Validate(models.EntityBusStop, *busStop); err != nil {
+		return err
+	}
+	return ds.transact(func(repos repository.Repositories) error {
+		if err := repos.BusStops.Add(busStop); err != nil {
+			return err
+		}
+		return ds.audit.record(repos, models.AuditAdd, models.EntityBusStop, busStop.ID, "", nil, busStop)
+	})
 }
 
 func (ds BusStopService) GetAll() ([]models.BusStop, error) {
@@ -54,11 +85,102 @@ func (ds BusStopService) GetAll() ([]models.BusStop, error) {
 }
 
 func (ds BusStopService) DeleteById(id string) error {
-	err := ds.repo.DeleteById(id)
-	return err
+	return ds.DeleteByIdWithPolicy(id, ds.deletePolicy)
+}
+
+// DeleteByIdWithPolicy deletes the bus stop. With DeleteRestrict it fails with an
+// integrity error listing the routes while the bus stop is assigned to any; with
+// DeleteCascade the assignments are removed in the same transaction.
+func (ds BusStopService) DeleteByIdWithPolicy(id string, policy DeletePolicy) error {
+	return ds.transact(func(repos repository.Repositories) error {
+		routes, err := repos.BusStops.GetAllRoutesById(id)
+		if err != nil {
+			return err
+		}
+		if len(routes) > 0 && policy != DeleteCascade {
+			return inUseError(models.EntityBusStop, "Bus stop", routes)
+		}
+		var before *models.BusStop
+		if ds.audit.enabled() {
+			if before, err = repos.BusStops.GetById(id); err != nil {
+				return err
+			}
+		}
+		if err := repos.BusStops.DeleteById(id); err != nil {
+			return err
+		}
+		return ds.audit.recordDelete(repos, models.EntityBusStop, id, before, routes)
+	})
+}
+
+// List returns one page of bus stops, see models.ListQuery.
+func (ds BusStopService) List(query models.ListQuery) (models.Page[models.BusStop], error) {
+	return ds.repo.List(query)
+}
+
+func (ds BusStopService) GetAllDeleted() ([]models.Trashed[models.BusStop], error) {
+	return ds.repo.GetAllDeleted()
+}
+
+// RestoreById takes the bus stop out of the trash together with the route
+// assignments it had when it was deleted.
+func (ds BusStopService) RestoreById(id string) error {
+	return ds.transact(func(repos repository.Repositories) error {
+		if err := repos.BusStops.RestoreById(id); err != nil {
+			return err
+		}
+		if !ds.audit.enabled() {
+			return nil
+		}
+		restored, err := repos.BusStops.GetById(id)
+		if err != nil {
+			return err
+		}
+		if err := ds.audit.record(repos, models.AuditRestore, models.EntityBusStop, id, "", nil, restored); err != nil {
+			return err
+		}
+		routes, err := repos.BusStops.GetAllRoutesById(id)
+		if err != nil {
+			return err
+		}
+		for _, route := range routes {
+			if err := ds.audit.record(repos, models.AuditAssign, models.EntityBusStop, id, route.ID, nil, restored); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// PurgeById permanently removes a bus stop that is in the trash.
+func (ds BusStopService) PurgeById(id string) error {
+	return ds.transact(func(repos repository.Repositories) error {
+		if err := repos.BusStops.PurgeById(id); err != nil {
+			return err
+		}
+		return ds.audit.record(repos, models.AuditPurge, models.EntityBusStop, id, "", nil, nil)
+	})
+}
+
+func (ds BusStopService) GetAllRoutesById(id string) ([]models.Route, error) {
+	return ds.repo.GetAllRoutesById(id)
 }
 
 func (ds BusStopService) UpdateById(busStop *models.BusStop) error {
-	err := ds.repo.UpdateById(busStop)
-	return err
+	if err := models.BusStopRules.Validate(models.EntityBusStop, *busStop); err != nil {
+		return err
+	}
+	return ds.transact(func(repos repository.Repositories) error {
+		var before *models.BusStop
+		if ds.audit.enabled() {
+			var err error
+			if before, err = repos.BusStops.GetById(busStop.ID); err != nil {
+				return err
+			}
+		}
+		if err := repos.BusStops.UpdateById(busStop); err != nil {
+			return err
+		}
+		return ds.audit.record(repos, models.AuditUpdate, models.EntityBusStop, busStop.ID, "", before, busStop)
+	})
 }
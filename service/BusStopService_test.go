@@ -1,20 +1,27 @@
 package service
 
 import (
+	"busManager/apperrors"
 	"busManager/models"
 	"errors"
 	"testing"
 )
 
 type MockBusStopRepository struct {
-	getByIdResp   *models.BusStop
-	getByIdErr    error
-	getByNameResp *models.BusStop
-	getByNameErr  error
-	addErr        error
-	getAllResp    []models.BusStop
-	deleteByIdErr error
-	updateByIdErr error
+	getByIdResp          *models.BusStop
+	getByIdErr           error
+	getByNameResp        *models.BusStop
+	getByNameErr         error
+	addErr               error
+	getAllResp           []models.BusStop
+	deleteByIdErr        error
+	updateByIdErr        error
+	getAllRoutesByIdResp []models.Route
+	getAllRoutesByIdErr  error
+	getAllDeletedResp    []models.Trashed[models.BusStop]
+	getAllDeletedErr     error
+	restoreByIdErr       error
+	purgeByIdErr         error
 }
 
 func (m *MockBusStopRepository) GetById(id string) (*models.BusStop, error) {
@@ -41,6 +48,26 @@ func (m *MockBusStopRepository) UpdateById(busStop *models.BusStop) error {
 	return m.updateByIdErr
 }
 
+func (m *MockBusStopRepository) GetAllRoutesById(id string) ([]models.Route, error) {
+	return m.getAllRoutesByIdResp, m.getAllRoutesByIdErr
+}
+
+func (m *MockBusStopRepository) List(query models.ListQuery) (models.Page[models.BusStop], error) {
+	return models.Page[models.BusStop]{Items: m.getAllResp, Total: len(m.getAllResp)}, nil
+}
+
+func (m *MockBusStopRepository) GetAllDeleted() ([]models.Trashed[models.BusStop], error) {
+	return m.getAllDeletedResp, m.getAllDeletedErr
+}
+
+func (m *MockBusStopRepository) RestoreById(id string) error {
+	return m.restoreByIdErr
+}
+
+func (m *MockBusStopRepository) PurgeById(id string) error {
+	return m.purgeByIdErr
+}
+
 func TestBusStopService_GetById(t *testing.T) {
 	busStop := &models.BusStop{ID: "1", Lat: 55.7558, Long: 37.6173, Name: "Stop A"}
 
@@ -117,6 +144,23 @@ func TestBusStopService_Add(t *testing.T) {
 			t.Errorf("Expected 'Database error', got %v", err)
 		}
 	})
+
+	t.Run("Invalid bus stop", func(t *testing.T) {
+		mockRepo := &MockBusStopRepository{addErr: errors.New("Database error")}
+		service := NewBusStopService(mockRepo)
+
+		err := service.Add(&models.BusStop{Lat: 95, Long: 37.6173})
+		var appErr *apperrors.Error
+		if !errors.As(err, &appErr) || appErr.Code != apperrors.CodeValidation {
+			t.Fatalf("Expected validation error, got %v", err)
+		}
+		if appErr.Fields["Name"] != "Name is required" || appErr.Fields["Lat"] != "Lat must be between -90 and 90" {
+			t.Errorf("Expected Name and Lat errors, got %v", appErr.Fields)
+		}
+		if err := service.Add(&models.BusStop{Name: "Stop A"}); !errors.Is(err, apperrors.ErrValidation) {
+			t.Errorf("Expected validation error for missing coordinates, got %v", err)
+		}
+	})
 }
 
 func TestBusStopService_GetAll(t *testing.T) {
@@ -127,7 +171,10 @@ func TestBusStopService_GetAll(t *testing.T) {
 		mockRepo := &MockBusStopRepository{getAllResp: []models.BusStop{busStop1, busStop2}}
 		service := NewBusStopService(mockRepo)
 
-		busStops := service.GetAll()
+		busStops, err := service.GetAll()
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
 		if len(busStops) != 2 {
 			t.Errorf("Expected 2 bus stops, got %d", len(busStops))
 		}
@@ -137,7 +184,10 @@ func TestBusStopService_GetAll(t *testing.T) {
 		mockRepo := &MockBusStopRepository{getAllResp: []models.BusStop{}}
 		service := NewBusStopService(mockRepo)
 
-		busStops := service.GetAll()
+		busStops, err := service.GetAll()
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
 		if len(busStops) != 0 {
 			t.Errorf("Expected 0 bus stops, got %d", len(busStops))
 		}
@@ -189,3 +239,71 @@ func TestBusStopService_UpdateById(t *testing.T) {
 		}
 	})
 }
+
+func TestBusStopService_DeleteByIdWithPolicy(t *testing.T) {
+	routes := []models.Route{{ID: "1", Number: "12"}}
+
+	t.Run("Restrict with assigned routes", func(t *testing.T) {
+		mockRepo := &MockBusStopRepository{getAllRoutesByIdResp: routes}
+		service := NewBusStopService(mockRepo)
+
+		err := service.DeleteByIdWithPolicy("1", DeleteRestrict)
+		if err == nil || err.Error() != "Bus stop is assigned to routes: 12" {
+			t.Errorf("Expected 'Bus stop is assigned to routes: 12', got %v", err)
+		}
+	})
+
+	t.Run("Cascade with assigned routes", func(t *testing.T) {
+		mockRepo := &MockBusStopRepository{getAllRoutesByIdResp: routes}
+		service := NewBusStopService(mockRepo)
+
+		err := service.DeleteByIdWithPolicy("1", DeleteCascade)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+}
+
+func TestParseDeletePolicy(t *testing.T) {
+	for input, expected := range map[string]DeletePolicy{"restrict": DeleteRestrict, " Cascade ": DeleteCascade} {
+		policy, err := ParseDeletePolicy(input)
+		if err != nil || policy != expected {
+			t.Errorf("Expected %s for %q, got %s (%v)", expected, input, policy, err)
+		}
+	}
+	if _, err := ParseDeletePolicy("ignore"); err == nil {
+		t.Errorf("Expected error for unknown policy")
+	}
+}
+
+func TestBusStopService_Trash(t *testing.T) {
+	t.Run("List trash", func(t *testing.T) {
+		trash := []models.Trashed[models.BusStop]{{Item: models.BusStop{ID: "1", Name: "Stop A"}}}
+		mockRepo := &MockBusStopRepository{getAllDeletedResp: trash}
+		service := NewBusStopService(mockRepo)
+
+		result, err := service.GetAllDeleted()
+		if err != nil || len(result) != 1 || result[0].Item.Name != "Stop A" {
+			t.Errorf("Expected trashed bus stop, got %v (%v)", result, err)
+		}
+	})
+
+	t.Run("Restore missing bus stop", func(t *testing.T) {
+		mockRepo := &MockBusStopRepository{restoreByIdErr: errors.New("Bus stop not found")}
+		service := NewBusStopService(mockRepo)
+
+		err := service.RestoreById("1")
+		if err == nil || err.Error() != "Bus stop not found" {
+			t.Errorf("Expected 'Bus stop not found' error, got %v", err)
+		}
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		mockRepo := &MockBusStopRepository{}
+		service := NewBusStopService(mockRepo)
+
+		if err := service.PurgeById("1"); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+}
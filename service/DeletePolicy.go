@@ -0,0 +1,40 @@
+package service
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"strings"
+)
+
+// DeletePolicy decides what happens when an entity that is still assigned to
+// routes is deleted.
+type DeletePolicy string
+
+const (
+	// DeleteRestrict refuses the deletion and reports the blocking routes.
+	DeleteRestrict DeletePolicy = "restrict"
+	// DeleteCascade removes the route assignments together with the entity.
+	DeleteCascade DeletePolicy = "cascade"
+)
+
+func ParseDeletePolicy(policy string) (DeletePolicy, error) {
+	switch DeletePolicy(strings.ToLower(strings.TrimSpace(policy))) {
+	case DeleteRestrict:
+		return DeleteRestrict, nil
+	case DeleteCascade:
+		return DeleteCascade, nil
+	}
+	message := "Unknown delete policy: " + policy
+	return "", apperrors.Validation("", message, map[string]string{"Policy": message})
+}
+
+// inUseError is returned by restricted deletes of entities assigned to
+// routes. name is the entity as it starts the message, e.g. "Bus stop"; the
+// blocking routes are the Details of the error.
+func inUseError(entityType, name string, routes []models.Route) error {
+	numbers := make([]string, 0, len(routes))
+	for _, route := range routes {
+		numbers = append(numbers, route.Number)
+	}
+	return apperrors.Integrity(entityType, name+" is assigned to routes: "+strings.Join(numbers, ", "), routes)
+}
@@ -1,20 +1,46 @@
 package service
 
 import (
+	"busManager/apperrors"
+	"busManager/documents"
 	"busManager/models"
 	"busManager/repository"
-	"errors"
+	"time"
 )
 
 type DriverService struct {
-	repo repository.IDriverRepository
+	repo         repository.IDriverRepository
+	uow          repository.IUnitOfWork
+	deletePolicy DeletePolicy
+	audit        auditor
 }
 
 func NewDriverService(r repository.IDriverRepository) *DriverService {
-	b := &DriverService{r}
+	b := &DriverService{repo: r, deletePolicy: DeleteRestrict}
 	return b
 }
 
+func (ds *DriverService) WithUnitOfWork(uow repository.IUnitOfWork) *DriverService {
+	ds.uow = uow
+	return ds
+}
+
+// WithAudit records every change made through the service in repo as user.
+func (ds *DriverService) WithAudit(repo repository.IAuditRepository, user string) *DriverService {
+	ds.audit = newAuditor(repo, user)
+	return ds
+}
+
+// WithDeletePolicy sets the policy used by DeleteById.
+func (ds *DriverService) WithDeletePolicy(policy DeletePolicy) *DriverService {
+	ds.deletePolicy = policy
+	return ds
+}
+
+func (ds DriverService) transact(fn func(repos repository.Repositories) error) error {
+	return transact(ds.uow, repository.Repositories{Drivers: ds.repo, Audit: ds.audit.repo}, fn)
+}
+
 func (ds DriverService) GetById(id string) (*models.Driver, error) {
 
 	driver, err := ds.repo.GetById(id)
@@ -22,40 +48,226 @@ func (ds DriverService) GetById(id string) (*models.Driver, error) {
 		return nil, err
 	}
 	if driver == nil {
-		return nil, errors.New("Driver not found")
+		return nil, apperrors.NotFound(models.EntityDriver, "Driver not found")
 	}
 	return driver, nil
 }
 
+// canonical returns the canonical form of a document number, or number
+// itself when parse rejects it so that drivers saved before the numbers were
+// checked can still be found.
+func canonical(number string, parse func(string) (string, error)) string {
+	if c, err := parse(number); err == nil {
+		return c
+	}
+	return number
+}
+
+// canonicalDocuments stores the document numbers of a validated driver in
+// the form the duplicate checks and lookups compare.
+func canonicalDocuments(driver *models.Driver) {
+	driver.PassportSeries = canonical(driver.PassportSeries, documents.ParsePassport)
+	driver.Snils = canonical(driver.Snils, documents.ParseSnils)
+	driver.LicenseSeries = canonical(driver.LicenseSeries, documents.ParseLicense)
+	var categories models.LicenseCategories
+	for _, category := range driver.LicenseCategories {
+		category = canonical(category, documents.ParseCategory)
+		if !categories.Has(category) {
+			categories = append(categories, category)
+		}
+	}
+	driver.LicenseCategories = categories
+}
+
+// GetByPassportSeries accepts the series and number with any spacing.
 func (ds DriverService) GetByPassportSeries(series string) (*models.Driver, error) {
-	driver, err := ds.repo.GetByPassportSeries(series)
+	driver, err := ds.repo.GetByPassportSeries(canonical(series, documents.ParsePassport))
 	if err != nil {
 		return nil, err
 	}
 	if driver == nil {
-		return nil, errors.New("Driver not found")
+		return nil, apperrors.NotFound(models.EntityDriver, "Driver not found")
 	}
 	return driver, nil
 }
 
-func (ds DriverService) Add(driver *models.Driver) error {
-	err := ds.repo.Add(driver)
-	return err
+// GetBySnils accepts the SNILS with or without dashes and spaces.
+func (ds DriverService) GetBySnils(snils string) (*models.Driver, error) {
+	driver, err := ds.repo.GetBySnils(canonical(snils, documents.ParseSnils))
+	if err != nil {
+		return nil, err
+	}
+	if driver == nil {
+		return nil, apperrors.NotFound(models.EntityDriver, "Driver not found")
+	}
+	return driver, nil
 }
 
-func (ds DriverService) GetAll() []models.Driver {
-	var m []models.Driver
-	m, _ = ds.repo.GetAll()
-	return m
+func (ds DriverService) Add(driver *models.Driver) error {
+	if err := models.DriverRules.Validate(models.EntityDriver, *driver); err != nil {
+		return err
+	}
+	canonicalDocuments(driver)
+	return ds.transact(func(repos repository.Repositories) error {
+		if err := repos.Drivers.Add(driver); err != nil {
+			return err
+		}
+		return ds.audit.record(repos, models.AuditAdd, models.EntityDriver, driver.ID, "", nil, driver)
+	})
+}
 
+func (ds DriverService) GetAll() ([]models.Driver, error) {
+	return ds.repo.GetAll()
 }
 
 func (ds DriverService) DeleteById(id string) error {
-	err := ds.repo.DeleteById(id)
-	return err
+	return ds.DeleteByIdWithPolicy(id, ds.deletePolicy)
+}
+
+// DeleteByIdWithPolicy deletes the driver. With DeleteRestrict it fails with an
+// integrity error listing the routes while the driver is assigned to any; with
+// DeleteCascade the assignments are removed in the same transaction.
+func (ds DriverService) DeleteByIdWithPolicy(id string, policy DeletePolicy) error {
+	return ds.transact(func(repos repository.Repositories) error {
+		routes, err := repos.Drivers.GetAllRoutesById(id)
+		if err != nil {
+			return err
+		}
+		if len(routes) > 0 && policy != DeleteCascade {
+			return inUseError(models.EntityDriver, "Driver", routes)
+		}
+		var before *models.Driver
+		if ds.audit.enabled() {
+			if before, err = repos.Drivers.GetById(id); err != nil {
+				return err
+			}
+		}
+		if err := repos.Drivers.DeleteById(id); err != nil {
+			return err
+		}
+		return ds.audit.recordDelete(repos, models.EntityDriver, id, before, routes)
+	})
+}
+
+// List returns one page of drivers, see models.ListQuery.
+func (ds DriverService) List(query models.ListQuery) (models.Page[models.Driver], error) {
+	return ds.repo.List(query)
+}
+
+func (ds DriverService) GetAllDeleted() ([]models.Trashed[models.Driver], error) {
+	return ds.repo.GetAllDeleted()
+}
+
+// RestoreById takes the driver out of the trash together with the route
+// assignments it had when it was deleted.
+func (ds DriverService) RestoreById(id string) error {
+	return ds.transact(func(repos repository.Repositories) error {
+		if err := repos.Drivers.RestoreById(id); err != nil {
+			return err
+		}
+		if !ds.audit.enabled() {
+			return nil
+		}
+		restored, err := repos.Drivers.GetById(id)
+		if err != nil {
+			return err
+		}
+		if err := ds.audit.record(repos, models.AuditRestore, models.EntityDriver, id, "", nil, restored); err != nil {
+			return err
+		}
+		routes, err := repos.Drivers.GetAllRoutesById(id)
+		if err != nil {
+			return err
+		}
+		for _, route := range routes {
+			if err := ds.audit.record(repos, models.AuditAssign, models.EntityDriver, id, route.ID, nil, restored); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// PurgeById permanently removes a driver that is in the trash.
+func (ds DriverService) PurgeById(id string) error {
+	return ds.transact(func(repos repository.Repositories) error {
+		if err := repos.Drivers.PurgeById(id); err != nil {
+			return err
+		}
+		return ds.audit.record(repos, models.AuditPurge, models.EntityDriver, id, "", nil, nil)
+	})
+}
+
+// GetAllLicensesExpiringWithin returns the drivers whose licence expires
+// within days from today, including those already expired, soonest first.
+func (ds DriverService) GetAllLicensesExpiringWithin(days int) ([]models.Driver, error) {
+	if days < 0 {
+		message := "Days must not be negative"
+		return nil, apperrors.Validation(models.EntityDriver, message, map[string]string{"Days": message})
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	return ds.repo.GetAllByLicenseExpiryBefore(today.AddDate(0, 0, days+1))
+}
+
+func (ds DriverService) GetAllRoutesById(id string) ([]models.Route, error) {
+	return ds.repo.GetAllRoutesById(id)
 }
 
 func (ds DriverService) UpdateById(driver *models.Driver) error {
-	err := ds.repo.UpdateById(driver)
-	return err
+	return ds.transact(func(repos repository.Repositories) error {
+		before, err := repos.Drivers.GetById(driver.ID)
+		if err != nil {
+			return err
+		}
+		if before == nil {
+			return apperrors.NotFound(models.EntityDriver, "Driver not found")
+		}
+		rules := models.DriverRules.Without(unchangedDocuments(driver, before)...)
+		if err := rules.Validate(models.EntityDriver, *driver); err != nil {
+			return err
+		}
+		canonicalDocuments(driver)
+		if err := repos.Drivers.UpdateById(driver); err != nil {
+			return err
+		}
+		return ds.audit.record(repos, models.AuditUpdate, models.EntityDriver, driver.ID, "", before, driver)
+	})
+}
+
+// unchangedDocuments returns the document fields of driver that still hold
+// the number stored in before. Like an unchanged bus register number, a
+// document saved before the numbers were checked is not checked again until
+// it is edited, so the rest of the driver can still be updated.
+func unchangedDocuments(driver, before *models.Driver) []string {
+	var fields []string
+	if driver.PassportSeries == before.PassportSeries {
+		fields = append(fields, "PassportSeries")
+	}
+	if driver.Snils == before.Snils {
+		fields = append(fields, "Snils")
+	}
+	if driver.LicenseSeries == before.LicenseSeries {
+		fields = append(fields, "LicenseSeries")
+	}
+	return fields
+}
+
+// GetAllWithInvalidDocuments lists the drivers whose passport, SNILS or
+// licence number, stored before the numbers were checked, is not valid.
+func (ds DriverService) GetAllWithInvalidDocuments() ([]models.Driver, error) {
+	all, err := ds.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	invalid := []models.Driver{}
+	for _, driver := range all {
+		if _, err := documents.ParsePassport(driver.PassportSeries); err != nil {
+			invalid = append(invalid, driver)
+		} else if _, err := documents.ParseSnils(driver.Snils); err != nil {
+			invalid = append(invalid, driver)
+		} else if _, err := documents.ParseLicense(driver.LicenseSeries); err != nil {
+			invalid = append(invalid, driver)
+		}
+	}
+	return invalid, nil
 }
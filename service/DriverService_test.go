@@ -1,23 +1,36 @@
 package service
 
 import (
+	"busManager/apperrors"
 	"busManager/models"
 	"errors"
 	"github.com/google/uuid"
+	"strings"
 	"testing"
 	"time"
 )
 
 type MockDriverRepository struct {
 	//repository.IDriverRepository
-	getByIdResp       *models.Driver
-	getByIdErr        error
-	getByPassportResp *models.Driver
-	getByPassportErr  error
-	addErr            error
-	getAllResp        []models.Driver
-	deleteByIdErr     error
-	updateByIdErr     error
+	getByIdResp          *models.Driver
+	getByIdErr           error
+	getByPassportResp    *models.Driver
+	getByPassportErr     error
+	getBySnilsResp       *models.Driver
+	getBySnilsErr        error
+	addErr               error
+	getAllResp           []models.Driver
+	getAllErr            error
+	deleteByIdErr        error
+	updateByIdErr        error
+	getAllRoutesByIdResp []models.Route
+	getAllRoutesByIdErr  error
+	getAllDeletedResp    []models.Trashed[models.Driver]
+	getAllDeletedErr     error
+	restoreByIdErr       error
+	purgeByIdErr         error
+	expiringBefore       time.Time
+	expiringResp         []models.Driver
 }
 
 func (m *MockDriverRepository) GetById(id string) (*models.Driver, error) {
@@ -28,12 +41,16 @@ func (m *MockDriverRepository) GetByPassportSeries(series string) (*models.Drive
 	return m.getByPassportResp, m.getByPassportErr
 }
 
+func (m *MockDriverRepository) GetBySnils(snils string) (*models.Driver, error) {
+	return m.getBySnilsResp, m.getBySnilsErr
+}
+
 func (m *MockDriverRepository) Add(driver *models.Driver) error {
 	return m.addErr
 }
 
 func (m *MockDriverRepository) GetAll() ([]models.Driver, error) {
-	return m.getAllResp, nil
+	return m.getAllResp, m.getAllErr
 }
 
 func (m *MockDriverRepository) DeleteById(id string) error {
@@ -44,17 +61,42 @@ func (m *MockDriverRepository) UpdateById(driver *models.Driver) error {
 	return m.updateByIdErr
 }
 
+func (m *MockDriverRepository) GetAllRoutesById(id string) ([]models.Route, error) {
+	return m.getAllRoutesByIdResp, m.getAllRoutesByIdErr
+}
+
+func (m *MockDriverRepository) GetAllByLicenseExpiryBefore(date time.Time) ([]models.Driver, error) {
+	m.expiringBefore = date
+	return m.expiringResp, nil
+}
+
+func (m *MockDriverRepository) List(query models.ListQuery) (models.Page[models.Driver], error) {
+	return models.Page[models.Driver]{Items: m.getAllResp, Total: len(m.getAllResp)}, nil
+}
+
+func (m *MockDriverRepository) GetAllDeleted() ([]models.Trashed[models.Driver], error) {
+	return m.getAllDeletedResp, m.getAllDeletedErr
+}
+
+func (m *MockDriverRepository) RestoreById(id string) error {
+	return m.restoreByIdErr
+}
+
+func (m *MockDriverRepository) PurgeById(id string) error {
+	return m.purgeByIdErr
+}
+
 func TestDriverService_GetById(t *testing.T) {
-	fixedTime, _ := time.Parse(time.RFC3339, "2022-11-11T11:11:11Z")
+	fixedTime, _ := time.Parse(time.RFC3339, "1985-11-11T11:11:11Z")
 	driver := &models.Driver{
 		ID:             uuid.New().String(),
 		Name:           "John",
 		Surname:        "Doe",
 		Patronymic:     "Ivanovich",
 		BirthDate:      fixedTime,
-		PassportSeries: "AB123456",
-		Snils:          "123-456-789 00",
-		LicenseSeries:  "CD789012",
+		PassportSeries: "4510 123456",
+		Snils:          "123-456-789 64",
+		LicenseSeries:  "77 12 345678",
 	}
 
 	t.Run("Get existing driver by ID", func(t *testing.T) {
@@ -82,16 +124,16 @@ func TestDriverService_GetById(t *testing.T) {
 }
 
 func TestDriverService_GetByPassportSeries(t *testing.T) {
-	fixedTime, _ := time.Parse(time.RFC3339, "2022-11-11T11:11:11Z")
+	fixedTime, _ := time.Parse(time.RFC3339, "1985-11-11T11:11:11Z")
 	driver := &models.Driver{
 		ID:             uuid.New().String(),
 		Name:           "John",
 		Surname:        "Doe",
 		Patronymic:     "Ivanovich",
 		BirthDate:      fixedTime,
-		PassportSeries: "AB123456",
-		Snils:          "123-456-789 00",
-		LicenseSeries:  "CD789012",
+		PassportSeries: "4510 123456",
+		Snils:          "123-456-789 64",
+		LicenseSeries:  "77 12 345678",
 	}
 
 	t.Run("Get existing driver by passport series", func(t *testing.T) {
@@ -119,15 +161,15 @@ func TestDriverService_GetByPassportSeries(t *testing.T) {
 }
 
 func TestDriverService_Add(t *testing.T) {
-	fixedTime, _ := time.Parse(time.RFC3339, "2022-11-11T11:11:11Z")
+	fixedTime, _ := time.Parse(time.RFC3339, "1985-11-11T11:11:11Z")
 	driver := &models.Driver{
 		Name:           "John",
 		Surname:        "Doe",
 		Patronymic:     "Ivanovich",
 		BirthDate:      fixedTime,
-		PassportSeries: "AB123456",
-		Snils:          "123-456-789 00",
-		LicenseSeries:  "CD789012",
+		PassportSeries: "4510 123456",
+		Snils:          "123-456-789 64",
+		LicenseSeries:  "77 12 345678",
 	}
 
 	t.Run("Add new driver", func(t *testing.T) {
@@ -149,19 +191,68 @@ func TestDriverService_Add(t *testing.T) {
 			t.Errorf("Expected 'Database error', got %v", err)
 		}
 	})
+
+	t.Run("Documents are stored in canonical form", func(t *testing.T) {
+		mockRepo := &MockDriverRepository{}
+		service := NewDriverService(mockRepo)
+
+		typed := *driver
+		typed.PassportSeries = "4510123456"
+		typed.Snils = "12345678964"
+		typed.LicenseSeries = "77ab 345678"
+		typed.LicenseCategories = models.LicenseCategories{"b", "D", "d", "ВЕ"}
+		if err := service.Add(&typed); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if typed.PassportSeries != "4510 123456" || typed.Snils != "123-456-789 64" || typed.LicenseSeries != "77 АВ 345678" {
+			t.Errorf("Expected canonical documents, got %q %q %q", typed.PassportSeries, typed.Snils, typed.LicenseSeries)
+		}
+		if strings.Join(typed.LicenseCategories, ",") != "B,D,BE" {
+			t.Errorf("Expected canonical categories B,D,BE, got %v", typed.LicenseCategories)
+		}
+	})
+
+	t.Run("Invalid licence", func(t *testing.T) {
+		mockRepo := &MockDriverRepository{}
+		service := NewDriverService(mockRepo)
+
+		invalid := *driver
+		invalid.LicenseCategories = models.LicenseCategories{"D", "F"}
+		invalid.LicenseIssueDate = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		invalid.LicenseExpiryDate = time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+		err := service.Add(&invalid)
+		var appErr *apperrors.Error
+		if !errors.As(err, &appErr) || appErr.Fields["LicenseCategories"] != "Unknown driving licence category F" ||
+			appErr.Fields["LicenseExpiryDate"] != "LicenseExpiryDate must be after LicenseIssueDate" {
+			t.Errorf("Expected licence validation errors, got %v", err)
+		}
+	})
+
+	t.Run("Invalid SNILS checksum", func(t *testing.T) {
+		mockRepo := &MockDriverRepository{}
+		service := NewDriverService(mockRepo)
+
+		invalid := *driver
+		invalid.Snils = "123-456-789 00"
+		err := service.Add(&invalid)
+		var appErr *apperrors.Error
+		if !errors.As(err, &appErr) || appErr.Code != apperrors.CodeValidation || appErr.Fields["Snils"] == "" {
+			t.Errorf("Expected validation error on Snils, got %v", err)
+		}
+	})
 }
 
 func TestDriverService_GetAll(t *testing.T) {
-	fixedTime, _ := time.Parse(time.RFC3339, "2022-11-11T11:11:11Z")
+	fixedTime, _ := time.Parse(time.RFC3339, "1985-11-11T11:11:11Z")
 	driver1 := models.Driver{
 		ID:             uuid.New().String(),
 		Name:           "John",
 		Surname:        "Doe",
 		Patronymic:     "Ivanovich",
 		BirthDate:      fixedTime,
-		PassportSeries: "AB123456",
-		Snils:          "123-456-789 00",
-		LicenseSeries:  "CD789012",
+		PassportSeries: "4510 123456",
+		Snils:          "123-456-789 64",
+		LicenseSeries:  "77 12 345678",
 	}
 	driver2 := models.Driver{
 		ID:             uuid.New().String(),
@@ -178,9 +269,9 @@ func TestDriverService_GetAll(t *testing.T) {
 		mockRepo := &MockDriverRepository{getAllResp: []models.Driver{driver1, driver2}}
 		service := NewDriverService(mockRepo)
 
-		drivers := service.GetAll()
-		if len(drivers) != 2 {
-			t.Errorf("Expected 2 drivers, got %d", len(drivers))
+		drivers, err := service.GetAll()
+		if err != nil || len(drivers) != 2 {
+			t.Errorf("Expected 2 drivers, got %d (%v)", len(drivers), err)
 		}
 	})
 
@@ -188,9 +279,42 @@ func TestDriverService_GetAll(t *testing.T) {
 		mockRepo := &MockDriverRepository{getAllResp: []models.Driver{}}
 		service := NewDriverService(mockRepo)
 
-		drivers := service.GetAll()
-		if len(drivers) != 0 {
-			t.Errorf("Expected 0 drivers, got %d", len(drivers))
+		drivers, err := service.GetAll()
+		if err != nil || len(drivers) != 0 {
+			t.Errorf("Expected 0 drivers, got %d (%v)", len(drivers), err)
+		}
+	})
+
+	t.Run("Get all with error from repo", func(t *testing.T) {
+		mockRepo := &MockDriverRepository{getAllErr: errors.New("Database error")}
+		service := NewDriverService(mockRepo)
+
+		if _, err := service.GetAll(); err == nil || err.Error() != "Database error" {
+			t.Errorf("Expected 'Database error', got %v", err)
+		}
+	})
+}
+
+func TestDriverService_GetAllLicensesExpiringWithin(t *testing.T) {
+	t.Run("Expiring within 30 days", func(t *testing.T) {
+		mockRepo := &MockDriverRepository{expiringResp: []models.Driver{{ID: "1"}}}
+		service := NewDriverService(mockRepo)
+
+		drivers, err := service.GetAllLicensesExpiringWithin(30)
+		if err != nil || len(drivers) != 1 {
+			t.Fatalf("Expected 1 driver, got %v %v", drivers, err)
+		}
+		want := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 31)
+		if !mockRepo.expiringBefore.Equal(want) {
+			t.Errorf("Expected licences expiring before %v, got %v", want, mockRepo.expiringBefore)
+		}
+	})
+
+	t.Run("Negative days", func(t *testing.T) {
+		service := NewDriverService(&MockDriverRepository{})
+
+		if _, err := service.GetAllLicensesExpiringWithin(-1); !errors.Is(err, apperrors.ErrValidation) {
+			t.Errorf("Expected validation error, got %v", err)
 		}
 	})
 }
@@ -218,20 +342,20 @@ func TestDriverService_DeleteById(t *testing.T) {
 }
 
 func TestDriverService_UpdateById(t *testing.T) {
-	fixedTime, _ := time.Parse(time.RFC3339, "2022-11-11T11:11:11Z")
+	fixedTime, _ := time.Parse(time.RFC3339, "1985-11-11T11:11:11Z")
 	driver := &models.Driver{
 		ID:             uuid.New().String(),
 		Name:           "John",
 		Surname:        "Doe",
 		Patronymic:     "Ivanovich",
 		BirthDate:      fixedTime,
-		PassportSeries: "AB123456",
-		Snils:          "123-456-789 00",
-		LicenseSeries:  "CD789012",
+		PassportSeries: "4510 123456",
+		Snils:          "123-456-789 64",
+		LicenseSeries:  "77 12 345678",
 	}
 
 	t.Run("Update existing driver", func(t *testing.T) {
-		mockRepo := &MockDriverRepository{}
+		mockRepo := &MockDriverRepository{getByIdResp: driver}
 		service := NewDriverService(mockRepo)
 
 		err := service.UpdateById(driver)
@@ -241,7 +365,7 @@ func TestDriverService_UpdateById(t *testing.T) {
 	})
 
 	t.Run("Update with error from repo", func(t *testing.T) {
-		mockRepo := &MockDriverRepository{updateByIdErr: errors.New("Database error")}
+		mockRepo := &MockDriverRepository{getByIdResp: driver, updateByIdErr: errors.New("Database error")}
 		service := NewDriverService(mockRepo)
 
 		err := service.UpdateById(driver)
@@ -249,4 +373,120 @@ func TestDriverService_UpdateById(t *testing.T) {
 			t.Errorf("Expected 'Database error', got %v", err)
 		}
 	})
+
+	t.Run("Update non-existent driver", func(t *testing.T) {
+		service := NewDriverService(&MockDriverRepository{})
+
+		if err := service.UpdateById(driver); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Expected not found error, got %v", err)
+		}
+	})
+}
+
+func TestDriverService_DeleteByIdWithPolicy(t *testing.T) {
+	routes := []models.Route{{ID: "1", Number: "12"}, {ID: "2", Number: "40"}}
+
+	t.Run("Restrict with assigned routes", func(t *testing.T) {
+		mockRepo := &MockDriverRepository{getAllRoutesByIdResp: routes}
+		service := NewDriverService(mockRepo)
+
+		err := service.DeleteById("1")
+		var inUse *apperrors.Error
+		if !errors.As(err, &inUse) || inUse.Code != apperrors.CodeIntegrity || inUse.Entity != models.EntityDriver {
+			t.Fatalf("Expected integrity error, got %v", err)
+		}
+		if blocking, _ := inUse.Details.([]models.Route); len(blocking) != 2 || err.Error() != "Driver is assigned to routes: 12, 40" {
+			t.Errorf("Expected blocking routes 12 and 40, got %v", err)
+		}
+	})
+
+	t.Run("Restrict without routes", func(t *testing.T) {
+		mockRepo := &MockDriverRepository{}
+		service := NewDriverService(mockRepo)
+
+		err := service.DeleteByIdWithPolicy("1", DeleteRestrict)
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("Cascade with assigned routes", func(t *testing.T) {
+		mockRepo := &MockDriverRepository{getAllRoutesByIdResp: routes}
+		service := NewDriverService(mockRepo).WithDeletePolicy(DeleteCascade)
+
+		err := service.DeleteById("1")
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("Routes lookup error", func(t *testing.T) {
+		mockRepo := &MockDriverRepository{getAllRoutesByIdErr: errors.New("Database error")}
+		service := NewDriverService(mockRepo)
+
+		err := service.DeleteByIdWithPolicy("1", DeleteCascade)
+		if err == nil || err.Error() != "Database error" {
+			t.Errorf("Expected 'Database error', got %v", err)
+		}
+	})
+}
+
+func TestDriverService_LegacyDocuments(t *testing.T) {
+	// newLegacyDriver stores a driver saved before the document numbers were
+	// checked: its passport number is too short.
+	newLegacyDriver := func(t *testing.T) (*DriverService, *models.Driver) {
+		repos, uow := newMemoryRepositories(t)
+		ds := NewDriverService(repos.Drivers).WithUnitOfWork(uow)
+		return ds, addTestDriver(t, repos, "4510 12345", "123-456-789 64", "77 12 345678")
+	}
+
+	t.Run("Unchanged document is kept", func(t *testing.T) {
+		ds, driver := newLegacyDriver(t)
+		updated := *driver
+		updated.Name = "Ivan"
+		if err := ds.UpdateById(&updated); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if stored, _ := ds.GetById(driver.ID); stored.Name != "Ivan" || stored.PassportSeries != "4510 12345" {
+			t.Errorf("Expected the name updated and the passport kept, got %+v", stored)
+		}
+	})
+
+	t.Run("Edited document is checked", func(t *testing.T) {
+		ds, driver := newLegacyDriver(t)
+		updated := *driver
+		updated.PassportSeries = "4510 1234"
+		err := ds.UpdateById(&updated)
+		var appErr *apperrors.Error
+		if !errors.As(err, &appErr) || appErr.Fields["PassportSeries"] == "" {
+			t.Errorf("Expected PassportSeries error, got %v", err)
+		}
+	})
+
+	t.Run("Corrected document", func(t *testing.T) {
+		ds, driver := newLegacyDriver(t)
+		updated := *driver
+		updated.PassportSeries = "4510123456"
+		if err := ds.UpdateById(&updated); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if stored, _ := ds.GetById(driver.ID); stored.PassportSeries != "4510 123456" {
+			t.Errorf("Expected the canonical passport, got %q", stored.PassportSeries)
+		}
+		if invalid, err := ds.GetAllWithInvalidDocuments(); err != nil || len(invalid) != 0 {
+			t.Errorf("Expected no invalid drivers, got %+v (%v)", invalid, err)
+		}
+	})
+
+	t.Run("GetAllWithInvalidDocuments", func(t *testing.T) {
+		ds, driver := newLegacyDriver(t)
+		valid := &models.Driver{Name: "Petr", Surname: "Petrov", BirthDate: driver.BirthDate,
+			PassportSeries: "4511 654321", Snils: "112-233-445 95", LicenseSeries: "77 13 111111"}
+		if err := ds.Add(valid); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if invalid, err := ds.GetAllWithInvalidDocuments(); err != nil || len(invalid) != 1 || invalid[0].ID != driver.ID {
+			t.Errorf("Expected only the legacy driver, got %+v (%v)", invalid, err)
+		}
+	})
 }
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"busManager/repository"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newMemoryRepositories returns the repositories of a fresh in-memory store and a unit of work over them.
+// Every test builds its own store, so a single subtest can be run on its own.
+func newMemoryRepositories(t *testing.T) (repository.Repositories, *repository.MemoryUnitOfWork) {
+	t.Helper()
+	store := repository.NewMemoryStore()
+	return store.Repositories(), repository.NewMemoryUnitOfWork(store)
+}
+
+// addTestBus stores a bus directly in the repository, bypassing the service under test.
+func addTestBus(t *testing.T, repos repository.Repositories, bus *models.Bus) *models.Bus {
+	t.Helper()
+	if err := repos.Buses.Add(bus); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return bus
+}
+
+// addTestDriver stores a driver born on 1985-11-11 with the given documents.
+func addTestDriver(t *testing.T, repos repository.Repositories, passport, snils, license string) *models.Driver {
+	t.Helper()
+	driver := &models.Driver{Name: "John", Surname: "Doe", BirthDate: time.Date(1985, 11, 11, 0, 0, 0, 0, time.UTC),
+		PassportSeries: passport, Snils: snils, LicenseSeries: license}
+	if err := repos.Drivers.Add(driver); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return driver
+}
+
+// addTestRoute stores a route directly in the repository.
+func addTestRoute(t *testing.T, repos repository.Repositories, route *models.Route) *models.Route {
+	t.Helper()
+	if err := repos.Routes.Add(route); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return route
+}
+
+// expectField fails the test unless err is a validation error with the given message on field.
+func expectField(t *testing.T, err error, field, message string) {
+	t.Helper()
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) || appErr.Code != apperrors.CodeValidation || appErr.Fields[field] != message {
+		t.Errorf("Expected %s error %q, got %v", field, message, err)
+	}
+}
@@ -0,0 +1,13 @@
+package service
+
+import (
+	"busManager/models"
+	"time"
+)
+
+type IAuditService interface {
+	Query(filter models.AuditFilter) ([]models.AuditEntry, error)
+	GetByEntity(entityType, id string) ([]models.AuditEntry, error)
+	GetByRoute(routeId string) ([]models.AuditEntry, error)
+	GetByTimeRange(from, to time.Time) ([]models.AuditEntry, error)
+}
@@ -0,0 +1,15 @@
+package service
+
+import (
+	"busManager/models"
+	"context"
+	"time"
+)
+
+type IBackupService interface {
+	Create() (*models.Backup, error)
+	List() ([]models.Backup, error)
+	Restore(name string) error
+	Rotate() error
+	Run(ctx context.Context, interval time.Duration)
+}
@@ -7,6 +7,15 @@ type IBusService interface {
 	GetByNumber(number string) (*models.Bus, error)
 	Add(bus *models.Bus) error
 	DeleteById(id string) error
-	GetAll() []models.Bus
+	DeleteByIdWithPolicy(id string, policy DeletePolicy) error
+	GetAllRoutesById(id string) ([]models.Route, error)
+	List(query models.ListQuery) (models.Page[models.Bus], error)
+	GetAllDeleted() ([]models.Trashed[models.Bus], error)
+	RestoreById(id string) error
+	PurgeById(id string) error
+	GetAll() ([]models.Bus, error)
+	GetAllWithInvalidNumber() ([]models.Bus, error)
 	UpdateById(bus *models.Bus) error
+	ChangeStatus(id, status, reason string) (*models.Bus, error)
+	GetStatusHistoryById(id string) ([]models.BusStatusChange, error)
 }
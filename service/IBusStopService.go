@@ -7,6 +7,12 @@ type IBusStopService interface {
 	GetByName(name string) (*models.BusStop, error)
 	Add(stop *models.BusStop) error
 	DeleteById(id string) error
+	DeleteByIdWithPolicy(id string, policy DeletePolicy) error
+	GetAllRoutesById(id string) ([]models.Route, error)
+	List(query models.ListQuery) (models.Page[models.BusStop], error)
+	GetAllDeleted() ([]models.Trashed[models.BusStop], error)
+	RestoreById(id string) error
+	PurgeById(id string) error
 	GetAll() ([]models.BusStop, error)
 	UpdateById(stop *models.BusStop) error
 }
@@ -5,8 +5,17 @@ import "busManager/models"
 type IDriverService interface {
 	GetById(id string) (*models.Driver, error)
 	GetByPassportSeries(series string) (*models.Driver, error)
+	GetBySnils(snils string) (*models.Driver, error)
 	Add(driver *models.Driver) error
 	DeleteById(id string) error
-	GetAll() []models.Driver
+	DeleteByIdWithPolicy(id string, policy DeletePolicy) error
+	GetAllRoutesById(id string) ([]models.Route, error)
+	GetAllLicensesExpiringWithin(days int) ([]models.Driver, error)
+	List(query models.ListQuery) (models.Page[models.Driver], error)
+	GetAllDeleted() ([]models.Trashed[models.Driver], error)
+	RestoreById(id string) error
+	PurgeById(id string) error
+	GetAll() ([]models.Driver, error)
+	GetAllWithInvalidDocuments() ([]models.Driver, error)
 	UpdateById(driver *models.Driver) error
 }
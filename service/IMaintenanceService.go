@@ -0,0 +1,14 @@
+package service
+
+import "busManager/models"
+
+type IMaintenanceService interface {
+	AddInterval(interval *models.MaintenanceInterval) error
+	GetAllIntervals() ([]models.MaintenanceInterval, error)
+	UpdateInterval(interval *models.MaintenanceInterval) error
+	DeleteIntervalById(id string) error
+	AddRecord(record *models.MaintenanceRecord) error
+	GetAllRecordsByBusId(busId string) ([]models.MaintenanceRecord, error)
+	GetPlanByBusId(busId string) ([]models.MaintenanceDue, error)
+	GetDue(days, kilometers int) ([]models.MaintenanceDue, error)
+}
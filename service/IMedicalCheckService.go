@@ -0,0 +1,15 @@
+package service
+
+import (
+	"busManager/models"
+	"time"
+)
+
+type IMedicalCheckService interface {
+	Add(check *models.MedicalCheck) error
+	GetById(id string) (*models.MedicalCheck, error)
+	GetAllByDriverId(driverId string) ([]models.MedicalCheck, error)
+	IsClearedToday(driverId string) (bool, error)
+	GetClearedToday() ([]models.Driver, error)
+	GetDailyReport(day time.Time) ([]models.UnclearedDriver, error)
+}
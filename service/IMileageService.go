@@ -0,0 +1,16 @@
+package service
+
+import (
+	"busManager/models"
+	"time"
+)
+
+type IMileageService interface {
+	AddReading(reading *models.OdometerReading) error
+	GetAllReadingsByBusId(busId string) ([]models.OdometerReading, error)
+	AddTrips(trips *models.OperatedTrips) error
+	GetAllTripsByBusId(busId string, from, to time.Time) ([]models.OperatedTrips, error)
+	GetDailyMileage(busId string, from, to time.Time) ([]models.Mileage, error)
+	GetMonthlyMileage(busId string, from, to time.Time) ([]models.Mileage, error)
+	GetByBusId(busId string) (*models.BusMileage, error)
+}
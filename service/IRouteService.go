@@ -7,6 +7,10 @@ type IRouteService interface {
 	GetByNumber(number string) (*models.Route, error)
 	Add(route *models.Route) error
 	DeleteById(id string) error
+	List(query models.ListQuery) (models.Page[models.Route], error)
+	GetAllDeleted() ([]models.Trashed[models.Route], error)
+	RestoreById(id string) error
+	PurgeById(id string) error
 	GetAll() ([]models.Route, error)
 	UpdateById(route *models.Route) error
 	AssignDriver(routeId, driverId string) error
@@ -18,5 +22,6 @@ type IRouteService interface {
 	GetAllDriversById(routeId string) ([]models.Driver, error)
 	GetAllBusStopsById(routeId string) ([]models.BusStop, error)
 	GetAllBusesById(routeId string) ([]models.Bus, error)
+	ListSuitableBuses(routeId string, query models.ListQuery) (models.Page[models.Bus], error)
 	// TODO: getall for all models, unassign
 }
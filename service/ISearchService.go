@@ -0,0 +1,7 @@
+package service
+
+import "busManager/models"
+
+type ISearchService interface {
+	Search(query string) ([]models.SearchHit, error)
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"busManager/models"
+	"time"
+)
+
+type IShiftService interface {
+	Add(shift *models.Shift) error
+	GetById(id string) (*models.Shift, error)
+	GetAllByDriverId(driverId string, from, to time.Time) ([]models.Shift, error)
+	DeleteById(id string) error
+	GetComplianceReport(driverId string, from, to time.Time) (*models.ComplianceReport, error)
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"busManager/models"
+	"time"
+)
+
+type IWorkOrderService interface {
+	Open(order *models.WorkOrder) error
+	GetById(id string) (*models.WorkOrder, error)
+	GetAllByBusId(busId string) ([]models.WorkOrder, error)
+	GetAllActive() ([]models.WorkOrder, error)
+	UpdateById(order *models.WorkOrder) error
+	Start(id string) (*models.WorkOrder, error)
+	Close(id string) (*models.WorkOrder, error)
+	Cancel(id string) (*models.WorkOrder, error)
+	GetCostByBus(from, to time.Time) ([]models.RepairCost, error)
+	GetCostByModel(from, to time.Time) ([]models.RepairCost, error)
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"busManager/repository"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A service is due soon in the plan of a bus when it falls due within these.
+const (
+	dueSoonDays       = 14
+	dueSoonKilometers = 1000
+)
+
+type MaintenanceService struct {
+	repo    repository.IMaintenanceRepository
+	busRepo repository.IBusRepository
+	mileage repository.IMileageRepository
+	uow     repository.IUnitOfWork
+	audit   auditor
+}
+
+func NewMaintenanceService(r repository.IMaintenanceRepository, busRepo repository.IBusRepository) *MaintenanceService {
+	return &MaintenanceService{repo: r, busRepo: busRepo}
+}
+
+func (ms *MaintenanceService) WithUnitOfWork(uow repository.IUnitOfWork) *MaintenanceService {
+	ms.uow = uow
+	return ms
+}
+
+// WithMileage makes plans count the odometer readings of the bus besides
+// the mileage recorded at its services.
+func (ms *MaintenanceService) WithMileage(repo repository.IMileageRepository) *MaintenanceService {
+	ms.mileage = repo
+	return ms
+}
+
+// WithAudit records every interval change and every service added through
+// the service in repo as user.
+func (ms *MaintenanceService) WithAudit(repo repository.IAuditRepository, user string) *MaintenanceService {
+	ms.audit = newAuditor(repo, user)
+	return ms
+}
+
+func (ms MaintenanceService) transact(fn func(repos repository.Repositories) error) error {
+	return transact(ms.uow, repository.Repositories{Buses: ms.busRepo, Maintenance: ms.repo, Audit: ms.audit.repo}, fn)
+}
+
+// checkIntervalTaken fails when another interval is set for the same brand,
+// model and kind, compared as Matches does.
+func checkIntervalTaken(repos repository.Repositories, interval models.MaintenanceInterval) error {
+	intervals, err := repos.Maintenance.GetAllIntervals()
+	if err != nil {
+		return err
+	}
+	for _, other := range intervals {
+		if other.ID != interval.ID && strings.EqualFold(strings.TrimSpace(other.Kind), strings.TrimSpace(interval.Kind)) &&
+			other.Matches(models.Bus{Brand: interval.Brand, BusModel: interval.BusModel}) {
+			return apperrors.AlreadyExists(models.EntityMaintenanceInterval, "Maintenance interval already exists")
+		}
+	}
+	return nil
+}
+
+func (ms MaintenanceService) AddInterval(interval *models.MaintenanceInterval) error {
+	if err := models.MaintenanceIntervalRules.Validate(models.EntityMaintenanceInterval, *interval); err != nil {
+		return err
+	}
+	return ms.transact(func(repos repository.Repositories) error {
+		if err := checkIntervalTaken(repos, *interval); err != nil {
+			return err
+		}
+		if err := repos.Maintenance.AddInterval(interval); err != nil {
+			return err
+		}
+		return ms.audit.record(repos, models.AuditAdd, models.EntityMaintenanceInterval, interval.ID, "", nil, interval)
+	})
+}
+
+func (ms MaintenanceService) GetAllIntervals() ([]models.MaintenanceInterval, error) {
+	return ms.repo.GetAllIntervals()
+}
+
+func (ms MaintenanceService) UpdateInterval(interval *models.MaintenanceInterval) error {
+	if err := models.MaintenanceIntervalRules.Validate(models.EntityMaintenanceInterval, *interval); err != nil {
+		return err
+	}
+	return ms.transact(func(repos repository.Repositories) error {
+		before, err := repos.Maintenance.GetIntervalById(interval.ID)
+		if err != nil {
+			return err
+		}
+		if err := checkIntervalTaken(repos, *interval); err != nil {
+			return err
+		}
+		if err := repos.Maintenance.UpdateInterval(interval); err != nil {
+			return err
+		}
+		return ms.audit.record(repos, models.AuditUpdate, models.EntityMaintenanceInterval, interval.ID, "", before, interval)
+	})
+}
+
+func (ms MaintenanceService) DeleteIntervalById(id string) error {
+	return ms.transact(func(repos repository.Repositories) error {
+		before, err := repos.Maintenance.GetIntervalById(id)
+		if err != nil {
+			return err
+		}
+		if err := repos.Maintenance.DeleteIntervalById(id); err != nil {
+			return err
+		}
+		return ms.audit.record(repos, models.AuditDelete, models.EntityMaintenanceInterval, id, "", before, nil)
+	})
+}
+
+// AddRecord records a service of a bus that is not in the trash and moves
+// the LastRepairDate of the bus forward to it. The mileage must fit between
+// the readings recorded at earlier and later services.
+func (ms MaintenanceService) AddRecord(record *models.MaintenanceRecord) error {
+	if err := models.MaintenanceRecordRules.Validate(models.EntityMaintenance, *record); err != nil {
+		return err
+	}
+	return ms.transact(func(repos repository.Repositories) error {
+		bus, err := repos.Buses.GetById(record.BusID)
+		if err != nil {
+			return err
+		}
+		if record.PerformedAt.Before(bus.AssemblyDate) {
+			message := "PerformedAt must not be before the assembly date of the bus"
+			return apperrors.Validation(models.EntityMaintenance, message, map[string]string{"PerformedAt": message})
+		}
+		history, err := repos.Maintenance.GetAllRecordsByBusId(record.BusID)
+		if err != nil {
+			return err
+		}
+		for _, earlier := range history {
+			var message string
+			if !earlier.PerformedAt.After(record.PerformedAt) && earlier.Mileage > record.Mileage {
+				message = fmt.Sprintf("Mileage must not be below %d km recorded on %s", earlier.Mileage, earlier.PerformedAt.Format(time.DateOnly))
+			} else if earlier.PerformedAt.After(record.PerformedAt) && earlier.Mileage < record.Mileage {
+				message = fmt.Sprintf("Mileage must not be above %d km recorded on %s", earlier.Mileage, earlier.PerformedAt.Format(time.DateOnly))
+			}
+			if message != "" {
+				return apperrors.Validation(models.EntityMaintenance, message, map[string]string{"Mileage": message})
+			}
+		}
+		if err := repos.Maintenance.AddRecord(record); err != nil {
+			return err
+		}
+		if err := ms.audit.record(repos, models.AuditAdd, models.EntityMaintenance, record.ID, "", nil, record); err != nil {
+			return err
+		}
+		if !record.PerformedAt.After(bus.LastRepairDate) {
+			return nil
+		}
+		updated := *bus
+		updated.LastRepairDate = record.PerformedAt
+		if err := repos.Buses.UpdateById(&updated); err != nil {
+			return err
+		}
+		return ms.audit.record(repos, models.AuditUpdate, models.EntityBus, bus.ID, "", bus, &updated)
+	})
+}
+
+func (ms MaintenanceService) GetAllRecordsByBusId(busId string) ([]models.MaintenanceRecord, error) {
+	return ms.repo.GetAllRecordsByBusId(busId)
+}
+
+// odometer returns the last odometer reading of the bus, or 0 without a
+// mileage repository or readings.
+func (ms MaintenanceService) odometer(busId string) (int, error) {
+	if ms.mileage == nil {
+		return 0, nil
+	}
+	readings, err := ms.mileage.GetAllReadingsByBusId(busId)
+	if err != nil || len(readings) == 0 {
+		return 0, err
+	}
+	return readings[len(readings)-1].Kilometers, nil
+}
+
+// plan returns the services bus needs by the intervals matching it, given
+// its records newest first and its odometer. Services falling due before
+// soon or within soonKilometers are due soon.
+func plan(bus models.Bus, intervals []models.MaintenanceInterval, records []models.MaintenanceRecord, odometer int, now, soon time.Time, soonKilometers int) []models.MaintenanceDue {
+	mileage := odometer
+	for _, record := range records {
+		mileage = max(mileage, record.Mileage)
+	}
+	var due []models.MaintenanceDue
+	for _, interval := range intervals {
+		if !interval.Matches(bus) {
+			continue
+		}
+		next := models.MaintenanceDue{Bus: bus, Interval: interval, Mileage: mileage, Status: models.MaintenanceOK}
+		since, sinceMileage := bus.AssemblyDate, 0
+		for _, record := range records {
+			if strings.EqualFold(strings.TrimSpace(record.Kind), strings.TrimSpace(interval.Kind)) {
+				next.Last = &record
+				since, sinceMileage = record.PerformedAt, record.Mileage
+				break
+			}
+		}
+		if interval.Days > 0 {
+			next.DueDate = since.AddDate(0, 0, interval.Days)
+		}
+		if interval.Kilometers > 0 {
+			next.DueMileage = sinceMileage + interval.Kilometers
+		}
+		byDate := !next.DueDate.IsZero()
+		byMileage := next.DueMileage > 0
+		switch {
+		case byDate && !now.Before(next.DueDate), byMileage && mileage >= next.DueMileage:
+			next.Status = models.MaintenanceOverdue
+		case byDate && !soon.Before(next.DueDate), byMileage && mileage+soonKilometers >= next.DueMileage:
+			next.Status = models.MaintenanceDueSoon
+		}
+		due = append(due, next)
+	}
+	return due
+}
+
+// GetPlanByBusId returns the next service of each kind the bus needs. A
+// service is due soon within two weeks or 1000 km.
+func (ms MaintenanceService) GetPlanByBusId(busId string) ([]models.MaintenanceDue, error) {
+	bus, err := ms.busRepo.GetById(busId)
+	if err != nil {
+		return nil, err
+	}
+	intervals, err := ms.repo.GetAllIntervals()
+	if err != nil {
+		return nil, err
+	}
+	records, err := ms.repo.GetAllRecordsByBusId(busId)
+	if err != nil {
+		return nil, err
+	}
+	odometer, err := ms.odometer(busId)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	return plan(*bus, intervals, records, odometer, now, now.AddDate(0, 0, dueSoonDays), dueSoonKilometers), nil
+}
+
+// GetDue lists the services of all buses but decommissioned ones that are
+// overdue or fall due within days or kilometers, overdue ones first.
+func (ms MaintenanceService) GetDue(days, kilometers int) ([]models.MaintenanceDue, error) {
+	fields := map[string]string{}
+	if days < 0 {
+		fields["Days"] = "Days must not be negative"
+	}
+	if kilometers < 0 {
+		fields["Kilometers"] = "Kilometers must not be negative"
+	}
+	if len(fields) > 0 {
+		return nil, apperrors.Validation(models.EntityMaintenance, "Invalid due soon limits", fields)
+	}
+	buses, err := ms.busRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	intervals, err := ms.repo.GetAllIntervals()
+	if err != nil {
+		return nil, err
+	}
+	records, err := ms.repo.GetAllRecords()
+	if err != nil {
+		return nil, err
+	}
+	byBus := map[string][]models.MaintenanceRecord{}
+	for _, record := range records {
+		byBus[record.BusID] = append(byBus[record.BusID], record)
+	}
+	now := time.Now().UTC()
+	due := []models.MaintenanceDue{}
+	for _, bus := range buses {
+		if bus.Status == models.BusDecommissioned {
+			continue
+		}
+		odometer, err := ms.odometer(bus.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, next := range plan(bus, intervals, byBus[bus.ID], odometer, now, now.AddDate(0, 0, days), kilometers) {
+			if next.Status != models.MaintenanceOK {
+				due = append(due, next)
+			}
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		a, b := due[i], due[j]
+		if (a.Status == models.MaintenanceOverdue) != (b.Status == models.MaintenanceOverdue) {
+			return a.Status == models.MaintenanceOverdue
+		}
+		if a.Bus.RegisterNumber != b.Bus.RegisterNumber {
+			return a.Bus.RegisterNumber < b.Bus.RegisterNumber
+		}
+		return a.Interval.Kind < b.Interval.Kind
+	})
+	return due, nil
+}
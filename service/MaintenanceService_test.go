@@ -0,0 +1,191 @@
+package service
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"busManager/repository"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newMemoryMaintenanceService(t *testing.T) (*MaintenanceService, repository.Repositories, *repository.MemoryUnitOfWork) {
+	repos, uow := newMemoryRepositories(t)
+	ms := NewMaintenanceService(repos.Maintenance, repos.Buses).WithUnitOfWork(uow).WithAudit(repos.Audit, "mechanic")
+	return ms, repos, uow
+}
+
+// addMaintenancePlan stores the intervals of a ЛиАЗ 5292 and a ПАЗ 3205, one bus of each, and a ТО-1 of the ЛиАЗ
+// performed 80 days before now at 100000 km.
+func addMaintenancePlan(t *testing.T, ms *MaintenanceService, repos repository.Repositories, now time.Time) (bus, other *models.Bus) {
+	t.Helper()
+	bus = addTestBus(t, repos, newValidBus("А123ВС77"))
+	other = newValidBus("В456ОР77")
+	other.Brand, other.BusModel = "ПАЗ", "3205"
+	addTestBus(t, repos, other)
+	for _, interval := range []*models.MaintenanceInterval{
+		{Brand: "ЛиАЗ", BusModel: "5292", Kind: "ТО-1", Days: 90, Kilometers: 10000},
+		{Brand: "ЛиАЗ", BusModel: "5292", Kind: "ТО-2", Kilometers: 20000},
+		{Brand: "ПАЗ", BusModel: "3205", Kind: "ТО-1", Days: 60},
+	} {
+		if err := ms.AddInterval(interval); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if err := ms.AddRecord(&models.MaintenanceRecord{BusID: bus.ID, Kind: "ТО-1", PerformedAt: now.AddDate(0, 0, -80), Mileage: 100000}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return bus, other
+}
+
+func TestMaintenanceService_AddInterval(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ms, repos, _ := newMemoryMaintenanceService(t)
+		interval := &models.MaintenanceInterval{Brand: "ЛиАЗ", BusModel: "5292", Kind: "ТО-1", Days: 90, Kilometers: 10000}
+		if err := ms.AddInterval(interval); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		entries, _ := repos.Audit.Query(models.AuditFilter{EntityType: models.EntityMaintenanceInterval, EntityId: interval.ID})
+		if len(entries) != 1 || entries[0].Action != models.AuditAdd {
+			t.Errorf("Expected the interval to be audited, got %+v", entries)
+		}
+	})
+
+	t.Run("Same kind for the model", func(t *testing.T) {
+		ms, _, _ := newMemoryMaintenanceService(t)
+		if err := ms.AddInterval(&models.MaintenanceInterval{Brand: "ЛиАЗ", BusModel: "5292", Kind: "ТО-1", Days: 90}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := ms.AddInterval(&models.MaintenanceInterval{Brand: " лиаз ", BusModel: "5292", Kind: "то-1", Days: 30}); !errors.Is(err, apperrors.ErrAlreadyExists) {
+			t.Errorf("Expected already exists error, got %v", err)
+		}
+	})
+
+	t.Run("Neither days nor kilometers", func(t *testing.T) {
+		ms, _, _ := newMemoryMaintenanceService(t)
+		err := ms.AddInterval(&models.MaintenanceInterval{Brand: "ЛиАЗ", BusModel: "5292", Kind: "ТО-3"})
+		expectField(t, err, "Days", "Days or Kilometers is required")
+	})
+}
+
+func TestMaintenanceService_AddRecord(t *testing.T) {
+	now := time.Now().UTC()
+	performed := now.AddDate(0, 0, -80)
+
+	t.Run("Updates LastRepairDate", func(t *testing.T) {
+		ms, repos, _ := newMemoryMaintenanceService(t)
+		bus := addTestBus(t, repos, newValidBus("А123ВС77"))
+		if err := ms.AddRecord(&models.MaintenanceRecord{BusID: bus.ID, Kind: "ТО-1", PerformedAt: performed, Mileage: 100000}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if stored, _ := repos.Buses.GetById(bus.ID); !stored.LastRepairDate.Equal(performed) {
+			t.Errorf("Expected LastRepairDate %v, got %v", performed, stored.LastRepairDate)
+		}
+		entries, _ := repos.Audit.Query(models.AuditFilter{EntityType: models.EntityBus, EntityId: bus.ID})
+		if len(entries) != 1 || entries[0].Action != models.AuditUpdate {
+			t.Errorf("Expected the LastRepairDate update to be audited, got %+v", entries)
+		}
+	})
+
+	t.Run("Older record keeps LastRepairDate", func(t *testing.T) {
+		ms, repos, _ := newMemoryMaintenanceService(t)
+		bus := addTestBus(t, repos, newValidBus("А123ВС77"))
+		record := &models.MaintenanceRecord{BusID: bus.ID, Kind: "ТО-1", PerformedAt: performed, Mileage: 100000}
+		repair := &models.MaintenanceRecord{BusID: bus.ID, Kind: "Ремонт", PerformedAt: now.AddDate(0, 0, -200), Mileage: 95000}
+		for _, r := range []*models.MaintenanceRecord{record, repair} {
+			if err := ms.AddRecord(r); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		}
+		if stored, _ := repos.Buses.GetById(bus.ID); !stored.LastRepairDate.Equal(performed) {
+			t.Errorf("Expected an older record to keep LastRepairDate, got %v", stored.LastRepairDate)
+		}
+		if records, _ := ms.GetAllRecordsByBusId(bus.ID); len(records) != 2 || records[0].ID != record.ID {
+			t.Errorf("Expected 2 records newest first, got %+v", records)
+		}
+	})
+
+	t.Run("Bus update keeps LastRepairDate", func(t *testing.T) {
+		ms, repos, uow := newMemoryMaintenanceService(t)
+		bus := addTestBus(t, repos, newValidBus("А123ВС77"))
+		if err := ms.AddRecord(&models.MaintenanceRecord{BusID: bus.ID, Kind: "ТО-1", PerformedAt: performed, Mileage: 100000}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		updated, _ := repos.Buses.GetById(bus.ID)
+		updated.LastRepairDate = now.AddDate(-1, 0, 0)
+		if err := NewBusService(repos.Buses).WithUnitOfWork(uow).UpdateById(updated); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if stored, _ := repos.Buses.GetById(bus.ID); !stored.LastRepairDate.Equal(performed) {
+			t.Errorf("Expected LastRepairDate to be kept, got %v", stored.LastRepairDate)
+		}
+	})
+
+	t.Run("Mileage below an earlier record", func(t *testing.T) {
+		ms, repos, _ := newMemoryMaintenanceService(t)
+		bus := addTestBus(t, repos, newValidBus("А123ВС77"))
+		if err := ms.AddRecord(&models.MaintenanceRecord{BusID: bus.ID, Kind: "ТО-1", PerformedAt: performed, Mileage: 100000}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		err := ms.AddRecord(&models.MaintenanceRecord{BusID: bus.ID, Kind: "ТО-1", PerformedAt: now.AddDate(0, 0, -50), Mileage: 90000})
+		expectField(t, err, "Mileage", "Mileage must not be below 100000 km recorded on "+performed.Format(time.DateOnly))
+	})
+
+	t.Run("Before assembly", func(t *testing.T) {
+		ms, repos, _ := newMemoryMaintenanceService(t)
+		bus := addTestBus(t, repos, newValidBus("А123ВС77"))
+		err := ms.AddRecord(&models.MaintenanceRecord{BusID: bus.ID, Kind: "ТО-1", PerformedAt: bus.AssemblyDate.AddDate(0, 0, -1)})
+		expectField(t, err, "PerformedAt", "PerformedAt must not be before the assembly date of the bus")
+	})
+
+	t.Run("Bus not found", func(t *testing.T) {
+		ms, _, _ := newMemoryMaintenanceService(t)
+		if err := ms.AddRecord(&models.MaintenanceRecord{BusID: "missing", Kind: "ТО-1", PerformedAt: now}); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Expected not found error, got %v", err)
+		}
+	})
+}
+
+func TestMaintenanceService_GetPlanByBusId(t *testing.T) {
+	ms, repos, _ := newMemoryMaintenanceService(t)
+	now := time.Now().UTC()
+	bus, _ := addMaintenancePlan(t, ms, repos, now)
+
+	due, err := ms.GetPlanByBusId(bus.ID)
+	if err != nil || len(due) != 2 {
+		t.Fatalf("Expected two services, got %+v (%v)", due, err)
+	}
+	if due[0].Interval.Kind != "ТО-1" || due[0].Status != models.MaintenanceDueSoon || due[0].Last == nil ||
+		!due[0].DueDate.Equal(now.AddDate(0, 0, 10)) || due[0].DueMileage != 110000 {
+		t.Errorf("Expected ТО-1 due soon in 10 days, got %+v", due[0])
+	}
+	if due[1].Interval.Kind != "ТО-2" || due[1].Status != models.MaintenanceOverdue || due[1].Last != nil ||
+		!due[1].DueDate.IsZero() || due[1].DueMileage != 20000 || due[1].Mileage != 100000 {
+		t.Errorf("Expected ТО-2 overdue by mileage, got %+v", due[1])
+	}
+}
+
+func TestMaintenanceService_GetDue(t *testing.T) {
+	ms, repos, _ := newMemoryMaintenanceService(t)
+	bus, other := addMaintenancePlan(t, ms, repos, time.Now().UTC())
+
+	t.Run("Overdue", func(t *testing.T) {
+		due, err := ms.GetDue(0, 0)
+		if err != nil || len(due) != 2 || due[0].Bus.ID != bus.ID || due[0].Interval.Kind != "ТО-2" || due[1].Bus.ID != other.ID {
+			t.Errorf("Expected the overdue services only, got %+v (%v)", due, err)
+		}
+	})
+
+	t.Run("Due soon", func(t *testing.T) {
+		due, err := ms.GetDue(14, 0)
+		if err != nil || len(due) != 3 || due[2].Status != models.MaintenanceDueSoon {
+			t.Errorf("Expected overdue services before the one due soon, got %+v (%v)", due, err)
+		}
+	})
+
+	t.Run("Negative days", func(t *testing.T) {
+		if _, err := ms.GetDue(-1, 0); !errors.Is(err, apperrors.ErrValidation) {
+			t.Errorf("Expected validation error, got %v", err)
+		}
+	})
+}
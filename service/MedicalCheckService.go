@@ -0,0 +1,151 @@
+package service
+
+import (
+	"busManager/calendar"
+	"busManager/models"
+	"busManager/repository"
+	"time"
+)
+
+type MedicalCheckService struct {
+	repo       repository.IMedicalCheckRepository
+	driverRepo repository.IDriverRepository
+	uow        repository.IUnitOfWork
+	audit      auditor
+	loc        *time.Location
+}
+
+func NewMedicalCheckService(r repository.IMedicalCheckRepository, driverRepo repository.IDriverRepository) *MedicalCheckService {
+	return &MedicalCheckService{repo: r, driverRepo: driverRepo, loc: time.Local}
+}
+
+// WithLocation sets the zone in which the days of the checks start; the
+// default is time.Local.
+func (ms *MedicalCheckService) WithLocation(loc *time.Location) *MedicalCheckService {
+	ms.loc = loc
+	return ms
+}
+
+func (ms *MedicalCheckService) WithUnitOfWork(uow repository.IUnitOfWork) *MedicalCheckService {
+	ms.uow = uow
+	return ms
+}
+
+// WithAudit records every check added through the service in repo as user.
+func (ms *MedicalCheckService) WithAudit(repo repository.IAuditRepository, user string) *MedicalCheckService {
+	ms.audit = newAuditor(repo, user)
+	return ms
+}
+
+func (ms MedicalCheckService) transact(fn func(repos repository.Repositories) error) error {
+	return transact(ms.uow, repository.Repositories{Drivers: ms.driverRepo, MedicalChecks: ms.repo, Audit: ms.audit.repo}, fn)
+}
+
+// Add records a check of a driver that is not in the trash.
+func (ms MedicalCheckService) Add(check *models.MedicalCheck) error {
+	if err := models.MedicalCheckRules.Validate(models.EntityMedicalCheck, *check); err != nil {
+		return err
+	}
+	return ms.transact(func(repos repository.Repositories) error {
+		if _, err := repos.Drivers.GetById(check.DriverID); err != nil {
+			return err
+		}
+		if err := repos.MedicalChecks.Add(check); err != nil {
+			return err
+		}
+		return ms.audit.record(repos, models.AuditAdd, models.EntityMedicalCheck, check.ID, "", nil, check)
+	})
+}
+
+func (ms MedicalCheckService) GetById(id string) (*models.MedicalCheck, error) {
+	return ms.repo.GetById(id)
+}
+
+func (ms MedicalCheckService) GetAllByDriverId(driverId string) ([]models.MedicalCheck, error) {
+	return ms.repo.GetAllByDriverId(driverId)
+}
+
+// latestChecks returns the last check of each driver examined on the day of
+// t. A driver is cleared when that check passed.
+func (ms MedicalCheckService) latestChecks(t time.Time) (map[string]models.MedicalCheck, error) {
+	checks, err := ms.repo.GetAllByTimeRange(calendar.Bounds(t, ms.loc))
+	if err != nil {
+		return nil, err
+	}
+	latest := map[string]models.MedicalCheck{}
+	for _, check := range checks {
+		if _, ok := latest[check.DriverID]; !ok {
+			latest[check.DriverID] = check
+		}
+	}
+	return latest, nil
+}
+
+// IsClearedToday reports whether the last check of the driver today passed.
+func (ms MedicalCheckService) IsClearedToday(driverId string) (bool, error) {
+	if _, err := ms.driverRepo.GetById(driverId); err != nil {
+		return false, err
+	}
+	latest, err := ms.latestChecks(time.Now())
+	if err != nil {
+		return false, err
+	}
+	check, ok := latest[driverId]
+	return ok && check.Cleared(), nil
+}
+
+// GetClearedToday returns the drivers whose last check today passed.
+func (ms MedicalCheckService) GetClearedToday() ([]models.Driver, error) {
+	return ms.clearedOn(time.Now())
+}
+
+func (ms MedicalCheckService) clearedOn(t time.Time) ([]models.Driver, error) {
+	latest, err := ms.latestChecks(t)
+	if err != nil {
+		return nil, err
+	}
+	drivers, err := ms.driverRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	cleared := []models.Driver{}
+	for _, driver := range drivers {
+		if check, ok := latest[driver.ID]; ok && check.Cleared() {
+			cleared = append(cleared, driver)
+		}
+	}
+	return cleared, nil
+}
+
+// GetDailyReport lists the drivers assigned to routes who were not cleared
+// on the day of t: never examined that day or failed their last check.
+func (ms MedicalCheckService) GetDailyReport(t time.Time) ([]models.UnclearedDriver, error) {
+	latest, err := ms.latestChecks(t)
+	if err != nil {
+		return nil, err
+	}
+	drivers, err := ms.driverRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	report := []models.UnclearedDriver{}
+	for _, driver := range drivers {
+		check, examined := latest[driver.ID]
+		if examined && check.Cleared() {
+			continue
+		}
+		routes, err := ms.driverRepo.GetAllRoutesById(driver.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(routes) == 0 {
+			continue
+		}
+		line := models.UnclearedDriver{Driver: driver, Routes: routes}
+		if examined {
+			line.LastCheck = &check
+		}
+		report = append(report, line)
+	}
+	return report, nil
+}
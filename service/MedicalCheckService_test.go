@@ -0,0 +1,172 @@
+package service
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"busManager/repository"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newMemoryMedicalCheckService(t *testing.T) (*MedicalCheckService, repository.Repositories) {
+	repos, uow := newMemoryRepositories(t)
+	ms := NewMedicalCheckService(repos.MedicalChecks, repos.Drivers).WithUnitOfWork(uow).WithAudit(repos.Audit, "nurse")
+	return ms, repos
+}
+
+func newMedicalCheck(driver *models.Driver, at time.Time, result string, alcohol float64) *models.MedicalCheck {
+	return &models.MedicalCheck{DriverID: driver.ID, CheckedAt: at, Examiner: "Dr. Ivanova", Result: result,
+		Systolic: 120, Diastolic: 80, Alcohol: alcohol}
+}
+
+// addMedicalDay stores the checks of a working day: three drivers of route 12, of whom one was cleared after
+// failing yesterday, one failed after passing and one was not examined, and an idle driver who passed yesterday.
+func addMedicalDay(t *testing.T, ms *MedicalCheckService, repos repository.Repositories, now time.Time) (cleared, failed, unexamined, idle *models.Driver) {
+	t.Helper()
+	cleared = addTestDriver(t, repos, "4510 123456", "123-456-789 64", "77 12 345678")
+	failed = addTestDriver(t, repos, "4511 654321", "112-233-445 95", "77 13 111111")
+	unexamined = addTestDriver(t, repos, "4512 111111", "111-111-111 45", "77 14 222222")
+	idle = addTestDriver(t, repos, "4513 222222", "222-222-222 90", "77 15 333333")
+	route := addTestRoute(t, repos, &models.Route{Number: "12"})
+	for _, driver := range []*models.Driver{cleared, failed, unexamined} {
+		if err := repos.Routes.AssignDriver(route.ID, driver.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	for _, c := range []*models.MedicalCheck{
+		newMedicalCheck(cleared, now.AddDate(0, 0, -1), models.MedicalFailed, 0),
+		newMedicalCheck(cleared, now, models.MedicalPassed, 0),
+		newMedicalCheck(failed, now, models.MedicalPassed, 0),
+		newMedicalCheck(failed, now, models.MedicalFailed, 0.3),
+		newMedicalCheck(idle, now.AddDate(0, 0, -1), models.MedicalPassed, 0),
+	} {
+		if err := ms.Add(c); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	return cleared, failed, unexamined, idle
+}
+
+func TestMedicalCheckService_Add(t *testing.T) {
+	now := time.Now().UTC()
+
+	t.Run("Success", func(t *testing.T) {
+		ms, repos := newMemoryMedicalCheckService(t)
+		driver := addTestDriver(t, repos, "4510 123456", "123-456-789 64", "77 12 345678")
+		check := newMedicalCheck(driver, now, models.MedicalPassed, 0)
+		if err := ms.Add(check); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		entries, _ := repos.Audit.Query(models.AuditFilter{EntityType: models.EntityMedicalCheck, EntityId: check.ID})
+		if len(entries) != 1 || entries[0].Action != models.AuditAdd || entries[0].User != "nurse" {
+			t.Errorf("Expected the check to be audited, got %+v", entries)
+		}
+	})
+
+	t.Run("Passed with alcohol", func(t *testing.T) {
+		ms, repos := newMemoryMedicalCheckService(t)
+		driver := addTestDriver(t, repos, "4510 123456", "123-456-789 64", "77 12 345678")
+		expectField(t, ms.Add(newMedicalCheck(driver, now, models.MedicalPassed, 0.2)), "Result", "A driver with alcohol cannot pass")
+	})
+
+	t.Run("Driver not found", func(t *testing.T) {
+		ms, _ := newMemoryMedicalCheckService(t)
+		if err := ms.Add(newMedicalCheck(&models.Driver{ID: "missing"}, now, models.MedicalPassed, 0)); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Expected driver not found, got %v", err)
+		}
+	})
+}
+
+func TestMedicalCheckService_GetAllByDriverId(t *testing.T) {
+	ms, repos := newMemoryMedicalCheckService(t)
+	cleared, _, unexamined, _ := addMedicalDay(t, ms, repos, time.Now().UTC())
+
+	t.Run("Newest first", func(t *testing.T) {
+		checks, err := ms.GetAllByDriverId(cleared.ID)
+		if err != nil || len(checks) != 2 || checks[0].Result != models.MedicalPassed {
+			t.Errorf("Expected two checks newest first, got %+v (%v)", checks, err)
+		}
+	})
+
+	t.Run("No checks", func(t *testing.T) {
+		if checks, err := ms.GetAllByDriverId(unexamined.ID); err != nil || len(checks) != 0 {
+			t.Errorf("Expected no checks, got %+v (%v)", checks, err)
+		}
+	})
+}
+
+func TestMedicalCheckService_IsClearedToday(t *testing.T) {
+	ms, repos := newMemoryMedicalCheckService(t)
+	cleared, failed, unexamined, idle := addMedicalDay(t, ms, repos, time.Now().UTC())
+
+	for _, tc := range []struct {
+		name   string
+		driver *models.Driver
+		want   bool
+	}{
+		{"Passed today", cleared, true},
+		{"Last check failed", failed, false},
+		{"Not examined", unexamined, false},
+		{"Passed yesterday", idle, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if ok, err := ms.IsClearedToday(tc.driver.ID); err != nil || ok != tc.want {
+				t.Errorf("Expected %v, got %v (%v)", tc.want, ok, err)
+			}
+		})
+	}
+}
+
+func TestMedicalCheckService_GetClearedToday(t *testing.T) {
+	ms, repos := newMemoryMedicalCheckService(t)
+	cleared, _, _, _ := addMedicalDay(t, ms, repos, time.Now().UTC())
+
+	drivers, err := ms.GetClearedToday()
+	if err != nil || len(drivers) != 1 || drivers[0].ID != cleared.ID {
+		t.Errorf("Expected only the cleared driver, got %+v (%v)", drivers, err)
+	}
+}
+
+func TestMedicalCheckService_GetDailyReport(t *testing.T) {
+	ms, repos := newMemoryMedicalCheckService(t)
+	now := time.Now().UTC()
+	_, failed, unexamined, _ := addMedicalDay(t, ms, repos, now)
+
+	report, err := ms.GetDailyReport(now)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(report) != 2 || report[0].Driver.ID != failed.ID || report[1].Driver.ID != unexamined.ID {
+		t.Fatalf("Expected the failed and the unexamined driver, got %+v", report)
+	}
+	if report[0].LastCheck == nil || report[0].LastCheck.Alcohol != 0.3 || report[1].LastCheck != nil {
+		t.Errorf("Expected last checks of the day, got %+v %+v", report[0].LastCheck, report[1].LastCheck)
+	}
+	if len(report[0].Routes) != 1 || report[0].Routes[0].Number != "12" {
+		t.Errorf("Expected route 12 in the report, got %+v", report[0].Routes)
+	}
+}
+
+func TestMedicalCheckService_Location(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	ms, repos := newMemoryMedicalCheckService(t)
+	ms.WithLocation(moscow)
+	driver := addTestDriver(t, repos, "4510 123456", "123-456-789 64", "77 12 345678")
+	route := addTestRoute(t, repos, &models.Route{Number: "12"})
+	if err := repos.Routes.AssignDriver(route.ID, driver.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Examined at 01:30 in Moscow for the night shift, which is still the
+	// day before in UTC.
+	if err := ms.Add(newMedicalCheck(driver, time.Date(2024, 5, 6, 22, 30, 0, 0, time.UTC), models.MedicalPassed, 0)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if report, err := ms.GetDailyReport(time.Date(2024, 5, 7, 12, 0, 0, 0, moscow)); err != nil || len(report) != 0 {
+		t.Errorf("Expected the driver cleared on May 7 in Moscow, got %+v (%v)", report, err)
+	}
+	if report, err := ms.GetDailyReport(time.Date(2024, 5, 6, 23, 0, 0, 0, moscow)); err != nil || len(report) != 1 || report[0].LastCheck != nil {
+		t.Errorf("Expected the driver unexamined on May 6 in Moscow, got %+v (%v)", report, err)
+	}
+}
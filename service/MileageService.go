@@ -0,0 +1,285 @@
+package service
+
+import (
+	"busManager/apperrors"
+	"busManager/calendar"
+	"busManager/models"
+	"busManager/repository"
+	"fmt"
+	"time"
+)
+
+// maxMileageDays bounds the period of a mileage report.
+const maxMileageDays = 3660
+
+type MileageService struct {
+	repo      repository.IMileageRepository
+	busRepo   repository.IBusRepository
+	routeRepo repository.IRouteRepository
+	uow       repository.IUnitOfWork
+	audit     auditor
+	loc       *time.Location
+}
+
+func NewMileageService(r repository.IMileageRepository, busRepo repository.IBusRepository, routeRepo repository.IRouteRepository) *MileageService {
+	return &MileageService{repo: r, busRepo: busRepo, routeRepo: routeRepo, loc: time.Local}
+}
+
+// WithLocation sets the zone in which the days of the trips and the mileage
+// reports start; the default is time.Local.
+func (ms *MileageService) WithLocation(loc *time.Location) *MileageService {
+	ms.loc = loc
+	return ms
+}
+
+func (ms *MileageService) WithUnitOfWork(uow repository.IUnitOfWork) *MileageService {
+	ms.uow = uow
+	return ms
+}
+
+// WithAudit records every reading and trip record added through the service
+// in repo as user.
+func (ms *MileageService) WithAudit(repo repository.IAuditRepository, user string) *MileageService {
+	ms.audit = newAuditor(repo, user)
+	return ms
+}
+
+func (ms MileageService) transact(fn func(repos repository.Repositories) error) error {
+	return transact(ms.uow, repository.Repositories{Buses: ms.busRepo, Routes: ms.routeRepo, Mileage: ms.repo, Audit: ms.audit.repo}, fn)
+}
+
+// AddReading records the odometer of a bus that is not in the trash. The
+// odometer never goes back: the reading must fit between the earlier and
+// later ones.
+func (ms MileageService) AddReading(reading *models.OdometerReading) error {
+	if err := models.OdometerReadingRules.Validate(models.EntityOdometerReading, *reading); err != nil {
+		return err
+	}
+	return ms.transact(func(repos repository.Repositories) error {
+		bus, err := repos.Buses.GetById(reading.BusID)
+		if err != nil {
+			return err
+		}
+		if reading.ReadAt.Before(bus.AssemblyDate) {
+			message := "ReadAt must not be before the assembly date of the bus"
+			return apperrors.Validation(models.EntityOdometerReading, message, map[string]string{"ReadAt": message})
+		}
+		readings, err := repos.Mileage.GetAllReadingsByBusId(reading.BusID)
+		if err != nil {
+			return err
+		}
+		for _, other := range readings {
+			var message string
+			if !other.ReadAt.After(reading.ReadAt) && other.Kilometers > reading.Kilometers {
+				message = fmt.Sprintf("Kilometers must not be below %d km read on %s", other.Kilometers, other.ReadAt.Format(time.DateOnly))
+			} else if other.ReadAt.After(reading.ReadAt) && other.Kilometers < reading.Kilometers {
+				message = fmt.Sprintf("Kilometers must not be above %d km read on %s", other.Kilometers, other.ReadAt.Format(time.DateOnly))
+			}
+			if message != "" {
+				return apperrors.Validation(models.EntityOdometerReading, message, map[string]string{"Kilometers": message})
+			}
+		}
+		if err := repos.Mileage.AddReading(reading); err != nil {
+			return err
+		}
+		return ms.audit.record(repos, models.AuditAdd, models.EntityOdometerReading, reading.ID, "", nil, reading)
+	})
+}
+
+func (ms MileageService) GetAllReadingsByBusId(busId string) ([]models.OdometerReading, error) {
+	return ms.repo.GetAllReadingsByBusId(busId)
+}
+
+// AddTrips records the trips a bus operated on a route on a day. Day is
+// truncated to the start of its day; bus and route must not be in the trash.
+func (ms MileageService) AddTrips(trips *models.OperatedTrips) error {
+	if err := models.OperatedTripsRules.Validate(models.EntityOperatedTrips, *trips); err != nil {
+		return err
+	}
+	trips.Day = calendar.Day(trips.Day, ms.loc)
+	return ms.transact(func(repos repository.Repositories) error {
+		bus, err := repos.Buses.GetById(trips.BusID)
+		if err != nil {
+			return err
+		}
+		if trips.Day.Before(calendar.Day(bus.AssemblyDate, ms.loc)) {
+			message := "Day must not be before the assembly date of the bus"
+			return apperrors.Validation(models.EntityOperatedTrips, message, map[string]string{"Day": message})
+		}
+		if _, err := repos.Routes.GetById(trips.RouteID); err != nil {
+			return err
+		}
+		if err := repos.Mileage.AddTrips(trips); err != nil {
+			return err
+		}
+		return ms.audit.record(repos, models.AuditAdd, models.EntityOperatedTrips, trips.ID, trips.RouteID, nil, trips)
+	})
+}
+
+// GetAllTripsByBusId returns the trips of the bus operated from from until
+// to, oldest first.
+func (ms MileageService) GetAllTripsByBusId(busId string, from, to time.Time) ([]models.OperatedTrips, error) {
+	if err := checkMileagePeriod(from, to); err != nil {
+		return nil, err
+	}
+	return ms.repo.GetAllTripsByBusId(busId, from, to)
+}
+
+func checkMileagePeriod(from, to time.Time) error {
+	var message string
+	if !to.After(from) {
+		message = "Period end must be after its start"
+	} else if to.Sub(from) > maxMileageDays*24*time.Hour {
+		message = fmt.Sprintf("Period must not be longer than %d days", maxMileageDays)
+	}
+	if message != "" {
+		return apperrors.Validation(models.EntityOdometerReading, message, map[string]string{"To": message})
+	}
+	return nil
+}
+
+// odometerAt interpolates the odometer linearly between the readings, oldest
+// first, around t. It is false when no reading is at or before t or none is
+// at or after it.
+func odometerAt(readings []models.OdometerReading, t time.Time) (float64, bool) {
+	for i, reading := range readings {
+		if reading.ReadAt.Equal(t) {
+			return float64(reading.Kilometers), true
+		}
+		if !reading.ReadAt.After(t) {
+			continue
+		}
+		if i == 0 {
+			return 0, false
+		}
+		prev := readings[i-1]
+		share := float64(t.Sub(prev.ReadAt)) / float64(reading.ReadAt.Sub(prev.ReadAt))
+		return float64(prev.Kilometers) + share*float64(reading.Kilometers-prev.Kilometers), true
+	}
+	return 0, false
+}
+
+// routeLengths maps the IDs of all routes, those in the trash included, to
+// their length.
+func (ms MileageService) routeLengths() (map[string]float64, error) {
+	routes, err := ms.routeRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	trashed, err := ms.routeRepo.GetAllDeleted()
+	if err != nil {
+		return nil, err
+	}
+	lengths := map[string]float64{}
+	for _, route := range routes {
+		lengths[route.ID] = route.Length
+	}
+	for _, route := range trashed {
+		lengths[route.Item.ID] = route.Item.Length
+	}
+	return lengths, nil
+}
+
+// GetDailyMileage returns the mileage of the bus on each day from the
+// day of from until to. Days covered by odometer readings are interpolated
+// from them; the others are estimated from the trips operated times the
+// route length.
+func (ms MileageService) GetDailyMileage(busId string, from, to time.Time) ([]models.Mileage, error) {
+	if err := checkMileagePeriod(from, to); err != nil {
+		return nil, err
+	}
+	if _, err := ms.busRepo.GetById(busId); err != nil {
+		return nil, err
+	}
+	readings, err := ms.repo.GetAllReadingsByBusId(busId)
+	if err != nil {
+		return nil, err
+	}
+	from = calendar.Day(from, ms.loc)
+	trips, err := ms.repo.GetAllTripsByBusId(busId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	lengths, err := ms.routeLengths()
+	if err != nil {
+		return nil, err
+	}
+	estimates := map[time.Time]float64{}
+	for _, t := range trips {
+		estimates[calendar.Day(t.Day, ms.loc)] += float64(t.Count) * lengths[t.RouteID]
+	}
+	days := []models.Mileage{}
+	for start := from; start.Before(to); start = start.AddDate(0, 0, 1) {
+		mileage := models.Mileage{From: start, To: start.AddDate(0, 0, 1)}
+		begin, ok := odometerAt(readings, mileage.From)
+		end, covered := odometerAt(readings, mileage.To)
+		if ok && covered {
+			mileage.Kilometers = end - begin
+		} else {
+			mileage.Kilometers, mileage.Estimated = estimates[start], true
+		}
+		days = append(days, mileage)
+	}
+	return days, nil
+}
+
+// GetMonthlyMileage returns the mileage of the bus per calendar month from
+// the month of from until to, summed up from its daily mileage. A month is
+// estimated when any of its days is.
+func (ms MileageService) GetMonthlyMileage(busId string, from, to time.Time) ([]models.Mileage, error) {
+	from = calendar.Month(from, ms.loc)
+	days, err := ms.GetDailyMileage(busId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	months := []models.Mileage{}
+	for _, d := range days {
+		if len(months) == 0 || d.From.Month() != months[len(months)-1].From.Month() {
+			months = append(months, models.Mileage{From: d.From})
+		}
+		month := &months[len(months)-1]
+		month.To = d.To
+		month.Kilometers += d.Kilometers
+		month.Estimated = month.Estimated || d.Estimated
+	}
+	return months, nil
+}
+
+// GetByBusId returns the last odometer reading of the bus and the mileage
+// estimated from the trips operated since. Trips on the day of the reading
+// count for the share of the day after it; without readings all trips of
+// the bus are estimated.
+func (ms MileageService) GetByBusId(busId string) (*models.BusMileage, error) {
+	bus, err := ms.busRepo.GetById(busId)
+	if err != nil {
+		return nil, err
+	}
+	readings, err := ms.repo.GetAllReadingsByBusId(busId)
+	if err != nil {
+		return nil, err
+	}
+	mileage := &models.BusMileage{BusID: busId}
+	since := calendar.Day(bus.AssemblyDate, ms.loc)
+	if len(readings) > 0 {
+		last := readings[len(readings)-1]
+		mileage.Odometer, mileage.ReadAt = last.Kilometers, last.ReadAt
+		since = calendar.Day(last.ReadAt, ms.loc)
+	}
+	trips, err := ms.repo.GetAllTripsByBusId(busId, since, calendar.Day(time.Now(), ms.loc).AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	lengths, err := ms.routeLengths()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range trips {
+		kilometers := float64(t.Count) * lengths[t.RouteID]
+		if !mileage.ReadAt.IsZero() && t.Day.Equal(since) {
+			kilometers *= float64(since.AddDate(0, 0, 1).Sub(mileage.ReadAt)) / float64(24*time.Hour)
+		}
+		mileage.Estimated += kilometers
+	}
+	mileage.Kilometers = float64(mileage.Odometer) + mileage.Estimated
+	return mileage, nil
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"busManager/repository"
+	"errors"
+	"testing"
+	"time"
+)
+
+// mayAt returns the hour of a day of May 2024.
+func mayAt(day, hour int) time.Time { return time.Date(2024, 5, day, hour, 0, 0, 0, time.UTC) }
+
+// newMemoryMileageService returns a mileage service, a ЛиАЗ 5292 and route 12 of 12.5 km.
+func newMemoryMileageService(t *testing.T) (*MileageService, repository.Repositories, *models.Bus, *models.Route) {
+	repos, uow := newMemoryRepositories(t)
+	ms := NewMileageService(repos.Mileage, repos.Buses, repos.Routes).WithUnitOfWork(uow).WithAudit(repos.Audit, "dispatcher").WithLocation(time.UTC)
+	bus := addTestBus(t, repos, newValidBus("А123ВС77"))
+	route := addTestRoute(t, repos, &models.Route{Number: "12", Length: 12.5})
+	return ms, repos, bus, route
+}
+
+// addMileage reads 100000 km on May 1 and 100600 km on May 3, and records ten trips of the route on May 3 and two
+// on April 25.
+func addMileage(t *testing.T, ms *MileageService, bus *models.Bus, route *models.Route) {
+	t.Helper()
+	for _, reading := range []*models.OdometerReading{
+		{BusID: bus.ID, ReadAt: mayAt(1, 0), Kilometers: 100000},
+		{BusID: bus.ID, ReadAt: mayAt(3, 0), Kilometers: 100600},
+	} {
+		if err := ms.AddReading(reading); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	for _, trips := range []*models.OperatedTrips{
+		{BusID: bus.ID, RouteID: route.ID, Day: mayAt(3, 0), Count: 10},
+		{BusID: bus.ID, RouteID: route.ID, Day: time.Date(2024, 4, 25, 0, 0, 0, 0, time.UTC), Count: 2},
+	} {
+		if err := ms.AddTrips(trips); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+}
+
+func TestMileageService_AddReading(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ms, repos, bus, _ := newMemoryMileageService(t)
+		reading := &models.OdometerReading{BusID: bus.ID, ReadAt: mayAt(1, 0), Kilometers: 100000}
+		if err := ms.AddReading(reading); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if readings, _ := ms.GetAllReadingsByBusId(bus.ID); len(readings) != 1 || readings[0].ID != reading.ID {
+			t.Errorf("Expected the reading to be stored, got %+v", readings)
+		}
+		entries, _ := repos.Audit.Query(models.AuditFilter{EntityType: models.EntityOdometerReading, EntityId: reading.ID})
+		if len(entries) != 1 || entries[0].Action != models.AuditAdd || entries[0].User != "dispatcher" {
+			t.Errorf("Expected the reading to be audited, got %+v", entries)
+		}
+	})
+
+	for kilometers, message := range map[int]string{
+		99000:  "Kilometers must not be below 100000 km read on 2024-05-01",
+		101000: "Kilometers must not be above 100600 km read on 2024-05-03",
+	} {
+		t.Run(message, func(t *testing.T) {
+			ms, _, bus, route := newMemoryMileageService(t)
+			addMileage(t, ms, bus, route)
+			expectField(t, ms.AddReading(&models.OdometerReading{BusID: bus.ID, ReadAt: mayAt(2, 0), Kilometers: kilometers}), "Kilometers", message)
+		})
+	}
+
+	t.Run("Before assembly", func(t *testing.T) {
+		ms, _, bus, _ := newMemoryMileageService(t)
+		err := ms.AddReading(&models.OdometerReading{BusID: bus.ID, ReadAt: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)})
+		expectField(t, err, "ReadAt", "ReadAt must not be before the assembly date of the bus")
+	})
+
+	t.Run("In the future", func(t *testing.T) {
+		ms, _, bus, _ := newMemoryMileageService(t)
+		if err := ms.AddReading(&models.OdometerReading{BusID: bus.ID, ReadAt: time.Now().AddDate(0, 0, 2)}); !errors.Is(err, apperrors.ErrValidation) {
+			t.Errorf("Expected a reading in the future to be rejected, got %v", err)
+		}
+	})
+}
+
+func TestMileageService_AddTrips(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ms, _, bus, route := newMemoryMileageService(t)
+		trips := &models.OperatedTrips{BusID: bus.ID, RouteID: route.ID, Day: mayAt(3, 15), Count: 10}
+		if err := ms.AddTrips(trips); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !trips.Day.Equal(mayAt(3, 0)) {
+			t.Errorf("Expected the day to be truncated, got %v", trips.Day)
+		}
+		if all, _ := ms.GetAllTripsByBusId(bus.ID, mayAt(1, 0), mayAt(5, 0)); len(all) != 1 || all[0].ID != trips.ID {
+			t.Errorf("Expected the trips of May, got %+v", all)
+		}
+	})
+
+	t.Run("Same day", func(t *testing.T) {
+		ms, _, bus, route := newMemoryMileageService(t)
+		if err := ms.AddTrips(&models.OperatedTrips{BusID: bus.ID, RouteID: route.ID, Day: mayAt(3, 15), Count: 10}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := ms.AddTrips(&models.OperatedTrips{BusID: bus.ID, RouteID: route.ID, Day: mayAt(3, 20), Count: 1}); !errors.Is(err, apperrors.ErrAlreadyExists) {
+			t.Errorf("Expected already exists error, got %v", err)
+		}
+	})
+
+	t.Run("Zero count", func(t *testing.T) {
+		ms, _, bus, route := newMemoryMileageService(t)
+		if err := ms.AddTrips(&models.OperatedTrips{BusID: bus.ID, RouteID: route.ID, Day: mayAt(4, 0)}); !errors.Is(err, apperrors.ErrValidation) {
+			t.Errorf("Expected a zero count to be rejected, got %v", err)
+		}
+	})
+}
+
+func TestMileageService_GetDailyMileage(t *testing.T) {
+	ms, _, bus, route := newMemoryMileageService(t)
+	addMileage(t, ms, bus, route)
+
+	t.Run("Success", func(t *testing.T) {
+		days, err := ms.GetDailyMileage(bus.ID, mayAt(1, 12), mayAt(4, 0))
+		if err != nil || len(days) != 3 {
+			t.Fatalf("Expected 3 days, got %+v (%v)", days, err)
+		}
+		want := []models.Mileage{
+			{From: mayAt(1, 0), To: mayAt(2, 0), Kilometers: 300},
+			{From: mayAt(2, 0), To: mayAt(3, 0), Kilometers: 300},
+			{From: mayAt(3, 0), To: mayAt(4, 0), Kilometers: 125, Estimated: true},
+		}
+		for i, day := range days {
+			if day != want[i] {
+				t.Errorf("Expected %+v, got %+v", want[i], day)
+			}
+		}
+	})
+
+	t.Run("Reversed period", func(t *testing.T) {
+		if _, err := ms.GetDailyMileage(bus.ID, mayAt(4, 0), mayAt(1, 0)); !errors.Is(err, apperrors.ErrValidation) {
+			t.Errorf("Expected a reversed period to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("Bus not found", func(t *testing.T) {
+		if _, err := ms.GetDailyMileage("missing", mayAt(1, 0), mayAt(4, 0)); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Expected not found error, got %v", err)
+		}
+	})
+}
+
+func TestMileageService_GetMonthlyMileage(t *testing.T) {
+	ms, _, bus, route := newMemoryMileageService(t)
+	addMileage(t, ms, bus, route)
+
+	months, err := ms.GetMonthlyMileage(bus.ID, time.Date(2024, 4, 20, 0, 0, 0, 0, time.UTC), time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || len(months) != 2 {
+		t.Fatalf("Expected 2 months, got %+v (%v)", months, err)
+	}
+	if april := months[0]; !april.From.Equal(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)) || april.Kilometers != 25 || !april.Estimated {
+		t.Errorf("Expected an estimated April of 25 km, got %+v", april)
+	}
+	if may := months[1]; !may.To.Equal(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)) || may.Kilometers != 725 || !may.Estimated {
+		t.Errorf("Expected a May of 725 km, got %+v", may)
+	}
+}
+
+func TestMileageService_Location(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	ms, repos, bus, route := newMemoryMileageService(t)
+	ms.WithLocation(moscow)
+	// Trips recorded before days had a zone were stored at midnight UTC.
+	if err := repos.Mileage.AddTrips(&models.OperatedTrips{BusID: bus.ID, RouteID: route.ID, Day: time.Date(2024, 5, 8, 0, 0, 0, 0, time.UTC), Count: 2}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// The first trips at 01:30 in Moscow belong to May 7, not to May 6 as in UTC.
+	trips := &models.OperatedTrips{BusID: bus.ID, RouteID: route.ID, Day: time.Date(2024, 5, 6, 22, 30, 0, 0, time.UTC), Count: 4}
+	if err := ms.AddTrips(trips); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	may7 := time.Date(2024, 5, 7, 0, 0, 0, 0, moscow)
+	if !trips.Day.Equal(may7) {
+		t.Errorf("Expected the day to start at midnight in Moscow, got %v", trips.Day)
+	}
+
+	days, err := ms.GetDailyMileage(bus.ID, time.Date(2024, 5, 6, 12, 0, 0, 0, moscow), may7.AddDate(0, 0, 2))
+	if err != nil || len(days) != 3 {
+		t.Fatalf("Expected 3 days, got %+v (%v)", days, err)
+	}
+	if !days[0].From.Equal(may7.AddDate(0, 0, -1)) || days[0].Kilometers != 0 || !days[1].From.Equal(may7) || days[1].Kilometers != 50 {
+		t.Errorf("Expected 50 km on May 7 in Moscow, got %+v", days)
+	}
+	if days[2].Kilometers != 25 {
+		t.Errorf("Expected the earlier trips to keep May 8, got %+v", days[2])
+	}
+
+	months, err := ms.GetMonthlyMileage(bus.ID, time.Date(2024, 4, 30, 22, 0, 0, 0, time.UTC), may7)
+	if err != nil || len(months) != 1 || !months[0].From.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, moscow)) {
+		t.Errorf("Expected May in Moscow, got %+v (%v)", months, err)
+	}
+}
+
+func TestMileageService_GetByBusId(t *testing.T) {
+	ms, _, bus, route := newMemoryMileageService(t)
+	addMileage(t, ms, bus, route)
+
+	mileage, err := ms.GetByBusId(bus.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := models.BusMileage{BusID: bus.ID, Odometer: 100600, ReadAt: mayAt(3, 0), Estimated: 125, Kilometers: 100725}
+	if *mileage != want {
+		t.Errorf("Expected %+v, got %+v", want, *mileage)
+	}
+}
+
+func TestMaintenanceService_WithMileage(t *testing.T) {
+	ms, repos, bus, route := newMemoryMileageService(t)
+	addMileage(t, ms, bus, route)
+	maintenance := NewMaintenanceService(repos.Maintenance, repos.Buses)
+	if err := maintenance.AddInterval(&models.MaintenanceInterval{Brand: bus.Brand, BusModel: bus.BusModel, Kind: "ТО-1", Kilometers: 5000}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := maintenance.AddRecord(&models.MaintenanceRecord{BusID: bus.ID, Kind: "ТО-1", PerformedAt: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), Mileage: 95000}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("By records", func(t *testing.T) {
+		due, _ := maintenance.GetPlanByBusId(bus.ID)
+		if len(due) != 1 || due[0].Status != models.MaintenanceOK {
+			t.Errorf("Expected the service not due by its records, got %+v", due)
+		}
+	})
+
+	t.Run("By odometer", func(t *testing.T) {
+		due, _ := maintenance.WithMileage(repos.Mileage).GetPlanByBusId(bus.ID)
+		if len(due) != 1 || due[0].Mileage != 100600 || due[0].Status != models.MaintenanceOverdue {
+			t.Errorf("Expected the service overdue by the odometer, got %+v", due)
+		}
+	})
+}
@@ -1,9 +1,11 @@
 package service
 
 import (
+	"busManager/apperrors"
 	"busManager/models"
 	"busManager/repository"
-	"errors"
+	"strconv"
+	"time"
 )
 
 type RouteService struct {
@@ -11,6 +13,8 @@ type RouteService struct {
 	driverRepo  repository.IDriverRepository
 	busRepo     repository.IBusRepository
 	busStopRepo repository.IBusStopRepository
+	uow         repository.IUnitOfWork
+	audit       auditor
 }
 
 func NewRouteService(
@@ -19,10 +23,33 @@ func NewRouteService(
 	busRepo repository.IBusRepository,
 	busStopRepo repository.IBusStopRepository,
 ) *RouteService {
-	b := &RouteService{r, driverRepo, busRepo, busStopRepo}
+	b := &RouteService{repo: r, driverRepo: driverRepo, busRepo: busRepo, busStopRepo: busStopRepo}
 	return b
 }
 
+// WithUnitOfWork makes assignment operations run their checks and writes in
+// one transaction. Without it the repositories are called directly.
+func (rs *RouteService) WithUnitOfWork(uow repository.IUnitOfWork) *RouteService {
+	rs.uow = uow
+	return rs
+}
+
+// WithAudit records every change made through the service in repo as user.
+func (rs *RouteService) WithAudit(repo repository.IAuditRepository, user string) *RouteService {
+	rs.audit = newAuditor(repo, user)
+	return rs
+}
+
+func (rs RouteService) transact(fn func(repos repository.Repositories) error) error {
+	return transact(rs.uow, repository.Repositories{
+		Buses:    rs.busRepo,
+		Drivers:  rs.driverRepo,
+		BusStops: rs.busStopRepo,
+		Routes:   rs.repo,
+		Audit:    rs.audit.repo,
+	}, fn)
+}
+
 func (rs RouteService) GetById(id string) (*models.Route, error) {
 
 	route, err := rs.repo.GetById(id)
@@ -30,7 +57,7 @@ func (rs RouteService) GetById(id string) (*models.Route, error) {
 		return nil, err
 	}
 	if route == nil {
-		return nil, errors.New("Route not found")
+		return nil, apperrors.NotFound(models.EntityRoute, "Route not found")
 	}
 	return route, nil
 }
@@ -41,14 +68,21 @@ func (rs RouteService) GetByNumber(number string) (*models.Route, error) {
 		return nil, err
 	}
 	if route == nil {
-		return nil, errors.New("Route not found")
+		return nil, apperrors.NotFound(models.EntityRoute, "Route not found")
 	}
 	return route, nil
 }
 
 func (rs RouteService) Add(route *models.Route) error {
-	err := rs.repo.Add(route)
-	return err
+	if err := models.RouteRules.Validate(models.EntityRoute, *route); err != nil {
+		return err
+	}
+	return rs.transact(func(repos repository.Repositories) error {
+		if err := repos.Routes.Add(route); err != nil {
+			return err
+		}
+		return rs.audit.record(repos, models.AuditAdd, models.EntityRoute, route.ID, route.ID, nil, route)
+	})
 }
 
 func (rs RouteService) GetAll() ([]models.Route, error) {
@@ -61,158 +95,321 @@ func (rs RouteService) GetAll() ([]models.Route, error) {
 
 }
 
+// DeleteById deletes the route together with its assignments. With auditing
+// each removed assignment is recorded as well.
 func (rs RouteService) DeleteById(id string) error {
-	err := rs.repo.DeleteById(id)
-	return err
+	return rs.transact(func(repos repository.Repositories) error {
+		if !rs.audit.enabled() {
+			return repos.Routes.DeleteById(id)
+		}
+		route, err := repos.Routes.GetById(id)
+		if err != nil {
+			return err
+		}
+		if err := rs.recordUnassignAll(repos, id); err != nil {
+			return err
+		}
+		if err := repos.Routes.DeleteById(id); err != nil {
+			return err
+		}
+		return rs.audit.record(repos, models.AuditDelete, models.EntityRoute, id, id, route, nil)
+	})
 }
 
-func (rs RouteService) UpdateById(route *models.Route) error {
-	err := rs.repo.UpdateById(route)
-	return err
+// List returns one page of routes, see models.ListQuery.
+func (rs RouteService) List(query models.ListQuery) (models.Page[models.Route], error) {
+	return rs.repo.List(query)
 }
 
-func (rs RouteService) AssignDriver(routeId, driverId string) error {
-	route, err := rs.GetById(routeId)
-	if route == nil {
-		return errors.New("Route not found")
-	}
+// ListSuitableBuses returns one page of the operable buses that meet the
+// requirements of the route, further filtered by query.
+func (rs RouteService) ListSuitableBuses(routeId string, query models.ListQuery) (models.Page[models.Bus], error) {
+	route, err := rs.repo.GetById(routeId)
 	if err != nil {
-		return err
+		return models.Page[models.Bus]{}, err
 	}
-
-	driver, err := rs.driverRepo.GetById(driverId)
-	if driver == nil {
-		return errors.New("Driver not found")
+	requirements := map[string]string{"Status": models.BusActive + "," + models.BusReserve}
+	if route.MinCapacity > 0 {
+		requirements["Capacity"] = strconv.Itoa(route.MinCapacity)
 	}
-	if err != nil {
-		return err
+	if route.BusClass != "" {
+		requirements["Class"] = route.BusClass
 	}
-	err = rs.repo.AssignDriver(routeId, driverId)
-	if err != nil {
-		return err
+	if route.LowFloor {
+		requirements["LowFloor"] = "true"
 	}
-	return nil
+	return rs.busRepo.List(withBusFilters(query, requirements))
 }
 
-func (rs RouteService) AssignBusStop(routeId, busStopId string) error {
-	route, err := rs.GetById(routeId)
-	if route == nil {
-		return errors.New("Route not found")
-	}
-	if err != nil {
-		return err
-	}
+func (rs RouteService) GetAllDeleted() ([]models.Trashed[models.Route], error) {
+	return rs.repo.GetAllDeleted()
+}
 
-	busStop, err := rs.busStopRepo.GetById(busStopId)
-	if busStop == nil {
-		return errors.New("Bus stop not found")
-	}
-	if err != nil {
-		return err
-	}
-	err = rs.repo.AssignBusStop(routeId, busStopId)
-	if err != nil {
-		return err
-	}
-	return nil
+// RestoreById takes the route out of the trash together with the
+// assignments it had when it was deleted.
+func (rs RouteService) RestoreById(id string) error {
+	return rs.transact(func(repos repository.Repositories) error {
+		if err := repos.Routes.RestoreById(id); err != nil {
+			return err
+		}
+		if !rs.audit.enabled() {
+			return nil
+		}
+		route, err := repos.Routes.GetById(id)
+		if err != nil {
+			return err
+		}
+		if err := rs.audit.record(repos, models.AuditRestore, models.EntityRoute, id, id, nil, route); err != nil {
+			return err
+		}
+		return rs.recordAssignAll(repos, id)
+	})
 }
 
-func (rs RouteService) AssignBus(routeId, busId string) error {
-	route, err := rs.GetById(routeId)
-	if route == nil {
-		return errors.New("Route not found")
-	}
-	if err != nil {
-		return err
-	}
+// PurgeById permanently removes a route that is in the trash.
+func (rs RouteService) PurgeById(id string) error {
+	return rs.transact(func(repos repository.Repositories) error {
+		if err := repos.Routes.PurgeById(id); err != nil {
+			return err
+		}
+		return rs.audit.record(repos, models.AuditPurge, models.EntityRoute, id, id, nil, nil)
+	})
+}
 
-	bus, err := rs.busRepo.GetById(busId)
-	if bus == nil {
-		return errors.New("Bus not found")
-	}
+func (rs RouteService) recordUnassignAll(repos repository.Repositories, routeId string) error {
+	buses, err := repos.Routes.GetAllBusesById(routeId)
 	if err != nil {
 		return err
 	}
-	err = rs.repo.AssignBus(routeId, busId)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func (rs RouteService) UnassignDriver(routeId, driverId string) error {
-	route, err := rs.GetById(routeId)
-	if route == nil {
-		return errors.New("Route not found")
+	for _, bus := range buses {
+		if err := rs.audit.record(repos, models.AuditUnassign, models.EntityBus, bus.ID, routeId, bus, nil); err != nil {
+			return err
+		}
 	}
+	drivers, err := repos.Routes.GetAllDriversById(routeId)
 	if err != nil {
 		return err
 	}
-
-	driver, err := rs.driverRepo.GetById(driverId)
-	if driver == nil {
-		return errors.New("Driver not found")
+	for _, driver := range drivers {
+		if err := rs.audit.record(repos, models.AuditUnassign, models.EntityDriver, driver.ID, routeId, driver, nil); err != nil {
+			return err
+		}
 	}
+	busStops, err := repos.Routes.GetAllBusStopsById(routeId)
 	if err != nil {
 		return err
 	}
-	err = rs.repo.UnassignDriver(routeId, driverId)
-	if err != nil {
-		return err
+	for _, busStop := range busStops {
+		if err := rs.audit.record(repos, models.AuditUnassign, models.EntityBusStop, busStop.ID, routeId, busStop, nil); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (rs RouteService) UnassignBusStop(routeId, busStopId string) error {
-	route, err := rs.GetById(routeId)
-	if route == nil {
-		return errors.New("Route not found")
-	}
+func (rs RouteService) recordAssignAll(repos repository.Repositories, routeId string) error {
+	buses, err := repos.Routes.GetAllBusesById(routeId)
 	if err != nil {
 		return err
 	}
-
-	busStop, err := rs.busStopRepo.GetById(busStopId)
-	if busStop == nil {
-		return errors.New("Bus stop not found")
+	for _, bus := range buses {
+		if err := rs.audit.record(repos, models.AuditAssign, models.EntityBus, bus.ID, routeId, nil, bus); err != nil {
+			return err
+		}
 	}
+	drivers, err := repos.Routes.GetAllDriversById(routeId)
 	if err != nil {
 		return err
 	}
-	err = rs.repo.UnassignBusStop(routeId, busStopId)
+	for _, driver := range drivers {
+		if err := rs.audit.record(repos, models.AuditAssign, models.EntityDriver, driver.ID, routeId, nil, driver); err != nil {
+			return err
+		}
+	}
+	busStops, err := repos.Routes.GetAllBusStopsById(routeId)
 	if err != nil {
 		return err
 	}
+	for _, busStop := range busStops {
+		if err := rs.audit.record(repos, models.AuditAssign, models.EntityBusStop, busStop.ID, routeId, nil, busStop); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (rs RouteService) UnassignBus(routeId, busId string) error {
-	route, err := rs.GetById(routeId)
-	if route == nil {
-		return errors.New("Route not found")
-	}
-	if err != nil {
+func (rs RouteService) UpdateById(route *models.Route) error {
+	if err := models.RouteRules.Validate(models.EntityRoute, *route); err != nil {
 		return err
 	}
+	return rs.transact(func(repos repository.Repositories) error {
+		var before *models.Route
+		if rs.audit.enabled() {
+			var err error
+			if before, err = repos.Routes.GetById(route.ID); err != nil {
+				return err
+			}
+		}
+		if err := repos.Routes.UpdateById(route); err != nil {
+			return err
+		}
+		return rs.audit.record(repos, models.AuditUpdate, models.EntityRoute, route.ID, route.ID, before, route)
+	})
+}
 
-	bus, err := rs.busRepo.GetById(busId)
-	if bus == nil {
-		return errors.New("Bus not found")
-	}
-	if err != nil {
-		return err
-	}
-	err = rs.repo.UnassignBus(routeId, busId)
-	if err != nil {
-		return err
-	}
-	return nil
+// AssignDriver refuses drivers whose licence has no category D or has
+// expired, see models.Driver.LicenseProblem.
+func (rs RouteService) AssignDriver(routeId, driverId string) error {
+	return rs.transact(func(repos repository.Repositories) error {
+		route, err := repos.Routes.GetById(routeId)
+		if err != nil {
+			return err
+		}
+		if route == nil {
+			return apperrors.NotFound(models.EntityRoute, "Route not found")
+		}
+		driver, err := repos.Drivers.GetById(driverId)
+		if err != nil {
+			return err
+		}
+		if driver == nil {
+			return apperrors.NotFound(models.EntityDriver, "Driver not found")
+		}
+		if field, reason := driver.LicenseProblem(time.Now()); reason != "" {
+			return apperrors.Validation(models.EntityDriver, reason, map[string]string{field: reason})
+		}
+		if err := repos.Routes.AssignDriver(routeId, driverId); err != nil {
+			return err
+		}
+		return rs.audit.record(repos, models.AuditAssign, models.EntityDriver, driverId, routeId, nil, driver)
+	})
+}
+
+func (rs RouteService) AssignBusStop(routeId, busStopId string) error {
+	return rs.transact(func(repos repository.Repositories) error {
+		route, err := repos.Routes.GetById(routeId)
+		if err != nil {
+			return err
+		}
+		if route == nil {
+			return apperrors.NotFound(models.EntityRoute, "Route not found")
+		}
+		busStop, err := repos.BusStops.GetById(busStopId)
+		if err != nil {
+			return err
+		}
+		if busStop == nil {
+			return apperrors.NotFound(models.EntityBusStop, "Bus stop not found")
+		}
+		if err := repos.Routes.AssignBusStop(routeId, busStopId); err != nil {
+			return err
+		}
+		return rs.audit.record(repos, models.AuditAssign, models.EntityBusStop, busStopId, routeId, nil, busStop)
+	})
+}
+
+func (rs RouteService) AssignBus(routeId, busId string) error {
+	return rs.transact(func(repos repository.Repositories) error {
+		route, err := repos.Routes.GetById(routeId)
+		if err != nil {
+			return err
+		}
+		if route == nil {
+			return apperrors.NotFound(models.EntityRoute, "Route not found")
+		}
+		bus, err := repos.Buses.GetById(busId)
+		if err != nil {
+			return err
+		}
+		if bus == nil {
+			return apperrors.NotFound(models.EntityBus, "Bus not found")
+		}
+		if !bus.Operable() {
+			return statusError("Bus is " + statusLabel(bus.Status))
+		}
+		if reasons := route.Unsuitable(*bus); reasons != nil {
+			return apperrors.Validation(models.EntityBus, "Bus does not suit route "+route.Number, reasons)
+		}
+		if err := repos.Routes.AssignBus(routeId, busId); err != nil {
+			return err
+		}
+		return rs.audit.record(repos, models.AuditAssign, models.EntityBus, busId, routeId, nil, bus)
+	})
+}
+
+func (rs RouteService) UnassignDriver(routeId, driverId string) error {
+	return rs.transact(func(repos repository.Repositories) error {
+		route, err := repos.Routes.GetById(routeId)
+		if err != nil {
+			return err
+		}
+		if route == nil {
+			return apperrors.NotFound(models.EntityRoute, "Route not found")
+		}
+		driver, err := repos.Drivers.GetById(driverId)
+		if err != nil {
+			return err
+		}
+		if driver == nil {
+			return apperrors.NotFound(models.EntityDriver, "Driver not found")
+		}
+		if err := repos.Routes.UnassignDriver(routeId, driverId); err != nil {
+			return err
+		}
+		return rs.audit.record(repos, models.AuditUnassign, models.EntityDriver, driverId, routeId, driver, nil)
+	})
+}
+
+func (rs RouteService) UnassignBusStop(routeId, busStopId string) error {
+	return rs.transact(func(repos repository.Repositories) error {
+		route, err := repos.Routes.GetById(routeId)
+		if err != nil {
+			return err
+		}
+		if route == nil {
+			return apperrors.NotFound(models.EntityRoute, "Route not found")
+		}
+		busStop, err := repos.BusStops.GetById(busStopId)
+		if err != nil {
+			return err
+		}
+		if busStop == nil {
+			return apperrors.NotFound(models.EntityBusStop, "Bus stop not found")
+		}
+		if err := repos.Routes.UnassignBusStop(routeId, busStopId); err != nil {
+			return err
+		}
+		return rs.audit.record(repos, models.AuditUnassign, models.EntityBusStop, busStopId, routeId, busStop, nil)
+	})
+}
+
+func (rs RouteService) UnassignBus(routeId, busId string) error {
+	return rs.transact(func(repos repository.Repositories) error {
+		route, err := repos.Routes.GetById(routeId)
+		if err != nil {
+			return err
+		}
+		if route == nil {
+			return apperrors.NotFound(models.EntityRoute, "Route not found")
+		}
+		bus, err := repos.Buses.GetById(busId)
+		if err != nil {
+			return err
+		}
+		if bus == nil {
+			return apperrors.NotFound(models.EntityBus, "Bus not found")
+		}
+		if err := repos.Routes.UnassignBus(routeId, busId); err != nil {
+			return err
+		}
+		return rs.audit.record(repos, models.AuditUnassign, models.EntityBus, busId, routeId, bus, nil)
+	})
 }
 
 func (rs RouteService) GetAllDriversById(routeId string) ([]models.Driver, error) {
 	route, err := rs.GetById(routeId)
 	if route == nil {
-		return nil, errors.New("Route not found")
+		return nil, apperrors.NotFound(models.EntityRoute, "Route not found")
 	}
 	if err != nil {
 		return nil, err
@@ -223,7 +420,7 @@ func (rs RouteService) GetAllDriversById(routeId string) ([]models.Driver, error
 		return nil, err
 	}
 	if drivers == nil {
-		return drivers, errors.New("Drivers not found")
+		return drivers, apperrors.NotFound(models.EntityDriver, "Drivers not found")
 	}
 	return drivers, nil
 }
@@ -231,7 +428,7 @@ func (rs RouteService) GetAllDriversById(routeId string) ([]models.Driver, error
 func (rs RouteService) GetAllBusStopsById(routeId string) ([]models.BusStop, error) {
 	route, err := rs.GetById(routeId)
 	if route == nil {
-		return nil, errors.New("Route not found")
+		return nil, apperrors.NotFound(models.EntityRoute, "Route not found")
 	}
 	if err != nil {
 		return nil, err
@@ -242,7 +439,7 @@ func (rs RouteService) GetAllBusStopsById(routeId string) ([]models.BusStop, err
 		return nil, err
 	}
 	if busStops == nil {
-		return busStops, errors.New("Bus stops not found")
+		return busStops, apperrors.NotFound(models.EntityBusStop, "Bus stops not found")
 	}
 	return busStops, nil
 }
@@ -250,7 +447,7 @@ func (rs RouteService) GetAllBusStopsById(routeId string) ([]models.BusStop, err
 func (rs RouteService) GetAllBusesById(routeId string) ([]models.Bus, error) {
 	route, err := rs.GetById(routeId)
 	if route == nil {
-		return nil, errors.New("Route not found")
+		return nil, apperrors.NotFound(models.EntityRoute, "Route not found")
 	}
 	if err != nil {
 		return nil, err
@@ -261,7 +458,7 @@ func (rs RouteService) GetAllBusesById(routeId string) ([]models.Bus, error) {
 		return nil, err
 	}
 	if buses == nil {
-		return buses, errors.New("Buses not found")
+		return buses, apperrors.NotFound(models.EntityBus, "Buses not found")
 	}
 	return buses, nil
 }
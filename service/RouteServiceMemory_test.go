@@ -0,0 +1,109 @@
+package service
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"busManager/repository"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newMemoryRouteService wires a RouteService to an in-memory store instead of mocks.
+func newMemoryRouteService(t *testing.T) (*RouteService, repository.Repositories) {
+	store := repository.NewMemoryStore()
+	repos := store.Repositories()
+	rs := NewRouteService(repos.Routes, repos.Drivers, repos.Buses, repos.BusStops).
+		WithUnitOfWork(repository.NewMemoryUnitOfWork(store))
+	return rs, repos
+}
+
+func TestRouteService_Memory_AssignBus(t *testing.T) {
+	rs, repos := newMemoryRouteService(t)
+	route := &models.Route{Number: "12"}
+	bus := &models.Bus{Brand: "Volvo", BusModel: "B7R", RegisterNumber: "ABC123",
+		AssemblyDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if err := rs.Add(route); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := repos.Buses.Add(bus); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		if err := rs.AssignBus(route.ID, bus.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		buses, err := rs.GetAllBusesById(route.ID)
+		if err != nil || len(buses) != 1 || buses[0].ID != bus.ID {
+			t.Errorf("Expected bus on route, got %v (%v)", buses, err)
+		}
+	})
+
+	t.Run("Bus not found", func(t *testing.T) {
+		err := rs.AssignBus(route.ID, "missing")
+		if err == nil || err.Error() != "Bus not found" {
+			t.Errorf("Expected 'Bus not found' error, got %v", err)
+		}
+	})
+
+	t.Run("Delete bus with restrict policy", func(t *testing.T) {
+		bs := NewBusService(repos.Buses)
+		err := bs.DeleteById(bus.ID)
+		if err == nil || err.Error() != "Bus is assigned to routes: 12" {
+			t.Errorf("Expected bus in use error, got %v", err)
+		}
+	})
+
+	t.Run("Delete bus with cascade policy", func(t *testing.T) {
+		bs := NewBusService(repos.Buses).WithDeletePolicy(DeleteCascade)
+		if err := bs.DeleteById(bus.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		buses, _ := rs.GetAllBusesById(route.ID)
+		if len(buses) != 0 {
+			t.Errorf("Expected route without buses, got %d", len(buses))
+		}
+	})
+}
+
+func TestRouteService_Memory_BusRequirements(t *testing.T) {
+	rs, repos := newMemoryRouteService(t)
+	route := &models.Route{Number: "12", MinCapacity: 80, LowFloor: true}
+	if err := rs.Add(route); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	bus := func(number string, seated, standing int, lowFloor bool, status string) *models.Bus {
+		b := &models.Bus{Brand: "ЛиАЗ", BusModel: "5292", RegisterNumber: number, AssemblyDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			SeatedCapacity: seated, StandingCapacity: standing, Class: models.BusClassLarge, LowFloor: lowFloor, Status: status}
+		if err := repos.Buses.Add(b); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return b
+	}
+	small := bus("А100АА77", 23, 18, false, models.BusActive)
+	large := bus("А200АА77", 25, 83, true, models.BusReserve)
+	bus("А300АА77", 25, 83, true, models.BusInRepair)
+
+	err := rs.AssignBus(route.ID, small.ID)
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) || appErr.Message != "Bus does not suit route 12" ||
+		appErr.Fields["Capacity"] != "Capacity must be at least 80 on route 12" || appErr.Fields["LowFloor"] == "" {
+		t.Errorf("Expected the small bus to be refused, got %v", err)
+	}
+	if err := rs.AssignBus(route.ID, large.ID); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	page, err := rs.ListSuitableBuses(route.ID, models.ListQuery{})
+	if err != nil || page.Total != 1 || page.Items[0].ID != large.ID {
+		t.Errorf("Expected only the large reserve bus, got %+v (%v)", page, err)
+	}
+	page, _ = rs.ListSuitableBuses(route.ID, models.ListQuery{Filters: map[string]string{"LowFloor": "false", "RegisterNumber": "а200"}})
+	if page.Total != 1 {
+		t.Errorf("Expected the route requirements to override the query, got %+v", page)
+	}
+	if _, err := rs.ListSuitableBuses("missing", models.ListQuery{}); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
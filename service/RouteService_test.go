@@ -1,6 +1,7 @@
 package service
 
 import (
+	"busManager/apperrors"
 	"busManager/models"
 
 	"errors"
@@ -31,6 +32,10 @@ type MockRouteRepository struct {
 	getAllBusStopsByIdErr  error
 	getAllBusesByIdResp    []models.Bus
 	getAllBusesByIdErr     error
+	getAllDeletedResp      []models.Trashed[models.Route]
+	getAllDeletedErr       error
+	restoreByIdErr         error
+	purgeByIdErr           error
 }
 
 func (m *MockRouteRepository) GetById(id string) (*models.Route, error) {
@@ -93,15 +98,38 @@ func (m *MockRouteRepository) GetAllBusesById(routeId string) ([]models.Bus, err
 	return m.getAllBusesByIdResp, m.getAllBusesByIdErr
 }
 
+func (m *MockRouteRepository) List(query models.ListQuery) (models.Page[models.Route], error) {
+	return models.Page[models.Route]{Items: m.getAllResp, Total: len(m.getAllResp)}, nil
+}
+
+func (m *MockRouteRepository) GetAllDeleted() ([]models.Trashed[models.Route], error) {
+	return m.getAllDeletedResp, m.getAllDeletedErr
+}
+
+func (m *MockRouteRepository) RestoreById(id string) error {
+	return m.restoreByIdErr
+}
+
+func (m *MockRouteRepository) PurgeById(id string) error {
+	return m.purgeByIdErr
+}
+
 type MockBusRepository struct {
-	getByIdResp     *models.Bus
-	getByIdErr      error
-	getByNumberResp *models.Bus
-	getByNumberErr  error
-	addErr          error
-	deleteByIdErr   error
-	getAllResp      []models.Bus
-	updateByIdErr   error
+	getByIdResp          *models.Bus
+	getByIdErr           error
+	getByNumberResp      *models.Bus
+	getByNumberErr       error
+	addErr               error
+	deleteByIdErr        error
+	getAllResp           []models.Bus
+	getAllErr            error
+	updateByIdErr        error
+	getAllRoutesByIdResp []models.Route
+	getAllRoutesByIdErr  error
+	getAllDeletedResp    []models.Trashed[models.Bus]
+	getAllDeletedErr     error
+	restoreByIdErr       error
+	purgeByIdErr         error
 }
 
 func (m *MockBusRepository) GetById(id string) (*models.Bus, error) {
@@ -121,13 +149,45 @@ func (m *MockBusRepository) DeleteById(id string) error {
 }
 
 func (m *MockBusRepository) GetAll() ([]models.Bus, error) {
-	return m.getAllResp, nil
+	return m.getAllResp, m.getAllErr
 }
 
 func (m *MockBusRepository) UpdateById(bus *models.Bus) error {
 	return m.updateByIdErr
 }
 
+func (m *MockBusRepository) GetAllWithInvalidNumber() ([]models.Bus, error) {
+	return []models.Bus{}, nil
+}
+
+func (m *MockBusRepository) AddStatusChange(change *models.BusStatusChange) error {
+	return nil
+}
+
+func (m *MockBusRepository) GetStatusHistoryById(id string) ([]models.BusStatusChange, error) {
+	return []models.BusStatusChange{}, nil
+}
+
+func (m *MockBusRepository) GetAllRoutesById(id string) ([]models.Route, error) {
+	return m.getAllRoutesByIdResp, m.getAllRoutesByIdErr
+}
+
+func (m *MockBusRepository) List(query models.ListQuery) (models.Page[models.Bus], error) {
+	return models.Page[models.Bus]{Items: m.getAllResp, Total: len(m.getAllResp)}, nil
+}
+
+func (m *MockBusRepository) GetAllDeleted() ([]models.Trashed[models.Bus], error) {
+	return m.getAllDeletedResp, m.getAllDeletedErr
+}
+
+func (m *MockBusRepository) RestoreById(id string) error {
+	return m.restoreByIdErr
+}
+
+func (m *MockBusRepository) PurgeById(id string) error {
+	return m.purgeByIdErr
+}
+
 func TestRouteService_GetById(t *testing.T) {
 	route := &models.Route{ID: uuid.New().String(), Number: "101"}
 
@@ -214,7 +274,10 @@ func TestRouteService_GetAll(t *testing.T) {
 		mockRepo := &MockRouteRepository{getAllResp: []models.Route{route1, route2}}
 		service := NewRouteService(mockRepo, nil, nil, nil)
 
-		routes := service.GetAll()
+		routes, err := service.GetAll()
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
 		if len(routes) != 2 {
 			t.Errorf("Expected 2 routes, got %d", len(routes))
 		}
@@ -224,7 +287,10 @@ func TestRouteService_GetAll(t *testing.T) {
 		mockRepo := &MockRouteRepository{getAllResp: []models.Route{}}
 		service := NewRouteService(mockRepo, nil, nil, nil)
 
-		routes := service.GetAll()
+		routes, err := service.GetAll()
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
 		if len(routes) != 0 {
 			t.Errorf("Expected 0 routes, got %d", len(routes))
 		}
@@ -282,7 +348,8 @@ func TestRouteService_AssignDriver(t *testing.T) {
 	driverID := uuid.New().String()
 	route := &models.Route{ID: routeID, Number: "101"}
 	birthDate := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
-	driver := &models.Driver{ID: driverID, Name: "John", Surname: "Doe", BirthDate: birthDate}
+	driver := &models.Driver{ID: driverID, Name: "John", Surname: "Doe", BirthDate: birthDate,
+		LicenseCategories: models.LicenseCategories{"B", "D"}, LicenseExpiryDate: time.Now().AddDate(1, 0, 0)}
 
 	t.Run("Success", func(t *testing.T) {
 		mockRouteRepo := &MockRouteRepository{getByIdResp: route}
@@ -295,6 +362,44 @@ func TestRouteService_AssignDriver(t *testing.T) {
 		}
 	})
 
+	t.Run("Ineligible licence", func(t *testing.T) {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		tests := []struct {
+			name          string
+			categories    models.LicenseCategories
+			expiry        time.Time
+			field, reason string
+		}{
+			{"No category D", models.LicenseCategories{"B", "C"}, today.AddDate(1, 0, 0), "LicenseCategories", "Driver licence has no category D"},
+			{"Unknown expiry", models.LicenseCategories{"D"}, time.Time{}, "LicenseExpiryDate", "Driver licence expiry date is unknown"},
+			{"Expired", models.LicenseCategories{"D"}, today.AddDate(0, 0, -1), "LicenseExpiryDate", "Driver licence expired on " + today.AddDate(0, 0, -1).Format(time.DateOnly)},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				ineligible := *driver
+				ineligible.LicenseCategories = tt.categories
+				ineligible.LicenseExpiryDate = tt.expiry
+				mockRouteRepo := &MockRouteRepository{getByIdResp: route}
+				mockDriverRepo := &MockDriverRepository{getByIdResp: &ineligible}
+				service := NewRouteService(mockRouteRepo, mockDriverRepo, nil, nil)
+
+				err := service.AssignDriver(routeID, driverID)
+				var appErr *apperrors.Error
+				if !errors.As(err, &appErr) || appErr.Code != apperrors.CodeValidation || appErr.Fields[tt.field] != tt.reason {
+					t.Errorf("Expected %q on %s, got %v", tt.reason, tt.field, err)
+				}
+			})
+		}
+
+		valid := *driver
+		valid.LicenseExpiryDate = today
+		mockDriverRepo := &MockDriverRepository{getByIdResp: &valid}
+		service := NewRouteService(&MockRouteRepository{getByIdResp: route}, mockDriverRepo, nil, nil)
+		if err := service.AssignDriver(routeID, driverID); err != nil {
+			t.Errorf("Expected a licence to be valid on its expiry day, got %v", err)
+		}
+	})
+
 	t.Run("Route not found", func(t *testing.T) {
 		mockRouteRepo := &MockRouteRepository{getByIdErr: errors.New("Route not found")}
 		mockDriverRepo := &MockDriverRepository{getByIdResp: driver}
@@ -391,6 +496,7 @@ func TestRouteService_AssignBus(t *testing.T) {
 		RegisterNumber: "X123YZ",
 		AssemblyDate:   time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
 		LastRepairDate: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+		Status:         models.BusActive,
 	}
 
 	t.Run("Success", func(t *testing.T) {
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"busManager/models"
+	"busManager/repository"
+)
+
+// searchLimit caps the hits returned for the search box.
+const searchLimit = 30
+
+type SearchService struct {
+	repo repository.ISearchRepository
+}
+
+func NewSearchService(r repository.ISearchRepository) *SearchService {
+	return &SearchService{repo: r}
+}
+
+// Search looks for buses, drivers, bus stops and routes whose names or
+// numbers contain words starting with the words of query.
+func (ss SearchService) Search(query string) ([]models.SearchHit, error) {
+	hits, err := ss.repo.Search(query, searchLimit)
+	if err != nil {
+		return nil, err
+	}
+	if hits == nil {
+		hits = []models.SearchHit{}
+	}
+	return hits, nil
+}
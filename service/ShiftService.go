@@ -0,0 +1,194 @@
+package service
+
+import (
+	"busManager/apperrors"
+	"busManager/calendar"
+	"busManager/compliance"
+	"busManager/models"
+	"busManager/repository"
+	"strings"
+	"time"
+)
+
+type ShiftService struct {
+	repo       repository.IShiftRepository
+	driverRepo repository.IDriverRepository
+	routeRepo  repository.IRouteRepository
+	limits     compliance.Limits
+	uow        repository.IUnitOfWork
+	audit      auditor
+}
+
+func NewShiftService(r repository.IShiftRepository, driverRepo repository.IDriverRepository, routeRepo repository.IRouteRepository) *ShiftService {
+	return &ShiftService{repo: r, driverRepo: driverRepo, routeRepo: routeRepo, limits: compliance.DefaultLimits}
+}
+
+func (ss *ShiftService) WithUnitOfWork(uow repository.IUnitOfWork) *ShiftService {
+	ss.uow = uow
+	return ss
+}
+
+// WithAudit records every change made through the service in repo as user.
+func (ss *ShiftService) WithAudit(repo repository.IAuditRepository, user string) *ShiftService {
+	ss.audit = newAuditor(repo, user)
+	return ss
+}
+
+// WithLimits replaces compliance.DefaultLimits.
+func (ss *ShiftService) WithLimits(limits compliance.Limits) *ShiftService {
+	ss.limits = limits
+	return ss
+}
+
+// WithLocation sets the zone in which the days and weeks of the limits start.
+func (ss *ShiftService) WithLocation(loc *time.Location) *ShiftService {
+	ss.limits.Location = loc
+	return ss
+}
+
+func (ss ShiftService) transact(fn func(repos repository.Repositories) error) error {
+	return transact(ss.uow, repository.Repositories{
+		Drivers: ss.driverRepo,
+		Routes:  ss.routeRepo,
+		Shifts:  ss.repo,
+		Audit:   ss.audit.repo,
+	}, fn)
+}
+
+// shiftWindow is how far around a shift other shifts of the driver can affect
+// its compliance: the rest before and after it and the weeks it is in.
+const shiftWindow = 8 * 24 * time.Hour
+
+// violationFields maps the compliance rules to the field reported in
+// validation errors.
+var violationFields = map[string]string{
+	models.RuleDailyDriving:      "End",
+	models.RuleWeeklyDriving:     "End",
+	models.RuleRestBetweenShifts: "Start",
+	models.RuleContinuousDriving: "Breaks",
+}
+
+// Add records a shift of a live driver on a live route. The shift must not
+// overlap other shifts of the driver and must not break a working time
+// limit that is kept without it.
+func (ss ShiftService) Add(shift *models.Shift) error {
+	if err := models.ShiftRules.Validate(models.EntityShift, *shift); err != nil {
+		return err
+	}
+	return ss.transact(func(repos repository.Repositories) error {
+		if _, err := repos.Drivers.GetById(shift.DriverID); err != nil {
+			return err
+		}
+		if _, err := repos.Routes.GetById(shift.RouteID); err != nil {
+			return err
+		}
+		shifts, err := repos.Shifts.GetAllByDriverId(shift.DriverID, shift.Start.Add(-shiftWindow), shift.End.Add(shiftWindow))
+		if err != nil {
+			return err
+		}
+		for _, other := range shifts {
+			if other.Overlaps(*shift) {
+				message := "Shift overlaps another shift of the driver"
+				return apperrors.Validation(models.EntityShift, message, map[string]string{"Start": message})
+			}
+		}
+		if err := ss.checkLimits(shifts, *shift); err != nil {
+			return err
+		}
+		if err := repos.Shifts.Add(shift); err != nil {
+			return err
+		}
+		return ss.audit.record(repos, models.AuditAdd, models.EntityShift, shift.ID, shift.RouteID, nil, shift)
+	})
+}
+
+// checkLimits returns a validation error listing the violations that adding
+// shift to shifts would introduce. Violations already present are left to
+// the compliance report.
+func (ss ShiftService) checkLimits(shifts []models.Shift, shift models.Shift) error {
+	existing := map[string]bool{}
+	for _, v := range ss.limits.Check(shifts) {
+		existing[v.Message] = true
+	}
+	var messages []string
+	fields := map[string]string{}
+	for _, v := range ss.limits.Check(append(shifts, shift)) {
+		if existing[v.Message] {
+			continue
+		}
+		messages = append(messages, v.Message)
+		if field := violationFields[v.Rule]; fields[field] == "" {
+			fields[field] = v.Message
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return apperrors.Validation(models.EntityShift, strings.Join(messages, "; "), fields)
+}
+
+func (ss ShiftService) GetById(id string) (*models.Shift, error) {
+	return ss.repo.GetById(id)
+}
+
+func (ss ShiftService) GetAllByDriverId(driverId string, from, to time.Time) ([]models.Shift, error) {
+	if err := checkPeriod(from, to); err != nil {
+		return nil, err
+	}
+	return ss.repo.GetAllByDriverId(driverId, from, to)
+}
+
+func (ss ShiftService) DeleteById(id string) error {
+	return ss.transact(func(repos repository.Repositories) error {
+		shift, err := repos.Shifts.GetById(id)
+		if err != nil {
+			return err
+		}
+		if err := repos.Shifts.DeleteById(id); err != nil {
+			return err
+		}
+		return ss.audit.record(repos, models.AuditDelete, models.EntityShift, id, shift.RouteID, shift, nil)
+	})
+}
+
+func checkPeriod(from, to time.Time) error {
+	if !to.After(from) {
+		message := "Period end must be after its start"
+		return apperrors.Validation(models.EntityShift, message, map[string]string{"To": message})
+	}
+	return nil
+}
+
+// GetComplianceReport returns the shifts of the driver starting from from
+// until to with their driving time and the violations found at them. Shifts
+// before the period are taken into account for rest and weekly limits.
+func (ss ShiftService) GetComplianceReport(driverId string, from, to time.Time) (*models.ComplianceReport, error) {
+	if err := checkPeriod(from, to); err != nil {
+		return nil, err
+	}
+	driver, err := ss.driverRepo.GetById(driverId)
+	if err != nil {
+		return nil, err
+	}
+	shifts, err := ss.repo.GetAllByDriverId(driverId, calendar.Week(from, ss.limits.Location).Add(-shiftWindow), to)
+	if err != nil {
+		return nil, err
+	}
+	report := &models.ComplianceReport{Driver: *driver, From: from, To: to, Shifts: []models.Shift{}, Violations: []models.Violation{}}
+	inPeriod := map[string]bool{}
+	var driving time.Duration
+	for _, shift := range shifts {
+		if !shift.Start.Before(from) && shift.Start.Before(to) {
+			report.Shifts = append(report.Shifts, shift)
+			inPeriod[shift.ID] = true
+			driving += shift.DrivingTime()
+		}
+	}
+	report.DrivingMinutes = int(driving / time.Minute)
+	for _, v := range ss.limits.Check(shifts) {
+		if inPeriod[v.ShiftID] {
+			report.Violations = append(report.Violations, v)
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"busManager/repository"
+	"errors"
+	"testing"
+	"time"
+)
+
+// shiftAt returns a time in the week starting on Monday 2024-05-06.
+func shiftAt(day int, hour, minute int) time.Time {
+	return time.Date(2024, 5, 6, hour, minute, 0, 0, time.UTC).AddDate(0, 0, day)
+}
+
+// newMemoryShiftService returns a shift service with one driver and one route, and a constructor of their shifts.
+func newMemoryShiftService(t *testing.T) (*ShiftService, repository.Repositories, func(start, end time.Time, breaks ...models.Break) *models.Shift) {
+	repos, uow := newMemoryRepositories(t)
+	ss := NewShiftService(repos.Shifts, repos.Drivers, repos.Routes).WithUnitOfWork(uow).WithAudit(repos.Audit, "dispatcher").WithLocation(time.UTC)
+	driver := addTestDriver(t, repos, "4510 123456", "123-456-789 64", "77 12 345678")
+	route := addTestRoute(t, repos, &models.Route{Number: "12"})
+	shift := func(start, end time.Time, breaks ...models.Break) *models.Shift {
+		return &models.Shift{DriverID: driver.ID, RouteID: route.ID, Start: start, End: end, Breaks: breaks}
+	}
+	return ss, repos, shift
+}
+
+func addTestShift(t *testing.T, repos repository.Repositories, shift *models.Shift) {
+	t.Helper()
+	if err := repos.Shifts.Add(shift); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestShiftService_Add(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ss, repos, shift := newMemoryShiftService(t)
+		for _, s := range []*models.Shift{
+			shift(shiftAt(0, 6, 0), shiftAt(0, 10, 0)),
+			shift(shiftAt(1, 6, 0), shiftAt(1, 14, 0), models.Break{Start: shiftAt(1, 10, 0), End: shiftAt(1, 10, 45)}),
+		} {
+			if err := ss.Add(s); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		}
+		if entries, _ := repos.Audit.Query(models.AuditFilter{EntityType: models.EntityShift}); len(entries) != 2 {
+			t.Errorf("Expected two audit entries, got %+v", entries)
+		}
+	})
+
+	t.Run("Unknown route", func(t *testing.T) {
+		ss, _, shift := newMemoryShiftService(t)
+		s := shift(shiftAt(2, 6, 0), shiftAt(2, 10, 0))
+		s.RouteID = "missing"
+		if err := ss.Add(s); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("Overlap", func(t *testing.T) {
+		ss, repos, shift := newMemoryShiftService(t)
+		addTestShift(t, repos, shift(shiftAt(0, 6, 0), shiftAt(0, 10, 0)))
+		expectField(t, ss.Add(shift(shiftAt(0, 9, 0), shiftAt(0, 12, 0))), "Start", "Shift overlaps another shift of the driver")
+	})
+
+	t.Run("Rest between shifts", func(t *testing.T) {
+		ss, repos, shift := newMemoryShiftService(t)
+		addTestShift(t, repos, shift(shiftAt(0, 6, 0), shiftAt(0, 10, 0)))
+		expectField(t, ss.Add(shift(shiftAt(0, 14, 0), shiftAt(0, 18, 0))), "Start",
+			"Rest before the shift starting 2024-05-06 14:00 is 4h, less than 11h")
+	})
+
+	t.Run("Daily driving", func(t *testing.T) {
+		ss, _, shift := newMemoryShiftService(t)
+		s := shift(shiftAt(2, 6, 0), shiftAt(2, 16, 30),
+			models.Break{Start: shiftAt(2, 9, 0), End: shiftAt(2, 9, 30)},
+			models.Break{Start: shiftAt(2, 13, 0), End: shiftAt(2, 13, 30)})
+		expectField(t, ss.Add(s), "End", "Driving time on 2024-05-08 is 9h30m, more than 9h")
+		if shifts, _ := ss.GetAllByDriverId(s.DriverID, shiftAt(2, 0, 0), shiftAt(3, 0, 0)); len(shifts) != 0 {
+			t.Errorf("Expected the rejected shift not to be stored, got %+v", shifts)
+		}
+	})
+
+	t.Run("Existing violations do not block", func(t *testing.T) {
+		ss, repos, shift := newMemoryShiftService(t)
+		addTestShift(t, repos, shift(shiftAt(2, 6, 0), shiftAt(2, 17, 0)))
+		if err := ss.Add(shift(shiftAt(3, 8, 0), shiftAt(3, 12, 0))); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+}
+
+func TestShiftService_GetComplianceReport(t *testing.T) {
+	ss, repos, shift := newMemoryShiftService(t)
+	driving := shift(shiftAt(1, 6, 0), shiftAt(1, 14, 0), models.Break{Start: shiftAt(1, 10, 0), End: shiftAt(1, 10, 45)})
+	for _, s := range []*models.Shift{
+		driving,
+		shift(shiftAt(2, 6, 0), shiftAt(2, 17, 0)),
+		shift(shiftAt(3, 8, 0), shiftAt(3, 12, 0)),
+	} {
+		addTestShift(t, repos, s)
+	}
+
+	t.Run("Violations", func(t *testing.T) {
+		report, err := ss.GetComplianceReport(driving.DriverID, shiftAt(1, 0, 0), shiftAt(3, 0, 0))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(report.Shifts) != 2 || report.DrivingMinutes != 7*60+15+11*60 {
+			t.Errorf("Expected two shifts with 18h15m of driving, got %+v", report)
+		}
+		rules := map[string]bool{}
+		for _, v := range report.Violations {
+			rules[v.Rule] = true
+		}
+		if len(report.Violations) != 2 || !rules[models.RuleDailyDriving] || !rules[models.RuleContinuousDriving] {
+			t.Errorf("Expected daily and continuous driving violations, got %+v", report.Violations)
+		}
+	})
+
+	t.Run("Reversed period", func(t *testing.T) {
+		if _, err := ss.GetComplianceReport(driving.DriverID, shiftAt(3, 0, 0), shiftAt(1, 0, 0)); !errors.Is(err, apperrors.ErrValidation) {
+			t.Errorf("Expected validation error, got %v", err)
+		}
+	})
+
+	t.Run("Driver not found", func(t *testing.T) {
+		if _, err := ss.GetComplianceReport("missing", shiftAt(1, 0, 0), shiftAt(3, 0, 0)); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Expected not found error, got %v", err)
+		}
+	})
+}
+
+func TestShiftService_DeleteById(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ss, repos, shift := newMemoryShiftService(t)
+		s := shift(shiftAt(3, 8, 0), shiftAt(3, 12, 0))
+		if err := ss.Add(s); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := ss.DeleteById(s.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if shifts, _ := ss.GetAllByDriverId(s.DriverID, shiftAt(3, 0, 0), shiftAt(4, 0, 0)); len(shifts) != 0 {
+			t.Errorf("Expected the shift to be deleted, got %+v", shifts)
+		}
+		entries, _ := repos.Audit.Query(models.AuditFilter{EntityType: models.EntityShift, EntityId: s.ID})
+		if len(entries) != 2 || entries[0].Action != models.AuditDelete {
+			t.Errorf("Expected add and delete audit entries, got %+v", entries)
+		}
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		ss, _, _ := newMemoryShiftService(t)
+		if err := ss.DeleteById("missing"); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Expected not found error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"busManager/apperrors"
+	"busManager/database"
+	"busManager/migrations"
+	"busManager/models"
+	"busManager/repository"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// openTestDatabase returns a database file migrated to the current schema.
+func openTestDatabase(t *testing.T) (*database.Database, *migrations.Migrator) {
+	t.Helper()
+	db, err := database.Open(filepath.Join(t.TempDir(), "db.db"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	m, err := migrations.NewMigrator(db.DB())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return db, m
+}
+
+// TestRouteService_AssignDriver_Upgraded upgrades a database holding a driver
+// saved before documents were checked and licences recorded, and assigns the
+// driver once their licence has been filled in.
+func TestRouteService_AssignDriver_Upgraded(t *testing.T) {
+	db, m := openTestDatabase(t)
+	latest, _ := m.Version()
+	// back to the schema before migration 0008 reformatted the documents
+	if err := m.Down(latest - 7); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	_, err := db.DB().Exec(`INSERT INTO drivers (id, name, surname, patronymic, birth_date, passport_series, snils, license_series)
+		VALUES ('legacy', 'John', 'Doe', '', '1985-11-11 00:00:00+00:00', '4510 12345', '12345678964', '7712345678')`)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	repos := repository.NewSqliteRepositories(db.DB())
+	uow := repository.NewSqliteUnitOfWork(db.DB())
+	rs := NewRouteService(repos.Routes, repos.Drivers, repos.Buses, repos.BusStops).WithUnitOfWork(uow)
+	ds := NewDriverService(repos.Drivers).WithUnitOfWork(uow)
+	route := addTestRoute(t, repos, &models.Route{Number: "12"})
+
+	err = rs.AssignDriver(route.ID, "legacy")
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) || appErr.Fields["LicenseCategories"] == "" {
+		t.Fatalf("Expected a LicenseCategories error before the licence is recorded, got %v", err)
+	}
+
+	driver, err := ds.GetById("legacy")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if driver.Snils != "123-456-789 64" || driver.PassportSeries != "4510 12345" {
+		t.Fatalf("Expected the SNILS reformatted and the short passport kept, got %+v", driver)
+	}
+	driver.LicenseCategories = models.LicenseCategories{"B", "D"}
+	driver.LicenseIssueDate = time.Now().AddDate(-1, 0, 0)
+	driver.LicenseExpiryDate = time.Now().AddDate(9, 0, 0)
+	if err := ds.UpdateById(driver); err != nil {
+		t.Fatalf("Expected the licence to be recorded without correcting the passport, got %v", err)
+	}
+	if err := rs.AssignDriver(route.ID, "legacy"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if drivers, _ := rs.GetAllDriversById(route.ID); len(drivers) != 1 || drivers[0].ID != "legacy" {
+		t.Errorf("Expected the driver on the route, got %+v", drivers)
+	}
+}
+
+// TestMedicalCheckService_Location_Sqlite checks that days in the depot zone
+// select the checks stored in UTC, around midnight and on the day the clocks
+// go forward.
+func TestMedicalCheckService_Location_Sqlite(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("No time zone database:", err)
+	}
+	db, _ := openTestDatabase(t)
+	repos := repository.NewSqliteRepositories(db.DB())
+	ms := NewMedicalCheckService(repos.MedicalChecks, repos.Drivers).WithUnitOfWork(repository.NewSqliteUnitOfWork(db.DB())).WithLocation(berlin)
+	driver := addTestDriver(t, repos, "4510 123456", "123-456-789 64", "77 12 345678")
+	route := addTestRoute(t, repos, &models.Route{Number: "12"})
+	if err := repos.Routes.AssignDriver(route.ID, driver.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// March 31 2024 has 23 hours in Berlin: it starts at 23:00 UTC on March 30
+	// and ends at 22:00 UTC.
+	for _, check := range []*models.MedicalCheck{
+		newMedicalCheck(driver, time.Date(2024, 3, 30, 22, 59, 0, 0, time.UTC), models.MedicalPassed, 0),
+		newMedicalCheck(driver, time.Date(2024, 3, 31, 21, 59, 0, 0, time.UTC), models.MedicalFailed, 0.1),
+		newMedicalCheck(driver, time.Date(2024, 3, 31, 22, 0, 0, 0, time.UTC), models.MedicalPassed, 0),
+	} {
+		if err := ms.Add(check); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	tests := []struct {
+		day    time.Time
+		failed bool
+	}{
+		{time.Date(2024, 3, 30, 12, 0, 0, 0, berlin), false},
+		{time.Date(2024, 3, 31, 12, 0, 0, 0, berlin), true},
+		{time.Date(2024, 4, 1, 12, 0, 0, 0, berlin), false},
+	}
+	for _, tt := range tests {
+		report, err := ms.GetDailyReport(tt.day)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if tt.failed {
+			if len(report) != 1 || report[0].LastCheck == nil || report[0].LastCheck.Result != models.MedicalFailed {
+				t.Errorf("Expected a failed check on %s, got %+v", tt.day.Format(time.DateOnly), report)
+			}
+		} else if len(report) != 0 {
+			t.Errorf("Expected the driver cleared on %s, got %+v", tt.day.Format(time.DateOnly), report)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package service
+
+import "busManager/repository"
+
+// transact runs fn inside uow, or directly against repos when no unit of
+// work is configured (e.g. in tests with mock repositories).
+func transact(uow repository.IUnitOfWork, repos repository.Repositories, fn func(repos repository.Repositories) error) error {
+	if uow == nil {
+		return fn(repos)
+	}
+	return uow.Do(fn)
+}
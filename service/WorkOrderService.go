@@ -0,0 +1,315 @@
+package service
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"busManager/repository"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type WorkOrderService struct {
+	repo    repository.IWorkOrderRepository
+	busRepo repository.IBusRepository
+	uow     repository.IUnitOfWork
+	audit   auditor
+}
+
+func NewWorkOrderService(r repository.IWorkOrderRepository, busRepo repository.IBusRepository) *WorkOrderService {
+	return &WorkOrderService{repo: r, busRepo: busRepo}
+}
+
+func (ws *WorkOrderService) WithUnitOfWork(uow repository.IUnitOfWork) *WorkOrderService {
+	ws.uow = uow
+	return ws
+}
+
+// WithAudit records every change made through the service in repo as user,
+// including the changes of the bus the orders are for.
+func (ws *WorkOrderService) WithAudit(repo repository.IAuditRepository, user string) *WorkOrderService {
+	ws.audit = newAuditor(repo, user)
+	return ws
+}
+
+func (ws WorkOrderService) transact(fn func(repos repository.Repositories) error) error {
+	return transact(ws.uow, repository.Repositories{Buses: ws.busRepo, WorkOrders: ws.repo, Audit: ws.audit.repo}, fn)
+}
+
+// updateBus saves bus as changed by change, see saveBus.
+func (ws WorkOrderService) updateBus(repos repository.Repositories, bus *models.Bus, reason string, change func(bus *models.Bus)) error {
+	updated := *bus
+	change(&updated)
+	return saveBus(repos, ws.audit, bus, &updated, reason)
+}
+
+// Open records a new order of a bus that is not in the trash or
+// decommissioned and puts the bus in repair. OpenedAt defaults to now.
+func (ws WorkOrderService) Open(order *models.WorkOrder) error {
+	if order.OpenedAt.IsZero() {
+		order.OpenedAt = time.Now().UTC()
+	}
+	order.Status = models.WorkOrderOpen
+	order.ClosedAt = time.Time{}
+	order.Cost = order.LabourCost + order.Parts.Cost()
+	if err := models.WorkOrderRules.Validate(models.EntityWorkOrder, *order); err != nil {
+		return err
+	}
+	return ws.transact(func(repos repository.Repositories) error {
+		bus, err := repos.Buses.GetById(order.BusID)
+		if err != nil {
+			return err
+		}
+		if bus.Status == models.BusDecommissioned {
+			message := "A decommissioned bus cannot get work orders"
+			return apperrors.Validation(models.EntityWorkOrder, message, map[string]string{"BusID": message})
+		}
+		if order.OpenedAt.Before(bus.AssemblyDate) {
+			message := "OpenedAt must not be before the assembly date of the bus"
+			return apperrors.Validation(models.EntityWorkOrder, message, map[string]string{"OpenedAt": message})
+		}
+		if err := repos.WorkOrders.Add(order); err != nil {
+			return err
+		}
+		if err := ws.audit.record(repos, models.AuditAdd, models.EntityWorkOrder, order.ID, "", nil, order); err != nil {
+			return err
+		}
+		return ws.updateBus(repos, bus, "Work order opened: "+order.Defect, func(bus *models.Bus) { bus.Status = models.BusInRepair })
+	})
+}
+
+func (ws WorkOrderService) GetById(id string) (*models.WorkOrder, error) {
+	return ws.repo.GetById(id)
+}
+
+func (ws WorkOrderService) GetAllByBusId(busId string) ([]models.WorkOrder, error) {
+	return ws.repo.GetAllByBusId(busId)
+}
+
+func (ws WorkOrderService) GetAllActive() ([]models.WorkOrder, error) {
+	return ws.repo.GetAllActive()
+}
+
+func finishedError() error {
+	message := "Closed and cancelled work orders cannot be changed"
+	return apperrors.Validation(models.EntityWorkOrder, message, map[string]string{"Status": message})
+}
+
+// UpdateById saves the defect, mechanic, parts and labour of an active
+// order. The bus, status and times change only through Start, Close and
+// Cancel and keep their stored values.
+func (ws WorkOrderService) UpdateById(order *models.WorkOrder) error {
+	return ws.transact(func(repos repository.Repositories) error {
+		before, err := repos.WorkOrders.GetById(order.ID)
+		if err != nil {
+			return err
+		}
+		if !before.Active() {
+			return finishedError()
+		}
+		order.BusID = before.BusID
+		order.Status = before.Status
+		order.OpenedAt = before.OpenedAt
+		order.ClosedAt = before.ClosedAt
+		order.Cost = order.LabourCost + order.Parts.Cost()
+		if err := models.WorkOrderRules.Validate(models.EntityWorkOrder, *order); err != nil {
+			return err
+		}
+		if err := repos.WorkOrders.UpdateById(order); err != nil {
+			return err
+		}
+		return ws.audit.record(repos, models.AuditUpdate, models.EntityWorkOrder, order.ID, "", before, order)
+	})
+}
+
+// Start marks an open order as being worked on.
+func (ws WorkOrderService) Start(id string) (*models.WorkOrder, error) {
+	return ws.transition(id, models.WorkOrderInProgress)
+}
+
+// Close finishes the repair: the bus leaves repair unless another order of
+// it is active, and its LastRepairDate moves forward to now. An order is
+// closed only with its mechanic set.
+func (ws WorkOrderService) Close(id string) (*models.WorkOrder, error) {
+	return ws.transition(id, models.WorkOrderClosed)
+}
+
+// Cancel drops an order opened by mistake; the bus leaves repair unless
+// another order of it is active.
+func (ws WorkOrderService) Cancel(id string) (*models.WorkOrder, error) {
+	return ws.transition(id, models.WorkOrderCancelled)
+}
+
+func (ws WorkOrderService) transition(id, status string) (*models.WorkOrder, error) {
+	var updated models.WorkOrder
+	err := ws.transact(func(repos repository.Repositories) error {
+		before, err := repos.WorkOrders.GetById(id)
+		if err != nil {
+			return err
+		}
+		if !before.Active() {
+			return finishedError()
+		}
+		if !models.CanTransition(before.Status, status) {
+			message := fmt.Sprintf("A work order cannot go from %s to %s", before.Status, status)
+			return apperrors.Validation(models.EntityWorkOrder, message, map[string]string{"Status": message})
+		}
+		if status == models.WorkOrderClosed && before.Mechanic == "" {
+			message := "Mechanic is required to close a work order"
+			return apperrors.Validation(models.EntityWorkOrder, message, map[string]string{"Mechanic": message})
+		}
+		updated = *before
+		updated.Status = status
+		if !updated.Active() {
+			updated.ClosedAt = time.Now().UTC()
+		}
+		if err := repos.WorkOrders.UpdateById(&updated); err != nil {
+			return err
+		}
+		if err := ws.audit.record(repos, models.AuditUpdate, models.EntityWorkOrder, id, "", before, &updated); err != nil {
+			return err
+		}
+		if updated.Active() {
+			return nil
+		}
+		return ws.release(repos, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// release takes the bus of a finished order out of repair when none of its
+// other orders is active. The bus awaits inspection when an order was
+// closed since it went in repair; when all of them were cancelled it gets
+// back the status it had before. A bus in the trash is left as it is.
+func (ws WorkOrderService) release(repos repository.Repositories, finished models.WorkOrder) error {
+	bus, err := repos.Buses.GetById(finished.BusID)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	orders, err := repos.WorkOrders.GetAllByBusId(bus.ID)
+	if err != nil {
+		return err
+	}
+	active := false
+	for _, order := range orders {
+		active = active || (order.ID != finished.ID && order.Active())
+	}
+	status := bus.Status
+	if !active && bus.Status == models.BusInRepair {
+		repair, err := lastRepair(repos, bus.ID)
+		if err != nil {
+			return err
+		}
+		status = models.BusActive
+		if repair != nil {
+			status = repair.From
+		}
+		for _, order := range append(orders, finished) {
+			if order.Status == models.WorkOrderClosed && (repair == nil || !order.ClosedAt.Before(repair.ChangedAt)) {
+				status = models.BusAwaitingInspection
+			}
+		}
+	}
+	return ws.updateBus(repos, bus, "Work order "+finished.Status, func(bus *models.Bus) {
+		bus.Status = status
+		if finished.Status == models.WorkOrderClosed && finished.ClosedAt.After(bus.LastRepairDate) {
+			bus.LastRepairDate = finished.ClosedAt
+		}
+	})
+}
+
+// closedOrders returns the orders closed in the period with their buses,
+// including buses in the trash. Orders of purged buses have no bus.
+func (ws WorkOrderService) closedOrders(from, to time.Time) ([]models.WorkOrder, map[string]models.Bus, error) {
+	if !to.After(from) {
+		message := "Period end must be after its start"
+		return nil, nil, apperrors.Validation(models.EntityWorkOrder, message, map[string]string{"To": message})
+	}
+	orders, err := ws.repo.GetAllClosedBetween(from, to)
+	if err != nil {
+		return nil, nil, err
+	}
+	buses, err := ws.busRepo.GetAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	trashed, err := ws.busRepo.GetAllDeleted()
+	if err != nil {
+		return nil, nil, err
+	}
+	byId := map[string]models.Bus{}
+	for _, bus := range buses {
+		byId[bus.ID] = bus
+	}
+	for _, bus := range trashed {
+		byId[bus.Item.ID] = bus.Item
+	}
+	return orders, byId, nil
+}
+
+// repairCosts sums orders up under the key of their bus, most expensive
+// first.
+func repairCosts(orders []models.WorkOrder, buses map[string]models.Bus, key func(bus models.Bus) models.RepairCost) []models.RepairCost {
+	costs := map[[3]string]*models.RepairCost{}
+	for _, order := range orders {
+		line := key(buses[order.BusID])
+		k := [3]string{line.BusID, line.Brand, line.BusModel}
+		sum, ok := costs[k]
+		if !ok {
+			sum = &line
+			costs[k] = sum
+		}
+		sum.Orders++
+		sum.LabourHours += order.LabourHours
+		sum.LabourCost += order.LabourCost
+		sum.PartsCost += order.Parts.Cost()
+		sum.Cost += order.Cost
+	}
+	report := []models.RepairCost{}
+	for _, sum := range costs {
+		report = append(report, *sum)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		a, b := report[i], report[j]
+		if a.Cost != b.Cost {
+			return a.Cost > b.Cost
+		}
+		if a.Brand+a.BusModel != b.Brand+b.BusModel {
+			return a.Brand+a.BusModel < b.Brand+b.BusModel
+		}
+		return a.RegisterNumber < b.RegisterNumber
+	})
+	return report
+}
+
+// GetCostByBus sums up the orders closed from from until to per bus. The
+// orders of purged buses make up one line without a bus, here and in
+// GetCostByModel.
+func (ws WorkOrderService) GetCostByBus(from, to time.Time) ([]models.RepairCost, error) {
+	orders, buses, err := ws.closedOrders(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return repairCosts(orders, buses, func(bus models.Bus) models.RepairCost {
+		return models.RepairCost{BusID: bus.ID, RegisterNumber: bus.RegisterNumber, Brand: bus.Brand, BusModel: bus.BusModel}
+	}), nil
+}
+
+// GetCostByModel sums up the orders closed from from until to per brand and
+// model.
+func (ws WorkOrderService) GetCostByModel(from, to time.Time) ([]models.RepairCost, error) {
+	orders, buses, err := ws.closedOrders(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return repairCosts(orders, buses, func(bus models.Bus) models.RepairCost {
+		return models.RepairCost{Brand: bus.Brand, BusModel: bus.BusModel}
+	}), nil
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"busManager/apperrors"
+	"busManager/models"
+	"busManager/repository"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newMemoryWorkOrderService returns a work order service and a ЛиАЗ 5292 in service.
+func newMemoryWorkOrderService(t *testing.T) (*WorkOrderService, repository.Repositories, *repository.MemoryUnitOfWork, *models.Bus) {
+	repos, uow := newMemoryRepositories(t)
+	ws := NewWorkOrderService(repos.WorkOrders, repos.Buses).WithUnitOfWork(uow).WithAudit(repos.Audit, "mechanic")
+	return ws, repos, uow, addTestBus(t, repos, newValidBus("А123ВС77"))
+}
+
+func openTestWorkOrder(t *testing.T, ws *WorkOrderService, order *models.WorkOrder) *models.WorkOrder {
+	t.Helper()
+	if err := ws.Open(order); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return order
+}
+
+func storedBus(t *testing.T, repos repository.Repositories, id string) *models.Bus {
+	t.Helper()
+	bus, err := repos.Buses.GetById(id)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return bus
+}
+
+func TestWorkOrderService_Open(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ws, repos, uow, bus := newMemoryWorkOrderService(t)
+		order := openTestWorkOrder(t, ws, &models.WorkOrder{BusID: bus.ID, Defect: "Brakes squeal",
+			Parts: models.Parts{{Name: "Pad", Quantity: 4, UnitPrice: 1500}}})
+		if order.Status != models.WorkOrderOpen || order.OpenedAt.IsZero() || order.Cost != 6000 {
+			t.Errorf("Expected an open order costing its parts, got %+v", order)
+		}
+		if stored := storedBus(t, repos, bus.ID); stored.Status != models.BusInRepair {
+			t.Errorf("Expected the bus to be in repair, got %s", stored.Status)
+		}
+		route := addTestRoute(t, repos, &models.Route{Number: "12"})
+		rs := NewRouteService(repos.Routes, repos.Drivers, repos.Buses, repos.BusStops).WithUnitOfWork(uow)
+		expectField(t, rs.AssignBus(route.ID, bus.ID), "Status", "Bus is in repair")
+	})
+
+	t.Run("Missing defect", func(t *testing.T) {
+		ws, _, _, bus := newMemoryWorkOrderService(t)
+		expectField(t, ws.Open(&models.WorkOrder{BusID: bus.ID}), "Defect", "Defect is required")
+	})
+
+	t.Run("Bus not found", func(t *testing.T) {
+		ws, _, _, _ := newMemoryWorkOrderService(t)
+		if err := ws.Open(&models.WorkOrder{BusID: "missing", Defect: "Door"}); !errors.Is(err, apperrors.ErrNotFound) {
+			t.Errorf("Expected not found error, got %v", err)
+		}
+	})
+}
+
+func TestWorkOrderService_UpdateById(t *testing.T) {
+	t.Run("Keeps the status", func(t *testing.T) {
+		ws, _, _, bus := newMemoryWorkOrderService(t)
+		order := openTestWorkOrder(t, ws, &models.WorkOrder{BusID: bus.ID, Defect: "Brakes squeal",
+			Parts: models.Parts{{Name: "Pad", Quantity: 4, UnitPrice: 1500}}})
+		updated := *order
+		updated.Status = models.WorkOrderClosed
+		updated.LabourHours, updated.LabourCost = 2, 3000
+		if err := ws.UpdateById(&updated); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if updated.Status != models.WorkOrderOpen || updated.Cost != 9000 || updated.Version != 2 {
+			t.Errorf("Expected the status kept and the cost updated, got %+v", updated)
+		}
+	})
+
+	t.Run("Stale version", func(t *testing.T) {
+		ws, _, _, bus := newMemoryWorkOrderService(t)
+		order := openTestWorkOrder(t, ws, &models.WorkOrder{BusID: bus.ID, Defect: "Brakes squeal"})
+		updated := *order
+		updated.Mechanic = "Petrov"
+		if err := ws.UpdateById(&updated); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := ws.UpdateById(order); !errors.Is(err, apperrors.ErrConflict) {
+			t.Errorf("Expected conflict error, got %v", err)
+		}
+	})
+}
+
+func TestWorkOrderService_Start(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ws, _, _, bus := newMemoryWorkOrderService(t)
+		order := openTestWorkOrder(t, ws, &models.WorkOrder{BusID: bus.ID, Defect: "Brakes squeal"})
+		if started, err := ws.Start(order.ID); err != nil || started.Status != models.WorkOrderInProgress {
+			t.Errorf("Expected order in progress, got %+v (%v)", started, err)
+		}
+	})
+
+	t.Run("Already in progress", func(t *testing.T) {
+		ws, _, _, bus := newMemoryWorkOrderService(t)
+		order := openTestWorkOrder(t, ws, &models.WorkOrder{BusID: bus.ID, Defect: "Brakes squeal"})
+		if _, err := ws.Start(order.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		_, err := ws.Start(order.ID)
+		expectField(t, err, "Status", "A work order cannot go from in_progress to in_progress")
+	})
+}
+
+func TestWorkOrderService_Close(t *testing.T) {
+	t.Run("Mechanic required", func(t *testing.T) {
+		ws, _, _, bus := newMemoryWorkOrderService(t)
+		order := openTestWorkOrder(t, ws, &models.WorkOrder{BusID: bus.ID, Defect: "Brakes squeal"})
+		_, err := ws.Close(order.ID)
+		expectField(t, err, "Mechanic", "Mechanic is required to close a work order")
+	})
+
+	t.Run("Other order keeps the bus in repair", func(t *testing.T) {
+		ws, repos, _, bus := newMemoryWorkOrderService(t)
+		brakes := openTestWorkOrder(t, ws, &models.WorkOrder{BusID: bus.ID, Defect: "Brakes squeal", Mechanic: "Petrov"})
+		openTestWorkOrder(t, ws, &models.WorkOrder{BusID: bus.ID, Defect: "Headlight out"})
+		closed, err := ws.Close(brakes.ID)
+		if err != nil || closed.Status != models.WorkOrderClosed || closed.ClosedAt.IsZero() {
+			t.Fatalf("Expected closed order, got %+v (%v)", closed, err)
+		}
+		if stored := storedBus(t, repos, bus.ID); stored.Status != models.BusInRepair || !stored.LastRepairDate.Equal(closed.ClosedAt) {
+			t.Errorf("Expected the bus repaired but still in repair for the other order, got %+v", stored)
+		}
+		entries, _ := repos.Audit.Query(models.AuditFilter{EntityType: models.EntityWorkOrder, EntityId: brakes.ID})
+		if len(entries) != 2 || entries[0].Action != models.AuditUpdate {
+			t.Errorf("Expected open and close audit entries, got %+v", entries)
+		}
+	})
+
+	t.Run("Last order awaits inspection", func(t *testing.T) {
+		ws, repos, uow, bus := newMemoryWorkOrderService(t)
+		order := openTestWorkOrder(t, ws, &models.WorkOrder{BusID: bus.ID, Defect: "Brakes squeal", Mechanic: "Petrov"})
+		if _, err := ws.Close(order.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if stored := storedBus(t, repos, bus.ID); stored.Status != models.BusAwaitingInspection {
+			t.Errorf("Expected the repaired bus awaiting inspection, got %s", stored.Status)
+		}
+		route := addTestRoute(t, repos, &models.Route{Number: "12"})
+		rs := NewRouteService(repos.Routes, repos.Drivers, repos.Buses, repos.BusStops).WithUnitOfWork(uow)
+		expectField(t, rs.AssignBus(route.ID, bus.ID), "Status", "Bus is awaiting inspection")
+		if _, err := NewBusService(repos.Buses).WithUnitOfWork(uow).ChangeStatus(bus.ID, models.BusActive, "Inspected"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := rs.AssignBus(route.ID, bus.ID); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+}
+
+func TestWorkOrderService_Cancel(t *testing.T) {
+	t.Run("After a repair", func(t *testing.T) {
+		ws, repos, _, bus := newMemoryWorkOrderService(t)
+		brakes := openTestWorkOrder(t, ws, &models.WorkOrder{BusID: bus.ID, Defect: "Brakes squeal", Mechanic: "Petrov"})
+		lights := openTestWorkOrder(t, ws, &models.WorkOrder{BusID: bus.ID, Defect: "Headlight out"})
+		closed, err := ws.Close(brakes.ID)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, err := ws.Cancel(lights.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if stored := storedBus(t, repos, bus.ID); stored.Status != models.BusAwaitingInspection || !stored.LastRepairDate.Equal(closed.ClosedAt) {
+			t.Errorf("Expected the repaired bus awaiting inspection with its last repair kept, got %+v", stored)
+		}
+	})
+
+	t.Run("Without a repair", func(t *testing.T) {
+		ws, repos, _, bus := newMemoryWorkOrderService(t)
+		order := openTestWorkOrder(t, ws, &models.WorkOrder{BusID: bus.ID, Defect: "Headlight out"})
+		if cancelled, err := ws.Cancel(order.ID); err != nil || cancelled.Status != models.WorkOrderCancelled {
+			t.Fatalf("Expected cancelled order, got %+v (%v)", cancelled, err)
+		}
+		if stored := storedBus(t, repos, bus.ID); stored.Status != models.BusActive || !stored.LastRepairDate.Equal(bus.LastRepairDate) {
+			t.Errorf("Expected the bus back in service, got %+v", stored)
+		}
+	})
+
+	t.Run("Closed order", func(t *testing.T) {
+		ws, _, _, bus := newMemoryWorkOrderService(t)
+		order := openTestWorkOrder(t, ws, &models.WorkOrder{BusID: bus.ID, Defect: "Brakes squeal", Mechanic: "Petrov"})
+		if _, err := ws.Close(order.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		_, err := ws.Cancel(order.ID)
+		expectField(t, err, "Status", "Closed and cancelled work orders cannot be changed")
+	})
+}
+
+func TestWorkOrderService_GetAllActive(t *testing.T) {
+	ws, _, _, bus := newMemoryWorkOrderService(t)
+	brakes := openTestWorkOrder(t, ws, &models.WorkOrder{BusID: bus.ID, Defect: "Brakes squeal"})
+	lights := openTestWorkOrder(t, ws, &models.WorkOrder{BusID: bus.ID, Defect: "Headlight out"})
+	if _, err := ws.Cancel(lights.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	active, err := ws.GetAllActive()
+	if err != nil || len(active) != 1 || active[0].ID != brakes.ID {
+		t.Errorf("Expected only the open order, got %+v (%v)", active, err)
+	}
+}
+
+// addRepairCosts closes a 9000 order of which 6000 in parts on bus and a 2000 order on a ПАЗ 3205, and cancels
+// another order on bus.
+func addRepairCosts(t *testing.T, ws *WorkOrderService, repos repository.Repositories, bus *models.Bus) (other *models.Bus) {
+	t.Helper()
+	other = newValidBus("В456ОР77")
+	other.Brand, other.BusModel = "ПАЗ", "3205"
+	addTestBus(t, repos, other)
+	brakes := openTestWorkOrder(t, ws, &models.WorkOrder{BusID: bus.ID, Defect: "Brakes squeal", Mechanic: "Petrov",
+		Parts: models.Parts{{Name: "Pad", Quantity: 4, UnitPrice: 1500}}, LabourHours: 2, LabourCost: 3000})
+	lights := openTestWorkOrder(t, ws, &models.WorkOrder{BusID: bus.ID, Defect: "Headlight out", LabourCost: 500})
+	door := openTestWorkOrder(t, ws, &models.WorkOrder{BusID: other.ID, Defect: "Door", Mechanic: "Ivanov", LabourCost: 2000})
+	for _, finish := range []func() (*models.WorkOrder, error){
+		func() (*models.WorkOrder, error) { return ws.Close(brakes.ID) },
+		func() (*models.WorkOrder, error) { return ws.Cancel(lights.ID) },
+		func() (*models.WorkOrder, error) { return ws.Close(door.ID) },
+	} {
+		if _, err := finish(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	return other
+}
+
+func TestWorkOrderService_GetCostByBus(t *testing.T) {
+	ws, repos, _, bus := newMemoryWorkOrderService(t)
+	other := addRepairCosts(t, ws, repos, bus)
+	from, to := time.Now().AddDate(0, 0, -1), time.Now().AddDate(0, 0, 1)
+
+	t.Run("Success", func(t *testing.T) {
+		byBus, err := ws.GetCostByBus(from, to)
+		if err != nil || len(byBus) != 2 || byBus[0].BusID != bus.ID || byBus[0].Cost != 9000 || byBus[0].PartsCost != 6000 ||
+			byBus[0].LabourHours != 2 || byBus[1].RegisterNumber != other.RegisterNumber || byBus[1].Orders != 1 {
+			t.Errorf("Expected costs per bus without the cancelled order, got %+v (%v)", byBus, err)
+		}
+	})
+
+	t.Run("Outside the period", func(t *testing.T) {
+		if byBus, err := ws.GetCostByBus(to, to.AddDate(0, 0, 1)); err != nil || len(byBus) != 0 {
+			t.Errorf("Expected no costs, got %+v (%v)", byBus, err)
+		}
+	})
+
+	t.Run("Reversed period", func(t *testing.T) {
+		if _, err := ws.GetCostByBus(to, from); !errors.Is(err, apperrors.ErrValidation) {
+			t.Errorf("Expected validation error, got %v", err)
+		}
+	})
+
+	t.Run("Purged bus", func(t *testing.T) {
+		if err := repos.Buses.DeleteById(other.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := repos.Buses.PurgeById(other.ID); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		byBus, err := ws.GetCostByBus(from, to)
+		if err != nil || len(byBus) != 2 || byBus[1].BusID != "" || byBus[1].Cost != 2000 {
+			t.Errorf("Expected the order of the purged bus kept in the costs, got %+v (%v)", byBus, err)
+		}
+	})
+}
+
+func TestWorkOrderService_GetCostByModel(t *testing.T) {
+	ws, repos, _, bus := newMemoryWorkOrderService(t)
+	addRepairCosts(t, ws, repos, bus)
+	from, to := time.Now().AddDate(0, 0, -1), time.Now().AddDate(0, 0, 1)
+
+	t.Run("Success", func(t *testing.T) {
+		byModel, err := ws.GetCostByModel(from, to)
+		if err != nil || len(byModel) != 2 || byModel[0].Brand != "ЛиАЗ" || byModel[0].BusID != "" || byModel[1].Cost != 2000 {
+			t.Errorf("Expected costs per model, got %+v (%v)", byModel, err)
+		}
+	})
+
+	t.Run("Reversed period", func(t *testing.T) {
+		if _, err := ws.GetCostByModel(to, from); !errors.Is(err, apperrors.ErrValidation) {
+			t.Errorf("Expected validation error, got %v", err)
+		}
+	})
+}
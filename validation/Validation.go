@@ -0,0 +1,175 @@
+// Package validation checks models against rules declared next to them. A
+// model lists Rules, each checking one field or a combination of fields, and
+// Validate reports every failed field at once.
+package validation
+
+import (
+	"busManager/apperrors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Check returns why value is invalid, or "" when it is valid. The reason is
+// put after the field name, e.g. "is required".
+type Check[V any] func(value V) string
+
+// Rule checks a model and reports the result on Field.
+type Rule[T any] struct {
+	Field string
+	check func(T) string
+}
+
+// Field checks the value of a field with checks, stopping at the first that
+// fails.
+func Field[T, V any](name string, value func(T) V, checks ...Check[V]) Rule[T] {
+	return Rule[T]{Field: name, check: func(model T) string {
+		v := value(model)
+		for _, check := range checks {
+			if reason := check(v); reason != "" {
+				return name + " " + reason
+			}
+		}
+		return ""
+	}}
+}
+
+// Cross is a rule over several fields reported on field; check returns the
+// whole message.
+func Cross[T any](field string, check func(T) string) Rule[T] {
+	return Rule[T]{Field: field, check: check}
+}
+
+// Parsed checks a field with a parser such as documents.ParseSnils; the
+// error of parse is the message. Empty values pass, leaving them to Required.
+func Parsed[T, V any](name string, value func(T) string, parse func(string) (V, error)) Rule[T] {
+	return Rule[T]{Field: name, check: func(model T) string {
+		v := value(model)
+		if strings.TrimSpace(v) == "" {
+			return ""
+		}
+		if _, err := parse(v); err != nil {
+			return err.Error()
+		}
+		return ""
+	}}
+}
+
+// Rules are the rules of a model, checked in order.
+type Rules[T any] []Rule[T]
+
+// Without returns the rules that do not report on any of fields.
+func (rules Rules[T]) Without(fields ...string) Rules[T] {
+	var kept Rules[T]
+	for _, rule := range rules {
+		if !slices.Contains(fields, rule.Field) {
+			kept = append(kept, rule)
+		}
+	}
+	return kept
+}
+
+// Validate runs all rules and returns a validation error of entity whose
+// Fields hold the first message for each failed field, or nil.
+func (rules Rules[T]) Validate(entity string, model T) error {
+	fields := map[string]string{}
+	var messages []string
+	for _, rule := range rules {
+		if _, failed := fields[rule.Field]; failed {
+			continue
+		}
+		if message := rule.check(model); message != "" {
+			fields[rule.Field] = message
+			messages = append(messages, message)
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return apperrors.Validation(entity, strings.Join(messages, "; "), fields)
+}
+
+func Required() Check[string] {
+	return func(value string) string {
+		if strings.TrimSpace(value) == "" {
+			return "is required"
+		}
+		return ""
+	}
+}
+
+// MaxLength counts characters, not bytes.
+func MaxLength(max int) Check[string] {
+	return func(value string) string {
+		if utf8.RuneCountInString(value) > max {
+			return fmt.Sprintf("must be at most %d characters long", max)
+		}
+		return ""
+	}
+}
+
+// Pattern requires the whole value to match pattern; reason explains the
+// expected format.
+func Pattern(pattern, reason string) Check[string] {
+	re := regexp.MustCompile(`^(?:` + pattern + `)$`)
+	return func(value string) string {
+		if !re.MatchString(value) {
+			return reason
+		}
+		return ""
+	}
+}
+
+// Range requires min <= value <= max.
+func Range(min, max float64) Check[float64] {
+	return func(value float64) string {
+		if value < min || value > max {
+			return fmt.Sprintf("must be between %g and %g", min, max)
+		}
+		return ""
+	}
+}
+
+// IntRange is Range for whole numbers, such as amounts of money in kopecks.
+func IntRange(min, max int64) Check[int64] {
+	return func(value int64) string {
+		if value < min || value > max {
+			return fmt.Sprintf("must be between %d and %d", min, max)
+		}
+		return ""
+	}
+}
+
+func RequiredTime() Check[time.Time] {
+	return func(value time.Time) string {
+		if value.IsZero() {
+			return "is required"
+		}
+		return ""
+	}
+}
+
+// NotBefore requires the date to be on or after the given one.
+func NotBefore(year int, month time.Month, day int) Check[time.Time] {
+	limit := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return func(value time.Time) string {
+		if value.Before(limit) {
+			return "must not be before " + limit.Format(time.DateOnly)
+		}
+		return ""
+	}
+}
+
+// NotInFuture allows any time until the end of the current day, so dates
+// entered in another time zone are not rejected.
+func NotInFuture() Check[time.Time] {
+	return func(value time.Time) string {
+		if value.After(time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)) {
+			return "must not be in the future"
+		}
+		return ""
+	}
+}
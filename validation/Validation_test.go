@@ -0,0 +1,84 @@
+package validation
+
+import (
+	"busManager/apperrors"
+	"errors"
+	"testing"
+	"time"
+)
+
+type trip struct {
+	Name  string
+	Speed float64
+	Fare  int64
+	Start time.Time
+	End   time.Time
+}
+
+var tripRules = Rules[trip]{
+	Field("Name", func(t trip) string { return t.Name }, Required(), MaxLength(5), Pattern(`[a-z]+`, "must be lower case")),
+	Field("Speed", func(t trip) float64 { return t.Speed }, Range(0, 120)),
+	Field("Fare", func(t trip) int64 { return t.Fare }, IntRange(0, 10000)),
+	Field("Start", func(t trip) time.Time { return t.Start }, RequiredTime(), NotBefore(2000, time.January, 1)),
+	Cross("End", func(t trip) string {
+		if t.End.Before(t.Start) {
+			return "End must not be before Start"
+		}
+		return ""
+	}),
+}
+
+func TestRules_Validate(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	valid := trip{Name: "abc", Speed: 60, Start: start, End: start.Add(time.Hour)}
+	if err := tripRules.Validate("trip", valid); err != nil {
+		t.Fatalf("Expected valid trip, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(*trip)
+		fields map[string]string
+	}{
+		{"Required", func(t *trip) { t.Name = " " }, map[string]string{"Name": "Name is required"}},
+		{"Length in characters", func(t *trip) { t.Name = "абвгде" }, map[string]string{"Name": "Name must be at most 5 characters long"}},
+		{"Pattern", func(t *trip) { t.Name = "ABC" }, map[string]string{"Name": "Name must be lower case"}},
+		{"Range", func(t *trip) { t.Speed = -1 }, map[string]string{"Speed": "Speed must be between 0 and 120"}},
+		{"Whole number range", func(t *trip) { t.Fare = 10001 }, map[string]string{"Fare": "Fare must be between 0 and 10000"}},
+		{"Cross field", func(t *trip) { t.End = t.Start.Add(-time.Hour) }, map[string]string{"End": "End must not be before Start"}},
+		{"Several fields", func(t *trip) { t.Name = ""; t.Start = time.Time{} }, map[string]string{
+			"Name": "Name is required", "Start": "Start is required"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalid := valid
+			tt.change(&invalid)
+			err := tripRules.Validate("trip", invalid)
+			var appErr *apperrors.Error
+			if !errors.As(err, &appErr) || appErr.Code != apperrors.CodeValidation || appErr.Entity != "trip" {
+				t.Fatalf("Expected validation error, got %v", err)
+			}
+			if len(appErr.Fields) != len(tt.fields) {
+				t.Errorf("Expected fields %v, got %v", tt.fields, appErr.Fields)
+			}
+			for field, message := range tt.fields {
+				if appErr.Fields[field] != message {
+					t.Errorf("Expected %s: %q, got %q", field, message, appErr.Fields[field])
+				}
+			}
+		})
+	}
+}
+
+func TestRules_Without(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	invalid := trip{Name: "ABC", Speed: -1, Start: start, End: start.Add(-time.Hour)}
+	err := tripRules.Without("Name", "End").Validate("trip", invalid)
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) || len(appErr.Fields) != 1 || appErr.Fields["Speed"] == "" {
+		t.Errorf("Expected only the Speed error, got %v", err)
+	}
+	if len(tripRules.Without("Name")) != len(tripRules)-1 || len(tripRules.Without()) != len(tripRules) {
+		t.Errorf("Expected Without to drop only the rules of the given fields")
+	}
+}